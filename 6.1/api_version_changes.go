@@ -0,0 +1,189 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+// apiFieldChanges lists the attributes of a resource that were renamed or
+// removed between an older version of the vTM REST API and this one. Names
+// are given in their flattened form, as used by the older providers.
+type apiFieldChanges struct {
+	renamed map[string]string
+	removed []string
+}
+
+// apiVersionChanges holds the attribute changes from each older API version
+// that has its own provider to this one, keyed by API version and then by
+// resource type.
+var apiVersionChanges = map[string]map[string]apiFieldChanges{
+	"4.0": {
+		"vtm_action": {
+			renamed: map[string]string{
+				"log_from": "email_from",
+			},
+		},
+		"vtm_global_settings": {
+			renamed: map[string]string{
+				"admin_support_tls11":                "admin_support_tls1_1",
+				"admin_support_tls12":                "admin_support_tls1_2",
+				"ospfv2_router_dead_interval":        "ospfv2_dead_interval",
+				"ssl_ssl3_allow_rehandshake":         "ssl_allow_rehandshake",
+				"ssl_ssl3_diffie_hellman_key_length": "ssl_diffie_hellman_modulus_size",
+				"ssl_ssl3_min_rehandshake_interval":  "ssl_min_rehandshake_interval",
+			},
+			removed: []string{
+				"admin_support_ssl2",
+				"appliance_manage_ncipher",
+				"appliance_nethsm_esn",
+				"appliance_nethsm_hash",
+				"appliance_nethsm_ip",
+				"appliance_nethsm_ncipher_rfs",
+				"data_plane_acceleration_cores",
+				"data_plane_acceleration_mode",
+				"data_plane_acceleration_tcp_delay_ack",
+				"data_plane_acceleration_tcp_win_scale",
+				"fault_tolerance_l4accel_child_timeout",
+				"fault_tolerance_l4accel_sync_port",
+				"l4accel_max_concurrent_connections",
+				"source_nat_ip_limit",
+				"source_nat_ip_local_port_range_high",
+				"source_nat_shared_pool_size",
+				"ssl_ssl3_ciphers",
+				"ssl_support_ssl2",
+				"trafficscript_array_elements",
+			},
+		},
+		"vtm_pool": {
+			renamed: map[string]string{
+				"ssl_ssl_support_ssl3":   "ssl_support_ssl3",
+				"ssl_ssl_support_tls1":   "ssl_support_tls1",
+				"ssl_ssl_support_tls1_1": "ssl_support_tls1_1",
+				"ssl_ssl_support_tls1_2": "ssl_support_tls1_2",
+			},
+			removed: []string{
+				"l4accel_snat",
+				"ssl_ssl_ciphers",
+				"ssl_ssl_support_ssl2",
+			},
+		},
+		"vtm_protection": {
+			renamed: map[string]string{
+				"connection_limiting_max_10_connections":  "concurrent_connections_max_10_connections",
+				"connection_limiting_max_1_connections":   "concurrent_connections_max_1_connections",
+				"connection_limiting_max_connection_rate": "connection_rate_max_connection_rate",
+				"connection_limiting_min_connections":     "concurrent_connections_min_connections",
+				"connection_limiting_rate_timer":          "connection_rate_rate_timer",
+				"per_process_connection_count":            "concurrent_connections_per_process_connection_count",
+			},
+		},
+		"vtm_traffic_ip_group": {
+			removed: []string{
+				"backend_traffic_ips",
+			},
+		},
+		"vtm_traffic_manager": {
+			removed: []string{
+				"appliance_force_hardware",
+				"appliance_managedpa",
+				"appliance_shim_client_id",
+				"appliance_shim_client_key",
+				"appliance_shim_enabled",
+				"appliance_shim_ips",
+				"appliance_shim_load_balance",
+				"appliance_shim_log_level",
+				"appliance_shim_mode",
+				"appliance_shim_portal_url",
+				"appliance_shim_proxy_host",
+				"appliance_shim_proxy_port",
+				"fault_tolerance_lss_dedicated_ips",
+			},
+		},
+		"vtm_virtual_server": {
+			renamed: map[string]string{
+				"add_cluster_ip":             "http_add_cluster_ip",
+				"add_x_forwarded_for":        "http_add_x_forwarded_for",
+				"add_x_forwarded_proto":      "http_add_x_forwarded_proto",
+				"autodetect_upgrade_headers": "http_autodetect_upgrade_headers",
+				"close_with_rst":             "tcp_close_with_rst",
+				"completionrules":            "completion_rules",
+				"so_nagle":                   "tcp_nagle",
+				"ssl_ssl_support_ssl3":       "ssl_support_ssl3",
+				"ssl_ssl_support_tls1":       "ssl_support_tls1",
+				"ssl_ssl_support_tls1_1":     "ssl_support_tls1_1",
+				"ssl_ssl_support_tls1_2":     "ssl_support_tls1_2",
+				"strip_x_forwarded_proto":    "http_strip_x_forwarded_proto",
+			},
+			removed: []string{
+				"bypass_data_plane_acceleration",
+				"l4accel_rst_on_service_failure",
+				"l4accel_service_ip_snat",
+				"l4accel_state_sync",
+				"l4accel_tcp_msl",
+				"l4accel_timeout",
+				"l4accel_udp_count_requests",
+				"ssl_prefer_sslv3",
+				"ssl_ssl_ciphers",
+				"ssl_ssl_support_ssl2",
+				"udp_end_transaction",
+			},
+		},
+	},
+	"5.2": {
+		"vtm_global_settings": {
+			removed: []string{
+				"data_plane_acceleration_cores",
+				"data_plane_acceleration_mode",
+				"data_plane_acceleration_tcp_delay_ack",
+				"data_plane_acceleration_tcp_win_scale",
+				"fault_tolerance_l4accel_child_timeout",
+				"fault_tolerance_l4accel_sync_port",
+				"l4accel_max_concurrent_connections",
+				"source_nat_ip_limit",
+				"source_nat_ip_local_port_range_high",
+				"source_nat_shared_pool_size",
+			},
+		},
+		"vtm_pool": {
+			removed: []string{
+				"l4accel_snat",
+			},
+		},
+		"vtm_traffic_ip_group": {
+			removed: []string{
+				"backend_traffic_ips",
+			},
+		},
+		"vtm_traffic_manager": {
+			removed: []string{
+				"appliance_managedpa",
+				"fault_tolerance_lss_dedicated_ips",
+			},
+		},
+		"vtm_virtual_server": {
+			removed: []string{
+				"bypass_data_plane_acceleration",
+				"l4accel_rst_on_service_failure",
+				"l4accel_service_ip_snat",
+				"l4accel_state_sync",
+				"l4accel_tcp_msl",
+				"l4accel_timeout",
+				"l4accel_udp_count_requests",
+				"udp_udp_end_transaction",
+			},
+		},
+	},
+	// Apart from the nested sections, the 6.0 attributes are unchanged.
+	"6.0": {},
+}
+
+// apiRenamedFields returns the renames for a resource type across all older
+// API versions. An attribute name is never reused for a different field, so
+// the renames can be applied without knowing which version wrote the state.
+func apiRenamedFields(resourceType string) map[string]string {
+	renamed := make(map[string]string)
+	for _, changes := range apiVersionChanges {
+		for oldName, newName := range changes[resourceType].renamed {
+			renamed[oldName] = newName
+		}
+	}
+	return renamed
+}
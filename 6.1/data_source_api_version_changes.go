@@ -0,0 +1,96 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceApiVersionChanges() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceApiVersionChangesRead,
+
+		Schema: map[string]*schema.Schema{
+
+			// The API version of the provider that the configuration is
+			//  being moved from.
+			"from_version": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"4.0", "5.2", "6.0"}, false),
+			},
+
+			// Only report changes to this resource type, for example
+			//  "vtm_virtual_server".
+			"resource_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Fields that no longer exist in this API version, as
+			//  "<resource type>.<field>". Their values are dropped from the
+			//  state when it is migrated.
+			"removed_fields": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			// Fields that have a new name or have moved into a nested
+			//  block, mapping "<resource type>.<old field>" to
+			//  "<resource type>.<new field>".
+			"renamed_fields": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceApiVersionChangesRead(d *schema.ResourceData, tm interface{}) error {
+	fromVersion := d.Get("from_version").(string)
+	resourceType := d.Get("resource_type").(string)
+
+	removedFields := []string{}
+	renamedFields := make(map[string]interface{})
+	for changedType, changes := range apiVersionChanges[fromVersion] {
+		if resourceType != "" && changedType != resourceType {
+			continue
+		}
+		for _, field := range changes.removed {
+			removedFields = append(removedFields, changedType+"."+field)
+		}
+		for oldField, newField := range changes.renamed {
+			renamedFields[changedType+"."+oldField] = changedType + "." + nestedFieldPath(changedType, newField)
+		}
+	}
+
+	// Fields of the nested sections kept their flattened names in every
+	// older provider.
+	for sectionedType, resource := range sectionedResources {
+		if resourceType != "" && sectionedType != resourceType {
+			continue
+		}
+		resourceSchema := resource.schema()
+		for prefix, block := range resource.sections {
+			for field := range resourceSchema[block].Elem.(*schema.Resource).Schema {
+				renamedFields[sectionedType+"."+prefix+"_"+field] = sectionedType + "." + block + "." + field
+			}
+		}
+	}
+	sort.Strings(removedFields)
+
+	d.Set("removed_fields", removedFields)
+	d.Set("renamed_fields", renamedFields)
+	if resourceType != "" {
+		d.SetId("api_version_changes_" + fromVersion + "_" + resourceType)
+	} else {
+		d.SetId("api_version_changes_" + fromVersion)
+	}
+	return nil
+}
@@ -12,6 +12,12 @@ func dataSourceGlobalSettings() *schema.Resource {
 	}
 }
 
+// dataSourceGlobalSettingsRead offers the nested section blocks as well as the
+// deprecated flattened attributes that they replaced.
 func dataSourceGlobalSettingsRead(d *schema.ResourceData, tm interface{}) error {
-	return resourceGlobalSettingsRead(d, tm)
+	if err := resourceGlobalSettingsRead(d, tm); err != nil {
+		return err
+	}
+	setSectionsFromAliases("vtm_global_settings", d)
+	return nil
 }
@@ -0,0 +1,62 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceConfigObject() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConfigObjectRead,
+
+		Schema: map[string]*schema.Schema{
+
+			// The configuration type, as it appears in the REST URI after
+			//  "/config/active/", for example "pools" or "ssl/server_keys".
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// JSON representation of all of the object's properties
+			"properties_json": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceConfigObjectRead(d *schema.ResourceData, tm interface{}) error {
+	objectType := d.Get("type").(string)
+	objectName := d.Get("name").(string)
+	client, clientErr := getRestClient(tm)
+	if clientErr != nil {
+		return clientErr
+	}
+	document, err := client.getJson(configPath(objectType, objectName))
+	if err != nil {
+		d.SetId("")
+		return fmt.Errorf("Failed to read vtm_config_object '%v/%v': %v", objectType, objectName, err.ErrorText)
+	}
+	propertiesJson, jsonErr := json.Marshal(document["properties"])
+	if jsonErr != nil {
+		return fmt.Errorf("Failed to read vtm_config_object '%v/%v': %v", objectType, objectName, jsonErr)
+	}
+	d.Set("properties_json", string(propertiesJson))
+	d.SetId(objectType + "/" + objectName)
+	return nil
+}
@@ -12,6 +12,12 @@ func dataSourcePool() *schema.Resource {
 	}
 }
 
+// dataSourcePoolRead offers the nested section blocks as well as the
+// deprecated flattened attributes that they replaced.
 func dataSourcePoolRead(d *schema.ResourceData, tm interface{}) error {
-	return resourcePoolRead(d, tm)
+	if err := resourcePoolRead(d, tm); err != nil {
+		return err
+	}
+	setSectionsFromAliases("vtm_pool", d)
+	return nil
 }
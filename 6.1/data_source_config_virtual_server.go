@@ -12,6 +12,12 @@ func dataSourceVirtualServer() *schema.Resource {
 	}
 }
 
+// dataSourceVirtualServerRead offers the nested section blocks as well as the
+// deprecated flattened attributes that they replaced.
 func dataSourceVirtualServerRead(d *schema.ResourceData, tm interface{}) error {
-	return resourceVirtualServerRead(d, tm)
+	if err := resourceVirtualServerRead(d, tm); err != nil {
+		return err
+	}
+	setSectionsFromAliases("vtm_virtual_server", d)
+	return nil
 }
@@ -0,0 +1,249 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func dataSourceRuleSimulation() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRuleSimulationRead,
+
+		Schema: map[string]*schema.Schema{
+
+			// The TrafficScript request rule to run.
+			"content": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateTrafficScript,
+			},
+
+			// The content of rules imported by the rule, by name. Rules
+			//  that are not listed are read from the traffic manager.
+			"imports": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+			},
+
+			// The mocked HTTP request to run the rule against.
+			"request": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+
+						// The request method.
+						"method": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "GET",
+						},
+
+						// The request path, without the query string.
+						"path": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "/",
+						},
+
+						// The query string, without the leading "?".
+						"query_string": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						// The HTTP version of the request.
+						"version": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "HTTP/1.1",
+						},
+
+						// The request headers.
+						"headers": &schema.Schema{
+							Type:     schema.TypeMap,
+							Optional: true,
+						},
+
+						// The request body.
+						"body": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						// The address of the client.
+						"remote_ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "192.0.2.1",
+						},
+
+						// The port of the client.
+						"remote_port": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(1, 65535),
+							Default:      40000,
+						},
+
+						// The address the request was received on.
+						"local_ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "192.0.2.100",
+						},
+
+						// The port the request was received on.
+						"local_port": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(1, 65535),
+							Default:      80,
+						},
+					},
+				},
+			},
+
+			// The calls the rule made that change the request or how it
+			//  is handled, in order, such as 'http.setHeader("X-A", "1")'.
+			"actions": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			// The pool chosen by the rule, if any.
+			"pool": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The request headers after the rule has run.
+			"request_headers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			// The request path and query string after the rule has run.
+			"request_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The location the rule redirected the request to, if any.
+			"redirect_location": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The status code of a response sent by the rule, or 0.
+			"response_code": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			// The headers of a response sent or changed by the rule.
+			"response_headers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			// The body of a response sent by the rule.
+			"response_body": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// Messages logged by the rule, as "<LEVEL>: <message>".
+			"logs": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceRuleSimulationRead(d *schema.ResourceData, tm interface{}) error {
+	program, err := parseTrafficScript(d.Get("content").(string))
+	if err != nil {
+		return fmt.Errorf("Failed to parse rule: %v", err)
+	}
+
+	importContent := d.Get("imports").(map[string]interface{})
+	imports := map[string]*tsNode{}
+	for _, statement := range program.children {
+		if statement.kind != "import" {
+			continue
+		}
+		content, ok := importContent[statement.value].(string)
+		if !ok {
+			rule, getErr := tm.(*vtm.VirtualTrafficManager).GetRule(statement.value)
+			if getErr != nil {
+				return fmt.Errorf("Failed to read vtm_rule '%v': %v", statement.value, getErr.ErrorText)
+			}
+			content = rule
+		}
+		imported, err := parseTrafficScript(content)
+		if err != nil {
+			return fmt.Errorf("Failed to parse imported rule '%v': %v", statement.value, err)
+		}
+		imports[statement.value] = imported
+	}
+
+	sim := &tsSimulation{
+		method:     "GET",
+		path:       "/",
+		version:    "HTTP/1.1",
+		remoteIP:   "192.0.2.1",
+		remotePort: 40000,
+		localIP:    "192.0.2.100",
+		localPort:  80,
+	}
+	if requests := d.Get("request").([]interface{}); len(requests) > 0 && requests[0] != nil {
+		request := requests[0].(map[string]interface{})
+		sim.method = request["method"].(string)
+		sim.path = request["path"].(string)
+		sim.query = request["query_string"].(string)
+		sim.version = request["version"].(string)
+		sim.body = request["body"].(string)
+		sim.remoteIP = request["remote_ip"].(string)
+		sim.remotePort = request["remote_port"].(int)
+		sim.localIP = request["local_ip"].(string)
+		sim.localPort = request["local_port"].(int)
+		headers := map[string]string{}
+		for name, value := range request["headers"].(map[string]interface{}) {
+			headers[name] = value.(string)
+		}
+		for _, name := range sortedStringMapKeys(headers) {
+			sim.headers = append(sim.headers, [2]string{name, headers[name]})
+		}
+	}
+
+	if err := runTrafficScript(program, imports, sim); err != nil {
+		return fmt.Errorf("Failed to simulate rule: %v", err)
+	}
+
+	requestURL := sim.path
+	if sim.query != "" {
+		requestURL += "?" + sim.query
+	}
+	d.Set("actions", sim.actions)
+	d.Set("pool", sim.pool)
+	d.Set("request_headers", tsHeaderMap(sim.headers))
+	d.Set("request_url", requestURL)
+	d.Set("redirect_location", sim.redirect)
+	d.Set("response_code", sim.responseCode)
+	d.Set("response_headers", tsHeaderMap(sim.responseHeaders))
+	d.Set("response_body", sim.responseBody)
+	d.Set("logs", sim.logs)
+	d.SetId("rule_simulation")
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func dataSourceSslCertificateAudit() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSslCertificateAuditRead,
+
+		Schema: map[string]*schema.Schema{
+
+			// The virtual servers to audit. By default every virtual
+			//  server with "ssl_decrypt" enabled is audited.
+			"virtual_servers": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			// Certificates that expire within this many days are
+			//  reported as expiring.
+			"expiry_warning_days": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Default:      30,
+			},
+
+			// The problems found with each audited virtual server.
+			"results": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+
+						// The name of the virtual server.
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						// Host mappings whose certificate does not cover
+						//  the host, as "<host> (<certificate>)".
+						"mismatched_hosts": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						// Certificates that have expired.
+						"expired_certificates": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						// Certificates that expire within
+						//  "expiry_warning_days".
+						"expiring_certificates": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						// Referenced vtm_ssl_server_key objects that do not
+						//  exist.
+						"missing_certificates": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						// Referenced vtm_ssl_server_key objects whose public
+						//  certificate cannot be parsed.
+						"invalid_certificates": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						// OCSP issuers that are not vtm_ssl_ca objects.
+						"missing_ocsp_issuers": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
+			// A description of every problem found, prefixed with the
+			//  name of the virtual server.
+			"problems": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceSslCertificateAuditRead(d *schema.ResourceData, tm interface{}) error {
+	vtmClient := tm.(*vtm.VirtualTrafficManager)
+
+	names := expandStringList(d.Get("virtual_servers").([]interface{}))
+	auditAll := len(names) == 0
+	if auditAll {
+		objectList, err := vtmClient.ListVirtualServers()
+		if err != nil {
+			return fmt.Errorf("Failed to read vtm_virtual_server_list: %v", err.ErrorText)
+		}
+		names = *objectList
+	}
+
+	caList, caErr := vtmClient.ListSslCas()
+	if caErr != nil {
+		return fmt.Errorf("Failed to read vtm_ssl_ca_list: %v", caErr.ErrorText)
+	}
+	cas := make(map[string]bool)
+	for _, ca := range *caList {
+		cas[ca] = true
+	}
+
+	now := time.Now()
+	warning := time.Duration(d.Get("expiry_warning_days").(int)) * 24 * time.Hour
+	certificates := make(map[string]*x509.Certificate)
+	loaded := make(map[string]bool)
+	results := make([]map[string]interface{}, 0, len(names))
+	problems := []string{}
+	for _, name := range names {
+		object, err := vtmClient.GetVirtualServer(name)
+		if err != nil {
+			return fmt.Errorf("Failed to read vtm_virtual_server '%v': %v", name, err.ErrorText)
+		}
+		if auditAll && !*object.Basic.SslDecrypt {
+			continue
+		}
+
+		vs := sslAuditVirtualServer{
+			name:            name,
+			defaultCert:     string(*object.Ssl.ServerCertDefault),
+			altCertificates: []string(*object.Ssl.ServerCertAltCertificates),
+		}
+		for _, item := range *object.Ssl.ServerCertHostMapping {
+			mapping := sslAuditHostMapping{host: string(*item.Host)}
+			if item.Certificate != nil && *item.Certificate != "" {
+				mapping.certificates = append(mapping.certificates, string(*item.Certificate))
+			}
+			if item.AltCertificates != nil {
+				mapping.certificates = append(mapping.certificates, []string(*item.AltCertificates)...)
+			}
+			vs.hostMappings = append(vs.hostMappings, mapping)
+		}
+		for _, item := range *object.Ssl.OcspIssuers {
+			vs.ocspIssuers = append(vs.ocspIssuers, string(*item.Issuer))
+		}
+
+		for _, keyName := range vs.certificateNames() {
+			if loaded[keyName] {
+				continue
+			}
+			loaded[keyName] = true
+			key, err := vtmClient.GetSslServerKey(keyName)
+			if err != nil {
+				if err.ErrorId == "resource.not_found" {
+					continue
+				}
+				return fmt.Errorf("Failed to read vtm_ssl_server_key '%v': %v", keyName, err.ErrorText)
+			}
+			certificates[keyName] = nil
+			if chain, err := parseSslCertificates(string(*key.Basic.Public)); err == nil {
+				certificates[keyName] = chain[0]
+			}
+		}
+
+		result := auditVirtualServerCertificates(vs, certificates, cas, now, warning)
+		results = append(results, map[string]interface{}{
+			"name":                  result.name,
+			"mismatched_hosts":      result.mismatchedHosts,
+			"expired_certificates":  result.expiredCertificates,
+			"expiring_certificates": result.expiringCertificates,
+			"missing_certificates":  result.missingCertificates,
+			"invalid_certificates":  result.invalidCertificates,
+			"missing_ocsp_issuers":  result.missingOcspIssuers,
+		})
+		problems = append(problems, result.problems()...)
+	}
+
+	d.Set("results", results)
+	d.Set("problems", problems)
+	d.SetId("ssl_certificate_audit")
+	return nil
+}
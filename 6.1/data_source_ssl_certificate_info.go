@@ -0,0 +1,218 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func dataSourceSslCertificateInfo() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSslCertificateInfoRead,
+
+		Schema: map[string]*schema.Schema{
+
+			// The name of a vtm_ssl_server_key whose public certificate
+			//  should be inspected.
+			"ssl_server_key": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ssl_client_key", "ssl_ca", "certificate"},
+			},
+
+			// The name of a vtm_ssl_client_key whose public certificate
+			//  should be inspected.
+			"ssl_client_key": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ssl_server_key", "ssl_ca", "certificate"},
+			},
+
+			// The name of a vtm_ssl_ca whose certificate should be
+			//  inspected.
+			"ssl_ca": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ssl_server_key", "ssl_client_key", "certificate"},
+			},
+
+			// A PEM encoded certificate to inspect, optionally followed by
+			//  its intermediates.
+			"certificate": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ssl_server_key", "ssl_client_key", "ssl_ca"},
+			},
+
+			// The names of vtm_ssl_ca objects that the certificate chain
+			//  is verified against.
+			"trusted_cas": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			// The subject of the certificate, for example
+			//  "CN=www.example.com, O=Example Ltd, C=GB".
+			"subject": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The issuer of the certificate.
+			"issuer": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The DNS names, IP addresses and email addresses in the
+			//  certificate's subject alternative names.
+			"sans": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			// The serial number, as colon separated hex.
+			"serial": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The time from which the certificate is valid, in RFC 3339
+			//  format.
+			"not_before": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The time at which the certificate expires, in RFC 3339
+			//  format.
+			"not_after": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The type of the certificate's public key, "rsa" or "ecdsa".
+			"key_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The size of the public key in bits.
+			"key_size": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			// The SHA-256 fingerprint of the certificate, as colon
+			//  separated hex.
+			"sha256_fingerprint": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The number of certificates in the PEM, including the
+			//  certificate itself.
+			"chain_length": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			// Whether the certificate chain verifies against the
+			//  "trusted_cas". Always false when no CAs are named.
+			"chain_valid": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			// Why the certificate chain failed to verify.
+			"chain_error": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceSslCertificateInfoRead(d *schema.ResourceData, tm interface{}) error {
+	certificatePem, source, err := getSslCertificatePem(d, tm.(*vtm.VirtualTrafficManager))
+	if err != nil {
+		return err
+	}
+	chain, err := parseSslCertificates(certificatePem)
+	if err != nil {
+		return fmt.Errorf("Failed to parse certificate of %s: %v", source, err)
+	}
+	info := describeSslCertificate(chain[0])
+
+	d.Set("subject", info.subject)
+	d.Set("issuer", info.issuer)
+	d.Set("sans", info.sans)
+	d.Set("serial", info.serial)
+	d.Set("not_before", info.notBefore.Format(time.RFC3339))
+	d.Set("not_after", info.notAfter.Format(time.RFC3339))
+	d.Set("key_type", info.keyType)
+	d.Set("key_size", info.keySize)
+	d.Set("sha256_fingerprint", info.sha256Fingerprint)
+	d.Set("chain_length", len(chain))
+
+	chainValid := false
+	chainError := "no trusted_cas were given"
+	if caNames := d.Get("trusted_cas").([]interface{}); len(caNames) > 0 {
+		cas := make([]string, 0, len(caNames))
+		for _, caName := range caNames {
+			ca, err := tm.(*vtm.VirtualTrafficManager).GetSslCa(caName.(string))
+			if err != nil {
+				return fmt.Errorf("Failed to read vtm_ssl_ca '%v': %v", caName, err.ErrorText)
+			}
+			cas = append(cas, ca)
+		}
+		chainError = ""
+		if err := verifySslCertificateChain(chain, cas, time.Now()); err != nil {
+			chainError = err.Error()
+		} else {
+			chainValid = true
+		}
+	}
+	d.Set("chain_valid", chainValid)
+	d.Set("chain_error", chainError)
+
+	d.SetId(info.sha256Fingerprint)
+	return nil
+}
+
+// getSslCertificatePem returns the PEM to inspect, and a description of
+// where it came from for error messages.
+func getSslCertificatePem(d *schema.ResourceData, tm *vtm.VirtualTrafficManager) (string, string, error) {
+	if name, ok := d.GetOk("ssl_server_key"); ok {
+		object, err := tm.GetSslServerKey(name.(string))
+		if err != nil {
+			return "", "", fmt.Errorf("Failed to read vtm_ssl_server_key '%v': %v", name, err.ErrorText)
+		}
+		return string(*object.Basic.Public), fmt.Sprintf("vtm_ssl_server_key '%v'", name), nil
+	}
+	if name, ok := d.GetOk("ssl_client_key"); ok {
+		object, err := tm.GetSslClientKey(name.(string))
+		if err != nil {
+			return "", "", fmt.Errorf("Failed to read vtm_ssl_client_key '%v': %v", name, err.ErrorText)
+		}
+		return string(*object.Basic.Public), fmt.Sprintf("vtm_ssl_client_key '%v'", name), nil
+	}
+	if name, ok := d.GetOk("ssl_ca"); ok {
+		object, err := tm.GetSslCa(name.(string))
+		if err != nil {
+			return "", "", fmt.Errorf("Failed to read vtm_ssl_ca '%v': %v", name, err.ErrorText)
+		}
+		return object, fmt.Sprintf("vtm_ssl_ca '%v'", name), nil
+	}
+	if certificate, ok := d.GetOk("certificate"); ok {
+		return certificate.(string), "certificate", nil
+	}
+	return "", "", fmt.Errorf("One of ssl_server_key, ssl_client_key, ssl_ca or certificate must be set")
+}
@@ -0,0 +1,765 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dnsRecordTypes are the resource record types accepted in a zone file, in
+// addition to the generic "TYPE<n>" form of RFC 3597.
+var dnsRecordTypes = map[string]bool{
+	"A": true, "AAAA": true, "AFSDB": true, "CAA": true, "CDNSKEY": true,
+	"CDS": true, "CERT": true, "CNAME": true, "DHCID": true, "DNAME": true,
+	"DNSKEY": true, "DS": true, "HINFO": true, "LOC": true, "MX": true,
+	"NAPTR": true, "NS": true, "NSEC": true, "NSEC3": true,
+	"NSEC3PARAM": true, "PTR": true, "RP": true, "RRSIG": true, "SOA": true,
+	"SPF": true, "SRV": true, "SSHFP": true, "TLSA": true, "TXT": true,
+	"URI": true,
+}
+
+var dnsClasses = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+var (
+	dnsGenericTypePattern = regexp.MustCompile(`^TYPE[0-9]+$`)
+	dnsTtlPattern         = regexp.MustCompile(`^([0-9]+[smhdwSMHDW]?)+$`)
+	dnsTtlPartPattern     = regexp.MustCompile(`([0-9]+)([smhdwSMHDW]?)`)
+	dnsCaaTagPattern      = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+)
+
+// dnsRecord is a resource record in a zone file. The owner name and rdata
+// are kept as written, so that the file can be rendered again unchanged.
+type dnsRecord struct {
+	line   int
+	name   string
+	fqdn   string
+	ttl    int
+	hasTtl bool
+	class  string
+	rtype  string
+	rdata  []string
+
+	// source is the record as parsed, with its text in the zone file and
+	// the offsets of its rdata within that text. It is nil for records
+	// added to the zone.
+	source *dnsRecordSource
+}
+
+type dnsRecordSource struct {
+	record       dnsRecord
+	text         string
+	rdataOffsets [][2]int
+}
+
+// dnsZoneEntry is a directive or a record, in the order they appear in the
+// zone file. text is the entry as written, and leading the blank and comment
+// lines before it, so that the entries that are not changed are rendered as
+// they were.
+type dnsZoneEntry struct {
+	directive string
+	args      []string
+	record    *dnsRecord
+	text      string
+	leading   string
+}
+
+// dnsZone is a parsed zone file. origin is the $ORIGIN in effect at the end
+// of the file, which names added to the zone are relative to, and trailing
+// the blank and comment lines after the last entry. warnings describe the
+// directives that are passed through to the traffic manager unchecked.
+type dnsZone struct {
+	entries  []dnsZoneEntry
+	origin   string
+	trailing string
+	warnings []string
+}
+
+// dnsZoneLine is a logical line of a zone file, with any parentheses
+// joining physical lines removed. start and end are the offsets of the
+// line's text, and offsets those of its tokens.
+type dnsZoneLine struct {
+	line         int
+	leadingSpace bool
+	tokens       []string
+	start, end   int
+	offsets      [][2]int
+}
+
+// lexZoneFile splits a zone file into logical lines of tokens, removing
+// comments. Quoted strings are kept as single tokens with their quotes.
+func lexZoneFile(content string) ([]dnsZoneLine, error) {
+	var lines []dnsZoneLine
+	var current dnsZoneLine
+	var token bytes.Buffer
+	inToken := false
+	tokenStart, tokenEnd := 0, 0
+	depth, depthLine := 0, 0
+	lineNumber := 1
+	atLineStart := true
+
+	endToken := func() {
+		if inToken {
+			current.tokens = append(current.tokens, token.String())
+			current.offsets = append(current.offsets, [2]int{tokenStart, tokenEnd})
+			token.Reset()
+			inToken = false
+		}
+	}
+	endLine := func(end int) {
+		endToken()
+		if len(current.tokens) > 0 {
+			current.end = end
+			lines = append(lines, current)
+		}
+		current = dnsZoneLine{}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if atLineStart {
+			atLineStart = false
+			if depth == 0 {
+				current.line = lineNumber
+				current.leadingSpace = c == ' ' || c == '\t'
+				current.start = i
+			}
+		}
+		switch {
+		case c == '\n':
+			endToken()
+			if depth == 0 {
+				endLine(i + 1)
+			}
+			lineNumber++
+			atLineStart = true
+		case c == ';':
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			endToken()
+		case c == '(':
+			endToken()
+			if depth == 0 {
+				depthLine = lineNumber
+			}
+			depth++
+		case c == ')':
+			endToken()
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced ')'", lineNumber)
+			}
+			depth--
+		case c == '"':
+			endToken()
+			start := lineNumber
+			tokenStart = i
+			token.WriteByte(c)
+			closed := false
+			for i+1 < len(content) {
+				i++
+				token.WriteByte(content[i])
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+					token.WriteByte(content[i])
+				} else if content[i] == '"' {
+					closed = true
+					break
+				} else if content[i] == '\n' {
+					break
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", start)
+			}
+			tokenEnd = i + 1
+			inToken = true
+			endToken()
+		case c == '\\' && i+1 < len(content):
+			if !inToken {
+				tokenStart = i
+			}
+			token.WriteByte(c)
+			token.WriteByte(content[i+1])
+			inToken = true
+			i++
+			tokenEnd = i + 1
+		default:
+			if !inToken {
+				tokenStart = i
+			}
+			token.WriteByte(c)
+			inToken = true
+			tokenEnd = i + 1
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("line %d: unbalanced '('", depthLine)
+	}
+	endLine(len(content))
+	return lines, nil
+}
+
+// parseZoneFile parses and validates an RFC 1035 zone file.
+func parseZoneFile(content string) (*dnsZone, error) {
+	lines, err := lexZoneFile(content)
+	if err != nil {
+		return nil, err
+	}
+	zone := &dnsZone{}
+	lastName := ""
+	previousEnd := 0
+	for _, line := range lines {
+		tokens := line.tokens
+		text, leading := content[line.start:line.end], content[previousEnd:line.start]
+		previousEnd = line.end
+		if !line.leadingSpace && strings.HasPrefix(tokens[0], "$") {
+			directive := strings.ToUpper(tokens[0])
+			switch directive {
+			case "$ORIGIN":
+				if len(tokens) != 2 || !strings.HasSuffix(tokens[1], ".") {
+					return nil, fmt.Errorf("line %d: $ORIGIN must be followed by an absolute domain name", line.line)
+				}
+				if err := validateDnsName(tokens[1]); err != nil {
+					return nil, fmt.Errorf("line %d: %v", line.line, err)
+				}
+				zone.origin = strings.ToLower(tokens[1])
+			case "$TTL":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: $TTL must be followed by a TTL", line.line)
+				}
+				if _, err := parseDnsTtl(tokens[1]); err != nil {
+					return nil, fmt.Errorf("line %d: %v", line.line, err)
+				}
+			default:
+				// $INCLUDE, $GENERATE and other directives are left to the
+				// traffic manager; the records they add are not checked.
+				zone.warnings = append(zone.warnings, fmt.Sprintf("line %d: records from %s are not checked", line.line, directive))
+			}
+			zone.entries = append(zone.entries, dnsZoneEntry{directive: directive, args: tokens[1:], text: text, leading: leading})
+			continue
+		}
+
+		record := &dnsRecord{line: line.line, name: lastName}
+		if !line.leadingSpace {
+			record.name = tokens[0]
+			tokens = tokens[1:]
+			if record.name != "@" {
+				if err := validateDnsName(record.name); err != nil {
+					return nil, fmt.Errorf("line %d: %v", line.line, err)
+				}
+			}
+		} else if lastName == "" {
+			return nil, fmt.Errorf("line %d: record has no owner name", line.line)
+		}
+		lastName = record.name
+		record.fqdn = dnsRecordFqdn(record.name, zone.origin)
+
+		for n := 0; n < 2 && len(tokens) > 0; n++ {
+			if dnsTtlPattern.MatchString(tokens[0]) && !record.hasTtl {
+				ttl, err := parseDnsTtl(tokens[0])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", line.line, err)
+				}
+				record.ttl, record.hasTtl = ttl, true
+				tokens = tokens[1:]
+			} else if dnsClasses[strings.ToUpper(tokens[0])] && record.class == "" {
+				record.class = strings.ToUpper(tokens[0])
+				tokens = tokens[1:]
+			}
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("line %d: record for '%s' has no type", line.line, record.name)
+		}
+		record.rtype = strings.ToUpper(tokens[0])
+		record.rdata = tokens[1:]
+		if err := validateDnsRecordData(record.rtype, record.rdata); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.line, err)
+		}
+		source := &dnsRecordSource{record: *record, text: text}
+		source.record.rdata = append([]string{}, record.rdata...)
+		for _, offset := range line.offsets[len(line.offsets)-len(record.rdata):] {
+			source.rdataOffsets = append(source.rdataOffsets, [2]int{offset[0] - line.start, offset[1] - line.start})
+		}
+		record.source = source
+		zone.entries = append(zone.entries, dnsZoneEntry{record: record, text: text, leading: leading})
+	}
+	zone.trailing = content[previousEnd:]
+	return zone, nil
+}
+
+// validateZoneFile is a ValidateFunc for zone file content. The traffic
+// manager is the judge of what it can load, so problems are reported as
+// warnings rather than errors.
+func validateZoneFile(v interface{}, k string) (ws []string, errors []error) {
+	zone, err := parseZoneFile(v.(string))
+	if err != nil {
+		return []string{fmt.Sprintf("%q is not a valid zone file: %v", k, err)}, nil
+	}
+	for _, warning := range zone.warnings {
+		ws = append(ws, fmt.Sprintf("%q: %s", k, warning))
+	}
+	return
+}
+
+// validateDnsName checks the length and labels of a domain name. Labels
+// are not restricted to host name characters, as zone files may contain
+// service names and wildcards.
+func validateDnsName(name string) error {
+	trimmed := strings.TrimSuffix(name, ".")
+	if trimmed == "" {
+		return nil
+	}
+	if len(trimmed) > 253 {
+		return fmt.Errorf("domain name '%s' is longer than 253 characters", name)
+	}
+	for _, label := range strings.Split(trimmed, ".") {
+		if label == "" {
+			return fmt.Errorf("domain name '%s' has an empty label", name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("domain name '%s' has a label longer than 63 characters", name)
+		}
+	}
+	return nil
+}
+
+// parseDnsTtl parses a TTL in seconds, or with BIND style units such as
+// "1h30m".
+func parseDnsTtl(value string) (int, error) {
+	if !dnsTtlPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid TTL '%s'", value)
+	}
+	units := map[string]int64{"": 1, "s": 1, "m": 60, "h": 3600, "d": 86400, "w": 604800}
+	var total int64
+	for _, part := range dnsTtlPartPattern.FindAllStringSubmatch(value, -1) {
+		number, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid TTL '%s'", value)
+		}
+		total += number * units[strings.ToLower(part[2])]
+		if total > 2147483647 {
+			return 0, fmt.Errorf("TTL '%s' is larger than 2147483647 seconds", value)
+		}
+	}
+	return int(total), nil
+}
+
+// validateDnsRecordData checks the rdata of the commonly used record types.
+// Other types need only have some data.
+func validateDnsRecordData(rtype string, rdata []string) error {
+	if !dnsRecordTypes[rtype] && !dnsGenericTypePattern.MatchString(rtype) {
+		return fmt.Errorf("unknown record type '%s'", rtype)
+	}
+	expect := func(count int) error {
+		if len(rdata) != count {
+			return fmt.Errorf("%s record must have %d fields, not %d", rtype, count, len(rdata))
+		}
+		return nil
+	}
+	number := func(value string, max uint64) error {
+		if parsed, err := strconv.ParseUint(value, 10, 64); err != nil || parsed > max {
+			return fmt.Errorf("%s record field '%s' must be a number up to %d", rtype, value, max)
+		}
+		return nil
+	}
+
+	switch rtype {
+	case "A", "AAAA":
+		if err := expect(1); err != nil {
+			return err
+		}
+		ip := net.ParseIP(rdata[0])
+		if ip == nil || (ip.To4() != nil) != (rtype == "A") || (rtype == "A" && strings.Contains(rdata[0], ":")) {
+			return fmt.Errorf("'%s' is not a valid %s record address", rdata[0], rtype)
+		}
+	case "CNAME", "DNAME", "NS", "PTR":
+		if err := expect(1); err != nil {
+			return err
+		}
+		return validateDnsName(rdata[0])
+	case "MX":
+		if err := expect(2); err != nil {
+			return err
+		}
+		if err := number(rdata[0], 65535); err != nil {
+			return err
+		}
+		return validateDnsName(rdata[1])
+	case "SRV":
+		if err := expect(4); err != nil {
+			return err
+		}
+		for _, field := range rdata[:3] {
+			if err := number(field, 65535); err != nil {
+				return err
+			}
+		}
+		return validateDnsName(rdata[3])
+	case "SOA":
+		if err := expect(7); err != nil {
+			return err
+		}
+		for _, name := range rdata[:2] {
+			if err := validateDnsName(name); err != nil {
+				return err
+			}
+		}
+		if err := number(rdata[2], 4294967295); err != nil {
+			return err
+		}
+		for _, field := range rdata[3:] {
+			if _, err := parseDnsTtl(field); err != nil {
+				return fmt.Errorf("SOA record field '%s' must be a time", field)
+			}
+		}
+	case "CAA":
+		if err := expect(3); err != nil {
+			return err
+		}
+		if err := number(rdata[0], 255); err != nil {
+			return err
+		}
+		if !dnsCaaTagPattern.MatchString(rdata[1]) {
+			return fmt.Errorf("CAA record tag '%s' must be alphanumeric", rdata[1])
+		}
+	default:
+		if len(rdata) == 0 {
+			return fmt.Errorf("%s record has no data", rtype)
+		}
+	}
+	return nil
+}
+
+// dnsRecordFqdn returns the lower case absolute form of a record's owner
+// name, or the relative form if the zone file does not set $ORIGIN.
+func dnsRecordFqdn(name, origin string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@" && origin != "":
+		return origin
+	case strings.HasSuffix(name, ".") || origin == "":
+		return name
+	case origin == ".":
+		return name + "."
+	}
+	return name + "." + origin
+}
+
+// rrsetKey identifies the records with the same owner name and type.
+func (record *dnsRecord) rrsetKey() string {
+	return record.fqdn + " " + record.rtype
+}
+
+// records returns the zone's records.
+func (zone *dnsZone) records() []*dnsRecord {
+	records := []*dnsRecord{}
+	for _, entry := range zone.entries {
+		if entry.record != nil {
+			records = append(records, entry.record)
+		}
+	}
+	return records
+}
+
+// rrset returns the records for name and rtype, with name relative to the
+// zone's final origin.
+func (zone *dnsZone) rrset(name, rtype string) []*dnsRecord {
+	key := dnsRecordFqdn(name, zone.origin) + " " + strings.ToUpper(rtype)
+	records := []*dnsRecord{}
+	for _, record := range zone.records() {
+		if record.rrsetKey() == key {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// setRrset replaces the records for name and rtype with ones holding each
+// of values, or removes them if values is empty. New records are placed
+// where the first of the old ones was, or at the end of the zone. Old
+// records with the same value and TTL are kept as they were written.
+func (zone *dnsZone) setRrset(name, rtype string, ttl int, values [][]string) {
+	rtype = strings.ToUpper(rtype)
+	key := dnsRecordFqdn(name, zone.origin) + " " + rtype
+	old := map[string][]dnsZoneEntry{}
+	for _, entry := range zone.entries {
+		if entry.record != nil && entry.record.rrsetKey() == key && entry.record.hasTtl == (ttl > 0) && (ttl == 0 || entry.record.ttl == ttl) {
+			value := strings.Join(entry.record.rdata, " ")
+			old[value] = append(old[value], entry)
+		}
+	}
+	var replacement []dnsZoneEntry
+	for _, value := range values {
+		joined := strings.Join(value, " ")
+		if kept := old[joined]; len(kept) > 0 {
+			replacement = append(replacement, dnsZoneEntry{record: kept[0].record})
+			old[joined] = kept[1:]
+			continue
+		}
+		replacement = append(replacement, dnsZoneEntry{record: &dnsRecord{
+			name:   name,
+			fqdn:   dnsRecordFqdn(name, zone.origin),
+			ttl:    ttl,
+			hasTtl: ttl > 0,
+			class:  "IN",
+			rtype:  rtype,
+			rdata:  value,
+		}})
+	}
+
+	// The comment lines before removed records are kept, before the entry
+	// that follows them.
+	entries := []dnsZoneEntry{}
+	placed := false
+	leading := ""
+	for i, entry := range zone.entries {
+		if entry.record == nil || entry.record.rrsetKey() != key {
+			entry.leading = leading + entry.leading
+			leading = ""
+			entries = append(entries, entry)
+			continue
+		}
+		leading += entry.leading
+		// Records after a later $ORIGIN would be named relative to the
+		// wrong origin, so only replace in place at the end of the zone.
+		if !placed && !zone.hasDirectiveAfter(i, "$ORIGIN") && len(replacement) > 0 {
+			replacement[0].leading = leading
+			leading = ""
+			entries = append(entries, replacement...)
+			placed = true
+		}
+	}
+	zone.trailing = leading + zone.trailing
+	if !placed {
+		entries = append(entries, replacement...)
+	}
+	zone.entries = entries
+}
+
+func (zone *dnsZone) hasDirectiveAfter(index int, directive string) bool {
+	for _, entry := range zone.entries[index+1:] {
+		if entry.directive == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// bumpSerial increases the serial number of the zone's SOA record, using
+// the date based YYYYMMDDnn form when the serial is not already larger.
+func (zone *dnsZone) bumpSerial(now time.Time) {
+	for _, record := range zone.records() {
+		if record.rtype != "SOA" || len(record.rdata) != 7 {
+			continue
+		}
+		serial, _ := strconv.ParseUint(record.rdata[2], 10, 32)
+		dated, _ := strconv.ParseUint(now.UTC().Format("20060102")+"00", 10, 32)
+		if serial < dated {
+			serial = dated
+		} else {
+			serial = (serial + 1) % 4294967296
+		}
+		record.rdata[2] = strconv.FormatUint(serial, 10)
+		return
+	}
+}
+
+// serial returns the serial number of the zone's SOA record, or zero.
+func (zone *dnsZone) serial() uint64 {
+	for _, record := range zone.records() {
+		if record.rtype == "SOA" && len(record.rdata) == 7 {
+			serial, _ := strconv.ParseUint(record.rdata[2], 10, 32)
+			return serial
+		}
+	}
+	return 0
+}
+
+// setSerial sets the serial number of the zone's SOA record.
+func (zone *dnsZone) setSerial(serial uint64) {
+	for _, record := range zone.records() {
+		if record.rtype == "SOA" && len(record.rdata) == 7 {
+			record.rdata[2] = strconv.FormatUint(serial, 10)
+			return
+		}
+	}
+}
+
+// render formats the zone as a zone file. Entries that have not changed
+// keep their original text, with their comments; records whose rdata has
+// changed have the new values spliced into their original text.
+func (zone *dnsZone) render() string {
+	var buffer bytes.Buffer
+	newLine := func() {
+		if buffer.Len() > 0 && !bytes.HasSuffix(buffer.Bytes(), []byte("\n")) {
+			buffer.WriteString("\n")
+		}
+	}
+	for _, entry := range zone.entries {
+		newLine()
+		buffer.WriteString(entry.leading)
+		newLine()
+		switch {
+		case entry.record != nil:
+			buffer.WriteString(entry.record.renderText())
+		case entry.text != "":
+			buffer.WriteString(entry.text)
+		default:
+			buffer.WriteString(entry.directive + " " + strings.Join(entry.args, " ") + "\n")
+		}
+	}
+	if zone.trailing != "" {
+		newLine()
+		buffer.WriteString(zone.trailing)
+	}
+	return buffer.String()
+}
+
+// renderText returns the record's original text, with any changed rdata
+// spliced into it, or the record formatted afresh if it was added or more
+// than its rdata values have changed.
+func (record *dnsRecord) renderText() string {
+	source := record.source
+	if source == nil || record.name != source.record.name || record.ttl != source.record.ttl ||
+		record.hasTtl != source.record.hasTtl || record.class != source.record.class ||
+		record.rtype != source.record.rtype || len(record.rdata) != len(source.record.rdata) {
+		return record.render() + "\n"
+	}
+	text := source.text
+	for i := len(record.rdata) - 1; i >= 0; i-- {
+		if record.rdata[i] != source.record.rdata[i] {
+			offset := source.rdataOffsets[i]
+			text = text[:offset[0]] + record.rdata[i] + text[offset[1]:]
+		}
+	}
+	return text
+}
+
+func (record *dnsRecord) render() string {
+	fields := []string{record.name}
+	if record.hasTtl {
+		fields = append(fields, strconv.Itoa(record.ttl))
+	}
+	if record.class != "" {
+		fields = append(fields, record.class)
+	}
+	fields = append(fields, record.rtype)
+	fields = append(fields, record.rdata...)
+	return strings.Join(fields, "\t")
+}
+
+// canonicalRecords returns a sorted description of the zone's records, for
+// comparing zones regardless of formatting. The SOA serial is left out if
+// ignoreSerial is set.
+func (zone *dnsZone) canonicalRecords(ignoreSerial bool) []string {
+	canonical := []string{}
+	for _, record := range zone.records() {
+		rdata := append([]string{}, record.rdata...)
+		if ignoreSerial && record.rtype == "SOA" && len(rdata) == 7 {
+			rdata[2] = ""
+		}
+		ttl := ""
+		if record.hasTtl {
+			ttl = strconv.Itoa(record.ttl)
+		}
+		canonical = append(canonical, strings.Join([]string{record.rrsetKey(), ttl, strings.Join(rdata, " ")}, " "))
+	}
+	sort.Strings(canonical)
+	return canonical
+}
+
+// parseDnsRecordValue parses the rdata of one record. TXT and SPF values
+// that are not already quoted are treated as a single string.
+func parseDnsRecordValue(rtype, value string) ([]string, error) {
+	rtype = strings.ToUpper(rtype)
+	if (rtype == "TXT" || rtype == "SPF") && !strings.HasPrefix(strings.TrimSpace(value), "\"") {
+		value = "\"" + strings.Replace(strings.Replace(value, "\\", "\\\\", -1), "\"", "\\\"", -1) + "\""
+	}
+	lines, err := lexZoneFile(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("record value '%s' must be a single line", value)
+	}
+	if err := validateDnsRecordData(rtype, lines[0].tokens); err != nil {
+		return nil, err
+	}
+	return lines[0].tokens, nil
+}
+
+// formatDnsRecordValue is the reverse of parseDnsRecordValue.
+func formatDnsRecordValue(rtype string, rdata []string) string {
+	if (rtype == "TXT" || rtype == "SPF") && len(rdata) == 1 && strings.HasPrefix(rdata[0], "\"") {
+		value := rdata[0][1 : len(rdata[0])-1]
+		return strings.Replace(strings.Replace(value, "\\\"", "\"", -1), "\\\\", "\\", -1)
+	}
+	return strings.Join(rdata, " ")
+}
+
+// filterDnsServerZoneFile removes the records from a zone file that are not
+// in the configured content. If what remains only differs from the
+// configured content in formatting or SOA serial, the configured content is
+// returned so that no change is reported.
+func filterDnsServerZoneFile(object, configured string) string {
+	zone, err := parseZoneFile(object)
+	if err != nil {
+		return object
+	}
+	configuredZone, err := parseZoneFile(configured)
+	if err != nil {
+		return object
+	}
+	keys := dnsZoneRrsetKeys(configuredZone)
+	entries := []dnsZoneEntry{}
+	for _, entry := range zone.entries {
+		if entry.record == nil || keys[entry.record.rrsetKey()] {
+			entries = append(entries, entry)
+		}
+	}
+	zone.entries = entries
+	if reflect.DeepEqual(zone.canonicalRecords(true), configuredZone.canonicalRecords(true)) {
+		return configured
+	}
+	return zone.render()
+}
+
+// mergeDnsServerZoneFile returns the configured content with the records of
+// zone that belong to neither the configured nor the previous content added
+// to it.
+func mergeDnsServerZoneFile(zone *dnsZone, configured, previous string) (*dnsZone, error) {
+	merged, err := parseZoneFile(configured)
+	if err != nil {
+		return nil, err
+	}
+	keys := dnsZoneRrsetKeys(merged)
+	if previousZone, err := parseZoneFile(previous); err == nil {
+		for key := range dnsZoneRrsetKeys(previousZone) {
+			keys[key] = true
+		}
+	}
+	for _, record := range zone.records() {
+		if !keys[record.rrsetKey()] {
+			merged.entries = append(merged.entries, dnsZoneEntry{record: record})
+		}
+	}
+	return merged, nil
+}
+
+func dnsZoneRrsetKeys(zone *dnsZone) map[string]bool {
+	keys := map[string]bool{}
+	for _, record := range zone.records() {
+		keys[record.rrsetKey()] = true
+	}
+	return keys
+}
@@ -0,0 +1,229 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testZoneFile = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1 hostmaster (
+		2018010100 ; serial
+		1d 2h 4w 1h )
+	IN	NS	ns1
+	IN	MX	10 mail.example.com.
+ns1	IN	A	192.0.2.1
+www	300	IN	A	192.0.2.10
+www	300	IN	A	192.0.2.11
+	IN	AAAA	2001:db8::10
+_sip._tcp	SRV	10 60 5060 sip.example.com.
+txt	TXT	"v=spf1 -all" ; a comment
+@	CAA	0 issue "letsencrypt.org"
+`
+
+func TestParseZoneFile(t *testing.T) {
+	zone, err := parseZoneFile(testZoneFile)
+	if err != nil {
+		t.Fatalf("Parsing zone file failed: %v", err)
+	}
+	records := zone.records()
+	if len(records) != 10 {
+		t.Fatalf("Zone file has %d records, expected 10", len(records))
+	}
+	soa := records[0]
+	if soa.fqdn != "example.com." || soa.rtype != "SOA" || len(soa.rdata) != 7 || soa.rdata[2] != "2018010100" {
+		t.Errorf("SOA record was parsed as %s %s %v", soa.fqdn, soa.rtype, soa.rdata)
+	}
+	if records[1].fqdn != "example.com." || records[1].rtype != "NS" {
+		t.Errorf("Record without an owner name was parsed as %s %s", records[1].fqdn, records[1].rtype)
+	}
+	if aaaa := records[6]; aaaa.fqdn != "www.example.com." || aaaa.hasTtl {
+		t.Errorf("AAAA record was parsed as %s with TTL %d", aaaa.fqdn, aaaa.ttl)
+	}
+	if www := zone.rrset("WWW", "a"); len(www) != 2 || www[1].ttl != 300 || www[1].rdata[0] != "192.0.2.11" {
+		t.Errorf("Found %d www A records", len(www))
+	}
+	if txt := records[8]; len(txt.rdata) != 1 || txt.rdata[0] != `"v=spf1 -all"` {
+		t.Errorf("TXT record was parsed as %v", txt.rdata)
+	}
+
+	// Rendering must give an equivalent zone file.
+	rendered, err := parseZoneFile(zone.render())
+	if err != nil {
+		t.Fatalf("Parsing rendered zone file failed: %v\n%s", err, zone.render())
+	}
+	if !reflect.DeepEqual(rendered.canonicalRecords(false), zone.canonicalRecords(false)) {
+		t.Errorf("Rendered zone file differs:\n%s", zone.render())
+	}
+}
+
+func TestParseZoneFileErrors(t *testing.T) {
+	tables := []struct {
+		content string
+		err     string
+	}{
+		{"TEST_TEXT", "line 1: record for 'TEST_TEXT' has no type"},
+		{"www IN A 192.0.2.1\nwww IN A 2001:db8::1", "line 2: '2001:db8::1' is not a valid A record address"},
+		{"@ IN SOA ns1 hostmaster ( 1 2 3 4 5", "line 1: unbalanced '('"},
+		{"@ IN SOA ns1 hostmaster 1 2 3 4 5 )", "line 1: unbalanced ')'"},
+		{"txt IN TXT \"unterminated", "line 1: unterminated quoted string"},
+		{"  IN A 192.0.2.1", "line 1: record has no owner name"},
+		{"www IN BOGUS data", "line 1: unknown record type 'BOGUS'"},
+		{"mail IN MX mail.example.com.", "line 1: MX record must have 2 fields, not 1"},
+		{"mail IN MX 70000 mail.example.com.", "line 1: MX record field '70000' must be a number up to 65535"},
+		{"$ORIGIN example.com", "line 1: $ORIGIN must be followed by an absolute domain name"},
+		{"$TTL forever", "line 1: invalid TTL 'forever'"},
+		{"a..b IN A 192.0.2.1", "line 1: domain name 'a..b' has an empty label"},
+	}
+	for _, table := range tables {
+		_, err := parseZoneFile(table.content)
+		if err == nil || err.Error() != table.err {
+			t.Errorf("Parsing %q gave error '%v', expected '%s'", table.content, err, table.err)
+		}
+	}
+}
+
+func TestParseZoneFileDirectives(t *testing.T) {
+	content := "$ORIGIN example.com.\n$INCLUDE other.zone\n$GENERATE 1-4 host$ A 192.0.2.$\nwww IN A 192.0.2.1\n"
+	zone, err := parseZoneFile(content)
+	if err != nil {
+		t.Fatalf("Parsing zone file failed: %v", err)
+	}
+	expected := []string{"line 2: records from $INCLUDE are not checked", "line 3: records from $GENERATE are not checked"}
+	if !reflect.DeepEqual(zone.warnings, expected) {
+		t.Errorf("Parsing zone file gave warnings %v", zone.warnings)
+	}
+	zone.setRrset("www", "A", 0, [][]string{{"192.0.2.2"}})
+	if rendered := zone.render(); !strings.HasPrefix(rendered, "$ORIGIN example.com.\n$INCLUDE other.zone\n$GENERATE 1-4 host$ A 192.0.2.$\n") {
+		t.Errorf("Directives were not kept:\n%s", rendered)
+	}
+
+	if ws, es := validateZoneFile("TEST_TEXT", "content"); len(ws) != 1 || len(es) != 0 {
+		t.Errorf("Validating an invalid zone file gave warnings %v and errors %v", ws, es)
+	}
+}
+
+func TestDnsZoneRenderKeepsText(t *testing.T) {
+	zone, err := parseZoneFile(testZoneFile)
+	if err != nil {
+		t.Fatalf("Parsing zone file failed: %v", err)
+	}
+	if rendered := zone.render(); rendered != testZoneFile {
+		t.Errorf("Unchanged zone file was rendered as:\n%s", rendered)
+	}
+
+	// The new serial is spliced into the SOA record, and the records that
+	// are not changed keep their comments and layout.
+	zone.setSerial(2018010101)
+	zone.setRrset("ns1", "A", 0, [][]string{{"192.0.2.2"}})
+	zone.setRrset("www", "A", 300, [][]string{{"192.0.2.10"}, {"192.0.2.12"}})
+	expected := strings.NewReplacer(
+		"2018010100 ; serial", "2018010101 ; serial",
+		"ns1\tIN\tA\t192.0.2.1\n", "ns1\tIN\tA\t192.0.2.2\n",
+		"www\t300\tIN\tA\t192.0.2.11\n", "www\t300\tIN\tA\t192.0.2.12\n",
+	).Replace(testZoneFile)
+	if rendered := zone.render(); rendered != expected {
+		t.Errorf("Changed zone file was rendered as:\n%s\nexpected:\n%s", rendered, expected)
+	}
+}
+
+func TestParseDnsTtl(t *testing.T) {
+	tables := map[string]int{"300": 300, "1h": 3600, "1h30m": 5400, "1W": 604800, "2d12h": 216000}
+	for value, expected := range tables {
+		if ttl, err := parseDnsTtl(value); err != nil || ttl != expected {
+			t.Errorf("TTL '%s' was parsed as %d (%v), expected %d", value, ttl, err, expected)
+		}
+	}
+	for _, value := range []string{"", "1y", "-1", "9999999999"} {
+		if _, err := parseDnsTtl(value); err == nil {
+			t.Errorf("TTL '%s' was accepted", value)
+		}
+	}
+}
+
+func TestDnsZoneSetRrset(t *testing.T) {
+	zone, err := parseZoneFile(testZoneFile)
+	if err != nil {
+		t.Fatalf("Parsing zone file failed: %v", err)
+	}
+	zone.setRrset("www", "A", 60, [][]string{{"192.0.2.20"}})
+	zone.setRrset("api", "CNAME", 0, [][]string{{"www"}})
+	zone.setRrset("txt", "TXT", 0, nil)
+
+	if www := zone.rrset("www.example.com.", "A"); len(www) != 1 || www[0].ttl != 60 || www[0].rdata[0] != "192.0.2.20" {
+		t.Errorf("www A records were not replaced")
+	}
+	// The replacement takes the place of the old records.
+	if records := zone.records(); records[4].rtype != "A" || records[5].rtype != "AAAA" {
+		t.Errorf("www A record was moved:\n%s", zone.render())
+	}
+	if api := zone.rrset("api", "CNAME"); len(api) != 1 || !strings.HasSuffix(zone.render(), "api\tIN\tCNAME\twww\n") {
+		t.Errorf("api CNAME record was not added:\n%s", zone.render())
+	}
+	if txt := zone.rrset("txt", "TXT"); len(txt) != 0 {
+		t.Errorf("txt TXT record was not removed")
+	}
+}
+
+func TestDnsZoneBumpSerial(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	tables := map[string]string{"1": "2018060100", "2018060100": "2018060101", "2018070105": "2018070106", "4294967295": "0"}
+	for serial, expected := range tables {
+		zone, err := parseZoneFile("@ IN SOA ns1 hostmaster " + serial + " 1d 2h 4w 1h")
+		if err != nil {
+			t.Fatalf("Parsing zone file failed: %v", err)
+		}
+		zone.bumpSerial(now)
+		if bumped := zone.records()[0].rdata[2]; bumped != expected {
+			t.Errorf("Serial %s was increased to %s, expected %s", serial, bumped, expected)
+		}
+	}
+}
+
+func TestDnsRecordValue(t *testing.T) {
+	rdata, err := parseDnsRecordValue("txt", `v=spf1 include:"x" -all`)
+	if err != nil || len(rdata) != 1 || rdata[0] != `"v=spf1 include:\"x\" -all"` {
+		t.Errorf("TXT value was parsed as %v (%v)", rdata, err)
+	}
+	if value := formatDnsRecordValue("TXT", rdata); value != `v=spf1 include:"x" -all` {
+		t.Errorf("TXT value was formatted as '%s'", value)
+	}
+	if rdata, err := parseDnsRecordValue("MX", "10 mail.example.com."); err != nil || len(rdata) != 2 {
+		t.Errorf("MX value was parsed as %v (%v)", rdata, err)
+	}
+	if _, err := parseDnsRecordValue("A", "192.0.2.300"); err == nil {
+		t.Errorf("Invalid A value was accepted")
+	}
+}
+
+func TestMergeDnsServerZoneFile(t *testing.T) {
+	server, _ := parseZoneFile(testZoneFile)
+	configured := "$ORIGIN example.com.\n@ IN SOA ns1 hostmaster 1 1d 2h 4w 1h\n@ IN NS ns1\nns1 IN A 192.0.2.2\n"
+	previous := configured + "old IN A 192.0.2.99\n"
+	merged, err := mergeDnsServerZoneFile(server, configured, previous)
+	if err != nil {
+		t.Fatalf("Merging zone file failed: %v", err)
+	}
+	// Records not in the configuration are kept, those that are take the
+	// configured values.
+	if ns1 := merged.rrset("ns1", "A"); len(ns1) != 1 || ns1[0].rdata[0] != "192.0.2.2" {
+		t.Errorf("ns1 A record was not replaced")
+	}
+	if len(merged.rrset("www", "A")) != 2 || len(merged.rrset("@", "MX")) != 1 {
+		t.Errorf("External records were not kept:\n%s", merged.render())
+	}
+
+	filtered := filterDnsServerZoneFile(merged.render(), configured)
+	if filtered != configured {
+		t.Errorf("Filtering the merged zone file gave:\n%s", filtered)
+	}
+	filtered = filterDnsServerZoneFile(testZoneFile, configured)
+	if !strings.Contains(filtered, "192.0.2.1") || strings.Contains(filtered, "www") {
+		t.Errorf("Filtering the zone file gave:\n%s", filtered)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dnssecAlgorithm describes a DNSSEC signing algorithm and the kind of key
+// that is generated for it.
+type dnssecAlgorithm struct {
+	number       uint8
+	keyAlgorithm string
+	size         int
+}
+
+// dnssecAlgorithms are the DNSSEC algorithms that keys can be generated for,
+// by their mnemonic from RFC 8624.
+var dnssecAlgorithms = map[string]dnssecAlgorithm{
+	"RSASHA256":       {8, "rsa", 2048},
+	"RSASHA512":       {10, "rsa", 2048},
+	"ECDSAP256SHA256": {13, "ecdsa", 256},
+	"ECDSAP384SHA384": {14, "ecdsa", 384},
+}
+
+// dnssecKey is the public half of a DNSSEC key, as published in a DNSKEY
+// record.
+type dnssecKey struct {
+	flags     uint16
+	algorithm uint8
+	publicKey []byte
+}
+
+// newDnssecKey encodes a public key for a DNSKEY record, with the flags of a
+// key signing key if ksk is set or a zone signing key otherwise.
+func newDnssecKey(publicKey crypto.PublicKey, algorithm string, ksk bool) (*dnssecKey, error) {
+	details, ok := dnssecAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DNSSEC algorithm '%s'", algorithm)
+	}
+	key := &dnssecKey{flags: 256, algorithm: details.number}
+	if ksk {
+		key.flags = 257
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		if details.keyAlgorithm != "rsa" {
+			return nil, fmt.Errorf("an RSA key cannot be used with algorithm %s", algorithm)
+		}
+		// RFC 3110: exponent length, exponent, modulus.
+		exponent := big.NewInt(int64(publicKey.E)).Bytes()
+		var buffer bytes.Buffer
+		if len(exponent) < 256 {
+			buffer.WriteByte(byte(len(exponent)))
+		} else {
+			buffer.WriteByte(0)
+			binary.Write(&buffer, binary.BigEndian, uint16(len(exponent)))
+		}
+		buffer.Write(exponent)
+		buffer.Write(publicKey.N.Bytes())
+		key.publicKey = buffer.Bytes()
+	case *ecdsa.PublicKey:
+		size := publicKey.Curve.Params().BitSize
+		if details.keyAlgorithm != "ecdsa" || size != details.size {
+			return nil, fmt.Errorf("a %d bit ECDSA key cannot be used with algorithm %s", size, algorithm)
+		}
+		// RFC 6605: the X and Y coordinates, each padded to the curve size.
+		length := (size + 7) / 8
+		key.publicKey = make([]byte, 2*length)
+		x, y := publicKey.X.Bytes(), publicKey.Y.Bytes()
+		copy(key.publicKey[length-len(x):length], x)
+		copy(key.publicKey[2*length-len(y):], y)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return key, nil
+}
+
+// rdata returns the wire format of the DNSKEY record data.
+func (key *dnssecKey) rdata() []byte {
+	rdata := make([]byte, 4, 4+len(key.publicKey))
+	binary.BigEndian.PutUint16(rdata, key.flags)
+	rdata[2] = 3
+	rdata[3] = key.algorithm
+	return append(rdata, key.publicKey...)
+}
+
+// keyTag calculates the key tag that identifies the key in RRSIG and DS
+// records, as described in RFC 4034 appendix B.
+func (key *dnssecKey) keyTag() int {
+	var tag uint32
+	for i, b := range key.rdata() {
+		if i&1 == 0 {
+			tag += uint32(b) << 8
+		} else {
+			tag += uint32(b)
+		}
+	}
+	tag += tag >> 16 & 0xffff
+	return int(tag & 0xffff)
+}
+
+// dnskeyRecord returns the DNSKEY record for the key in zone file format.
+func (key *dnssecKey) dnskeyRecord(domain string) string {
+	return fmt.Sprintf("%s IN DNSKEY %d 3 %d %s", dnsFqdn(domain), key.flags, key.algorithm,
+		base64.StdEncoding.EncodeToString(key.publicKey))
+}
+
+// dsRecord returns the DS record, with a SHA-256 digest, that the parent
+// zone publishes to delegate trust to the key.
+func (key *dnssecKey) dsRecord(domain string) string {
+	digest := sha256.New()
+	digest.Write(dnsWireName(domain))
+	digest.Write(key.rdata())
+	return fmt.Sprintf("%s IN DS %d %d 2 %s", dnsFqdn(domain), key.keyTag(), key.algorithm,
+		strings.ToUpper(hex.EncodeToString(digest.Sum(nil))))
+}
+
+// dnssecKeyNote returns the note of the vtm_ssl_server_key that holds a
+// generated DNSSEC key, from which the key can be imported.
+func dnssecKeyNote(keyType, algorithm, domain string) string {
+	return fmt.Sprintf("DNSSEC %s (%s) for %s", strings.ToUpper(keyType), algorithm, dnsFqdn(domain))
+}
+
+var dnssecKeyNotePattern = regexp.MustCompile(`^DNSSEC (KSK|ZSK)(?: \(([A-Z0-9]+)\))? for (\S+)$`)
+
+// parseDnssecKeyNote returns the key type, algorithm and domain recorded in
+// the note of a generated DNSSEC key. Keys generated before the algorithm
+// was recorded have none.
+func parseDnssecKeyNote(note string) (keyType, algorithm, domain string, ok bool) {
+	match := dnssecKeyNotePattern.FindStringSubmatch(note)
+	if match == nil {
+		return "", "", "", false
+	}
+	return strings.ToLower(match[1]), match[2], match[3], true
+}
+
+// dnssecPublicKeyAlgorithm returns the DNSSEC algorithm that keys like
+// publicKey are generated for. RSA keys are assumed to be for RSASHA256.
+func dnssecPublicKeyAlgorithm(publicKey crypto.PublicKey) (string, error) {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSASHA256", nil
+	case *ecdsa.PublicKey:
+		for name, algorithm := range dnssecAlgorithms {
+			if algorithm.keyAlgorithm == "ecdsa" && algorithm.size == publicKey.Curve.Params().BitSize {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// dnsFqdn returns domain in lower case with a trailing dot.
+func dnsFqdn(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + "."
+}
+
+// dnsWireName returns the canonical wire format of a domain name, as used
+// when calculating DS digests.
+func dnsWireName(domain string) []byte {
+	var wire bytes.Buffer
+	for _, label := range strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".") {
+		if label != "" {
+			wire.WriteByte(byte(len(label)))
+			wire.WriteString(label)
+		}
+	}
+	wire.WriteByte(0)
+	return wire.Bytes()
+}
+
+// dnssecKeyStage works out the stage of a key's rollover at time now from
+// the RFC 3339 timestamps at which it is published, activated and retired.
+// A key with no publish time is published immediately, one with no activate
+// time is active once published, and one with no retire time is never
+// retired.
+func dnssecKeyStage(publish, activate, retire string, now time.Time) (string, error) {
+	times := make([]time.Time, 3)
+	for i, value := range []string{publish, activate, retire} {
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", fmt.Errorf("invalid timestamp '%s': %v", value, err)
+		}
+		times[i] = parsed
+	}
+	if times[1].IsZero() {
+		times[1] = times[0]
+	} else if times[1].Before(times[0]) {
+		return "", fmt.Errorf("activate must not be before publish")
+	}
+	if !times[2].IsZero() && times[2].Before(times[1]) {
+		return "", fmt.Errorf("retire must not be before publish or activate")
+	}
+
+	switch {
+	case !times[2].IsZero() && !now.Before(times[2]):
+		return "retired", nil
+	case !now.Before(times[1]):
+		return "active", nil
+	case !now.Before(times[0]):
+		return "published", nil
+	}
+	return "generated", nil
+}
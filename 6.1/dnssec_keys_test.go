@@ -0,0 +1,114 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDnssecKeyRecords(t *testing.T) {
+	// The ECDSA P-256 key signing key from RFC 6605 section 6.1.
+	publicKey, _ := base64.StdEncoding.DecodeString("GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA==")
+	ecdsaKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(publicKey[:32]),
+		Y:     new(big.Int).SetBytes(publicKey[32:]),
+	}
+	key, err := newDnssecKey(ecdsaKey, "ECDSAP256SHA256", true)
+	if err != nil {
+		t.Fatalf("Encoding RFC 6605 key failed: %v", err)
+	}
+	if tag := key.keyTag(); tag != 55648 {
+		t.Errorf("RFC 6605 key has key tag %d, expected 55648", tag)
+	}
+	expected := "example.net. IN DNSKEY 257 3 13 GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA=="
+	if record := key.dnskeyRecord("example.net"); record != expected {
+		t.Errorf("RFC 6605 key gave DNSKEY '%s', expected '%s'", record, expected)
+	}
+	expected = "example.net. IN DS 55648 13 2 B4C8C1FE2E7477127B27115656AD6256F424625BF5C1E2770CE6D6E37DF61D17"
+	if record := key.dsRecord("Example.NET."); record != expected {
+		t.Errorf("RFC 6605 key gave DS '%s', expected '%s'", record, expected)
+	}
+
+	if _, err := newDnssecKey(ecdsaKey, "ECDSAP384SHA384", false); err == nil {
+		t.Errorf("Encoding a P-256 key for ECDSAP384SHA384 succeeded")
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Generating RSA key failed: %v", err)
+	}
+	key, err = newDnssecKey(&rsaKey.PublicKey, "RSASHA256", false)
+	if err != nil {
+		t.Fatalf("Encoding RSA key failed: %v", err)
+	}
+	if key.flags != 256 || key.publicKey[0] != 3 || len(key.publicKey) != 1+3+128 {
+		t.Errorf("RSA key was encoded as flags %d, public key %x", key.flags, key.publicKey)
+	}
+	if _, err := newDnssecKey(&rsaKey.PublicKey, "ECDSAP256SHA256", false); err == nil {
+		t.Errorf("Encoding an RSA key for ECDSAP256SHA256 succeeded")
+	}
+}
+
+func TestDnssecKeyStage(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	tables := []struct {
+		publish  string
+		activate string
+		retire   string
+		stage    string
+	}{
+		{"", "", "", "active"},
+		{"2018-07-01T00:00:00Z", "", "", "generated"},
+		{"2018-05-01T00:00:00Z", "2018-07-01T00:00:00Z", "", "published"},
+		{"2018-05-01T00:00:00Z", "2018-05-15T00:00:00Z", "2018-07-01T00:00:00Z", "active"},
+		{"", "", "2018-06-01T00:00:00Z", "retired"},
+		{"2018-07-01T00:00:00+01:00", "", "", "generated"},
+	}
+
+	for _, table := range tables {
+		stage, err := dnssecKeyStage(table.publish, table.activate, table.retire, now)
+		if err != nil || stage != table.stage {
+			t.Errorf("Key published %s, activated %s and retired %s is '%s' (%v), expected '%s'", table.publish, table.activate, table.retire, stage, err, table.stage)
+		}
+	}
+
+	errorTables := [][3]string{
+		{"yesterday", "", ""},
+		{"2018-05-01T00:00:00Z", "2018-04-01T00:00:00Z", ""},
+		{"2018-05-01T00:00:00Z", "", "2018-04-01T00:00:00Z"},
+	}
+	for _, table := range errorTables {
+		if _, err := dnssecKeyStage(table[0], table[1], table[2], now); err == nil {
+			t.Errorf("Key published %s, activated %s and retired %s was accepted", table[0], table[1], table[2])
+		}
+	}
+}
+
+func TestParseDnssecKeyNote(t *testing.T) {
+	tables := []struct {
+		note      string
+		keyType   string
+		algorithm string
+		domain    string
+		ok        bool
+	}{
+		{dnssecKeyNote("ksk", "RSASHA512", "Example.com"), "ksk", "RSASHA512", "example.com.", true},
+		{"DNSSEC ZSK for example.com.", "zsk", "", "example.com.", true},
+		{"Web server certificate", "", "", "", false},
+	}
+
+	for _, table := range tables {
+		keyType, algorithm, domain, ok := parseDnssecKeyNote(table.note)
+		if keyType != table.keyType || algorithm != table.algorithm || domain != table.domain || ok != table.ok {
+			t.Errorf("Parsing note '%s' gave %s, %s, %s, %v", table.note, keyType, algorithm, domain, ok)
+		}
+	}
+}
@@ -0,0 +1,533 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// exportableResource describes a resource type that the "export" command
+// walks. Singleton resources have no list function and are read once using
+// singletonId.
+type exportableResource struct {
+	resourceType string
+	list         func(tm *vtm.VirtualTrafficManager) (*[]string, *vtm.ReqError)
+	singletonId  string
+}
+
+// Resource types are listed so that referenced objects are written before
+// the objects that refer to them.
+var exportableResources = []exportableResource{
+	{resourceType: "vtm_global_settings", singletonId: "global_setting"},
+	{resourceType: "vtm_security", singletonId: "security"},
+	{resourceType: "vtm_traffic_manager", list: (*vtm.VirtualTrafficManager).ListTrafficManagers},
+	{resourceType: "vtm_license_key", list: (*vtm.VirtualTrafficManager).ListLicenseKeys},
+	{resourceType: "vtm_cloud_api_credential", list: (*vtm.VirtualTrafficManager).ListCloudApiCredentials},
+	{resourceType: "vtm_extra_file", list: (*vtm.VirtualTrafficManager).ListExtraFiles},
+	{resourceType: "vtm_action_program", list: (*vtm.VirtualTrafficManager).ListActionPrograms},
+	{resourceType: "vtm_monitor_script", list: (*vtm.VirtualTrafficManager).ListMonitorScripts},
+	{resourceType: "vtm_servicediscovery", list: (*vtm.VirtualTrafficManager).ListServicediscoverys},
+	{resourceType: "vtm_kerberos_keytab", list: (*vtm.VirtualTrafficManager).ListKerberosKeytabs},
+	{resourceType: "vtm_kerberos_krb5conf", list: (*vtm.VirtualTrafficManager).ListKerberosKrb5Confs},
+	{resourceType: "vtm_kerberos_principal", list: (*vtm.VirtualTrafficManager).ListKerberosPrincipals},
+	{resourceType: "vtm_ssl_ca", list: (*vtm.VirtualTrafficManager).ListSslCas},
+	{resourceType: "vtm_ssl_client_key", list: (*vtm.VirtualTrafficManager).ListSslClientKeys},
+	{resourceType: "vtm_ssl_server_key", list: (*vtm.VirtualTrafficManager).ListSslServerKeys},
+	{resourceType: "vtm_ssl_ticket_key", list: (*vtm.VirtualTrafficManager).ListSslTicketKeys},
+	{resourceType: "vtm_rule", list: (*vtm.VirtualTrafficManager).ListRules},
+	{resourceType: "vtm_rule_authenticator", list: (*vtm.VirtualTrafficManager).ListRuleAuthenticators},
+	{resourceType: "vtm_user_authenticator", list: (*vtm.VirtualTrafficManager).ListUserAuthenticators},
+	{resourceType: "vtm_user_group", list: (*vtm.VirtualTrafficManager).ListUserGroups},
+	{resourceType: "vtm_saml_trustedidp", list: (*vtm.VirtualTrafficManager).ListSamlTrustedidps},
+	{resourceType: "vtm_monitor", list: (*vtm.VirtualTrafficManager).ListMonitors},
+	{resourceType: "vtm_bandwidth", list: (*vtm.VirtualTrafficManager).ListBandwidths},
+	{resourceType: "vtm_persistence", list: (*vtm.VirtualTrafficManager).ListPersistences},
+	{resourceType: "vtm_protection", list: (*vtm.VirtualTrafficManager).ListProtections},
+	{resourceType: "vtm_rate", list: (*vtm.VirtualTrafficManager).ListRates},
+	{resourceType: "vtm_service_level_monitor", list: (*vtm.VirtualTrafficManager).ListServiceLevelMonitors},
+	{resourceType: "vtm_aptimizer_scope", list: (*vtm.VirtualTrafficManager).ListAptimizerScopes},
+	{resourceType: "vtm_aptimizer_profile", list: (*vtm.VirtualTrafficManager).ListAptimizerProfiles},
+	{resourceType: "vtm_action", list: (*vtm.VirtualTrafficManager).ListActions},
+	{resourceType: "vtm_custom", list: (*vtm.VirtualTrafficManager).ListCustoms},
+	{resourceType: "vtm_location", list: (*vtm.VirtualTrafficManager).ListLocations},
+	{resourceType: "vtm_bgpneighbor", list: (*vtm.VirtualTrafficManager).ListBgpneighbors},
+	{resourceType: "vtm_log_export", list: (*vtm.VirtualTrafficManager).ListLogExports},
+	{resourceType: "vtm_dns_server_zone_file", list: (*vtm.VirtualTrafficManager).ListDnsServerZoneFiles},
+	{resourceType: "vtm_dns_server_zone", list: (*vtm.VirtualTrafficManager).ListDnsServerZones},
+	{resourceType: "vtm_traffic_ip_group", list: (*vtm.VirtualTrafficManager).ListTrafficIpGroups},
+	{resourceType: "vtm_pool", list: (*vtm.VirtualTrafficManager).ListPools},
+	{resourceType: "vtm_glb_service", list: (*vtm.VirtualTrafficManager).ListGlbServices},
+	{resourceType: "vtm_virtual_server", list: (*vtm.VirtualTrafficManager).ListVirtualServers},
+	{resourceType: "vtm_event_type", list: (*vtm.VirtualTrafficManager).ListEventTypes},
+}
+
+// Fields whose values are the names of other configuration objects. Where
+// the named object is also exported, the value is replaced by a reference to
+// it so that Terraform knows about the dependency.
+var exportReferenceFields = map[string]string{
+	"actions":                                "vtm_action",
+	"aptimizer_profile":                      "vtm_aptimizer_profile",
+	"auth_saml_idp":                          "vtm_saml_trustedidp",
+	"bandwidth_class":                        "vtm_bandwidth",
+	"certificate":                            "vtm_ssl_server_key",
+	"client_cert_cas":                        "vtm_ssl_ca",
+	"cloud_credentials":                      "vtm_cloud_api_credential",
+	"cloudcredentials_objects":               "vtm_cloud_api_credential",
+	"completion_rules":                       "vtm_rule",
+	"connection_errors_error_file":           "vtm_extra_file",
+	"dns_zones":                              "vtm_dns_server_zone",
+	"failure_pool":                           "vtm_pool",
+	"glb_objects":                            "vtm_glb_service",
+	"glb_services":                           "vtm_glb_service",
+	"kerberos_protocol_transition_principal": "vtm_kerberos_principal",
+	"licensekeys_objects":                    "vtm_license_key",
+	"listen_on_traffic_ips":                  "vtm_traffic_ip_group",
+	"locations_objects":                      "vtm_location",
+	"monitors":                               "vtm_monitor",
+	"monitors_objects":                       "vtm_monitor",
+	"persistence_class":                      "vtm_persistence",
+	"pool":                                   "vtm_pool",
+	"pools_objects":                          "vtm_pool",
+	"protection_class":                       "vtm_protection",
+	"protection_objects":                     "vtm_protection",
+	"request_rules":                          "vtm_rule",
+	"response_rules":                         "vtm_rule",
+	"rules":                                  "vtm_rule",
+	"rules_objects":                          "vtm_rule",
+	"server_cert_alt_certificates":           "vtm_ssl_server_key",
+	"server_cert_default":                    "vtm_ssl_server_key",
+	"slm_class":                              "vtm_service_level_monitor",
+	"slm_objects":                            "vtm_service_level_monitor",
+	"vservers_objects":                       "vtm_virtual_server",
+	"zonefile":                               "vtm_dns_server_zone_file",
+}
+
+// Fields that hold passwords and keys, or that the vTM never returns in
+// clear text. They are exported as variables that have to be supplied before
+// the configuration can be applied, so that secrets are not written to disk.
+// Fields in nested blocks are given as "block.field", and secret file content
+// is exported as base64 where the object may be binary.
+var exportSecretFields = map[string][]string{
+	"vtm_action":               {"soap_password", "trap_auth_password", "trap_priv_password"},
+	"vtm_bgpneighbor":          {"authentication_password"},
+	"vtm_cloud_api_credential": {"cred2", "cred3"},
+	"vtm_global_settings": {
+		"appliance.bootloader_password",
+		"ec2_secret_access_key",
+		"log_export_auth_hec_token",
+		"log_export_auth_password",
+		"ospfv2_authentication_shared_secret_a",
+		"ospfv2_authentication_shared_secret_b",
+		"remote_licensing_owner_secret",
+		"ssl_hardware_azure_client_secret",
+	},
+	"vtm_kerberos_keytab":    {"content"},
+	"vtm_license_key":        {"content"},
+	"vtm_rule_authenticator": {"ldap_bind_password"},
+	"vtm_ssl_client_key":     {"private"},
+	"vtm_ssl_server_key":     {"private"},
+	"vtm_traffic_manager":    {"snmp_auth_password", "snmp_priv_password"},
+	"vtm_user_authenticator": {"ldap_search_password", "radius_secret", "tacacs_plus_secret"},
+}
+
+func isExportSecretField(resourceType, field string) bool {
+	for _, secret := range exportSecretFields[resourceType] {
+		if secret == field {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedObject is a configuration object that has been read from the vTM
+// and is ready to be written out as HCL.
+type exportedObject struct {
+	resourceType string
+	objectName   string
+	hclName      string
+	data         *schema.ResourceData
+}
+
+type exporter struct {
+	outputDir string
+	objects   []*exportedObject
+	// Maps resource type and object name to the HCL resource name
+	hclNames  map[string]map[string]string
+	usedHcl   map[string]bool
+	variables bytes.Buffer
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	baseUrl := flags.String("base-url", os.Getenv("VTM_BASE_URL"), "Base URL of the vTM REST API")
+	username := flags.String("username", envWithDefault("VTM_USERNAME", "admin"), "vTM admin user")
+	password := flags.String("password", os.Getenv("VTM_PASSWORD"), "vTM admin password")
+	verifySslCert := flags.Bool("verify-ssl-cert", os.Getenv("VTM_VERIFY_SSL_CERT") != "false", "Check that the vTM REST interface SSL certificate is trusted")
+	outputDir := flags.String("output", "vtm_export", "Directory to write the exported configuration to")
+	types := flags.String("types", "", "Comma-separated list of resource types to export (default all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *baseUrl == "" || *password == "" {
+		return fmt.Errorf("-base-url and -password (or VTM_BASE_URL and VTM_PASSWORD) must be set")
+	}
+
+	tm, contactable, contactErr := vtm.NewVirtualTrafficManager(*baseUrl, *username, *password, *verifySslCert, false)
+	if contactable != true {
+		return fmt.Errorf("Failed to connect to Virtual Traffic Manager at '%v': %v", *baseUrl, contactErr.ErrorText)
+	}
+	registerRestClient(tm, *baseUrl, *username, *password, *verifySslCert)
+
+	wanted := map[string]bool{}
+	for _, resourceType := range strings.Split(*types, ",") {
+		if resourceType = strings.TrimSpace(resourceType); resourceType != "" {
+			wanted[resourceType] = true
+		}
+	}
+
+	e := &exporter{
+		outputDir: *outputDir,
+		hclNames:  map[string]map[string]string{},
+		usedHcl:   map[string]bool{},
+	}
+	if err := e.readAll(tm, wanted); err != nil {
+		return err
+	}
+	return e.writeAll()
+}
+
+func envWithDefault(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (e *exporter) readAll(tm *vtm.VirtualTrafficManager, wanted map[string]bool) error {
+	resourcesMap := Provider().(*schema.Provider).ResourcesMap
+	for _, exportable := range exportableResources {
+		if len(wanted) > 0 && !wanted[exportable.resourceType] {
+			continue
+		}
+		resource := resourcesMap[exportable.resourceType]
+		objectNames := []string{exportable.singletonId}
+		if exportable.list != nil {
+			objectList, err := exportable.list(tm)
+			if err != nil {
+				return fmt.Errorf("Failed to list %s objects: %v", exportable.resourceType, err.ErrorText)
+			}
+			objectNames = *objectList
+			sort.Strings(objectNames)
+		}
+		for _, objectName := range objectNames {
+			d := resource.Data(&terraform.InstanceState{ID: objectName})
+			if err := resource.Read(d, tm); err != nil {
+				log.Printf("[WARN] Skipping %s '%s': %v", exportable.resourceType, objectName, err)
+				continue
+			}
+			if d.Id() == "" {
+				continue
+			}
+			setSectionsFromAliases(exportable.resourceType, d)
+			e.addObject(exportable.resourceType, objectName, d)
+		}
+	}
+	return nil
+}
+
+var hclNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func (e *exporter) addObject(resourceType, objectName string, d *schema.ResourceData) {
+	hclName := strings.ToLower(hclNameInvalidChars.ReplaceAllString(objectName, "_"))
+	if hclName == "" || !(hclName[0] == '_' || (hclName[0] >= 'a' && hclName[0] <= 'z')) {
+		hclName = "_" + hclName
+	}
+	candidate := hclName
+	for i := 2; e.usedHcl[resourceType+"."+candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", hclName, i)
+	}
+	e.usedHcl[resourceType+"."+candidate] = true
+	if e.hclNames[resourceType] == nil {
+		e.hclNames[resourceType] = map[string]string{}
+	}
+	e.hclNames[resourceType][objectName] = candidate
+	e.objects = append(e.objects, &exportedObject{
+		resourceType: resourceType,
+		objectName:   objectName,
+		hclName:      candidate,
+		data:         d,
+	})
+}
+
+func (e *exporter) writeAll() error {
+	if err := os.MkdirAll(e.outputDir, 0755); err != nil {
+		return err
+	}
+	resourcesMap := Provider().(*schema.Provider).ResourcesMap
+	configs := map[string]*bytes.Buffer{}
+	var imports bytes.Buffer
+	imports.WriteString("#!/bin/sh\nset -e\n\n")
+
+	for _, object := range e.objects {
+		resource := resourcesMap[object.resourceType]
+		config, ok := configs[object.resourceType]
+		if !ok {
+			config = &bytes.Buffer{}
+			configs[object.resourceType] = config
+		}
+		fmt.Fprintf(config, "resource \"%s\" \"%s\" {\n", object.resourceType, object.hclName)
+		if _, ok := resource.Schema["name"]; ok {
+			fmt.Fprintf(config, "  name = %s\n", hclString(object.objectName))
+		}
+		for _, field := range sortedSchemaKeys(resource.Schema) {
+			fieldSchema := resource.Schema[field]
+			if field == "name" || skipExportField(field, resource.Schema) {
+				continue
+			}
+			value := object.data.Get(field)
+			if field == "content" {
+				if err := e.writeContent(config, object, resource.Schema, value.(string)); err != nil {
+					return err
+				}
+				continue
+			}
+			if isDefaultValue(fieldSchema, value) {
+				continue
+			}
+			e.writeField(config, object, "  ", "", field, fieldSchema, value)
+		}
+		config.WriteString("}\n\n")
+
+		importId := object.objectName
+		if importId == "" {
+			importId = object.data.Id()
+		}
+		fmt.Fprintf(&imports, "terraform import %s %s\n",
+			shellQuote(object.resourceType+"."+object.hclName), shellQuote(importId))
+	}
+
+	for resourceType, config := range configs {
+		path := filepath.Join(e.outputDir, resourceType+".tf")
+		if err := ioutil.WriteFile(path, []byte(config.String()), 0644); err != nil {
+			return err
+		}
+	}
+	if e.variables.Len() > 0 {
+		if err := ioutil.WriteFile(filepath.Join(e.outputDir, "variables.tf"), []byte(e.variables.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(filepath.Join(e.outputDir, "import.sh"), []byte(imports.String()), 0755)
+}
+
+// writeContent writes the content of file-like objects (rules, extra files,
+// scripts) to a separate file. Objects that can be uploaded from a local
+// file refer to it with "source", so that binary content is kept byte for
+// byte, and rules read it with file(). Secret content is exported as a
+// variable instead.
+func (e *exporter) writeContent(out *bytes.Buffer, object *exportedObject, resourceSchema map[string]*schema.Schema, content string) error {
+	_, hasSource := resourceSchema["source"]
+	if isExportSecretField(object.resourceType, "content") {
+		if content == "" {
+			return nil
+		}
+		if _, ok := resourceSchema["content_base64"]; ok {
+			fmt.Fprintf(out, "  content_base64 = %s\n", e.addVariable(object, "content_base64"))
+		} else {
+			fmt.Fprintf(out, "  content = %s\n", e.addVariable(object, "content"))
+		}
+		return nil
+	}
+	path, err := e.writeContentFile(object, content)
+	if err != nil {
+		return err
+	}
+	if hasSource {
+		fmt.Fprintf(out, "  source = \"${path.module}/%s\"\n", path)
+	} else {
+		fmt.Fprintf(out, "  content = \"${file(\"${path.module}/%s\")}\"\n", path)
+	}
+	return nil
+}
+
+// writeContentFile stores the content of a file-like object alongside the
+// HCL, and returns its path relative to the output directory.
+func (e *exporter) writeContentFile(object *exportedObject, content string) (string, error) {
+	directory := strings.TrimPrefix(object.resourceType, "vtm_")
+	if object.resourceType == "vtm_rule" {
+		directory = "rules"
+	}
+	if err := os.MkdirAll(filepath.Join(e.outputDir, directory), 0755); err != nil {
+		return "", err
+	}
+	path := directory + "/" + object.hclName
+	return path, ioutil.WriteFile(filepath.Join(e.outputDir, path), []byte(content), 0644)
+}
+
+// addVariable declares a variable for a secret field of an object, and
+// returns a reference to it.
+func (e *exporter) addVariable(object *exportedObject, field string) string {
+	variable := fmt.Sprintf("%s_%s_%s", object.resourceType, object.hclName, strings.Replace(field, ".", "_", -1))
+	fmt.Fprintf(&e.variables, "variable \"%s\" {}\n", variable)
+	return fmt.Sprintf("\"${var.%s}\"", variable)
+}
+
+// writeField writes a field, or a block for each row of a table. The path
+// is the name of the enclosing block, followed by a dot, for nested fields.
+func (e *exporter) writeField(out *bytes.Buffer, object *exportedObject, indent, path, field string, fieldSchema *schema.Schema, value interface{}) {
+	if rowResource, ok := fieldSchema.Elem.(*schema.Resource); ok {
+		for _, row := range exportListValues(value) {
+			fmt.Fprintf(out, "%s%s {\n", indent, field)
+			rowMap := row.(map[string]interface{})
+			for _, rowField := range sortedSchemaKeys(rowResource.Schema) {
+				rowFieldSchema := rowResource.Schema[rowField]
+				if rowValue, ok := rowMap[rowField]; ok && !isDefaultValue(rowFieldSchema, rowValue) {
+					e.writeField(out, object, indent+"  ", path+field+".", rowField, rowFieldSchema, rowValue)
+				}
+			}
+			fmt.Fprintf(out, "%s}\n", indent)
+		}
+		return
+	}
+	if isExportSecretField(object.resourceType, path+field) {
+		fmt.Fprintf(out, "%s%s = %s\n", indent, field, e.addVariable(object, path+field))
+		return
+	}
+	fmt.Fprintf(out, "%s%s = %s\n", indent, field, e.hclValue(field, value))
+}
+
+func (e *exporter) hclValue(field string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return e.hclReference(field, v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *schema.Set, []interface{}:
+		items := []string{}
+		for _, item := range exportListValues(v) {
+			items = append(items, e.hclValue(field, item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := []string{}
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", hclString(key), e.hclValue("", v[key])))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return hclString(fmt.Sprintf("%v", value))
+}
+
+func (e *exporter) hclReference(field, value string) string {
+	if resourceType, ok := exportReferenceFields[field]; ok {
+		if hclName, ok := e.hclNames[resourceType][value]; ok {
+			return fmt.Sprintf("\"${%s.%s.name}\"", resourceType, hclName)
+		}
+	}
+	return hclString(value)
+}
+
+// exportListValues returns the items of a list or set, with sets sorted so
+// that the output is stable between runs.
+func exportListValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case *schema.Set:
+		items := v.List()
+		sort.Slice(items, func(i, j int) bool {
+			return fmt.Sprintf("%v", items[i]) < fmt.Sprintf("%v", items[j])
+		})
+		return items
+	}
+	return nil
+}
+
+// skipExportField reports whether a field should be left out of the HCL:
+// computed-only fields, deprecated aliases of fields in nested sections, and
+// the JSON form of tables that are written as blocks.
+func skipExportField(field string, resourceSchema map[string]*schema.Schema) bool {
+	if resourceSchema[field].Computed && !resourceSchema[field].Optional {
+		return true
+	}
+	if resourceSchema[field].Deprecated != "" {
+		return true
+	}
+	if strings.HasSuffix(field, "_json") {
+		_, hasTable := resourceSchema[strings.TrimSuffix(field, "_json")]
+		return hasTable
+	}
+	return false
+}
+
+// isDefaultValue reports whether value matches the schema default, or the
+// zero value when the field has no default.
+func isDefaultValue(fieldSchema *schema.Schema, value interface{}) bool {
+	if fieldSchema.Default != nil {
+		return reflect.DeepEqual(fieldSchema.Default, value)
+	}
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	case []map[string]interface{}:
+		return len(v) == 0
+	case *schema.Set:
+		return v.Len() == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func hclString(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
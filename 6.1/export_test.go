@@ -0,0 +1,149 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestHclString(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line one\nline two", `"line one\nline two"`},
+		{`C:\path`, `"C:\\path"`},
+		{"${var.x}", `"$${var.x}"`},
+	}
+
+	for _, table := range tables {
+		if output := hclString(table.input); output != table.output {
+			t.Errorf("hclString failed: %s -> %s (expected %s)", table.input, output, table.output)
+		}
+	}
+}
+
+func TestIsDefaultValue(t *testing.T) {
+	tables := []struct {
+		fieldSchema *schema.Schema
+		value       interface{}
+		result      bool
+	}{
+		{&schema.Schema{Type: schema.TypeInt, Default: 50}, 50, true},
+		{&schema.Schema{Type: schema.TypeInt, Default: 50}, 0, false},
+		{&schema.Schema{Type: schema.TypeString}, "", true},
+		{&schema.Schema{Type: schema.TypeString}, "value", false},
+		{&schema.Schema{Type: schema.TypeBool, Default: true}, false, false},
+		{&schema.Schema{Type: schema.TypeList}, []interface{}{}, true},
+		{&schema.Schema{Type: schema.TypeList}, []interface{}{"a"}, false},
+	}
+
+	for _, table := range tables {
+		if result := isDefaultValue(table.fieldSchema, table.value); result != table.result {
+			t.Errorf("isDefaultValue failed: %#v -> %t", table.value, result)
+		}
+	}
+}
+
+func TestExportReferences(t *testing.T) {
+	e := &exporter{
+		hclNames: map[string]map[string]string{},
+		usedHcl:  map[string]bool{},
+	}
+	e.addObject("vtm_pool", "web pool", nil)
+	e.addObject("vtm_pool", "web-pool", nil)
+	e.addObject("vtm_rule", "1st", nil)
+
+	tables := []struct {
+		field  string
+		value  interface{}
+		output string
+	}{
+		{"pool", "web pool", `"${vtm_pool.web_pool.name}"`},
+		{"failure_pool", "web-pool", `"${vtm_pool.web-pool.name}"`},
+		{"pool", "unknown", `"unknown"`},
+		{"note", "web pool", `"web pool"`},
+		{"request_rules", []interface{}{"1st", "other"}, `["${vtm_rule._1st.name}", "other"]`},
+	}
+
+	for _, table := range tables {
+		if output := e.hclValue(table.field, table.value); output != table.output {
+			t.Errorf("hclValue failed: %s=%v -> %s (expected %s)", table.field, table.value, output, table.output)
+		}
+	}
+}
+
+func TestExportContentAndSecrets(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "TestExportContentAndSecrets")
+	if err != nil {
+		t.Fatalf("Creating temporary directory failed: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	e := &exporter{
+		outputDir: outputDir,
+		hclNames:  map[string]map[string]string{},
+		usedHcl:   map[string]bool{},
+	}
+	resourcesMap := Provider().(*schema.Provider).ResourcesMap
+	objects := []struct {
+		resourceType string
+		objectName   string
+		fields       map[string]interface{}
+	}{
+		{"vtm_extra_file", "binary", map[string]interface{}{"content": "\x00\xff\r\n"}},
+		{"vtm_rule", "rule", map[string]interface{}{"content": "http.redirect(\"/\");"}},
+		{"vtm_kerberos_keytab", "keytab", map[string]interface{}{"content": "\x05\x02"}},
+		{"vtm_license_key", "license", map[string]interface{}{"content": "licence"}},
+		{"vtm_user_authenticator", "ldap", map[string]interface{}{"ldap_search_password": "secret", "ldap_server": "ldap.example.com"}},
+	}
+	for _, object := range objects {
+		d := resourcesMap[object.resourceType].Data(&terraform.InstanceState{ID: object.objectName})
+		for field, value := range object.fields {
+			d.Set(field, value)
+		}
+		e.addObject(object.resourceType, object.objectName, d)
+	}
+	if err := e.writeAll(); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	readOutput := func(path string) string {
+		data, err := ioutil.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatalf("Reading %s failed: %v", path, err)
+		}
+		return string(data)
+	}
+	expected := map[string]string{
+		"vtm_extra_file.tf":         `source = "${path.module}/extra_file/binary"`,
+		"vtm_rule.tf":               `content = "${file("${path.module}/rules/rule")}"`,
+		"vtm_kerberos_keytab.tf":    `content_base64 = "${var.vtm_kerberos_keytab_keytab_content_base64}"`,
+		"vtm_license_key.tf":        `content = "${var.vtm_license_key_license_content}"`,
+		"vtm_user_authenticator.tf": `ldap_search_password = "${var.vtm_user_authenticator_ldap_ldap_search_password}"`,
+		"variables.tf":              `variable "vtm_license_key_license_content" {}`,
+	}
+	for path, line := range expected {
+		if output := readOutput(path); !strings.Contains(output, line) {
+			t.Errorf("%s does not contain %s:\n%s", path, line, output)
+		}
+	}
+	if content := readOutput("extra_file/binary"); content != "\x00\xff\r\n" {
+		t.Errorf("Extra file content was not written byte for byte: %q", content)
+	}
+	for _, path := range []string{"kerberos_keytab", "license_key"} {
+		if _, err := os.Stat(filepath.Join(outputDir, path)); err == nil {
+			t.Errorf("Secret content was written to %s", path)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// extraFileDirectoryFile is a local file to be kept as an extra file.
+type extraFileDirectoryFile struct {
+	path string
+	hash string
+}
+
+// matchExtraFilePattern reports whether a glob pattern matches a file's
+// path relative to the directory, or its base name.
+func matchExtraFilePattern(pattern, relative string) bool {
+	if matched, _ := path.Match(pattern, relative); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, path.Base(relative))
+	return matched
+}
+
+func validateExtraFilePattern(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := path.Match(v.(string), ""); err != nil {
+		errors = append(errors, fmt.Errorf("%q is not a valid pattern: %v", k, err))
+	}
+	return
+}
+
+// extraFileDirectoryName returns the extra file name for a file's path
+// relative to the directory. Extra files cannot be in directories, so "/"
+// is replaced by "_".
+func extraFileDirectoryName(prefix, relative string) string {
+	return prefix + strings.Replace(relative, "/", "_", -1)
+}
+
+// scanExtraFileDirectory returns the files under sourceDir that match one of
+// the include patterns, or all files if there are none, and none of the
+// exclude patterns, by extra file name. Directories whose names start with
+// "." (such as ".git") are skipped.
+func scanExtraFileDirectory(sourceDir, prefix string, include, exclude []string) (map[string]extraFileDirectoryFile, error) {
+	files := map[string]extraFileDirectoryFile{}
+	relatives := map[string]string{}
+	err := filepath.Walk(sourceDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if file != sourceDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relative, err := filepath.Rel(sourceDir, file)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		included := len(include) == 0
+		for _, pattern := range include {
+			included = included || matchExtraFilePattern(pattern, relative)
+		}
+		for _, pattern := range exclude {
+			included = included && !matchExtraFilePattern(pattern, relative)
+		}
+		if !included {
+			return nil
+		}
+
+		name := extraFileDirectoryName(prefix, relative)
+		if other, ok := relatives[name]; ok {
+			return fmt.Errorf("'%s' and '%s' would both be uploaded as '%s'", other, relative, name)
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		relatives[name] = relative
+		files[name] = extraFileDirectoryFile{path: file, hash: fileContentHash(content)}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read source_dir '%s': %v", sourceDir, err)
+	}
+	return files, nil
+}
+
+// extraFileDirectoryHashes returns the hashes of files by name.
+func extraFileDirectoryHashes(files map[string]extraFileDirectoryFile) map[string]string {
+	hashes := map[string]string{}
+	for name, file := range files {
+		hashes[name] = file.hash
+	}
+	return hashes
+}
+
+// planExtraFileSync returns the names of the local files that are missing
+// or different on the traffic manager, and of the remote files that no
+// longer exist locally.
+func planExtraFileSync(local, remote map[string]string) (upload []string, remove []string) {
+	for name, hash := range local {
+		if remote[name] != hash {
+			upload = append(upload, name)
+		}
+	}
+	for name := range remote {
+		if _, ok := local[name]; !ok {
+			remove = append(remove, name)
+		}
+	}
+	sort.Strings(upload)
+	sort.Strings(remove)
+	return upload, remove
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanExtraFileDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extra_file_directory")
+	if err != nil {
+		t.Fatalf("Creating temporary directory failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"maintenance.html":  "<html>down</html>",
+		"errors/404.html":   "<html>missing</html>",
+		"errors/notes.txt":  "not uploaded",
+		"lookup.csv":        "a,b",
+		".git/config":       "skipped",
+		"errors/draft.html": "excluded",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Writing %s failed: %v", name, err)
+		}
+	}
+
+	files, err := scanExtraFileDirectory(dir, "site_", []string{"*.html", "lookup.csv"}, []string{"errors/draft.*"})
+	if err != nil {
+		t.Fatalf("Scanning directory failed: %v", err)
+	}
+	expected := map[string]string{
+		"site_maintenance.html": fileContentHash([]byte("<html>down</html>")),
+		"site_errors_404.html":  fileContentHash([]byte("<html>missing</html>")),
+		"site_lookup.csv":       fileContentHash([]byte("a,b")),
+	}
+	if hashes := extraFileDirectoryHashes(files); !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("Scanning directory found %v, expected %v", hashes, expected)
+	}
+	if path := files["site_errors_404.html"].path; path != filepath.Join(dir, "errors", "404.html") {
+		t.Errorf("site_errors_404.html was read from %s", path)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "errors_404.html"), []byte("clash"), 0644)
+	if _, err := scanExtraFileDirectory(dir, "", nil, nil); err == nil {
+		t.Errorf("Files with the same extra file name were accepted")
+	}
+	if _, err := scanExtraFileDirectory(filepath.Join(dir, "missing"), "", nil, nil); err == nil {
+		t.Errorf("Scanning a missing directory succeeded")
+	}
+}
+
+func TestPlanExtraFileSync(t *testing.T) {
+	local := map[string]string{"a": "1", "b": "2", "c": "3"}
+	remote := map[string]string{"a": "1", "b": "changed", "d": "4"}
+	upload, remove := planExtraFileSync(local, remote)
+	if expected := []string{"b", "c"}; !reflect.DeepEqual(upload, expected) {
+		t.Errorf("Planned uploads %v, expected %v", upload, expected)
+	}
+	if expected := []string{"d"}; !reflect.DeepEqual(remove, expected) {
+		t.Errorf("Planned deletions %v, expected %v", remove, expected)
+	}
+}
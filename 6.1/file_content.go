@@ -0,0 +1,188 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// File-like objects (extra files, action programs, monitor scripts and
+// keytabs) can be given as text in "content", as base64 in
+// "content_base64", or as a local file in "source". The last two are
+// uploaded byte for byte, and only their SHA-256 hash is kept in the state,
+// as "source_hash".
+
+// addFileContentSchema adds the alternatives to "content" to the schema of
+// a file-like object.
+func addFileContentSchema(fields map[string]*schema.Schema) map[string]*schema.Schema {
+	fields["content"].ConflictsWith = []string{"content_base64", "source"}
+
+	// Object content encoded as base64, for binary files. Only a hash
+	//  is kept in the state.
+	fields["content_base64"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"content", "source"},
+		ValidateFunc:  validateFileContentBase64,
+		StateFunc:     hashFileContentBase64,
+	}
+
+	// The path of a local file to upload as the object content
+	fields["source"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"content", "content_base64"},
+	}
+
+	// The SHA-256 hash of the object content, in hex
+	fields["source_hash"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	return fields
+}
+
+func fileContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func validateFileContentBase64(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := base64.StdEncoding.DecodeString(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q is not valid base64: %v", k, err))
+	}
+	return
+}
+
+// hashFileContentBase64 stores the hash of the decoded content in place of
+// the content itself.
+func hashFileContentBase64(v interface{}) string {
+	content, err := base64.StdEncoding.DecodeString(v.(string))
+	if err != nil {
+		return ""
+	}
+	return fileContentHash(content)
+}
+
+// getFileContentBytes returns the content to upload from whichever of the
+// content attributes is set.
+func getFileContentBytes(content, contentBase64, source string) ([]byte, error) {
+	switch {
+	case source != "":
+		data, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read source '%s': %v", source, err)
+		}
+		return data, nil
+	case contentBase64 != "":
+		data, err := base64.StdEncoding.DecodeString(contentBase64)
+		if err != nil {
+			return nil, fmt.Errorf("content_base64 is not valid base64: %v", err)
+		}
+		return data, nil
+	}
+	return []byte(content), nil
+}
+
+// fileContentCustomizeDiff checks that exactly one of the content
+// attributes is set, and plans an upload when the local content no longer
+// matches the hash of the content on the traffic manager. Values that are
+// not known until apply read as empty strings.
+func fileContentCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	content := d.Get("content").(string)
+	contentBase64 := d.Get("content_base64").(string)
+	source := d.Get("source").(string)
+	set := 0
+	for _, value := range []string{content, contentBase64, source} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("Only one of content, content_base64 and source may be set")
+	}
+	if set == 0 {
+		if d.HasChange("content") || d.HasChange("content_base64") || d.HasChange("source") {
+			return d.SetNewComputed("source_hash")
+		}
+		return fmt.Errorf("One of content, content_base64 or source must be set")
+	}
+	var hash string
+	if contentBase64 != "" && !d.HasChange("content_base64") {
+		// The state only holds the hash of unchanged content_base64.
+		hash = contentBase64
+	} else {
+		data, err := getFileContentBytes(content, contentBase64, source)
+		if err != nil {
+			return err
+		}
+		hash = fileContentHash(data)
+	}
+	if hash != d.Get("source_hash").(string) {
+		return d.SetNew("source_hash", hash)
+	}
+	return nil
+}
+
+// readFileContent reads the raw content of a file-like object, sets its
+// hash, and sets "content" unless the content is managed with
+// "content_base64" or "source". The content is returned for resources that
+// read more from it. If the object no longer exists, the ID is cleared and
+// no content is returned.
+func readFileContent(d *schema.ResourceData, tm interface{}, objectType, objectName string) ([]byte, error) {
+	client, err := getRestClient(tm)
+	if err != nil {
+		return nil, err
+	}
+	data, readErr := client.getFile(configPath(objectType, objectName))
+	if readErr != nil {
+		if readErr.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil, nil
+		}
+		return nil, readErr
+	}
+	hash := fileContentHash(data)
+	d.Set("source_hash", hash)
+	if contentBase64 := d.Get("content_base64").(string); contentBase64 != "" {
+		// Content changed outside Terraform cannot be put back from the
+		// hash in the state, so clear it to have the configured content
+		// uploaded again.
+		if contentBase64 != hash {
+			d.Set("content_base64", "")
+		}
+	} else if d.Get("source").(string) == "" {
+		d.Set("content", string(data))
+	}
+	return data, nil
+}
+
+// writeFileContent uploads the content of a file-like object unchanged.
+func writeFileContent(d *schema.ResourceData, tm interface{}, objectType, objectName string) error {
+	contentBase64 := d.Get("content_base64").(string)
+	if contentBase64 != "" && !d.HasChange("content_base64") {
+		// The state only holds the hash of unchanged content_base64, and
+		// the content on the traffic manager already matches it.
+		return nil
+	}
+	data, err := getFileContentBytes(d.Get("content").(string), contentBase64, d.Get("source").(string))
+	if err != nil {
+		return err
+	}
+	client, err := getRestClient(tm)
+	if err != nil {
+		return err
+	}
+	if writeErr := client.putFile(configPath(objectType, objectName), data); writeErr != nil {
+		return writeErr
+	}
+	d.Set("source_hash", fileContentHash(data))
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGetFileContentBytes(t *testing.T) {
+	binary := []byte{0x05, 0x02, 0x00, 0xff, 0x0d, 0x0a}
+	source, err := ioutil.TempFile("", "file_content")
+	if err != nil {
+		t.Fatalf("Creating temporary file failed: %v", err)
+	}
+	defer os.Remove(source.Name())
+	source.Write(binary)
+	source.Close()
+
+	tables := []struct {
+		content, contentBase64, source string
+		expected                       []byte
+	}{
+		{"text\r\n", "", "", []byte("text\r\n")},
+		{"", "BQIA/w0K", "", binary},
+		{"", "", source.Name(), binary},
+		{"", "", "", []byte{}},
+	}
+	for _, table := range tables {
+		data, err := getFileContentBytes(table.content, table.contentBase64, table.source)
+		if err != nil {
+			t.Errorf("Getting content from %q/%q/%q failed: %v", table.content, table.contentBase64, table.source, err)
+		} else if !bytes.Equal(data, table.expected) {
+			t.Errorf("Content from %q/%q/%q was %v, expected %v", table.content, table.contentBase64, table.source, data, table.expected)
+		}
+	}
+
+	if _, err := getFileContentBytes("", "", source.Name()+".missing"); err == nil {
+		t.Errorf("Reading a missing source file succeeded")
+	}
+	if hash := hashFileContentBase64("BQIA/w0K"); hash != fileContentHash(binary) {
+		t.Errorf("content_base64 was stored as %s, expected the hash of the decoded content", hash)
+	}
+	if _, errors := validateFileContentBase64("BQIA/w0", "content_base64"); len(errors) != 1 {
+		t.Errorf("Invalid base64 was accepted")
+	}
+}
+
+func TestFileContentCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema: addFileContentSchema(map[string]*schema.Schema{
+			"content": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		}),
+		CustomizeDiff: fileContentCustomizeDiff,
+	}
+	tables := []struct {
+		raw   map[string]interface{}
+		valid bool
+	}{
+		{map[string]interface{}{"content": "text"}, true},
+		{map[string]interface{}{"content_base64": "BQIA/w0K"}, true},
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"content": ""}, false},
+	}
+	for _, table := range tables {
+		rawConfig, err := config.NewRawConfig(table.raw)
+		if err != nil {
+			t.Fatalf("Building configuration %v failed: %v", table.raw, err)
+		}
+		diff, err := r.Diff(nil, terraform.NewResourceConfig(rawConfig), nil)
+		if table.valid && err != nil {
+			t.Errorf("Planning %v failed: %v", table.raw, err)
+		} else if !table.valid && err == nil {
+			t.Errorf("Planning %v succeeded, expected an error", table.raw)
+		} else if table.valid && diff.Attributes["source_hash"] == nil {
+			t.Errorf("Planning %v did not plan source_hash", table.raw)
+		}
+	}
+}
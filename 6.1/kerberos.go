@@ -0,0 +1,425 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// krb5Relation is a "tag = value" line of a krb5.conf file, or a
+// "tag = { ... }" subsection when values is set.
+type krb5Relation struct {
+	line   int
+	tag    string
+	value  string
+	values []*krb5Relation
+}
+
+// krb5Realm holds the settings of a realm in the [realms] section.
+type krb5Realm struct {
+	name          string
+	kdcs          []string
+	adminServers  []string
+	defaultDomain string
+}
+
+// krb5Conf holds the parts of a krb5.conf file that the traffic manager
+// uses to find the realm of a principal and its KDCs.
+type krb5Conf struct {
+	sections    map[string][]*krb5Relation
+	libdefaults map[string]string
+	realms      []*krb5Realm
+	domainRealm map[string]string
+}
+
+// parseKrb5Conf reads a krb5.conf file in the MIT profile format: sections
+// in square brackets holding "tag = value" relations, which may be
+// subsections in braces. Lines starting with "#" or ";" are comments, and
+// "include", "includedir" and "module" directives are allowed before any
+// section.
+func parseKrb5Conf(content string) (*krb5Conf, error) {
+	conf := &krb5Conf{
+		sections:    map[string][]*krb5Relation{},
+		libdefaults: map[string]string{},
+		realms:      []*krb5Realm{},
+		domainRealm: map[string]string{},
+	}
+	section := ""
+	stack := []*krb5Relation{}
+	for number, line := range strings.Split(content, "\n") {
+		number++
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if len(stack) > 0 {
+				return nil, fmt.Errorf("line %d: section starts before '%s' is closed", number, stack[len(stack)-1].tag)
+			}
+			end := strings.Index(line, "]")
+			if end < 2 {
+				return nil, fmt.Errorf("line %d: invalid section header '%s'", number, line)
+			}
+			section = strings.TrimSpace(line[1:end])
+			if _, ok := conf.sections[section]; !ok {
+				conf.sections[section] = []*krb5Relation{}
+			}
+			continue
+		}
+		if section == "" {
+			if fields := strings.Fields(line); len(fields) == 2 && (fields[0] == "include" || fields[0] == "includedir" || fields[0] == "module") {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: '%s' is not in a section", number, line)
+		}
+		if strings.HasPrefix(line, "}") {
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: '}' without a matching '{'", number)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		equals := strings.Index(line, "=")
+		if equals < 1 {
+			return nil, fmt.Errorf("line %d: expected 'tag = value', found '%s'", number, line)
+		}
+		relation := &krb5Relation{line: number, tag: strings.TrimSpace(line[:equals])}
+		value := strings.TrimSpace(line[equals+1:])
+		if value == "{" {
+			relation.values = []*krb5Relation{}
+		} else {
+			// A trailing "*" marks the value as final
+			relation.value = strings.TrimSpace(strings.TrimSuffix(value, "*"))
+		}
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			parent.values = append(parent.values, relation)
+		} else {
+			conf.sections[section] = append(conf.sections[section], relation)
+		}
+		if relation.values != nil {
+			stack = append(stack, relation)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("line %d: '%s' is not closed", stack[len(stack)-1].line, stack[len(stack)-1].tag)
+	}
+
+	for _, relation := range conf.sections["libdefaults"] {
+		if relation.values == nil {
+			conf.libdefaults[relation.tag] = relation.value
+		}
+	}
+	for _, relation := range conf.sections["realms"] {
+		if relation.values == nil {
+			return nil, fmt.Errorf("line %d: realm '%s' must be a subsection in braces", relation.line, relation.tag)
+		}
+		realm := &krb5Realm{name: relation.tag, kdcs: []string{}, adminServers: []string{}}
+		for _, setting := range relation.values {
+			switch setting.tag {
+			case "kdc":
+				realm.kdcs = append(realm.kdcs, setting.value)
+			case "admin_server":
+				realm.adminServers = append(realm.adminServers, setting.value)
+			case "default_domain":
+				realm.defaultDomain = setting.value
+			}
+		}
+		conf.realms = append(conf.realms, realm)
+	}
+	for _, relation := range conf.sections["domain_realm"] {
+		conf.domainRealm[relation.tag] = relation.value
+	}
+	if err := conf.checkRealms(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// validateKrb5Conf warns about content that is not a valid krb5.conf file.
+// The traffic manager accepts any content, so it is not rejected.
+func validateKrb5Conf(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := parseKrb5Conf(v.(string)); err != nil {
+		ws = append(ws, fmt.Sprintf("%q is not a valid krb5.conf file: %v", k, err))
+	}
+	return
+}
+
+// checkRealms checks that the default realm and the realms that domains map
+// to are defined in [realms]. Realms that are not defined can only be found
+// through DNS, so they are allowed unless dns_lookup_kdc is turned off, but
+// a realm that differs only in case from a defined realm is always an error,
+// as realm names are case sensitive.
+func (conf *krb5Conf) checkRealms() error {
+	defined := map[string]bool{}
+	folded := map[string]string{}
+	for _, realm := range conf.realms {
+		defined[realm.name] = true
+		folded[strings.ToUpper(realm.name)] = realm.name
+	}
+	dnsLookup := true
+	switch strings.ToLower(conf.libdefaults["dns_lookup_kdc"]) {
+	case "false", "no", "0", "off":
+		dnsLookup = false
+	}
+	check := func(what, realm string) error {
+		if defined[realm] {
+			return nil
+		}
+		if name, ok := folded[strings.ToUpper(realm)]; ok {
+			return fmt.Errorf("%s realm '%s' is not defined in [realms]; realm names are case sensitive, did you mean '%s'?", what, realm, name)
+		}
+		if !dnsLookup && len(conf.realms) > 0 {
+			return fmt.Errorf("%s realm '%s' is not defined in [realms], and dns_lookup_kdc is off", what, realm)
+		}
+		return nil
+	}
+
+	if realm, ok := conf.libdefaults["default_realm"]; ok {
+		if err := check("default_realm", realm); err != nil {
+			return err
+		}
+	}
+	for _, domain := range sortedStringMapKeys(conf.domainRealm) {
+		if err := check(fmt.Sprintf("domain_realm '%s' maps to", domain), conf.domainRealm[domain]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateKrb5Conf writes a krb5.conf file from its sections.
+func generateKrb5Conf(libdefaults map[string]string, realms []*krb5Realm, domainRealm map[string]string) string {
+	var buffer bytes.Buffer
+	if len(libdefaults) > 0 {
+		buffer.WriteString("[libdefaults]\n")
+		for _, tag := range sortedStringMapKeys(libdefaults) {
+			buffer.WriteString(fmt.Sprintf("\t%s = %s\n", tag, libdefaults[tag]))
+		}
+	}
+	if len(realms) > 0 {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString("[realms]\n")
+		for _, realm := range realms {
+			buffer.WriteString(fmt.Sprintf("\t%s = {\n", realm.name))
+			for _, kdc := range realm.kdcs {
+				buffer.WriteString(fmt.Sprintf("\t\tkdc = %s\n", kdc))
+			}
+			for _, adminServer := range realm.adminServers {
+				buffer.WriteString(fmt.Sprintf("\t\tadmin_server = %s\n", adminServer))
+			}
+			if realm.defaultDomain != "" {
+				buffer.WriteString(fmt.Sprintf("\t\tdefault_domain = %s\n", realm.defaultDomain))
+			}
+			buffer.WriteString("\t}\n")
+		}
+	}
+	if len(domainRealm) > 0 {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString("[domain_realm]\n")
+		for _, domain := range sortedStringMapKeys(domainRealm) {
+			buffer.WriteString(fmt.Sprintf("\t%s = %s\n", domain, domainRealm[domain]))
+		}
+	}
+	return buffer.String()
+}
+
+// keytabEntry is a key in a keytab file.
+type keytabEntry struct {
+	principal string
+	kvno      int
+	enctype   string
+}
+
+// keytabEnctypes are the names of the encryption types, from RFC 3961 and
+// its successors.
+var keytabEnctypes = map[uint16]string{
+	1:  "des-cbc-crc",
+	2:  "des-cbc-md4",
+	3:  "des-cbc-md5",
+	16: "des3-cbc-sha1",
+	17: "aes128-cts-hmac-sha1-96",
+	18: "aes256-cts-hmac-sha1-96",
+	19: "aes128-cts-hmac-sha256-128",
+	20: "aes256-cts-hmac-sha384-192",
+	23: "arcfour-hmac",
+	24: "arcfour-hmac-exp",
+	25: "camellia128-cts-cmac",
+	26: "camellia256-cts-cmac",
+}
+
+// keytabReader reads the big-endian fields of a keytab.
+type keytabReader struct {
+	data []byte
+	err  error
+}
+
+func (r *keytabReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("keytab is truncated")
+		return nil
+	}
+	value := r.data[:n]
+	r.data = r.data[n:]
+	return value
+}
+
+func (r *keytabReader) uint8() uint8 {
+	if value := r.bytes(1); value != nil {
+		return value[0]
+	}
+	return 0
+}
+
+func (r *keytabReader) uint16() uint16 {
+	if value := r.bytes(2); value != nil {
+		return binary.BigEndian.Uint16(value)
+	}
+	return 0
+}
+
+func (r *keytabReader) uint32() uint32 {
+	if value := r.bytes(4); value != nil {
+		return binary.BigEndian.Uint32(value)
+	}
+	return 0
+}
+
+func (r *keytabReader) string() string {
+	return string(r.bytes(int(r.uint16())))
+}
+
+// parseKeytab reads the entries of a keytab file in the version 2 format
+// written by MIT and Heimdal Kerberos and by Active Directory's ktpass.
+// Key material is skipped.
+func parseKeytab(data []byte) ([]keytabEntry, error) {
+	if len(data) < 2 || data[0] != 0x05 {
+		return nil, fmt.Errorf("not a keytab file")
+	}
+	if data[1] != 0x02 {
+		return nil, fmt.Errorf("keytab version %d is not supported, only version 2 is", data[1])
+	}
+	entries := []keytabEntry{}
+	file := &keytabReader{data: data[2:]}
+	for len(file.data) > 0 {
+		size := int32(file.uint32())
+		if size == 0 {
+			// Some writers leave zero padding at the end of the file
+			break
+		}
+		if size < 0 {
+			// Deleted entries are left as holes of -size bytes
+			file.bytes(int(-size))
+			continue
+		}
+		entry := &keytabReader{data: file.bytes(int(size))}
+		if file.err != nil {
+			return nil, file.err
+		}
+		components := int(entry.uint16())
+		realm := entry.string()
+		names := make([]string, components)
+		for i := range names {
+			names[i] = entry.string()
+		}
+		entry.uint32() // name type
+		entry.uint32() // timestamp
+		kvno := int(entry.uint8())
+		enctype := entry.uint16()
+		entry.string() // key
+		if entry.err != nil {
+			return nil, fmt.Errorf("keytab entry %d: %v", len(entries)+1, entry.err)
+		}
+		// Newer keytabs give the full key version after the key
+		if len(entry.data) >= 4 {
+			if kvno32 := entry.uint32(); kvno32 != 0 {
+				kvno = int(kvno32)
+			}
+		}
+		name, ok := keytabEnctypes[enctype]
+		if !ok {
+			name = strconv.Itoa(int(enctype))
+		}
+		entries = append(entries, keytabEntry{
+			principal: strings.Join(names, "/") + "@" + realm,
+			kvno:      kvno,
+			enctype:   name,
+		})
+	}
+	if file.err != nil {
+		return nil, file.err
+	}
+	return entries, nil
+}
+
+// validateKeytab warns about content that is not a keytab. The traffic
+// manager accepts any content, so it is not rejected.
+func validateKeytab(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := parseKeytab([]byte(v.(string))); err != nil {
+		ws = append(ws, fmt.Sprintf("%q is not a valid keytab: %v", k, err))
+	}
+	return
+}
+
+// validateKeytabBase64 rejects content_base64 that is not base64, and warns
+// about content that is not a keytab.
+func validateKeytabBase64(v interface{}, k string) (ws []string, errors []error) {
+	if ws, errors = validateFileContentBase64(v, k); len(errors) > 0 {
+		return
+	}
+	data, _ := base64.StdEncoding.DecodeString(v.(string))
+	return validateKeytab(string(data), k)
+}
+
+// keytabPrincipals returns the principals with keys in a keytab, sorted.
+func keytabPrincipals(entries []keytabEntry) []string {
+	found := map[string]bool{}
+	for _, entry := range entries {
+		found[entry.principal] = true
+	}
+	principals := []string{}
+	for principal := range found {
+		principals = append(principals, principal)
+	}
+	sort.Strings(principals)
+	return principals
+}
+
+// kerberosPrincipalName returns the full name of the principal for a
+// service in a realm. The service may already include the realm, and an
+// empty realm is left to be matched against any realm.
+func kerberosPrincipalName(service, realm string) string {
+	if strings.Contains(service, "@") || realm == "" {
+		return service
+	}
+	return service + "@" + realm
+}
+
+// keytabHasPrincipal reports whether a keytab has a key for a principal.
+// A principal without a realm matches the principal in any realm.
+func keytabHasPrincipal(entries []keytabEntry, principal string) bool {
+	for _, entry := range entries {
+		if entry.principal == principal {
+			return true
+		}
+		if !strings.Contains(principal, "@") && strings.HasPrefix(entry.principal, principal+"@") {
+			return true
+		}
+	}
+	return false
+}
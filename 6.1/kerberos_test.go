@@ -0,0 +1,216 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testKrb5Conf = `
+includedir /etc/krb5.conf.d/
+
+[libdefaults]
+	default_realm = EXAMPLE.COM
+	dns_lookup_kdc = false *
+
+# Realms with explicit KDCs
+[realms]
+	EXAMPLE.COM = {
+		kdc = kdc1.example.com:88
+		kdc = kdc2.example.com:88
+		admin_server = kdc1.example.com
+		default_domain = example.com
+	}
+	CORP.EXAMPLE.COM = {
+		kdc = dc.corp.example.com
+	}
+
+[domain_realm]
+	.example.com = EXAMPLE.COM
+	.corp.example.com = CORP.EXAMPLE.COM
+
+[appdefaults]
+	pam = {
+		debug = false
+		ticket_lifetime = 36000
+	}
+`
+
+func TestParseKrb5Conf(t *testing.T) {
+	conf, err := parseKrb5Conf(testKrb5Conf)
+	if err != nil {
+		t.Fatalf("Parsing krb5.conf failed: %v", err)
+	}
+	if expected := map[string]string{"default_realm": "EXAMPLE.COM", "dns_lookup_kdc": "false"}; !reflect.DeepEqual(conf.libdefaults, expected) {
+		t.Errorf("Parsed libdefaults %v, expected %v", conf.libdefaults, expected)
+	}
+	expectedRealms := []*krb5Realm{
+		{name: "EXAMPLE.COM", kdcs: []string{"kdc1.example.com:88", "kdc2.example.com:88"}, adminServers: []string{"kdc1.example.com"}, defaultDomain: "example.com"},
+		{name: "CORP.EXAMPLE.COM", kdcs: []string{"dc.corp.example.com"}, adminServers: []string{}},
+	}
+	if !reflect.DeepEqual(conf.realms, expectedRealms) {
+		t.Errorf("Parsed realms %+v, expected %+v", conf.realms, expectedRealms)
+	}
+	if expected := map[string]string{".example.com": "EXAMPLE.COM", ".corp.example.com": "CORP.EXAMPLE.COM"}; !reflect.DeepEqual(conf.domainRealm, expected) {
+		t.Errorf("Parsed domain_realm %v, expected %v", conf.domainRealm, expected)
+	}
+
+	generated := generateKrb5Conf(conf.libdefaults, conf.realms, conf.domainRealm)
+	regenerated, err := parseKrb5Conf(generated)
+	if err != nil {
+		t.Fatalf("Parsing generated krb5.conf failed: %v\n%s", err, generated)
+	}
+	if !reflect.DeepEqual(regenerated.realms, conf.realms) || !reflect.DeepEqual(regenerated.domainRealm, conf.domainRealm) {
+		t.Errorf("Generated krb5.conf does not match:\n%s", generated)
+	}
+}
+
+func TestParseKrb5ConfErrors(t *testing.T) {
+	tests := map[string]string{
+		"default_realm = EXAMPLE.COM":                                                "line 1: 'default_realm = EXAMPLE.COM' is not in a section",
+		"[realms]\nEXAMPLE.COM = {\nkdc = kdc.example.com\n":                         "line 2: 'EXAMPLE.COM' is not closed",
+		"[realms]\nEXAMPLE.COM = kdc.example.com":                                    "line 2: realm 'EXAMPLE.COM' must be a subsection in braces",
+		"[libdefaults]\n}":                                                           "line 2: '}' without a matching '{'",
+		"[libdefaults]\nforwardable":                                                 "line 2: expected 'tag = value', found 'forwardable'",
+		strings.Replace(testKrb5Conf, "= CORP.EXAMPLE.COM", "= corp.example.com", 1): "domain_realm '.corp.example.com' maps to realm 'corp.example.com' is not defined in [realms]; realm names are case sensitive, did you mean 'CORP.EXAMPLE.COM'?",
+		strings.Replace(testKrb5Conf, "= CORP.EXAMPLE.COM", "= OTHER.COM", 1):        "domain_realm '.corp.example.com' maps to realm 'OTHER.COM' is not defined in [realms], and dns_lookup_kdc is off",
+	}
+	for content, expected := range tests {
+		if _, err := parseKrb5Conf(content); err == nil || err.Error() != expected {
+			t.Errorf("Parsing %q returned error %v, expected %q", content, err, expected)
+		}
+	}
+
+	// Realms that are not defined can be found through DNS
+	if _, err := parseKrb5Conf(strings.Replace(strings.Replace(testKrb5Conf, "= CORP.EXAMPLE.COM", "= OTHER.COM", 1), "false *", "true", 1)); err != nil {
+		t.Errorf("Parsing krb5.conf with DNS lookup failed: %v", err)
+	}
+}
+
+// testKeytab writes a keytab with an entry for each principal, and a
+// deleted entry.
+func testKeytab(principals ...string) []byte {
+	var keytab bytes.Buffer
+	keytab.Write([]byte{0x05, 0x02})
+	writeString := func(entry *bytes.Buffer, value string) {
+		binary.Write(entry, binary.BigEndian, uint16(len(value)))
+		entry.WriteString(value)
+	}
+	for i, principal := range principals {
+		var entry bytes.Buffer
+		at := strings.Index(principal, "@")
+		components := strings.Split(principal[:at], "/")
+		binary.Write(&entry, binary.BigEndian, uint16(len(components)))
+		writeString(&entry, principal[at+1:])
+		for _, component := range components {
+			writeString(&entry, component)
+		}
+		binary.Write(&entry, binary.BigEndian, uint32(1))
+		binary.Write(&entry, binary.BigEndian, uint32(1514764800))
+		entry.WriteByte(3)
+		binary.Write(&entry, binary.BigEndian, uint16(17+i))
+		writeString(&entry, "0123456789abcdef")
+		if i > 0 {
+			binary.Write(&entry, binary.BigEndian, uint32(300))
+		}
+		binary.Write(&keytab, binary.BigEndian, int32(entry.Len()))
+		keytab.Write(entry.Bytes())
+	}
+	binary.Write(&keytab, binary.BigEndian, int32(-8))
+	keytab.Write(make([]byte, 8))
+	return keytab.Bytes()
+}
+
+func TestParseKeytab(t *testing.T) {
+	entries, err := parseKeytab(testKeytab("HTTP/www.example.com@EXAMPLE.COM", "vtm@CORP.EXAMPLE.COM"))
+	if err != nil {
+		t.Fatalf("Parsing keytab failed: %v", err)
+	}
+	expected := []keytabEntry{
+		{principal: "HTTP/www.example.com@EXAMPLE.COM", kvno: 3, enctype: "aes128-cts-hmac-sha1-96"},
+		{principal: "vtm@CORP.EXAMPLE.COM", kvno: 300, enctype: "aes256-cts-hmac-sha1-96"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Parsed keytab entries %+v, expected %+v", entries, expected)
+	}
+
+	principal := kerberosPrincipalName("HTTP/www.example.com", "EXAMPLE.COM")
+	if !keytabHasPrincipal(entries, principal) || !keytabHasPrincipal(entries, "vtm") {
+		t.Errorf("Keytab entries %+v do not include %s and vtm", entries, principal)
+	}
+	if keytabHasPrincipal(entries, kerberosPrincipalName("HTTP/www.example.com", "CORP.EXAMPLE.COM")) {
+		t.Errorf("Keytab entries %+v include a principal in the wrong realm", entries)
+	}
+
+	if _, err := parseKeytab([]byte("TEST_TEXT")); err == nil {
+		t.Errorf("Parsing text as a keytab succeeded")
+	}
+	keytab := testKeytab("HTTP/www.example.com@EXAMPLE.COM")
+	if _, err := parseKeytab(keytab[:20]); err == nil {
+		t.Errorf("Parsing a truncated keytab succeeded")
+	}
+}
+
+// testMitKeytab was written by MIT Kerberos 1.20's krb5_kt_add_entry, as
+// used by ktutil, with random keys.
+const testMitKeytab = "0502" +
+	"000000570002000b4558414d504c452e434f4d000448545450000f7777772e6578616d706c652e636f6d" +
+	"000000016ad4d35f0300120020c3948b1481d4ebc5b6743ccc3b2858cbffa2ce94b2df7c9a2e7be8a669215318" +
+	"00000003" +
+	"000000470002000b4558414d504c452e434f4d000448545450000f7777772e6578616d706c652e636f6d" +
+	"000000016ad4d35f03001100109ab7c2d8238d52455ca07419240c60b7" +
+	"00000003" +
+	"0000004a00010010434f52502e4558414d504c452e434f4d000376746d" +
+	"000000016ad4d35f0100120020b3550b2c526e9ebeec790f2b89a3fcdfc7256dfba66ba1345d9b75d75853f673" +
+	"00000001"
+
+func TestParseMitKeytab(t *testing.T) {
+	data, err := hex.DecodeString(testMitKeytab)
+	if err != nil {
+		t.Fatalf("Decoding keytab failed: %v", err)
+	}
+	entries, err := parseKeytab(data)
+	if err != nil {
+		t.Fatalf("Parsing keytab failed: %v", err)
+	}
+	expected := []keytabEntry{
+		{principal: "HTTP/www.example.com@EXAMPLE.COM", kvno: 3, enctype: "aes256-cts-hmac-sha1-96"},
+		{principal: "HTTP/www.example.com@EXAMPLE.COM", kvno: 3, enctype: "aes128-cts-hmac-sha1-96"},
+		{principal: "vtm@CORP.EXAMPLE.COM", kvno: 1, enctype: "aes256-cts-hmac-sha1-96"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Parsed keytab entries %+v, expected %+v", entries, expected)
+	}
+	if principals := keytabPrincipals(entries); !reflect.DeepEqual(principals, []string{"HTTP/www.example.com@EXAMPLE.COM", "vtm@CORP.EXAMPLE.COM"}) {
+		t.Errorf("Keytab principals were %v", principals)
+	}
+}
+
+func TestValidateKerberosContent(t *testing.T) {
+	// The traffic manager accepts any content, so content that is not
+	// valid is only warned about
+	if ws, errors := validateKeytab("TEST_TEXT", "content"); len(ws) != 1 || len(errors) != 0 {
+		t.Errorf("Validating text as a keytab gave warnings %v and errors %v", ws, errors)
+	}
+	if ws, errors := validateKeytab(string(testKeytab("vtm@EXAMPLE.COM")), "content"); len(ws) != 0 || len(errors) != 0 {
+		t.Errorf("Validating a keytab gave warnings %v and errors %v", ws, errors)
+	}
+	if ws, errors := validateKeytabBase64("VEVTVF9URVhU", "content_base64"); len(ws) != 1 || len(errors) != 0 {
+		t.Errorf("Validating base64 text as a keytab gave warnings %v and errors %v", ws, errors)
+	}
+	if _, errors := validateKeytabBase64("not base64!", "content_base64"); len(errors) != 1 {
+		t.Errorf("Validating content_base64 that is not base64 gave errors %v", errors)
+	}
+	if ws, errors := validateKrb5Conf("TEST_TEXT", "content"); len(ws) != 1 || len(errors) != 0 {
+		t.Errorf("Validating text as a krb5.conf file gave warnings %v and errors %v", ws, errors)
+	}
+	if ws, errors := validateKrb5Conf(testKrb5Conf, "content"); len(ws) != 0 || len(errors) != 0 {
+		t.Errorf("Validating a krb5.conf file gave warnings %v and errors %v", ws, errors)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// licenseKey holds the details read from the text of a license key. Fields
+// are read from lines of "name: value" or "name = value", and anything else,
+// such as the signature, is skipped; every field is kept in fields, by its
+// normalised name.
+type licenseKey struct {
+	fields    map[string]string
+	serial    string
+	expiry    time.Time
+	bandwidth int
+	features  []string
+}
+
+var licenseKeyFieldPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9 _-]*?)\s*[:=]\s*(.*)$`)
+
+// licenseKeyFieldAliases maps the names used for each field, after
+// normalising, to the field they hold.
+var licenseKeyFieldAliases = map[string]string{
+	"serial":           "serial",
+	"serial_number":    "serial",
+	"serialnumber":     "serial",
+	"expires":          "expiry",
+	"expiry":           "expiry",
+	"expiry_date":      "expiry",
+	"expiration":       "expiry",
+	"expiration_date":  "expiry",
+	"expires_on":       "expiry",
+	"valid_until":      "expiry",
+	"bandwidth":        "bandwidth",
+	"bandwidth_limit":  "bandwidth",
+	"max_bandwidth":    "bandwidth",
+	"throughput":       "bandwidth",
+	"features":         "features",
+	"feature":          "features",
+	"feature_set":      "features",
+	"enabled_features": "features",
+	"modules":          "features",
+}
+
+var licenseKeyDateFormats = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"2 Jan 2006",
+	"02-Jan-2006",
+	"Jan 2 2006",
+	"January 2, 2006",
+	"Mon Jan 2 15:04:05 2006",
+}
+
+// licenseKeyName normalises a field or feature name to lower case, with
+// spaces and dashes replaced by "_".
+func licenseKeyName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// parseLicenseKey reads the fields of a license key. Comments, blank lines
+// and the signature are skipped; a key without any recognised field is
+// returned with empty details rather than an error, as only the traffic
+// manager can tell whether it is valid.
+func parseLicenseKey(content string) *licenseKey {
+	key := &licenseKey{fields: map[string]string{}, features: []string{}}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-----") {
+			continue
+		}
+		match := licenseKeyFieldPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name := licenseKeyName(match[1])
+		value := strings.TrimSpace(match[2])
+		key.fields[name] = value
+
+		switch licenseKeyFieldAliases[name] {
+		case "serial":
+			key.serial = value
+		case "expiry":
+			key.expiry, _ = parseLicenseKeyDate(value)
+		case "bandwidth":
+			key.bandwidth, _ = parseLicenseKeyBandwidth(value)
+		case "features":
+			for _, feature := range splitLicenseKeyFeatures(value) {
+				key.features = append(key.features, licenseKeyName(feature))
+			}
+		}
+	}
+	sort.Strings(key.features)
+	return key
+}
+
+// splitLicenseKeyFeatures splits a list of features separated by commas or
+// semicolons, or by spaces if there are neither, so that names may contain
+// spaces when they are separated by commas.
+func splitLicenseKeyFeatures(value string) []string {
+	separators := func(r rune) bool { return r == ',' || r == ';' }
+	if !strings.ContainsAny(value, ",;") {
+		separators = unicode.IsSpace
+	}
+	features := []string{}
+	for _, feature := range strings.FieldsFunc(value, separators) {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// parseLicenseKeyDate reads a date in one of the common formats, or as a
+// Unix timestamp.
+func parseLicenseKeyDate(value string) (time.Time, bool) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), true
+	}
+	for _, format := range licenseKeyDateFormats {
+		if date, err := time.Parse(format, value); err == nil {
+			return date.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+var licenseKeyBandwidthPattern = regexp.MustCompile(`(?i)^([0-9]+(?:\.[0-9]+)?)\s*([kmg]?)(?:bps|bit/s|bits/s|b/s)?$`)
+
+// parseLicenseKeyBandwidth reads a bandwidth limit in Mbit/s. Values in
+// kbit/s and Gbit/s are converted, and "unlimited" is 0.
+func parseLicenseKeyBandwidth(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "unlimited", "none", "0":
+		return 0, true
+	}
+	match := licenseKeyBandwidthPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	limit, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToLower(match[2]) {
+	case "k":
+		limit /= 1000
+	case "g":
+		limit *= 1000
+	}
+	return int(limit + 0.5), true
+}
+
+// expiryDate returns the expiry date as "YYYY-MM-DD", or "" if the key does
+// not give one.
+func (key *licenseKey) expiryDate() string {
+	if key.expiry.IsZero() {
+		return ""
+	}
+	return key.expiry.Format("2006-01-02")
+}
+
+func (key *licenseKey) expired(now time.Time) bool {
+	return !key.expiry.IsZero() && now.After(key.expiry)
+}
+
+// licenseFeatureAliases maps the feature names a license key may list to
+// the features that need a license. "all" and "enterprise" cover every
+// feature.
+var licenseFeatureAliases = map[string]string{
+	"glb":                      "glb",
+	"gslb":                     "glb",
+	"global_load_balancing":    "glb",
+	"aptimizer":                "aptimizer",
+	"web_accelerator":          "aptimizer",
+	"webaccel":                 "aptimizer",
+	"service_protection":       "service_protection",
+	"protection":               "service_protection",
+	"dos_protection":           "service_protection",
+	"bandwidth":                "bandwidth",
+	"bandwidth_management":     "bandwidth",
+	"slm":                      "slm",
+	"service_level_monitoring": "slm",
+	"all":                      "all",
+	"enterprise":               "all",
+}
+
+// licensedFeatureResources maps the resource types that can only be used
+// with a license for a feature to that feature.
+var licensedFeatureResources = map[string]string{
+	"vtm_glb_service":           "glb",
+	"vtm_aptimizer_profile":     "aptimizer",
+	"vtm_aptimizer_scope":       "aptimizer",
+	"vtm_protection":            "service_protection",
+	"vtm_bandwidth":             "bandwidth",
+	"vtm_service_level_monitor": "slm",
+}
+
+// licensedFeatureNames are the names used for each feature in messages.
+var licensedFeatureNames = map[string]string{
+	"glb":                "Global Load Balancing",
+	"aptimizer":          "Web Accelerator (Aptimizer)",
+	"service_protection": "Service Protection",
+	"bandwidth":          "Bandwidth Management",
+	"slm":                "Service Level Monitoring",
+}
+
+// covers reports whether the key enables a feature. A key that has expired
+// covers nothing, and neither does a key that does not list its features,
+// as it cannot be shown to enable any.
+func (key *licenseKey) covers(feature string, now time.Time) bool {
+	if key.expired(now) {
+		return false
+	}
+	for _, listed := range key.features {
+		alias := licenseFeatureAliases[listed]
+		if alias == feature || alias == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// licenseCoversFeature reports whether any of the keys enables a feature.
+func licenseCoversFeature(keys []*licenseKey, feature string, now time.Time) bool {
+	for _, key := range keys {
+		if key.covers(feature, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// unlicensedFeatures returns the features, sorted, used by resources of the
+// given types that none of the keys enable.
+func unlicensedFeatures(keys []*licenseKey, resourceTypes []string, now time.Time) []string {
+	found := map[string]bool{}
+	for _, resourceType := range resourceTypes {
+		feature, ok := licensedFeatureResources[resourceType]
+		if ok && !licenseCoversFeature(keys, feature, now) {
+			found[feature] = true
+		}
+	}
+	features := []string{}
+	for feature := range found {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
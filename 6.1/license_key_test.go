@@ -0,0 +1,93 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testLicenseKey = `
+# Virtual Traffic Manager license
+Serial Number: VTM-1234-5678
+Expires: 2030-06-30
+Bandwidth: 1 Gbps
+Enabled-Features: GLB, service protection, Bandwidth_Management
+-----BEGIN SIGNATURE-----
+c2lnbmF0dXJlOiBub3QgYSByZWFsIHNpZ25hdHVyZQ==
+-----END SIGNATURE-----
+`
+
+func TestParseLicenseKey(t *testing.T) {
+	key := parseLicenseKey(testLicenseKey)
+	if key.serial != "VTM-1234-5678" {
+		t.Errorf("Parsed serial %q", key.serial)
+	}
+	if key.expiryDate() != "2030-06-30" {
+		t.Errorf("Parsed expiry date %q", key.expiryDate())
+	}
+	if key.bandwidth != 1000 {
+		t.Errorf("Parsed bandwidth limit %d, expected 1000", key.bandwidth)
+	}
+	if expected := []string{"bandwidth_management", "glb", "service_protection"}; !reflect.DeepEqual(key.features, expected) {
+		t.Errorf("Parsed features %v, expected %v", key.features, expected)
+	}
+	if key.fields["serial_number"] != "VTM-1234-5678" {
+		t.Errorf("Parsed fields %v", key.fields)
+	}
+}
+
+func TestParseLicenseKeyValues(t *testing.T) {
+	dates := map[string]string{
+		"2025-01-31":           "2025-01-31",
+		"31 Jan 2025":          "2025-01-31",
+		"January 31, 2025":     "2025-01-31",
+		"2025-01-31T12:00:00Z": "2025-01-31",
+		"1738324800":           "2025-01-31",
+	}
+	for value, expected := range dates {
+		key := parseLicenseKey("valid until = " + value)
+		if key.expiryDate() != expected {
+			t.Errorf("Parsed expiry date %q as %q, expected %q", value, key.expiryDate(), expected)
+		}
+	}
+
+	limits := map[string]int{
+		"500":       500,
+		"500 Mbps":  500,
+		"2.5Gbit/s": 2500,
+		"100 kbps":  0,
+		"unlimited": 0,
+	}
+	for value, expected := range limits {
+		if limit, ok := parseLicenseKeyBandwidth(value); !ok || limit != expected {
+			t.Errorf("Parsed bandwidth %q as %d, expected %d", value, limit, expected)
+		}
+	}
+	if _, ok := parseLicenseKeyBandwidth("fast"); ok {
+		t.Errorf("Parsed bandwidth \"fast\"")
+	}
+}
+
+func TestUnlicensedFeatures(t *testing.T) {
+	now := time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)
+	inUse := []string{"vtm_glb_service", "vtm_aptimizer_scope", "vtm_protection", "vtm_pool"}
+
+	keys := []*licenseKey{parseLicenseKey(testLicenseKey)}
+	if features, expected := unlicensedFeatures(keys, inUse, now), []string{"aptimizer"}; !reflect.DeepEqual(features, expected) {
+		t.Errorf("Unlicensed features %v, expected %v", features, expected)
+	}
+
+	keys = append(keys, parseLicenseKey("features: webaccel"))
+	if features := unlicensedFeatures(keys, inUse, now); len(features) != 0 {
+		t.Errorf("Unlicensed features %v, expected none", features)
+	}
+
+	// Expired keys, and keys that do not list features, cover nothing
+	keys = []*licenseKey{parseLicenseKey("expires: 2027-12-31\nfeatures: all"), parseLicenseKey("serial: 42")}
+	if features, expected := unlicensedFeatures(keys, inUse, now), []string{"aptimizer", "glb", "service_protection"}; !reflect.DeepEqual(features, expected) {
+		t.Errorf("Unlicensed features %v, expected %v", features, expected)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// Some resources manage a single entry of a table or list that belongs to
+// another configuration object. The REST API can only replace that object as
+// a whole, so every change is a read-modify-write of the complete object.
+const configObjectModifyTimeout = 2 * time.Minute
+
+var (
+	configObjectLocksMutex sync.Mutex
+	configObjectLocks      = map[string]*sync.Mutex{}
+)
+
+// lockConfigObject serialises changes to a configuration object made by this
+// provider, and returns the function that releases the lock.
+func lockConfigObject(objectType, objectName string) func() {
+	key := objectType + "/" + objectName
+	configObjectLocksMutex.Lock()
+	lock, ok := configObjectLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		configObjectLocks[key] = lock
+	}
+	configObjectLocksMutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// modifyConfigObject calls modify, which should read the object and make its
+// change, reporting whether the change was needed. When write is false,
+// modify only reports whether the object still needs the change. A write is
+// checked once against a fresh copy of the object, and is only made again if
+// that copy no longer has the change, because another client overwrote it.
+func modifyConfigObject(objectType, objectName string, modify func(write bool) (bool, error)) error {
+	unlock := lockConfigObject(objectType, objectName)
+	defer unlock()
+
+	return resource.Retry(configObjectModifyTimeout, func() *resource.RetryError {
+		written, err := modify(true)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if !written {
+			return nil
+		}
+		overwritten, err := modify(false)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if overwritten {
+			return resource.RetryableError(fmt.Errorf(
+				"Changes to %s '%s' were overwritten by another client", objectType, objectName,
+			))
+		}
+		return nil
+	})
+}
+
+// configObjectReader fetches a configuration object, and returns the address
+// of the part of it that is changed, for example &object.Basic.Permissions,
+// with the function that writes the object back.
+type configObjectReader func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError)
+
+// modifyConfigObjectField applies update to part of a configuration object,
+// and writes the object back if that changed anything. update is given the
+// part as decoded JSON, so that table rows keep the fields that the change
+// does not touch, and may return anything with the same JSON form. The rows
+// of a table are compared with the defaults from rowSchema filled in, as the
+// traffic manager may leave those fields out.
+func modifyConfigObjectField(tm interface{}, objectType, objectName string, read configObjectReader, rowSchema map[string]*schema.Schema, update func(interface{}) (interface{}, error)) error {
+	return modifyConfigObject(objectType, objectName, func(write bool) (bool, error) {
+		field, apply, err := read(tm.(*vtm.VirtualTrafficManager), objectName)
+		if err != nil {
+			return false, fmt.Errorf("%v", err.ErrorText)
+		}
+		current, decodeErr := decodeJsonValue(field)
+		if decodeErr != nil {
+			return false, decodeErr
+		}
+		updated, updateErr := update(current)
+		if updateErr != nil {
+			return false, updateErr
+		}
+		// Both are decoded afresh, as update may have changed the rows it
+		// was given.
+		original, _ := decodeJsonValue(field)
+		updated, decodeErr = decodeJsonValue(updated)
+		if decodeErr != nil {
+			return false, decodeErr
+		}
+		if reflect.DeepEqual(normaliseTableRows(updated, rowSchema), normaliseTableRows(original, rowSchema)) {
+			return false, nil
+		}
+		if !write {
+			return true, nil
+		}
+		if encodeErr := setJsonValue(field, updated); encodeErr != nil {
+			return false, encodeErr
+		}
+		if applyErr := apply(); applyErr != nil {
+			info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
+			return false, fmt.Errorf("%s %s", applyErr.ErrorText, info)
+		}
+		return true, nil
+	})
+}
+
+// decodeJsonValue returns the decoded JSON form of a value.
+func decodeJsonValue(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// setJsonValue replaces the value at target with one decoded from the JSON
+// form of value. The target is cleared first, so that no field of an
+// existing row survives where value leaves it out.
+func setJsonValue(target, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	element := reflect.ValueOf(target).Elem()
+	element.Set(reflect.Zero(element.Type()))
+	return json.Unmarshal(encoded, target)
+}
+
+// normaliseTableRows fills in the defaults from rowSchema in the decoded rows
+// of a table or list, so that tables can be compared.
+func normaliseTableRows(value interface{}, rowSchema map[string]*schema.Schema) interface{} {
+	if value == nil {
+		// A missing table or list is the same as an empty one.
+		return []interface{}{}
+	}
+	rows, ok := value.([]interface{})
+	if !ok || rowSchema == nil {
+		return value
+	}
+	normalised := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		fields, ok := row.(map[string]interface{})
+		if !ok {
+			normalised = append(normalised, row)
+			continue
+		}
+		item := map[string]interface{}{}
+		for field, fieldValue := range fields {
+			item[field] = fieldValue
+		}
+		for field, fieldSchema := range rowSchema {
+			item[field] = normalizeTableValue(fields[field], fieldSchema)
+		}
+		normalised = append(normalised, item)
+	}
+	return normalised
+}
+
+// jsonRows returns the rows of a decoded JSON table; a missing table has
+// none.
+func jsonRows(value interface{}) []interface{} {
+	if rows, ok := value.([]interface{}); ok {
+		return rows
+	}
+	return []interface{}{}
+}
+
+// jsonStringList returns the entries of a decoded JSON list of strings.
+func jsonStringList(value interface{}) []string {
+	list := []string{}
+	for _, item := range jsonRows(value) {
+		if entry, ok := item.(string); ok {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
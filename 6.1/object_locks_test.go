@@ -0,0 +1,49 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestModifyConfigObjectVerifiesOnce(t *testing.T) {
+	calls := []bool{}
+	err := modifyConfigObject("vtm_pool", "test_modify_once", func(write bool) (bool, error) {
+		calls = append(calls, write)
+		return write, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(calls) != "[true false]" {
+		t.Errorf("Expected one write and one check, got %v", calls)
+	}
+}
+
+func TestModifyConfigObjectRetriesOverwrite(t *testing.T) {
+	calls := []bool{}
+	err := modifyConfigObject("vtm_pool", "test_modify_overwritten", func(write bool) (bool, error) {
+		calls = append(calls, write)
+		// The first write is overwritten by another client
+		return write || len(calls) == 2, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(calls) != "[true false true false]" {
+		t.Errorf("Expected the write to be made again, got %v", calls)
+	}
+}
+
+func TestModifyConfigObjectUnchanged(t *testing.T) {
+	calls := 0
+	err := modifyConfigObject("vtm_pool", "test_modify_unchanged", func(write bool) (bool, error) {
+		calls++
+		return false, nil
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("Expected a single read and no error, got %d calls and %v", calls, err)
+	}
+}
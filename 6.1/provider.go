@@ -39,51 +39,62 @@ func Provider() terraform.ResourceProvider {
 				Description: "Check that vTM REST interface SSL certificate is trusted",
 			},
 		},
-		ResourcesMap: map[string]*schema.Resource{
-			"vtm_backups_full":          resourceSystemBackupsFull(),
-			"vtm_action":                resourceAction(),
-			"vtm_action_program":        resourceActionProgram(),
-			"vtm_appliance_nat":         resourceApplianceNat(),
-			"vtm_aptimizer_profile":     resourceAptimizerProfile(),
-			"vtm_aptimizer_scope":       resourceAptimizerScope(),
-			"vtm_bandwidth":             resourceBandwidth(),
-			"vtm_bgpneighbor":           resourceBgpneighbor(),
-			"vtm_cloud_api_credential":  resourceCloudApiCredential(),
-			"vtm_custom":                resourceCustom(),
-			"vtm_dns_server_zone":       resourceDnsServerZone(),
-			"vtm_dns_server_zone_file":  resourceDnsServerZoneFile(),
-			"vtm_event_type":            resourceEventType(),
-			"vtm_extra_file":            resourceExtraFile(),
-			"vtm_glb_service":           resourceGlbService(),
-			"vtm_global_settings":       resourceGlobalSettings(),
-			"vtm_kerberos_keytab":       resourceKerberosKeytab(),
-			"vtm_kerberos_krb5conf":     resourceKerberosKrb5Conf(),
-			"vtm_kerberos_principal":    resourceKerberosPrincipal(),
-			"vtm_license_key":           resourceLicenseKey(),
-			"vtm_location":              resourceLocation(),
-			"vtm_log_export":            resourceLogExport(),
-			"vtm_monitor":               resourceMonitor(),
-			"vtm_monitor_script":        resourceMonitorScript(),
-			"vtm_persistence":           resourcePersistence(),
-			"vtm_pool":                  resourcePool(),
-			"vtm_protection":            resourceProtection(),
-			"vtm_rate":                  resourceRate(),
-			"vtm_rule":                  resourceRule(),
-			"vtm_rule_authenticator":    resourceRuleAuthenticator(),
-			"vtm_saml_trustedidp":       resourceSamlTrustedidp(),
-			"vtm_security":              resourceSecurity(),
-			"vtm_service_level_monitor": resourceServiceLevelMonitor(),
-			"vtm_servicediscovery":      resourceServicediscovery(),
-			"vtm_ssl_ca":                resourceSslCa(),
-			"vtm_ssl_client_key":        resourceSslClientKey(),
-			"vtm_ssl_server_key":        resourceSslServerKey(),
-			"vtm_ssl_ticket_key":        resourceSslTicketKey(),
-			"vtm_traffic_ip_group":      resourceTrafficIpGroup(),
-			"vtm_traffic_manager":       resourceTrafficManager(),
-			"vtm_user_authenticator":    resourceUserAuthenticator(),
-			"vtm_user_group":            resourceUserGroup(),
-			"vtm_virtual_server":        resourceVirtualServer(),
-		},
+		ResourcesMap: withStateMigrations(map[string]*schema.Resource{
+			"vtm_backups_full":                   resourceSystemBackupsFull(),
+			"vtm_action":                         resourceAction(),
+			"vtm_action_program":                 resourceActionProgram(),
+			"vtm_appliance_nat":                  resourceApplianceNat(),
+			"vtm_aptimizer_profile":              resourceAptimizerProfile(),
+			"vtm_aptimizer_scope":                resourceAptimizerScope(),
+			"vtm_bandwidth":                      resourceBandwidth(),
+			"vtm_bgpneighbor":                    resourceBgpneighbor(),
+			"vtm_cloud_api_credential":           resourceCloudApiCredential(),
+			"vtm_config_object":                  resourceConfigObject(),
+			"vtm_custom":                         resourceCustom(),
+			"vtm_custom_string_list":             resourceCustomStringList(),
+			"vtm_dns_server_record":              resourceDnsServerRecord(),
+			"vtm_dns_server_zone":                resourceDnsServerZone(),
+			"vtm_dns_server_zone_file":           resourceDnsServerZoneFile(),
+			"vtm_event_type":                     resourceEventType(),
+			"vtm_event_type_action":              resourceEventTypeAction(),
+			"vtm_event_type_objects":             resourceEventTypeObjects(),
+			"vtm_extra_file":                     resourceExtraFile(),
+			"vtm_extra_file_directory":           resourceExtraFileDirectory(),
+			"vtm_glb_dnssec_key":                 resourceGlbDnssecKey(),
+			"vtm_glb_service":                    resourceGlbService(),
+			"vtm_global_settings":                resourceGlobalSettings(),
+			"vtm_kerberos_keytab":                resourceKerberosKeytab(),
+			"vtm_kerberos_krb5conf":              resourceKerberosKrb5Conf(),
+			"vtm_kerberos_principal":             resourceKerberosPrincipal(),
+			"vtm_license_key":                    resourceLicenseKey(),
+			"vtm_location":                       resourceLocation(),
+			"vtm_log_export":                     resourceLogExport(),
+			"vtm_monitor":                        resourceMonitor(),
+			"vtm_monitor_script":                 resourceMonitorScript(),
+			"vtm_persistence":                    resourcePersistence(),
+			"vtm_pool":                           resourcePool(),
+			"vtm_pool_node":                      resourcePoolNode(),
+			"vtm_protection":                     resourceProtection(),
+			"vtm_rate":                           resourceRate(),
+			"vtm_rule":                           resourceRule(),
+			"vtm_rule_authenticator":             resourceRuleAuthenticator(),
+			"vtm_saml_trustedidp":                resourceSamlTrustedidp(),
+			"vtm_security":                       resourceSecurity(),
+			"vtm_service_level_monitor":          resourceServiceLevelMonitor(),
+			"vtm_servicediscovery":               resourceServicediscovery(),
+			"vtm_ssl_ca":                         resourceSslCa(),
+			"vtm_ssl_client_key":                 resourceSslClientKey(),
+			"vtm_ssl_server_key":                 resourceSslServerKey(),
+			"vtm_ssl_ticket_key":                 resourceSslTicketKey(),
+			"vtm_traffic_ip_group":               resourceTrafficIpGroup(),
+			"vtm_traffic_ip_group_address":       resourceTrafficIpGroupAddress(),
+			"vtm_traffic_manager":                resourceTrafficManager(),
+			"vtm_user_authenticator":             resourceUserAuthenticator(),
+			"vtm_user_group":                     resourceUserGroup(),
+			"vtm_user_group_permission":          resourceUserGroupPermission(),
+			"vtm_virtual_server":                 resourceVirtualServer(),
+			"vtm_virtual_server_rule_attachment": resourceVirtualServerRuleAttachment(),
+		}),
 		DataSourcesMap: map[string]*schema.Resource{
 			"vtm_backups_full":                                     dataSourceSystemBackupsFull(),
 			"vtm_backups_full_list":                                dataSourceSystemBackupsFullList(),
@@ -93,6 +104,7 @@ func Provider() terraform.ResourceProvider {
 			"vtm_action_program":                                   dataSourceActionProgram(),
 			"vtm_action_program_list":                              dataSourceActionProgramList(),
 			"vtm_action_stats":                                     dataSourceActionStatistics(),
+			"vtm_api_version_changes":                              dataSourceApiVersionChanges(),
 			"vtm_appliance_nat":                                    dataSourceApplianceNat(),
 			"vtm_appliance_nat_many_to_one_all_ports_table":        dataSourceApplianceNatManyToOneAllPortsTable(),
 			"vtm_appliance_nat_many_to_one_port_locked_table":      dataSourceApplianceNatManyToOnePortLockedTable(),
@@ -117,6 +129,7 @@ func Provider() terraform.ResourceProvider {
 			"vtm_cloud_api_credential":                             dataSourceCloudApiCredential(),
 			"vtm_cloud_api_credential_list":                        dataSourceCloudApiCredentialList(),
 			"vtm_cloud_api_credential_stats":                       dataSourceCloudApiCredentialStatistics(),
+			"vtm_config_object":                                    dataSourceConfigObject(),
 			"vtm_connection_rate_limit_stats":                      dataSourceConnectionRateLimitStatistics(),
 			"vtm_custom":                                           dataSourceCustom(),
 			"vtm_custom_list":                                      dataSourceCustomList(),
@@ -183,6 +196,7 @@ func Provider() terraform.ResourceProvider {
 			"vtm_rule_authenticator_list":                          dataSourceRuleAuthenticatorList(),
 			"vtm_rule_authenticator_stats":                         dataSourceRuleAuthenticatorStatistics(),
 			"vtm_rule_list":                                        dataSourceRuleList(),
+			"vtm_rule_simulation":                                  dataSourceRuleSimulation(),
 			"vtm_rule_stats":                                       dataSourceRuleStatistics(),
 			"vtm_saml_trustedidp":                                  dataSourceSamlTrustedidp(),
 			"vtm_saml_trustedidp_list":                             dataSourceSamlTrustedidpList(),
@@ -197,6 +211,8 @@ func Provider() terraform.ResourceProvider {
 			"vtm_ssl_ca_list":                                      dataSourceSslCaList(),
 			"vtm_ssl_client_key":                                   dataSourceSslClientKey(),
 			"vtm_ssl_client_key_list":                              dataSourceSslClientKeyList(),
+			"vtm_ssl_certificate_audit":                            dataSourceSslCertificateAudit(),
+			"vtm_ssl_certificate_info":                             dataSourceSslCertificateInfo(),
 			"vtm_ssl_ocsp_stapling_stats":                          dataSourceSslOcspStaplingStatistics(),
 			"vtm_ssl_server_key":                                   dataSourceSslServerKey(),
 			"vtm_ssl_server_key_list":                              dataSourceSslServerKeyList(),
@@ -244,5 +260,6 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 	if contactable != true {
 		return nil, fmt.Errorf("Failed to connect to Virtual Traffic Manager at '%v': %v", baseUrl, contactErr.ErrorText)
 	}
+	registerRestClient(tm, baseUrl, username, password, verifySslCert)
 	return tm, nil
 }
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -232,3 +233,134 @@ func TestGetStringListMatchingRegex(t *testing.T) {
 		}
 	}
 }
+
+func TestSuppressEquivalentTableJsonDiffs(t *testing.T) {
+	tables := []struct {
+		old    string
+		new    string
+		result bool
+	}{
+		// Key order and formatting
+		{`[{"node":"10.0.0.1:80","priority":1,"source_ip":"","state":"active","weight":1}]`, `[ { "weight": 1, "node": "10.0.0.1:80" } ]`, true},
+		// Row order in a set
+		{`[{"node":"10.0.0.1:80"},{"node":"10.0.0.2:80"}]`, `[{"node":"10.0.0.2:80"},{"node":"10.0.0.1:80"}]`, true},
+		// Defaults are filled in
+		{`[{"node":"10.0.0.1:80","state":"active","weight":1}]`, `[{"node":"10.0.0.1:80"}]`, true},
+		// Changed value
+		{`[{"node":"10.0.0.1:80","state":"draining"}]`, `[{"node":"10.0.0.1:80","state":"active"}]`, false},
+		// Removed row
+		{`[{"node":"10.0.0.1:80"},{"node":"10.0.0.2:80"}]`, `[{"node":"10.0.0.1:80"}]`, false},
+		// Invalid JSON is never suppressed
+		{`[{"node":"10.0.0.1:80"}]`, `[{"node":`, false},
+	}
+
+	suppress := suppressEquivalentTableJsonDiffs(getResourcePoolSchema, "nodes_table")
+	for _, table := range tables {
+		if suppress("nodes_table_json", table.old, table.new, nil) != table.result {
+			t.Errorf("Suppressing diff failed: %s -> %s, expected %t", table.old, table.new, table.result)
+		}
+	}
+}
+
+func TestValidateTableJson(t *testing.T) {
+	tables := []struct {
+		value  string
+		errors []string
+	}{
+		{`[{"node": "10.0.0.1:80", "weight": 10, "state": "draining"}]`, []string{}},
+		{`[]`, []string{}},
+		{`{"node": "10.0.0.1:80"}`, []string{"nodes_table_json: failed to parse table JSON"}},
+		{`[{"weight": 10}]`, []string{`nodes_table_json[0]: missing required key "node"`}},
+		{`[{"node": "10.0.0.1:80"}, {"node": "10.0.0.2:80", "wieght": 10}]`, []string{`nodes_table_json[1]: unknown key "wieght"`}},
+		{`[{"node": "10.0.0.1:80", "state": "paused"}]`, []string{"expected nodes_table_json[0].state to be one of [active disabled draining]"}},
+		{`[{"node": "10.0.0.1:80", "weight": 150}]`, []string{"expected nodes_table_json[0].weight to be in the range (1 - 100)"}},
+		{`[{"node": "10.0.0.1:80", "weight": 1.5}]`, []string{"nodes_table_json[0].weight: expected an integer"}},
+		{`[{"node": 80}]`, []string{"nodes_table_json[0].node: expected a string"}},
+	}
+
+	validate := validateTableJson(getResourcePoolSchema, "nodes_table")
+	for _, table := range tables {
+		_, es := validate(table.value, "nodes_table_json")
+		if len(es) != len(table.errors) {
+			t.Errorf("Validating %s returned %d errors, expected %d: %v", table.value, len(es), len(table.errors), es)
+			continue
+		}
+		for index, err := range es {
+			if !strings.HasPrefix(err.Error(), table.errors[index]) {
+				t.Errorf("Validating %s returned '%v', expected '%s'", table.value, err, table.errors[index])
+			}
+		}
+	}
+}
+
+func TestMergeExternalEntries(t *testing.T) {
+	tables := []struct {
+		configured []string
+		current    []string
+		result     []string
+	}{
+		// External rules keep their place relative to managed ones
+		{[]string{"a", "b"}, []string{"waf", "a", "headers", "b"}, []string{"waf", "a", "headers", "b"}},
+		// Managed rules follow the configured order
+		{[]string{"b", "a"}, []string{"a", "waf", "b"}, []string{"b", "a", "waf"}},
+		// Removed managed rules are dropped, external ones kept
+		{[]string{"a"}, []string{"a", "old", "waf"}, []string{"a", "waf"}},
+		// Added managed rules go where they are configured
+		{[]string{"new", "a"}, []string{"waf", "a"}, []string{"waf", "new", "a"}},
+		{[]string{}, []string{"waf"}, []string{"waf"}},
+	}
+
+	for _, table := range tables {
+		managed := map[string]bool{"a": true, "b": true, "old": true, "new": true}
+		merged := mergeExternalEntries(table.configured, table.current, managed)
+		if !reflect.DeepEqual(merged, table.result) {
+			t.Errorf("Merging %v into %v gave %v, expected %v", table.current, table.configured, merged, table.result)
+		}
+	}
+}
+
+func TestUnmanagedTableRows(t *testing.T) {
+	current := []map[string]interface{}{
+		{"domain": "a.example.com", "ssl_key": []string{"k1", "k2"}},
+		{"domain": "b.example.com", "ssl_key": []string{"k3"}},
+	}
+	tables := []struct {
+		listField string
+		managed   map[string]bool
+		result    string
+	}{
+		{"", map[string]bool{"a.example.com": true}, `[{"domain":"b.example.com","ssl_key":["k3"]}]`},
+		// Rows keep the entries of their list that are not managed
+		{"ssl_key", map[string]bool{"a.example.com/k1": true}, `[{"domain":"a.example.com","ssl_key":["k2"]},{"domain":"b.example.com","ssl_key":["k3"]}]`},
+		{"ssl_key", map[string]bool{"a.example.com/k1": true, "a.example.com/k2": true, "b.example.com/k3": true}, `[]`},
+	}
+
+	for _, table := range tables {
+		rows := unmanagedTableRows(&current, "domain", table.listField, table.managed)
+		if encoded, _ := json.Marshal(rows); string(encoded) != table.result {
+			t.Errorf("Unmanaged rows for %v were %s, expected %s", table.managed, encoded, table.result)
+		}
+	}
+}
+
+func TestMergeTableRows(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"domain": "a.example.com", "ssl_key": []interface{}{"k1"}, "note": "kept"},
+	}
+	additions := []interface{}{
+		map[string]interface{}{"domain": "a.example.com", "ssl_key": []string{"k1", "k2"}},
+		map[string]interface{}{"domain": "b.example.com", "ssl_key": []string{"k3"}},
+	}
+	merged := mergeTableRows(rows, additions, "domain", "ssl_key")
+	expected := `[{"domain":"a.example.com","note":"kept","ssl_key":["k1","k2"]},{"domain":"b.example.com","ssl_key":["k3"]}]`
+	if encoded, _ := json.Marshal(merged); string(encoded) != expected {
+		t.Errorf("Merged rows were %s, expected %s", encoded, expected)
+	}
+
+	removed := removeTableRowEntry(merged, "domain", "ssl_key", "b.example.com", "k3")
+	removed = removeTableRowEntry(removed, "domain", "ssl_key", "a.example.com", "k1")
+	expected = `[{"domain":"a.example.com","note":"kept","ssl_key":["k2"]}]`
+	if encoded, _ := json.Marshal(removed); string(encoded) != expected {
+		t.Errorf("Rows after removing keys were %s, expected %s", encoded, expected)
+	}
+}
@@ -333,7 +333,9 @@ func resourceActionExists(d *schema.ResourceData, tm interface{}) (bool, error)
 func resourceActionCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	object := tm.(*vtm.VirtualTrafficManager).NewAction(objectName, d.Get("type").(string))
-	resourceActionObjectFieldAssignments(d, object)
+	if err := resourceActionObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_action '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -349,7 +351,9 @@ func resourceActionUpdate(d *schema.ResourceData, tm interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_action '%v': %v", objectName, err)
 	}
-	resourceActionObjectFieldAssignments(d, object)
+	if err := resourceActionObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_action '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -359,7 +363,7 @@ func resourceActionUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceActionObjectFieldAssignments(d *schema.ResourceData, object *vtm.Action) {
+func resourceActionObjectFieldAssignments(d *schema.ResourceData, object *vtm.Action) error {
 	setString(&object.Basic.Note, d, "note")
 	setInt(&object.Basic.SyslogMsgLenLimit, d, "syslog_msg_len_limit")
 	setInt(&object.Basic.Timeout, d, "timeout")
@@ -378,7 +382,9 @@ func resourceActionObjectFieldAssignments(d *schema.ResourceData, object *vtm.Ac
 
 	object.Program.Arguments = &vtm.ActionArgumentsTable{}
 	if programArgumentsJson, ok := d.GetOk("program_arguments_json"); ok {
-		_ = json.Unmarshal([]byte(programArgumentsJson.(string)), object.Program.Arguments)
+		if err := json.Unmarshal([]byte(programArgumentsJson.(string)), object.Program.Arguments); err != nil {
+			return fmt.Errorf("Invalid program_arguments_json: %v", err)
+		}
 	} else if programArguments, ok := d.GetOk("program_arguments"); ok {
 		for _, row := range programArguments.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -405,6 +411,7 @@ func resourceActionObjectFieldAssignments(d *schema.ResourceData, object *vtm.Ac
 	setString(&object.Trap.Traphost, d, "trap_traphost")
 	setString(&object.Trap.Username, d, "trap_username")
 	setString(&object.Trap.Version, d, "trap_version")
+	return nil
 }
 
 func resourceActionDelete(d *schema.ResourceData, tm interface{}) error {
@@ -20,6 +20,8 @@ func resourceActionProgram() *schema.Resource {
 		Update: resourceActionProgramUpdate,
 		Delete: resourceActionProgramDelete,
 
+		CustomizeDiff: fileContentCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -29,7 +31,7 @@ func resourceActionProgram() *schema.Resource {
 }
 
 func getResourceActionProgramSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	return addFileContentSchema(map[string]*schema.Schema{
 
 		"name": &schema.Schema{
 			Type:         schema.TypeString,
@@ -41,36 +43,23 @@ func getResourceActionProgramSchema() map[string]*schema.Schema {
 		// Object text
 		"content": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
 		},
-	}
+	})
 }
 
-func resourceActionProgramRead(d *schema.ResourceData, tm interface{}) (readError error) {
+func resourceActionProgramRead(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	if objectName == "" {
 		objectName = d.Id()
 		d.Set("name", objectName)
 	}
-	object, err := tm.(*vtm.VirtualTrafficManager).GetActionProgram(objectName)
-	if err != nil {
-		if err.ErrorId == "resource.not_found" {
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("Failed to read vtm_action_program '%v': %v", objectName, err.ErrorText)
+	if _, err := readFileContent(d, tm, "action_programs", objectName); err != nil {
+		return fmt.Errorf("Failed to read vtm_action_program '%v': %v", objectName, err)
+	}
+	if d.Id() == "" {
+		return nil
 	}
-
-	var lastAssignedField string
-
-	defer func() {
-		r := recover()
-		if r != nil {
-			readError = fmt.Errorf("Field '%s' missing from vTM configuration", lastAssignedField)
-		}
-	}()
-
-	d.Set("content", object)
 	d.SetId(objectName)
 	return nil
 }
@@ -100,10 +89,8 @@ func resourceActionProgramCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceActionProgramUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
-	objectContent := d.Get("content").(string)
-	err := tm.(*vtm.VirtualTrafficManager).SetActionProgram(objectName, objectContent)
-	if err != nil {
-		return fmt.Errorf("Failed to create vtm_action_program '%v': %v", objectName, err.ErrorText)
+	if err := writeFileContent(d, tm, "action_programs", objectName); err != nil {
+		return fmt.Errorf("Failed to create vtm_action_program '%v': %v", objectName, err)
 	}
 	d.SetId(objectName)
 	return nil
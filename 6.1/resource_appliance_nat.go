@@ -60,9 +60,10 @@ func getResourceApplianceNatSchema() map[string]*schema.Schema {
 
 		// JSON representation of many_to_one_all_ports
 		"many_to_one_all_ports_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceApplianceNatSchema, "many_to_one_all_ports"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceApplianceNatSchema, "many_to_one_all_ports"),
 		},
 
 		// This is table 'many_to_one_port_locked'
@@ -109,9 +110,10 @@ func getResourceApplianceNatSchema() map[string]*schema.Schema {
 
 		// JSON representation of many_to_one_port_locked
 		"many_to_one_port_locked_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceApplianceNatSchema, "many_to_one_port_locked"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceApplianceNatSchema, "many_to_one_port_locked"),
 		},
 
 		// This is table 'one_to_one'
@@ -150,9 +152,10 @@ func getResourceApplianceNatSchema() map[string]*schema.Schema {
 
 		// JSON representation of one_to_one
 		"one_to_one_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceApplianceNatSchema, "one_to_one"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceApplianceNatSchema, "one_to_one"),
 		},
 
 		// This is table 'port_mapping'
@@ -193,9 +196,10 @@ func getResourceApplianceNatSchema() map[string]*schema.Schema {
 
 		// JSON representation of port_mapping
 		"port_mapping_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceApplianceNatSchema, "port_mapping"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceApplianceNatSchema, "port_mapping"),
 		},
 	}
 }
@@ -230,9 +234,12 @@ func resourceApplianceNatRead(d *schema.ResourceData, tm interface{}) (readError
 		}
 		manyToOneAllPorts = append(manyToOneAllPorts, itemTerraform)
 	}
-	d.Set("many_to_one_all_ports", manyToOneAllPorts)
-	manyToOneAllPortsJson, _ := json.Marshal(manyToOneAllPorts)
-	d.Set("many_to_one_all_ports_json", manyToOneAllPortsJson)
+	if _, ok := d.GetOk("many_to_one_all_ports_json"); ok {
+		manyToOneAllPortsJson, _ := json.Marshal(manyToOneAllPorts)
+		d.Set("many_to_one_all_ports_json", string(manyToOneAllPortsJson))
+	} else {
+		d.Set("many_to_one_all_ports", manyToOneAllPorts)
+	}
 	lastAssignedField = "many_to_one_port_locked"
 	manyToOnePortLocked := make([]map[string]interface{}, 0, len(*object.Basic.ManyToOnePortLocked))
 	for _, item := range *object.Basic.ManyToOnePortLocked {
@@ -254,9 +261,12 @@ func resourceApplianceNatRead(d *schema.ResourceData, tm interface{}) (readError
 		}
 		manyToOnePortLocked = append(manyToOnePortLocked, itemTerraform)
 	}
-	d.Set("many_to_one_port_locked", manyToOnePortLocked)
-	manyToOnePortLockedJson, _ := json.Marshal(manyToOnePortLocked)
-	d.Set("many_to_one_port_locked_json", manyToOnePortLockedJson)
+	if _, ok := d.GetOk("many_to_one_port_locked_json"); ok {
+		manyToOnePortLockedJson, _ := json.Marshal(manyToOnePortLocked)
+		d.Set("many_to_one_port_locked_json", string(manyToOnePortLockedJson))
+	} else {
+		d.Set("many_to_one_port_locked", manyToOnePortLocked)
+	}
 	lastAssignedField = "one_to_one"
 	oneToOne := make([]map[string]interface{}, 0, len(*object.Basic.OneToOne))
 	for _, item := range *object.Basic.OneToOne {
@@ -275,9 +285,12 @@ func resourceApplianceNatRead(d *schema.ResourceData, tm interface{}) (readError
 		}
 		oneToOne = append(oneToOne, itemTerraform)
 	}
-	d.Set("one_to_one", oneToOne)
-	oneToOneJson, _ := json.Marshal(oneToOne)
-	d.Set("one_to_one_json", oneToOneJson)
+	if _, ok := d.GetOk("one_to_one_json"); ok {
+		oneToOneJson, _ := json.Marshal(oneToOne)
+		d.Set("one_to_one_json", string(oneToOneJson))
+	} else {
+		d.Set("one_to_one", oneToOne)
+	}
 	lastAssignedField = "port_mapping"
 	portMapping := make([]map[string]interface{}, 0, len(*object.Basic.PortMapping))
 	for _, item := range *object.Basic.PortMapping {
@@ -296,9 +309,12 @@ func resourceApplianceNatRead(d *schema.ResourceData, tm interface{}) (readError
 		}
 		portMapping = append(portMapping, itemTerraform)
 	}
-	d.Set("port_mapping", portMapping)
-	portMappingJson, _ := json.Marshal(portMapping)
-	d.Set("port_mapping_json", portMappingJson)
+	if _, ok := d.GetOk("port_mapping_json"); ok {
+		portMappingJson, _ := json.Marshal(portMapping)
+		d.Set("port_mapping_json", string(portMappingJson))
+	} else {
+		d.Set("port_mapping", portMapping)
+	}
 	d.SetId("nat")
 	return nil
 }
@@ -311,7 +327,9 @@ func resourceApplianceNatUpdate(d *schema.ResourceData, tm interface{}) error {
 
 	object.Basic.ManyToOneAllPorts = &vtm.ApplianceNatManyToOneAllPortsTable{}
 	if manyToOneAllPortsJson, ok := d.GetOk("many_to_one_all_ports_json"); ok {
-		_ = json.Unmarshal([]byte(manyToOneAllPortsJson.(string)), object.Basic.ManyToOneAllPorts)
+		if err := json.Unmarshal([]byte(manyToOneAllPortsJson.(string)), object.Basic.ManyToOneAllPorts); err != nil {
+			return fmt.Errorf("Error updating vtm_nat: Invalid many_to_one_all_ports_json: %v", err)
+		}
 	} else if manyToOneAllPorts, ok := d.GetOk("many_to_one_all_ports"); ok {
 		for _, row := range manyToOneAllPorts.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -328,7 +346,9 @@ func resourceApplianceNatUpdate(d *schema.ResourceData, tm interface{}) error {
 
 	object.Basic.ManyToOnePortLocked = &vtm.ApplianceNatManyToOnePortLockedTable{}
 	if manyToOnePortLockedJson, ok := d.GetOk("many_to_one_port_locked_json"); ok {
-		_ = json.Unmarshal([]byte(manyToOnePortLockedJson.(string)), object.Basic.ManyToOnePortLocked)
+		if err := json.Unmarshal([]byte(manyToOnePortLockedJson.(string)), object.Basic.ManyToOnePortLocked); err != nil {
+			return fmt.Errorf("Error updating vtm_nat: Invalid many_to_one_port_locked_json: %v", err)
+		}
 	} else if manyToOnePortLocked, ok := d.GetOk("many_to_one_port_locked"); ok {
 		for _, row := range manyToOnePortLocked.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -347,7 +367,9 @@ func resourceApplianceNatUpdate(d *schema.ResourceData, tm interface{}) error {
 
 	object.Basic.OneToOne = &vtm.ApplianceNatOneToOneTable{}
 	if oneToOneJson, ok := d.GetOk("one_to_one_json"); ok {
-		_ = json.Unmarshal([]byte(oneToOneJson.(string)), object.Basic.OneToOne)
+		if err := json.Unmarshal([]byte(oneToOneJson.(string)), object.Basic.OneToOne); err != nil {
+			return fmt.Errorf("Error updating vtm_nat: Invalid one_to_one_json: %v", err)
+		}
 	} else if oneToOne, ok := d.GetOk("one_to_one"); ok {
 		for _, row := range oneToOne.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -365,7 +387,9 @@ func resourceApplianceNatUpdate(d *schema.ResourceData, tm interface{}) error {
 
 	object.Basic.PortMapping = &vtm.ApplianceNatPortMappingTable{}
 	if portMappingJson, ok := d.GetOk("port_mapping_json"); ok {
-		_ = json.Unmarshal([]byte(portMappingJson.(string)), object.Basic.PortMapping)
+		if err := json.Unmarshal([]byte(portMappingJson.(string)), object.Basic.PortMapping); err != nil {
+			return fmt.Errorf("Error updating vtm_nat: Invalid port_mapping_json: %v", err)
+		}
 	} else if portMapping, ok := d.GetOk("port_mapping"); ok {
 		for _, row := range portMapping.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -23,7 +23,9 @@ func resourceAptimizerProfile() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceAptimizerProfileSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_aptimizer_profile"),
+
+		Schema: addLicensedFeatureSchema(getResourceAptimizerProfileSchema()),
 	}
 }
 
@@ -111,6 +113,7 @@ func resourceAptimizerProfileRead(d *schema.ResourceData, tm interface{}) (readE
 	d.Set("mode", string(*object.Basic.Mode))
 	lastAssignedField = "show_info_bar"
 	d.Set("show_info_bar", bool(*object.Basic.ShowInfoBar))
+	d.Set("license_warning", licenseWarning(tm, "vtm_aptimizer_profile"))
 	d.SetId(objectName)
 	return nil
 }
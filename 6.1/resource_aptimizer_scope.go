@@ -23,7 +23,9 @@ func resourceAptimizerScope() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceAptimizerScopeSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_aptimizer_scope"),
+
+		Schema: addLicensedFeatureSchema(getResourceAptimizerScopeSchema()),
 	}
 }
 
@@ -91,6 +93,7 @@ func resourceAptimizerScopeRead(d *schema.ResourceData, tm interface{}) (readErr
 	d.Set("hostnames", []string(*object.Basic.Hostnames))
 	lastAssignedField = "root"
 	d.Set("root", string(*object.Basic.Root))
+	d.Set("license_warning", licenseWarning(tm, "vtm_aptimizer_scope"))
 	d.SetId(objectName)
 	return nil
 }
@@ -23,7 +23,9 @@ func resourceBandwidth() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceBandwidthSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_bandwidth"),
+
+		Schema: addLicensedFeatureSchema(getResourceBandwidthSchema()),
 	}
 }
 
@@ -92,6 +94,7 @@ func resourceBandwidthRead(d *schema.ResourceData, tm interface{}) (readError er
 	d.Set("note", string(*object.Basic.Note))
 	lastAssignedField = "sharing"
 	d.Set("sharing", string(*object.Basic.Sharing))
+	d.Set("license_warning", licenseWarning(tm, "vtm_bandwidth"))
 	d.SetId(objectName)
 	return nil
 }
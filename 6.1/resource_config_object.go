@@ -0,0 +1,341 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceConfigObject() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceConfigObjectRead,
+		Exists: resourceConfigObjectExists,
+		Create: resourceConfigObjectCreate,
+		Update: resourceConfigObjectUpdate,
+		Delete: resourceConfigObjectDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConfigObjectImport,
+		},
+
+		Schema: getResourceConfigObjectSchema(),
+	}
+}
+
+func getResourceConfigObjectSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The configuration type, as it appears in the REST URI after
+		//  "/config/active/", for example "pools" or "ssl/server_keys".
+		"type": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		"name": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// JSON representation of the object's "properties", keyed by
+		//  section. Only the keys given here are compared against the vTM,
+		//  so defaults added by the server do not produce a diff. Removing
+		//  a key resets it to its default.
+		"properties_json": &schema.Schema{
+			Type:             schema.TypeString,
+			Required:         true,
+			ValidateFunc:     validation.ValidateJsonString,
+			DiffSuppressFunc: suppressEquivalentJsonDiffs,
+		},
+	}
+}
+
+func resourceConfigObjectRead(d *schema.ResourceData, tm interface{}) error {
+	objectType := d.Get("type").(string)
+	objectName := d.Get("name").(string)
+	client, clientErr := getRestClient(tm)
+	if clientErr != nil {
+		return clientErr
+	}
+	document, err := client.getJson(configPath(objectType, objectName))
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_config_object '%v/%v': %v", objectType, objectName, err.ErrorText)
+	}
+	properties, ok := document["properties"]
+	if !ok {
+		return fmt.Errorf("Failed to read vtm_config_object '%v/%v': response has no properties", objectType, objectName)
+	}
+
+	// Only keep the keys that are being managed, so that values the vTM
+	// fills in by default are not reported as drift.
+	if configured, ok := d.GetOk("properties_json"); ok {
+		var managed interface{}
+		if json.Unmarshal([]byte(configured.(string)), &managed) == nil {
+			properties = filterJsonToKeys(properties, managed)
+		}
+	}
+	propertiesJson, jsonErr := json.Marshal(properties)
+	if jsonErr != nil {
+		return fmt.Errorf("Failed to read vtm_config_object '%v/%v': %v", objectType, objectName, jsonErr)
+	}
+	d.Set("properties_json", string(propertiesJson))
+	d.SetId(objectType + "/" + objectName)
+	return nil
+}
+
+func resourceConfigObjectExists(d *schema.ResourceData, tm interface{}) (bool, error) {
+	client, clientErr := getRestClient(tm)
+	if clientErr != nil {
+		return false, clientErr
+	}
+	_, err := client.getJson(configPath(d.Get("type").(string), d.Get("name").(string)))
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			return false, nil
+		}
+		return false, fmt.Errorf("%v", err.ErrorText)
+	}
+	return true, nil
+}
+
+func resourceConfigObjectCreate(d *schema.ResourceData, tm interface{}) error {
+	err := resourceConfigObjectUpdate(d, tm)
+	if err != nil {
+		return fmt.Errorf("%v", strings.Replace(err.Error(), "updating", "creating", 1))
+	}
+	return nil
+}
+
+func resourceConfigObjectUpdate(d *schema.ResourceData, tm interface{}) error {
+	objectType := d.Get("type").(string)
+	objectName := d.Get("name").(string)
+	client, clientErr := getRestClient(tm)
+	if clientErr != nil {
+		return clientErr
+	}
+	var properties interface{}
+	if jsonErr := json.Unmarshal([]byte(d.Get("properties_json").(string)), &properties); jsonErr != nil {
+		return fmt.Errorf("Error updating vtm_config_object '%v/%v': %v", objectType, objectName, jsonErr)
+	}
+	// The vTM leaves keys that are left out of an update unchanged, so keys
+	// removed from properties_json are sent with their defaults.
+	oldJson, _ := d.GetChange("properties_json")
+	var oldProperties interface{}
+	if json.Unmarshal([]byte(oldJson.(string)), &oldProperties) == nil {
+		if reset := addRemovedJsonDefaults(objectType, oldProperties, properties, ""); len(reset) > 0 {
+			log.Printf("[DEBUG] vtm_config_object '%v/%v': resetting %s", objectType, objectName, strings.Join(reset, ", "))
+		}
+	}
+	err := client.putJson(configPath(objectType, objectName), map[string]interface{}{"properties": properties})
+	if err != nil {
+		info := ""
+		if errorInfo, ok := err.ErrorInfo.(map[string]interface{}); ok {
+			info = formatErrorInfo(errorInfo)
+		}
+		return fmt.Errorf("Error updating vtm_config_object '%v/%v': %s %s", objectType, objectName, err.ErrorText, info)
+	}
+	d.SetId(objectType + "/" + objectName)
+	return nil
+}
+
+func resourceConfigObjectDelete(d *schema.ResourceData, tm interface{}) error {
+	objectType := d.Get("type").(string)
+	objectName := d.Get("name").(string)
+	client, clientErr := getRestClient(tm)
+	if clientErr != nil {
+		return clientErr
+	}
+	err := client.delete(configPath(objectType, objectName))
+	if err != nil && err.ErrorId != "resource.not_found" {
+		return fmt.Errorf("Failed to delete vtm_config_object '%v/%v': %v", objectType, objectName, err.ErrorText)
+	}
+	d.SetId("")
+	return nil
+}
+
+// resourceConfigObjectImport accepts IDs of the form "<type>/<name>". Known
+// types may contain slashes, and the name is everything after the type, so
+// names may contain slashes too.
+func resourceConfigObjectImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	separator := strings.Index(d.Id(), "/")
+	for objectType := range configObjectResourceTypes {
+		if strings.HasPrefix(d.Id(), objectType+"/") && len(objectType) > separator {
+			separator = len(objectType)
+		}
+	}
+	if separator <= 0 || separator == len(d.Id())-1 {
+		return nil, fmt.Errorf("Invalid vtm_config_object ID '%s', expected '<type>/<name>'", d.Id())
+	}
+	d.Set("type", d.Id()[:separator])
+	d.Set("name", d.Id()[separator+1:])
+	return []*schema.ResourceData{d}, nil
+}
+
+// configObjectResourceTypes maps the configuration types that this provider
+// also has a resource for to the resource, whose schema gives the defaults
+// of the type's properties.
+var configObjectResourceTypes = map[string]string{
+	"actions":                "vtm_action",
+	"appliance/nats":         "vtm_appliance_nat",
+	"aptimizer/profiles":     "vtm_aptimizer_profile",
+	"aptimizer/scopes":       "vtm_aptimizer_scope",
+	"bandwidth":              "vtm_bandwidth",
+	"bgpneighbors":           "vtm_bgpneighbor",
+	"cloud_api_credentials":  "vtm_cloud_api_credential",
+	"custom":                 "vtm_custom",
+	"dns_server/zones":       "vtm_dns_server_zone",
+	"event_types":            "vtm_event_type",
+	"glb_services":           "vtm_glb_service",
+	"global_settings":        "vtm_global_settings",
+	"kerberos/principals":    "vtm_kerberos_principal",
+	"locations":              "vtm_location",
+	"log_export":             "vtm_log_export",
+	"monitors":               "vtm_monitor",
+	"persistence":            "vtm_persistence",
+	"pools":                  "vtm_pool",
+	"protection":             "vtm_protection",
+	"rate":                   "vtm_rate",
+	"rule_authenticators":    "vtm_rule_authenticator",
+	"saml/trustedidps":       "vtm_saml_trustedidp",
+	"security":               "vtm_security",
+	"service_level_monitors": "vtm_service_level_monitor",
+	"ssl/client_keys":        "vtm_ssl_client_key",
+	"ssl/server_keys":        "vtm_ssl_server_key",
+	"ssl/ticket_keys":        "vtm_ssl_ticket_key",
+	"traffic_ip_groups":      "vtm_traffic_ip_group",
+	"traffic_managers":       "vtm_traffic_manager",
+	"user_authenticators":    "vtm_user_authenticator",
+	"user_groups":            "vtm_user_group",
+	"virtual_servers":        "vtm_virtual_server",
+}
+
+// configObjectDefault returns the value that resets a "<section>.<key>"
+// property of a configuration type: the default of the matching field of
+// the provider's own resource for the type, or null if it is not known.
+func configObjectDefault(objectType, path string) interface{} {
+	resourceType, ok := configObjectResourceTypes[objectType]
+	if !ok {
+		return nil
+	}
+	parts := strings.Split(path, ".")
+	if len(parts) != 2 {
+		return nil
+	}
+	field := parts[0] + "_" + parts[1]
+	if parts[0] == "basic" {
+		field = parts[1]
+	}
+	fieldSchema, ok := Provider().(*schema.Provider).ResourcesMap[resourceType].Schema[field]
+	if !ok {
+		return nil
+	}
+	if fieldSchema.Default != nil {
+		return fieldSchema.Default
+	}
+	switch fieldSchema.Type {
+	case schema.TypeBool:
+		return false
+	case schema.TypeInt, schema.TypeFloat:
+		return 0
+	case schema.TypeString:
+		return ""
+	case schema.TypeList, schema.TypeSet:
+		return []interface{}{}
+	case schema.TypeMap:
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// addRemovedJsonDefaults adds the keys of oldValue that are not in newValue
+// to newValue, with the values that reset them to their defaults, and
+// returns their paths. Keys of removed sections are reset one by one. Lists
+// are compared as a whole, as tables are always replaced.
+func addRemovedJsonDefaults(objectType string, oldValue, newValue interface{}, path string) []string {
+	oldMap, ok := oldValue.(map[string]interface{})
+	if !ok {
+		return []string{}
+	}
+	newMap, ok := newValue.(map[string]interface{})
+	if !ok {
+		return []string{}
+	}
+	reset := []string{}
+	for _, key := range sortedMapKeys(oldMap) {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		newSubValue, ok := newMap[key]
+		if !ok {
+			if _, isMap := oldMap[key].(map[string]interface{}); !isMap {
+				newMap[key] = configObjectDefault(objectType, keyPath)
+				reset = append(reset, keyPath)
+				continue
+			}
+			newSubValue = map[string]interface{}{}
+			newMap[key] = newSubValue
+		}
+		reset = append(reset, addRemovedJsonDefaults(objectType, oldMap[key], newSubValue, keyPath)...)
+	}
+	return reset
+}
+
+// filterJsonToKeys returns the parts of value whose keys also appear in
+// template. Lists of objects are filtered row by row against the union of
+// the keys used in the template's rows.
+func filterJsonToKeys(value, template interface{}) interface{} {
+	switch templateValue := template.(type) {
+	case map[string]interface{}:
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		filtered := make(map[string]interface{}, len(templateValue))
+		for key, subTemplate := range templateValue {
+			if subValue, ok := valueMap[key]; ok {
+				filtered[key] = filterJsonToKeys(subValue, subTemplate)
+			}
+		}
+		return filtered
+	case []interface{}:
+		valueList, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		rowTemplate := make(map[string]interface{})
+		for _, row := range templateValue {
+			rowMap, ok := row.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			for key, subTemplate := range rowMap {
+				rowTemplate[key] = subTemplate
+			}
+		}
+		if len(rowTemplate) == 0 {
+			return value
+		}
+		filtered := make([]interface{}, 0, len(valueList))
+		for _, row := range valueList {
+			filtered = append(filtered, filterJsonToKeys(row, rowTemplate))
+		}
+		return filtered
+	}
+	return value
+}
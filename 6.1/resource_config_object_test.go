@@ -0,0 +1,202 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Creation and deletion of a vtm_config_object object
+ *   - Server-added defaults and key order do not produce a diff
+ *   - Changes made outside of Terraform to managed keys are detected
+ *   - Removing a key from properties_json resets it to its default
+ *   - Removed keys and sections are sent with their defaults
+ *   - Import IDs are split after the type, so names may contain '/'
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestResourceConfigObject(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestConfigObject")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConfigObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getBasicConfigObjectConfig(objName, `{"basic": {"note": "one", "max_idle_connections_pernode": 10}}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConfigObjectExists,
+				),
+			},
+			{
+				Config:             getBasicConfigObjectConfig(objName, `{"basic": {"max_idle_connections_pernode": 10, "note": "one"}}`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				PreConfig: func() {
+					tm, _ := getTestVtm()
+					pool, _ := tm.GetPool(objName)
+					pool.Basic.Note = getStringAddr("changed")
+					pool.Apply()
+				},
+				Config:             getBasicConfigObjectConfig(objName, `{"basic": {"note": "one", "max_idle_connections_pernode": 10}}`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: getBasicConfigObjectConfig(objName, `{"basic": {"max_idle_connections_pernode": 10}}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConfigObjectExists,
+					func(s *terraform.State) error {
+						tm, _ := getTestVtm()
+						pool, err := tm.GetPool(objName)
+						if err != nil {
+							return fmt.Errorf("Failed to read pool '%s': %v", objName, err)
+						}
+						if *pool.Basic.Note != "" {
+							return fmt.Errorf("Removed key note was not reset, it is '%s'", *pool.Basic.Note)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConfigObjectExists(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_config_object" {
+			continue
+		}
+		objectType := tfResource.Primary.Attributes["type"]
+		objectName := tfResource.Primary.Attributes["name"]
+		client, err := getRestClient(testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+		if _, err := client.getJson(configPath(objectType, objectName)); err != nil {
+			return fmt.Errorf("Config object %s/%s does not exist: %#v", objectType, objectName, err)
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckConfigObjectDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_config_object" {
+			continue
+		}
+		objectType := tfResource.Primary.Attributes["type"]
+		objectName := tfResource.Primary.Attributes["name"]
+		client, err := getRestClient(testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+		if _, err := client.getJson(configPath(objectType, objectName)); err == nil {
+			return fmt.Errorf("Config object %s/%s still exists", objectType, objectName)
+		}
+	}
+
+	return nil
+}
+
+func getBasicConfigObjectConfig(name, properties string) string {
+	return fmt.Sprintf(`
+        resource "vtm_config_object" "test_vtm_config_object" {
+			type = "pools"
+			name = "%s"
+			properties_json = <<EOF
+%s
+EOF
+        }`,
+		name, properties,
+	)
+}
+
+func TestConfigObjectRemovedJsonDefaults(t *testing.T) {
+	oldProperties := map[string]interface{}{
+		"basic": map[string]interface{}{
+			"note":                         "one",
+			"max_idle_connections_pernode": 10,
+			"nodes_table":                  []interface{}{},
+			"not_a_pool_key":               true,
+		},
+		"auto_scaling": map[string]interface{}{
+			"port": 8080,
+		},
+	}
+	newProperties := map[string]interface{}{
+		"basic": map[string]interface{}{
+			"nodes_table": []interface{}{},
+		},
+	}
+	reset := addRemovedJsonDefaults("pools", oldProperties, newProperties, "")
+	expectedReset := []string{
+		"auto_scaling.port",
+		"basic.max_idle_connections_pernode",
+		"basic.not_a_pool_key",
+		"basic.note",
+	}
+	if !reflect.DeepEqual(reset, expectedReset) {
+		t.Errorf("Reset keys are %v, expected %v", reset, expectedReset)
+	}
+	expectedProperties := map[string]interface{}{
+		"basic": map[string]interface{}{
+			"note":                         "",
+			"max_idle_connections_pernode": 50,
+			"nodes_table":                  []interface{}{},
+			"not_a_pool_key":               nil,
+		},
+		"auto_scaling": map[string]interface{}{
+			"port": 80,
+		},
+	}
+	if !reflect.DeepEqual(newProperties, expectedProperties) {
+		t.Errorf("Properties are %v, expected %v", newProperties, expectedProperties)
+	}
+
+	unknownProperties := map[string]interface{}{"basic": map[string]interface{}{}}
+	addRemovedJsonDefaults("not_a_type", map[string]interface{}{"basic": map[string]interface{}{"note": "one"}}, unknownProperties, "")
+	if value, ok := unknownProperties["basic"].(map[string]interface{})["note"]; !ok || value != nil {
+		t.Errorf("Removed key of an unknown type is %v, expected null", value)
+	}
+}
+
+func TestResourceConfigObjectImport(t *testing.T) {
+	for id, expected := range map[string][2]string{
+		"pools/web":                 {"pools", "web"},
+		"pools/web/eu":              {"pools", "web/eu"},
+		"ssl/server_keys/key/1":     {"ssl/server_keys", "key/1"},
+		"dns_server/zones/a.b/c.d":  {"dns_server/zones", "a.b/c.d"},
+		"new_objects/name/with/sep": {"new_objects", "name/with/sep"},
+	} {
+		d := resourceConfigObject().TestResourceData()
+		d.SetId(id)
+		if _, err := resourceConfigObjectImport(d, nil); err != nil {
+			t.Errorf("Importing '%s' failed: %v", id, err)
+			continue
+		}
+		if d.Get("type") != expected[0] || d.Get("name") != expected[1] {
+			t.Errorf("Importing '%s' gave type '%v' and name '%v', expected '%s' and '%s'", id, d.Get("type"), d.Get("name"), expected[0], expected[1])
+		}
+	}
+	for _, id := range []string{"pools", "pools/", "/web"} {
+		d := resourceConfigObject().TestResourceData()
+		d.SetId(id)
+		if _, err := resourceConfigObjectImport(d, nil); err == nil {
+			t.Errorf("Importing '%s' did not fail", id)
+		}
+	}
+}
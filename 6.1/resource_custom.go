@@ -63,9 +63,20 @@ func getResourceCustomSchema() map[string]*schema.Schema {
 
 		// JSON representation of string_lists
 		"string_lists_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceCustomSchema, "string_lists"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceCustomSchema, "string_lists"),
+		},
+
+		// Only manage the string lists listed in string_lists or
+		//  string_lists_json, leaving any others, such as those added by
+		//  vtm_custom_string_list resources, in place. This setting is not
+		//  stored on the vTM.
+		"ignore_external_string_lists": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
 		},
 	}
 }
@@ -96,7 +107,11 @@ func resourceCustomRead(d *schema.ResourceData, tm interface{}) (readError error
 
 	lastAssignedField = "string_lists"
 	stringLists := make([]map[string]interface{}, 0, len(*object.Basic.StringLists))
+	managedStringLists := tableRowKeys(d.Get("string_lists"), d.Get("string_lists_json"), "name", "")
 	for _, item := range *object.Basic.StringLists {
+		if d.Get("ignore_external_string_lists") == true && (item.Name == nil || !managedStringLists[string(*item.Name)]) {
+			continue
+		}
 		itemTerraform := make(map[string]interface{})
 		if item.Name != nil {
 			itemTerraform["name"] = string(*item.Name)
@@ -106,9 +121,12 @@ func resourceCustomRead(d *schema.ResourceData, tm interface{}) (readError error
 		}
 		stringLists = append(stringLists, itemTerraform)
 	}
-	d.Set("string_lists", stringLists)
-	stringListsJson, _ := json.Marshal(stringLists)
-	d.Set("string_lists_json", stringListsJson)
+	if _, ok := d.GetOk("string_lists_json"); ok {
+		stringListsJson, _ := json.Marshal(stringLists)
+		d.Set("string_lists_json", string(stringListsJson))
+	} else {
+		d.Set("string_lists", stringLists)
+	}
 	d.SetId(objectName)
 	return nil
 }
@@ -130,8 +148,12 @@ func resourceCustomExists(d *schema.ResourceData, tm interface{}) (bool, error)
 
 func resourceCustomCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_custom", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewCustom(objectName)
-	resourceCustomObjectFieldAssignments(d, object)
+	if err := resourceCustomObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_custom '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -143,11 +165,15 @@ func resourceCustomCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceCustomUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_custom", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetCustom(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_custom '%v': %v", objectName, err)
 	}
-	resourceCustomObjectFieldAssignments(d, object)
+	if err := resourceCustomObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_custom '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -157,11 +183,14 @@ func resourceCustomUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceCustomObjectFieldAssignments(d *schema.ResourceData, object *vtm.Custom) {
+func resourceCustomObjectFieldAssignments(d *schema.ResourceData, object *vtm.Custom) error {
 
+	externalStringLists := externalTableRows(d, "ignore_external_string_lists", "string_lists", "name", "", object.Basic.StringLists)
 	object.Basic.StringLists = &vtm.CustomStringListsTable{}
 	if stringListsJson, ok := d.GetOk("string_lists_json"); ok {
-		_ = json.Unmarshal([]byte(stringListsJson.(string)), object.Basic.StringLists)
+		if err := json.Unmarshal([]byte(stringListsJson.(string)), object.Basic.StringLists); err != nil {
+			return fmt.Errorf("Invalid string_lists_json: %v", err)
+		}
 	} else if stringLists, ok := d.GetOk("string_lists"); ok {
 		for _, row := range stringLists.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -174,6 +203,10 @@ func resourceCustomObjectFieldAssignments(d *schema.ResourceData, object *vtm.Cu
 	} else {
 		d.Set("string_lists", make([]map[string]interface{}, 0, len(*object.Basic.StringLists)))
 	}
+	if err := addTableRows(&object.Basic.StringLists, externalStringLists, "name", ""); err != nil {
+		return fmt.Errorf("Failed to keep external string lists: %v", err)
+	}
+	return nil
 }
 
 func resourceCustomDelete(d *schema.ResourceData, tm interface{}) error {
@@ -0,0 +1,76 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_custom_string_list manages one named list of a custom configuration
+// set, as read by TrafficScript, leaving the other lists alone. A vtm_custom
+// resource for the same set should set ignore_external_string_lists so that
+// it does not remove the list again.
+func resourceCustomStringList() *schema.Resource {
+	return &schema.Resource{
+		Read:   customStringListAttachment.Read,
+		Create: customStringListAttachment.Create,
+		Update: customStringListAttachment.Update,
+		Delete: customStringListAttachment.Delete,
+
+		Importer: &schema.ResourceImporter{
+			State: customStringListAttachment.Import,
+		},
+
+		Schema: getResourceCustomStringListSchema(),
+	}
+}
+
+func getResourceCustomStringListSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the custom configuration set.
+		"custom": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The name of the string list.
+		"name": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The values of the string list, in order.
+		"values": &schema.Schema{
+			Type:     schema.TypeList,
+			Required: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+var customStringListAttachment = &tableRowAttachment{
+	resourceType: "vtm_custom_string_list",
+	objectType:   "vtm_custom",
+	objectAttr:   "custom",
+	keyAttr:      "name",
+	schema:       getResourceCustomStringListSchema,
+	fields:       map[string]string{"values": "value"},
+	read: func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetCustom(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &object.Basic.StringLists, func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	},
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - A string list added to a set owned by a vtm_custom resource
+ *   - A vtm_custom with ignore_external_string_lists keeps it
+ *   - Changing the values of the list keeps their order
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceCustomStringList(t *testing.T) {
+	customName := acctest.RandomWithPrefix("TestCustomStringList")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckCustomDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getCustomStringListConfig(customName, `["10.0.0.0/8"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_custom.test_vtm_custom", "string_lists.#", "1"),
+					resource.TestCheckResourceAttr("vtm_custom_string_list.blocked", "values.#", "1"),
+					testAccCheckCustomStringListCount(customName, 2),
+				),
+			},
+			{
+				Config: getCustomStringListConfig(customName, `["192.168.0.0/16", "10.0.0.0/8"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_custom_string_list.blocked", "values.0", "192.168.0.0/16"),
+					resource.TestCheckResourceAttr("vtm_custom_string_list.blocked", "values.1", "10.0.0.0/8"),
+					testAccCheckCustomStringListCount(customName, 2),
+				),
+			},
+			{
+				Config:             getCustomStringListConfig(customName, `["192.168.0.0/16", "10.0.0.0/8"]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func testAccCheckCustomStringListCount(customName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		custom, err := tm.GetCustom(customName)
+		if err != nil {
+			return fmt.Errorf("Custom %s does not exist: %#v", customName, err)
+		}
+		if len(*custom.Basic.StringLists) != expected {
+			return fmt.Errorf("Custom %s has %d string lists, expected %d", customName, len(*custom.Basic.StringLists), expected)
+		}
+		return nil
+	}
+}
+
+func getCustomStringListConfig(customName, blocked string) string {
+	return fmt.Sprintf(`
+        resource "vtm_custom" "test_vtm_custom" {
+			name = "%s"
+			ignore_external_string_lists = true
+			string_lists {
+				name = "allowed"
+				value = ["172.16.0.0/12"]
+			}
+		}
+
+		resource "vtm_custom_string_list" "blocked" {
+			custom = "${vtm_custom.test_vtm_custom.name}"
+			name = "blocked"
+			values = %s
+		}`,
+		customName, blocked,
+	)
+}
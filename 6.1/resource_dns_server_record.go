@@ -0,0 +1,229 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_dns_server_record manages the records with one name and type in a zone
+// file, leaving the other records alone. A vtm_dns_server_zone_file resource
+// for the same file should set ignore_external_records so that it does not
+// remove the records again.
+func resourceDnsServerRecord() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceDnsServerRecordRead,
+		Create: resourceDnsServerRecordCreate,
+		Update: resourceDnsServerRecordUpdate,
+		Delete: resourceDnsServerRecordDelete,
+
+		CustomizeDiff: resourceDnsServerRecordCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceDnsServerRecordImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+
+			// The name of the vtm_dns_server_zone_file the records are in.
+			"zone_file": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// The owner name of the records, relative to the zone file's
+			//  $ORIGIN unless it ends with a dot. "@" is the origin itself.
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// The record type, such as "A", "CNAME" or "MX".
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				StateFunc: func(v interface{}) string {
+					return strings.ToUpper(v.(string))
+				},
+			},
+
+			// The TTL of the records in seconds, or 0 to use the zone file's
+			//  $TTL.
+			"ttl": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 2147483647),
+				Default:      0,
+			},
+
+			// The data of each record, as it would be written in a zone file,
+			//  such as "10 mail.example.com." for an MX record. TXT values
+			//  that are not quoted are treated as a single string.
+			"values": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceDnsServerRecordCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	rtype := d.Get("type").(string)
+	if rtype == "" {
+		return nil
+	}
+	if strings.ToUpper(rtype) == "SOA" {
+		return fmt.Errorf("SOA records cannot be managed with vtm_dns_server_record")
+	}
+	if name := d.Get("name").(string); name != "@" {
+		if err := validateDnsName(name); err != nil {
+			return err
+		}
+	}
+	// Values that are not known until apply read as empty strings.
+	for _, value := range d.Get("values").([]interface{}) {
+		if value.(string) == "" {
+			continue
+		}
+		if _, err := parseDnsRecordValue(rtype, value.(string)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDnsServerRecordValues parses and checks the values of a record.
+func getDnsServerRecordValues(rtype string, values []interface{}) ([][]string, error) {
+	parsed := [][]string{}
+	for _, value := range values {
+		rdata, err := parseDnsRecordValue(rtype, value.(string))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, rdata)
+	}
+	return parsed, nil
+}
+
+func resourceDnsServerRecordRead(d *schema.ResourceData, tm interface{}) error {
+	zoneName := d.Get("zone_file").(string)
+	recordName := d.Get("name").(string)
+	rtype := strings.ToUpper(d.Get("type").(string))
+	object, err := tm.(*vtm.VirtualTrafficManager).GetDnsServerZoneFile(zoneName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_dns_server_record '%v/%v/%v': %v", zoneName, recordName, rtype, err.ErrorText)
+	}
+	zone, parseErr := parseZoneFile(object)
+	if parseErr != nil {
+		return fmt.Errorf("Failed to read vtm_dns_server_record '%v/%v/%v': %v", zoneName, recordName, rtype, parseErr)
+	}
+	records := zone.rrset(recordName, rtype)
+	if len(records) == 0 {
+		d.SetId("")
+		return nil
+	}
+	values := []string{}
+	for _, record := range records {
+		values = append(values, formatDnsRecordValue(rtype, record.rdata))
+	}
+	d.Set("ttl", records[0].ttl)
+	d.Set("values", values)
+	d.Set("type", rtype)
+	d.SetId(zoneName + "/" + recordName + "/" + rtype)
+	return nil
+}
+
+func resourceDnsServerRecordCreate(d *schema.ResourceData, tm interface{}) error {
+	zoneName := d.Get("zone_file").(string)
+	recordName := d.Get("name").(string)
+	rtype := strings.ToUpper(d.Get("type").(string))
+	if _, err := tm.(*vtm.VirtualTrafficManager).GetDnsServerZoneFile(zoneName); err != nil {
+		return fmt.Errorf("Error creating vtm_dns_server_record '%s/%s/%s': vtm_dns_server_zone_file '%s': %v", zoneName, recordName, rtype, zoneName, err.ErrorText)
+	}
+
+	// Only the first pass may find the records already present; later
+	// passes see the records this resource wrote itself.
+	written := false
+	err := modifyDnsServerZoneFile(tm, zoneName, func(zone *dnsZone) (*dnsZone, error) {
+		if !written && len(zone.rrset(recordName, rtype)) > 0 {
+			return nil, fmt.Errorf("records already exist in the zone file; import them with the ID '%s/%s/%s'", zoneName, recordName, rtype)
+		}
+		written = true
+		return setDnsServerRecord(zone, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating vtm_dns_server_record '%s/%s/%s': %v", zoneName, recordName, rtype, err)
+	}
+	d.SetId(zoneName + "/" + recordName + "/" + rtype)
+	return nil
+}
+
+func resourceDnsServerRecordUpdate(d *schema.ResourceData, tm interface{}) error {
+	zoneName := d.Get("zone_file").(string)
+	recordName := d.Get("name").(string)
+	rtype := strings.ToUpper(d.Get("type").(string))
+	err := modifyDnsServerZoneFile(tm, zoneName, func(zone *dnsZone) (*dnsZone, error) {
+		return setDnsServerRecord(zone, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating vtm_dns_server_record '%s/%s/%s': %v", zoneName, recordName, rtype, err)
+	}
+	return nil
+}
+
+func resourceDnsServerRecordDelete(d *schema.ResourceData, tm interface{}) error {
+	zoneName := d.Get("zone_file").(string)
+	recordName := d.Get("name").(string)
+	rtype := strings.ToUpper(d.Get("type").(string))
+	// There is nothing to remove if the zone file has already been deleted.
+	if _, err := tm.(*vtm.VirtualTrafficManager).GetDnsServerZoneFile(zoneName); err == nil {
+		err := modifyDnsServerZoneFile(tm, zoneName, func(zone *dnsZone) (*dnsZone, error) {
+			zone.setRrset(recordName, rtype, 0, nil)
+			return zone, nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to delete vtm_dns_server_record '%v/%v/%v': %v", zoneName, recordName, rtype, err)
+		}
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceDnsServerRecordImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("Invalid vtm_dns_server_record ID '%s', expected '<zone_file>/<name>/<type>'", d.Id())
+	}
+	d.Set("zone_file", parts[0])
+	d.Set("name", parts[1])
+	d.Set("type", strings.ToUpper(parts[2]))
+	return []*schema.ResourceData{d}, nil
+}
+
+// setDnsServerRecord replaces the resource's records in the zone.
+func setDnsServerRecord(zone *dnsZone, d *schema.ResourceData) (*dnsZone, error) {
+	rtype := strings.ToUpper(d.Get("type").(string))
+	values, err := getDnsServerRecordValues(rtype, d.Get("values").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	zone.setRrset(d.Get("name").(string), rtype, d.Get("ttl").(int), values)
+	return zone, nil
+}
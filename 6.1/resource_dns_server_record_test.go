@@ -0,0 +1,135 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Records added to a zone file by vtm_dns_server_record resources
+ *   - A vtm_dns_server_zone_file with ignore_external_records keeps those
+ *     records, without reporting them as drift
+ *   - Changing one record's values increases the SOA serial and leaves the
+ *     other records untouched
+ *   - Deleting a vtm_dns_server_record only removes its own records
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceDnsServerRecord(t *testing.T) {
+	zoneName := acctest.RandomWithPrefix("TestDnsServerRecord")
+	var serial uint64
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDnsServerZoneFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getDnsServerRecordConfig(zoneName, "192.0.2.10", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_dns_server_record.www", "id", zoneName+"/www/A"),
+					testAccCheckDnsServerRecords(zoneName, "www", "A", 2, &serial),
+					testAccCheckDnsServerRecords(zoneName, "@", "MX", 1, nil),
+					testAccCheckDnsServerRecords(zoneName, "ns1", "A", 1, nil),
+				),
+			},
+			{
+				// The zone file must not report the records as drift
+				Config:             getDnsServerRecordConfig(zoneName, "192.0.2.10", true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				Config: getDnsServerRecordConfig(zoneName, "192.0.2.20", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_dns_server_record.www", "values.0", "192.0.2.20"),
+					testAccCheckDnsServerRecords(zoneName, "www", "A", 2, &serial),
+					testAccCheckDnsServerRecords(zoneName, "@", "MX", 1, nil),
+				),
+			},
+			{
+				Config: getDnsServerRecordConfig(zoneName, "192.0.2.20", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDnsServerRecords(zoneName, "@", "MX", 0, nil),
+					testAccCheckDnsServerRecords(zoneName, "www", "A", 2, nil),
+				),
+			},
+			{
+				ResourceName:      "vtm_dns_server_record.www",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testAccCheckDnsServerRecords checks the number of records with a name and
+// type in a zone file. If serial is set, the SOA serial must have increased
+// past it.
+func testAccCheckDnsServerRecords(zoneName, name, rtype string, expected int, serial *uint64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		content, err := tm.GetDnsServerZoneFile(zoneName)
+		if err != nil {
+			return fmt.Errorf("DnsServerZoneFile %s does not exist: %#v", zoneName, err)
+		}
+		zone, parseErr := parseZoneFile(content)
+		if parseErr != nil {
+			return fmt.Errorf("DnsServerZoneFile %s is not valid: %v", zoneName, parseErr)
+		}
+		if count := len(zone.rrset(name, rtype)); count != expected {
+			return fmt.Errorf("DnsServerZoneFile %s has %d %s %s records, expected %d", zoneName, count, name, rtype, expected)
+		}
+		if serial != nil {
+			if zone.serial() <= *serial {
+				return fmt.Errorf("DnsServerZoneFile %s has serial %d, expected more than %d", zoneName, zone.serial(), *serial)
+			}
+			*serial = zone.serial()
+		}
+		return nil
+	}
+}
+
+func getDnsServerRecordConfig(zoneName, address string, withMx bool) string {
+	config := fmt.Sprintf(`
+        resource "vtm_dns_server_zone_file" "test_vtm_dns_server_zone_file" {
+			name = "%s"
+			ignore_external_records = true
+			content = <<EOF
+$ORIGIN example.com.
+$TTL 1h
+@ IN SOA ns1 hostmaster 1 1d 2h 4w 1h
+@ IN NS ns1
+ns1 IN A 192.0.2.1
+EOF
+		}
+
+		resource "vtm_dns_server_record" "www" {
+			zone_file = "${vtm_dns_server_zone_file.test_vtm_dns_server_zone_file.name}"
+			name = "www"
+			type = "A"
+			ttl = 300
+			values = ["%s", "192.0.2.11"]
+		}`,
+		zoneName, address,
+	)
+	if withMx {
+		config += `
+
+		resource "vtm_dns_server_record" "mx" {
+			zone_file = "${vtm_dns_server_zone_file.test_vtm_dns_server_zone_file.name}"
+			name = "@"
+			type = "MX"
+			values = ["10 mail.example.com."]
+		}`
+	}
+	return config
+}
@@ -5,7 +5,9 @@ package main
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -38,10 +40,21 @@ func getResourceDnsServerZoneFileSchema() map[string]*schema.Schema {
 			ValidateFunc: validation.NoZeroValues,
 		},
 
-		// Object text
+		// Object text, an RFC 1035 zone file. Problems found in it are
+		//  reported as warnings when the plan is made.
 		"content": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateZoneFile,
+		},
+
+		// Leave records that are not in "content", such as those managed by
+		//  vtm_dns_server_record resources, in the zone file. The SOA serial
+		//  is then increased whenever the file is written.
+		"ignore_external_records": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
 		},
 	}
 }
@@ -70,6 +83,9 @@ func resourceDnsServerZoneFileRead(d *schema.ResourceData, tm interface{}) (read
 		}
 	}()
 
+	if d.Get("ignore_external_records").(bool) {
+		object = filterDnsServerZoneFile(object, d.Get("content").(string))
+	}
 	d.Set("content", object)
 	d.SetId(objectName)
 	return nil
@@ -101,6 +117,20 @@ func resourceDnsServerZoneFileCreate(d *schema.ResourceData, tm interface{}) err
 func resourceDnsServerZoneFileUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	objectContent := d.Get("content").(string)
+	if d.Get("ignore_external_records").(bool) {
+		oldContent, _ := d.GetChange("content")
+		err := modifyDnsServerZoneFile(tm, objectName, func(zone *dnsZone) (*dnsZone, error) {
+			return mergeDnsServerZoneFile(zone, objectContent, oldContent.(string))
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to update vtm_zone_file '%v': %v", objectName, err)
+		}
+		d.SetId(objectName)
+		return nil
+	}
+
+	unlock := lockConfigObject("vtm_dns_server_zone_file", objectName)
+	defer unlock()
 	err := tm.(*vtm.VirtualTrafficManager).SetDnsServerZoneFile(objectName, objectContent)
 	if err != nil {
 		return fmt.Errorf("Failed to create vtm_zone_file '%v': %v", objectName, err.ErrorText)
@@ -118,3 +148,38 @@ func resourceDnsServerZoneFileDelete(d *schema.ResourceData, tm interface{}) err
 	d.SetId("")
 	return nil
 }
+
+// modifyDnsServerZoneFile applies update to a parsed zone file, and writes it
+// back with an increased SOA serial if that changed any records. A missing
+// zone file is treated as empty.
+func modifyDnsServerZoneFile(tm interface{}, objectName string, update func(*dnsZone) (*dnsZone, error)) error {
+	return modifyConfigObject("vtm_dns_server_zone_file", objectName, func(write bool) (bool, error) {
+		object, err := tm.(*vtm.VirtualTrafficManager).GetDnsServerZoneFile(objectName)
+		if err != nil && err.ErrorId != "resource.not_found" {
+			return false, fmt.Errorf("vtm_dns_server_zone_file '%s': %v", objectName, err.ErrorText)
+		}
+		zone, parseErr := parseZoneFile(object)
+		if parseErr != nil {
+			return false, fmt.Errorf("vtm_dns_server_zone_file '%s' is not a valid zone file: %v", objectName, parseErr)
+		}
+		records, serial := zone.canonicalRecords(true), zone.serial()
+		updated, updateErr := update(zone)
+		if updateErr != nil {
+			return false, updateErr
+		}
+		if err == nil && reflect.DeepEqual(updated.canonicalRecords(true), records) {
+			return false, nil
+		}
+		if !write {
+			return true, nil
+		}
+		if updated.serial() <= serial {
+			updated.setSerial(serial)
+			updated.bumpSerial(time.Now())
+		}
+		if setErr := tm.(*vtm.VirtualTrafficManager).SetDnsServerZoneFile(objectName, updated.render()); setErr != nil {
+			return false, fmt.Errorf("%v", setErr.ErrorText)
+		}
+		return true, nil
+	})
+}
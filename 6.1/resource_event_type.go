@@ -11,6 +11,89 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// eventTypeObjectCategories are the event categories that can be limited to
+// a list of configuration objects.
+var eventTypeObjectCategories = []string{"cloudcredentials", "glb", "licensekeys", "locations", "monitors", "pools", "protection", "rules", "slm", "vservers", "zxtms"}
+
+// eventTypeSubscriptionLists returns the action and object lists of an event
+// type, keyed by the name of their field.
+func eventTypeSubscriptionLists(object *vtm.EventType) map[string]**[]string {
+	return map[string]**[]string{
+		"actions":                  &object.Basic.Actions,
+		"cloudcredentials_objects": &object.Cloudcredentials.Objects,
+		"glb_objects":              &object.Glb.Objects,
+		"licensekeys_objects":      &object.Licensekeys.Objects,
+		"locations_objects":        &object.Locations.Objects,
+		"monitors_objects":         &object.Monitors.Objects,
+		"pools_objects":            &object.Pools.Objects,
+		"protection_objects":       &object.Protection.Objects,
+		"rules_objects":            &object.Rules.Objects,
+		"slm_objects":              &object.Slm.Objects,
+		"vservers_objects":         &object.Vservers.Objects,
+		"zxtms_objects":            &object.Zxtms.Objects,
+	}
+}
+
+// eventTypeCategoryEventTags returns the event tag lists of the categories
+// that can be limited to a list of objects.
+func eventTypeCategoryEventTags(object *vtm.EventType) map[string]**[]string {
+	return map[string]**[]string{
+		"cloudcredentials": &object.Cloudcredentials.EventTags,
+		"glb":              &object.Glb.EventTags,
+		"licensekeys":      &object.Licensekeys.EventTags,
+		"locations":        &object.Locations.EventTags,
+		"monitors":         &object.Monitors.EventTags,
+		"pools":            &object.Pools.EventTags,
+		"protection":       &object.Protection.EventTags,
+		"rules":            &object.Rules.EventTags,
+		"slm":              &object.Slm.EventTags,
+		"vservers":         &object.Vservers.EventTags,
+		"zxtms":            &object.Zxtms.EventTags,
+	}
+}
+
+// eventTypeParkedEventTags returns the event tags in the state of the
+// categories that have neither event tags nor objects on the vTM, when the
+// event type ignores external subscriptions. vtm_event_type_objects clears
+// a category's event tags along with its last object, as an empty object
+// list matches every object, and the tags are kept in the state until other
+// resources subscribe objects to the category again.
+func eventTypeParkedEventTags(d *schema.ResourceData, object *vtm.EventType) map[string][]interface{} {
+	parked := map[string][]interface{}{}
+	if d.Get("ignore_external_subscriptions") != true {
+		return parked
+	}
+	for category, tags := range eventTypeCategoryEventTags(object) {
+		objects := eventTypeSubscriptionLists(object)[category+"_objects"]
+		if (*tags != nil && len(**tags) > 0) || (*objects != nil && len(**objects) > 0) {
+			continue
+		}
+		if stateTags := d.Get(category + "_event_tags").([]interface{}); len(stateTags) > 0 {
+			parked[category+"_event_tags"] = stateTags
+		}
+	}
+	return parked
+}
+
+// modifyEventTypeList applies update to one of the event type's current
+// action or object lists, and writes the event type back if that changed
+// anything.
+func modifyEventTypeList(tm interface{}, eventType, field string, update func([]string) ([]string, error)) error {
+	read := func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetEventType(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return eventTypeSubscriptionLists(object)[field], func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	}
+	return modifyConfigObjectField(tm, "vtm_event_type", eventType, read, nil, func(value interface{}) (interface{}, error) {
+		return update(jsonStringList(value))
+	})
+}
+
 func resourceEventType() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceEventTypeRead,
@@ -54,6 +137,16 @@ func getResourceEventTypeSchema() map[string]*schema.Schema {
 			Default:  false,
 		},
 
+		// Only manage the actions and objects listed here, leaving any
+		//  others, such as those added by vtm_event_type_action and
+		//  vtm_event_type_objects resources, in place. This setting is not
+		//  stored on the vTM.
+		"ignore_external_subscriptions": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
 		// A description of this event type.
 		"note": &schema.Schema{
 			Type:     schema.TypeString,
@@ -289,8 +382,10 @@ func resourceEventTypeRead(d *schema.ResourceData, tm interface{}) (readError er
 		}
 	}()
 
+	parkedEventTags := eventTypeParkedEventTags(d, object)
+
 	lastAssignedField = "actions"
-	d.Set("actions", []string(*object.Basic.Actions))
+	d.Set("actions", managedListEntries(d, "ignore_external_subscriptions", "actions", []string(*object.Basic.Actions)))
 	lastAssignedField = "built_in"
 	d.Set("built_in", bool(*object.Basic.BuiltIn))
 	lastAssignedField = "note"
@@ -298,7 +393,7 @@ func resourceEventTypeRead(d *schema.ResourceData, tm interface{}) (readError er
 	lastAssignedField = "cloudcredentials_event_tags"
 	d.Set("cloudcredentials_event_tags", []string(*object.Cloudcredentials.EventTags))
 	lastAssignedField = "cloudcredentials_objects"
-	d.Set("cloudcredentials_objects", []string(*object.Cloudcredentials.Objects))
+	d.Set("cloudcredentials_objects", managedListEntries(d, "ignore_external_subscriptions", "cloudcredentials_objects", []string(*object.Cloudcredentials.Objects)))
 	lastAssignedField = "config_event_tags"
 	d.Set("config_event_tags", []string(*object.Config.EventTags))
 	lastAssignedField = "faulttolerance_event_tags"
@@ -308,37 +403,37 @@ func resourceEventTypeRead(d *schema.ResourceData, tm interface{}) (readError er
 	lastAssignedField = "glb_event_tags"
 	d.Set("glb_event_tags", []string(*object.Glb.EventTags))
 	lastAssignedField = "glb_objects"
-	d.Set("glb_objects", []string(*object.Glb.Objects))
+	d.Set("glb_objects", managedListEntries(d, "ignore_external_subscriptions", "glb_objects", []string(*object.Glb.Objects)))
 	lastAssignedField = "java_event_tags"
 	d.Set("java_event_tags", []string(*object.Java.EventTags))
 	lastAssignedField = "licensekeys_event_tags"
 	d.Set("licensekeys_event_tags", []string(*object.Licensekeys.EventTags))
 	lastAssignedField = "licensekeys_objects"
-	d.Set("licensekeys_objects", []string(*object.Licensekeys.Objects))
+	d.Set("licensekeys_objects", managedListEntries(d, "ignore_external_subscriptions", "licensekeys_objects", []string(*object.Licensekeys.Objects)))
 	lastAssignedField = "locations_event_tags"
 	d.Set("locations_event_tags", []string(*object.Locations.EventTags))
 	lastAssignedField = "locations_objects"
-	d.Set("locations_objects", []string(*object.Locations.Objects))
+	d.Set("locations_objects", managedListEntries(d, "ignore_external_subscriptions", "locations_objects", []string(*object.Locations.Objects)))
 	lastAssignedField = "monitors_event_tags"
 	d.Set("monitors_event_tags", []string(*object.Monitors.EventTags))
 	lastAssignedField = "monitors_objects"
-	d.Set("monitors_objects", []string(*object.Monitors.Objects))
+	d.Set("monitors_objects", managedListEntries(d, "ignore_external_subscriptions", "monitors_objects", []string(*object.Monitors.Objects)))
 	lastAssignedField = "pools_event_tags"
 	d.Set("pools_event_tags", []string(*object.Pools.EventTags))
 	lastAssignedField = "pools_objects"
-	d.Set("pools_objects", []string(*object.Pools.Objects))
+	d.Set("pools_objects", managedListEntries(d, "ignore_external_subscriptions", "pools_objects", []string(*object.Pools.Objects)))
 	lastAssignedField = "protection_event_tags"
 	d.Set("protection_event_tags", []string(*object.Protection.EventTags))
 	lastAssignedField = "protection_objects"
-	d.Set("protection_objects", []string(*object.Protection.Objects))
+	d.Set("protection_objects", managedListEntries(d, "ignore_external_subscriptions", "protection_objects", []string(*object.Protection.Objects)))
 	lastAssignedField = "rules_event_tags"
 	d.Set("rules_event_tags", []string(*object.Rules.EventTags))
 	lastAssignedField = "rules_objects"
-	d.Set("rules_objects", []string(*object.Rules.Objects))
+	d.Set("rules_objects", managedListEntries(d, "ignore_external_subscriptions", "rules_objects", []string(*object.Rules.Objects)))
 	lastAssignedField = "slm_event_tags"
 	d.Set("slm_event_tags", []string(*object.Slm.EventTags))
 	lastAssignedField = "slm_objects"
-	d.Set("slm_objects", []string(*object.Slm.Objects))
+	d.Set("slm_objects", managedListEntries(d, "ignore_external_subscriptions", "slm_objects", []string(*object.Slm.Objects)))
 	lastAssignedField = "ssl_event_tags"
 	d.Set("ssl_event_tags", []string(*object.Ssl.EventTags))
 	lastAssignedField = "sslhw_event_tags"
@@ -348,11 +443,14 @@ func resourceEventTypeRead(d *schema.ResourceData, tm interface{}) (readError er
 	lastAssignedField = "vservers_event_tags"
 	d.Set("vservers_event_tags", []string(*object.Vservers.EventTags))
 	lastAssignedField = "vservers_objects"
-	d.Set("vservers_objects", []string(*object.Vservers.Objects))
+	d.Set("vservers_objects", managedListEntries(d, "ignore_external_subscriptions", "vservers_objects", []string(*object.Vservers.Objects)))
 	lastAssignedField = "zxtms_event_tags"
 	d.Set("zxtms_event_tags", []string(*object.Zxtms.EventTags))
 	lastAssignedField = "zxtms_objects"
-	d.Set("zxtms_objects", []string(*object.Zxtms.Objects))
+	d.Set("zxtms_objects", managedListEntries(d, "ignore_external_subscriptions", "zxtms_objects", []string(*object.Zxtms.Objects)))
+	for field, tags := range parkedEventTags {
+		d.Set(field, tags)
+	}
 	d.SetId(objectName)
 	return nil
 }
@@ -374,6 +472,8 @@ func resourceEventTypeExists(d *schema.ResourceData, tm interface{}) (bool, erro
 
 func resourceEventTypeCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_event_type", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewEventType(objectName)
 	resourceEventTypeObjectFieldAssignments(d, object)
 	_, applyErr := object.Apply()
@@ -387,6 +487,8 @@ func resourceEventTypeCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceEventTypeUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_event_type", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetEventType(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_event_type '%v': %v", objectName, err)
@@ -402,6 +504,16 @@ func resourceEventTypeUpdate(d *schema.ResourceData, tm interface{}) error {
 }
 
 func resourceEventTypeObjectFieldAssignments(d *schema.ResourceData, object *vtm.EventType) {
+	currentSubscriptions := map[string][]string{}
+	for field, values := range eventTypeSubscriptionLists(object) {
+		if *values != nil {
+			currentSubscriptions[field] = **values
+		}
+	}
+	parkedEventTags := map[string][]interface{}{}
+	if !d.IsNewResource() {
+		parkedEventTags = eventTypeParkedEventTags(d, object)
+	}
 
 	if _, ok := d.GetOk("actions"); ok {
 		setStringList(&object.Basic.Actions, d, "actions")
@@ -614,6 +726,23 @@ func resourceEventTypeObjectFieldAssignments(d *schema.ResourceData, object *vtm
 		object.Zxtms.Objects = &[]string{}
 		d.Set("zxtms_objects", []string(*object.Zxtms.Objects))
 	}
+
+	// Actions and objects added by vtm_event_type_action and
+	// vtm_event_type_objects resources are kept.
+	if d.Get("ignore_external_subscriptions") == true {
+		for field, values := range eventTypeSubscriptionLists(object) {
+			merged := mergeExternalListEntries(d, field, **values, currentSubscriptions[field])
+			*values = &merged
+		}
+		// Parked event tags are only written once the category has objects
+		// again, so that it does not match every object.
+		for category, tags := range eventTypeCategoryEventTags(object) {
+			objects := eventTypeSubscriptionLists(object)[category+"_objects"]
+			if _, ok := parkedEventTags[category+"_event_tags"]; ok && len(**objects) == 0 {
+				*tags = &[]string{}
+			}
+		}
+	}
 }
 
 func resourceEventTypeDelete(d *schema.ResourceData, tm interface{}) error {
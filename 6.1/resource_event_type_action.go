@@ -0,0 +1,118 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_event_type_action adds one action to an existing event type, leaving
+// its other actions alone. A vtm_event_type resource for the same event type
+// should set ignore_external_subscriptions so that it does not remove the
+// action again.
+func resourceEventTypeAction() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceEventTypeActionRead,
+		Create: resourceEventTypeActionCreate,
+		Delete: resourceEventTypeActionDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceEventTypeActionImport,
+		},
+
+		Schema: getResourceEventTypeActionSchema(),
+	}
+}
+
+func getResourceEventTypeActionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the event type.
+		"event_type": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The name of the action to trigger.
+		"action": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+	}
+}
+
+func resourceEventTypeActionRead(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	action := d.Get("action").(string)
+	object, err := tm.(*vtm.VirtualTrafficManager).GetEventType(eventType)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_event_type_action '%v/%v': %v", eventType, action, err.ErrorText)
+	}
+	if object.Basic.Actions == nil || indexOfString([]string(*object.Basic.Actions), action) < 0 {
+		d.SetId("")
+		return nil
+	}
+	d.SetId(eventType + "/" + action)
+	return nil
+}
+
+func resourceEventTypeActionCreate(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	action := d.Get("action").(string)
+
+	// Only the first pass may find the action already present; later passes
+	// see the action this resource added itself.
+	written := false
+	err := modifyEventTypeList(tm, eventType, "actions", func(actions []string) ([]string, error) {
+		if indexOfString(actions, action) >= 0 {
+			if !written {
+				return nil, fmt.Errorf("action is already triggered by the event type; import it with the ID '%s/%s'", eventType, action)
+			}
+			return actions, nil
+		}
+		written = true
+		return append(append([]string{}, actions...), action), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating vtm_event_type_action '%s/%s': %v", eventType, action, err)
+	}
+	d.SetId(eventType + "/" + action)
+	return nil
+}
+
+func resourceEventTypeActionDelete(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	action := d.Get("action").(string)
+	err := modifyEventTypeList(tm, eventType, "actions", func(actions []string) ([]string, error) {
+		return removeString(actions, action), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete vtm_event_type_action '%v/%v': %v", eventType, action, err)
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceEventTypeActionImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	separator := strings.LastIndex(d.Id(), "/")
+	if separator <= 0 || separator == len(d.Id())-1 {
+		return nil, fmt.Errorf("Invalid vtm_event_type_action ID '%s', expected '<event_type>/<action>'", d.Id())
+	}
+	d.Set("event_type", d.Id()[:separator])
+	d.Set("action", d.Id()[separator+1:])
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - An action added to an event type owned by a vtm_event_type resource
+ *   - A vtm_event_type with ignore_external_subscriptions keeps the action
+ *   - Deleting the vtm_event_type_action only removes its own action
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceEventTypeAction(t *testing.T) {
+	eventTypeName := acctest.RandomWithPrefix("TestEventTypeAction")
+	sharedAction := acctest.RandomWithPrefix("TestSharedAction")
+	pagerAction := acctest.RandomWithPrefix("TestPagerAction")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckEventTypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getEventTypeActionConfig(eventTypeName, sharedAction, pagerAction, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_event_type.test_vtm_event_type", "actions.#", "1"),
+					resource.TestCheckResourceAttr("vtm_event_type_action.pager", "id", eventTypeName+"/"+pagerAction),
+					testAccCheckEventTypeActionCount(eventTypeName, 2),
+				),
+			},
+			{
+				// The event type resource must not report the pager action as drift
+				Config:             getEventTypeActionConfig(eventTypeName, sharedAction, pagerAction, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				Config: getEventTypeActionConfig(eventTypeName, sharedAction, pagerAction, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckEventTypeActionCount(eventTypeName, 1),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckEventTypeActionCount(eventTypeName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		eventType, err := tm.GetEventType(eventTypeName)
+		if err != nil {
+			return fmt.Errorf("EventType %s does not exist: %#v", eventTypeName, err)
+		}
+		if len(*eventType.Basic.Actions) != expected {
+			return fmt.Errorf("EventType %s has %d actions, expected %d", eventTypeName, len(*eventType.Basic.Actions), expected)
+		}
+		return nil
+	}
+}
+
+func getEventTypeActionConfig(eventTypeName, sharedAction, pagerAction string, withPager bool) string {
+	config := fmt.Sprintf(`
+        resource "vtm_action" "shared" {
+			name = "%s"
+			type = "email"
+		}
+
+		resource "vtm_action" "pager" {
+			name = "%s"
+			type = "email"
+		}
+
+		resource "vtm_event_type" "test_vtm_event_type" {
+			name = "%s"
+			ignore_external_subscriptions = true
+			actions = ["${vtm_action.shared.name}"]
+			pools_event_tags = ["pooldied"]
+		}`,
+		sharedAction, pagerAction, eventTypeName,
+	)
+	if withPager {
+		config += `
+
+		resource "vtm_event_type_action" "pager" {
+			event_type = "${vtm_event_type.test_vtm_event_type.name}"
+			action = "${vtm_action.pager.name}"
+		}`
+	}
+	return config
+}
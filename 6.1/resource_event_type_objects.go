@@ -0,0 +1,199 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_event_type_objects limits one category of an existing event type to a
+// set of objects, for example the pools of one application, by adding them
+// to the category's object list. Objects added by other resources, or by the
+// vtm_event_type resource itself, are left alone; that resource should set
+// ignore_external_subscriptions. The resource's ID is
+// "<event_type>/<category>/<object>,<object>,..." with the objects sorted,
+// so that it is unique among the resources that subscribe to the event type.
+func resourceEventTypeObjects() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceEventTypeObjectsRead,
+		Create: resourceEventTypeObjectsCreate,
+		Update: resourceEventTypeObjectsUpdate,
+		Delete: resourceEventTypeObjectsDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceEventTypeObjectsImport,
+		},
+
+		Schema: getResourceEventTypeObjectsSchema(),
+	}
+}
+
+func getResourceEventTypeObjectsSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the event type.
+		"event_type": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The event category, for example "pools" or "vservers".
+		"category": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(eventTypeObjectCategories, false),
+		},
+
+		// The names of the objects whose events should match.
+		"objects": &schema.Schema{
+			Type:     schema.TypeSet,
+			Required: true,
+			MinItems: 1,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+func resourceEventTypeObjectsRead(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	category := d.Get("category").(string)
+	object, err := tm.(*vtm.VirtualTrafficManager).GetEventType(eventType)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_event_type_objects '%v/%v': %v", eventType, category, err.ErrorText)
+	}
+	current := []string{}
+	if values := eventTypeSubscriptionLists(object)[category+"_objects"]; *values != nil {
+		current = **values
+	}
+	managed := expandStringSet(d.Get("objects").(*schema.Set))
+	objects := []string{}
+	for _, name := range managed {
+		if indexOfString(current, name) >= 0 {
+			objects = append(objects, name)
+		}
+	}
+	d.Set("objects", objects)
+	// Resources created before the objects were part of the ID only had
+	// the event type's name.
+	if d.Id() == eventType {
+		d.SetId(eventTypeObjectsId(eventType, category, managed))
+	}
+	return nil
+}
+
+func resourceEventTypeObjectsCreate(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	category := d.Get("category").(string)
+	objects := expandStringSet(d.Get("objects").(*schema.Set))
+	err := modifyEventTypeObjects(tm, eventType, category, func(current []string) []string {
+		return addMissingStrings(current, objects)
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating vtm_event_type_objects '%s/%s': %v", eventType, category, err)
+	}
+	d.SetId(eventTypeObjectsId(eventType, category, objects))
+	return nil
+}
+
+func resourceEventTypeObjectsUpdate(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	category := d.Get("category").(string)
+	oldObjects, newObjects := d.GetChange("objects")
+	removed := expandStringSet(oldObjects.(*schema.Set).Difference(newObjects.(*schema.Set)))
+	objects := expandStringSet(newObjects.(*schema.Set))
+	err := modifyEventTypeObjects(tm, eventType, category, func(current []string) []string {
+		for _, name := range removed {
+			current = removeString(current, name)
+		}
+		return addMissingStrings(current, objects)
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating vtm_event_type_objects '%s/%s': %v", eventType, category, err)
+	}
+	d.SetId(eventTypeObjectsId(eventType, category, objects))
+	return nil
+}
+
+func resourceEventTypeObjectsDelete(d *schema.ResourceData, tm interface{}) error {
+	eventType := d.Get("event_type").(string)
+	category := d.Get("category").(string)
+	objects := expandStringSet(d.Get("objects").(*schema.Set))
+	err := modifyEventTypeObjects(tm, eventType, category, func(current []string) []string {
+		for _, name := range objects {
+			current = removeString(current, name)
+		}
+		return current
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete vtm_event_type_objects '%v/%v': %v", eventType, category, err)
+	}
+	d.SetId("")
+	return nil
+}
+
+// resourceEventTypeObjectsImport takes an ID of the form
+// "<event_type>/<category>/<object>,<object>,...". The objects have to be
+// listed, so that an import never takes over objects that other resources
+// or the vtm_event_type subscribed.
+func resourceEventTypeObjectsImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	for _, category := range eventTypeObjectCategories {
+		separator := "/" + category + "/"
+		index := strings.Index(d.Id(), separator)
+		if index <= 0 || index+len(separator) == len(d.Id()) {
+			continue
+		}
+		eventType := d.Id()[:index]
+		objects := strings.Split(d.Id()[index+len(separator):], ",")
+		d.Set("event_type", eventType)
+		d.Set("category", category)
+		d.Set("objects", objects)
+		d.SetId(eventTypeObjectsId(eventType, category, objects))
+		return []*schema.ResourceData{d}, nil
+	}
+	return nil, fmt.Errorf("Invalid vtm_event_type_objects ID '%s', expected '<event_type>/<category>/<object>,...'", d.Id())
+}
+
+// eventTypeObjectsId returns the ID of a vtm_event_type_objects resource.
+func eventTypeObjectsId(eventType, category string, objects []string) string {
+	sorted := append([]string{}, objects...)
+	sort.Strings(sorted)
+	return eventType + "/" + category + "/" + strings.Join(sorted, ",")
+}
+
+// modifyEventTypeObjects applies update to the object list of one category
+// of an event type. An empty object list makes the category match the
+// events of every object, so when update removes the last object the
+// category's event tags are cleared too, and it matches nothing.
+func modifyEventTypeObjects(tm interface{}, eventType, category string, update func([]string) []string) error {
+	read := func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetEventType(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		objects := eventTypeSubscriptionLists(object)[category+"_objects"]
+		return objects, func() *vtm.ReqError {
+			if *objects == nil || len(**objects) == 0 {
+				*eventTypeCategoryEventTags(object)[category] = &[]string{}
+			}
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	}
+	return modifyConfigObjectField(tm, "vtm_event_type", eventType, read, nil, func(value interface{}) (interface{}, error) {
+		return update(jsonStringList(value)), nil
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Pools added to an event type by two vtm_event_type_objects resources
+ *   - Changing the objects of one resource leaves the other's alone
+ *   - A vtm_event_type with ignore_external_subscriptions keeps them
+ *   - The ID holds the event type, category and sorted objects
+ *   - Importing a resource with its list of objects
+ *   - Removing the last objects clears the category's event tags, and the
+ *     vtm_event_type keeps them in its state without a diff
+ *   - Import IDs without a list of objects are rejected
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceEventTypeObjects(t *testing.T) {
+	eventTypeName := acctest.RandomWithPrefix("TestEventTypeObjects")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckEventTypeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getEventTypeObjectsConfig(eventTypeName, `["app1-web", "app1-api"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_event_type.test_vtm_event_type", "pools_objects.#", "0"),
+					resource.TestCheckResourceAttr("vtm_event_type_objects.app1", "objects.#", "2"),
+					testAccCheckEventTypePools(eventTypeName, []string{"app1-api", "app1-web", "app2-web"}),
+				),
+			},
+			{
+				Config: getEventTypeObjectsConfig(eventTypeName, `["app1-web"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_event_type_objects.app1", "id", eventTypeName+"/pools/app1-web"),
+					resource.TestCheckResourceAttr("vtm_event_type_objects.app2", "id", eventTypeName+"/pools/app2-web"),
+					testAccCheckEventTypePools(eventTypeName, []string{"app1-web", "app2-web"}),
+				),
+			},
+			{
+				ResourceName:      "vtm_event_type_objects.app1",
+				ImportState:       true,
+				ImportStateId:     eventTypeName + "/pools/app1-web",
+				ImportStateVerify: true,
+			},
+			{
+				Config:             getEventTypeObjectsConfig(eventTypeName, `["app1-web"]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				ResourceName:  "vtm_event_type_objects.app1",
+				ImportState:   true,
+				ImportStateId: eventTypeName + "/pools",
+				ExpectError:   regexp.MustCompile("expected '<event_type>/<category>/<object>,...'"),
+			},
+			{
+				Config: getEventTypeOnlyConfig(eventTypeName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckEventTypePools(eventTypeName, []string{}),
+					testAccCheckEventTypePoolEventTags(eventTypeName, []string{}),
+					resource.TestCheckResourceAttr("vtm_event_type.test_vtm_event_type", "pools_event_tags.#", "1"),
+				),
+			},
+			{
+				Config:             getEventTypeOnlyConfig(eventTypeName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func TestResourceEventTypeObjectsImport(t *testing.T) {
+	for id, expected := range map[string][3]string{
+		"Service failures/pools/web,api": {"Service failures", "pools", "Service failures/pools/api,web"},
+		"custom/glb/glb1,glb3,glb2":      {"custom", "glb", "custom/glb/glb1,glb2,glb3"},
+		"custom/licensekeys/key1":        {"custom", "licensekeys", "custom/licensekeys/key1"},
+		"Service failures/monitors/ping": {"Service failures", "monitors", "Service failures/monitors/ping"},
+	} {
+		d := schema.TestResourceDataRaw(t, getResourceEventTypeObjectsSchema(), map[string]interface{}{})
+		d.SetId(id)
+		if _, err := resourceEventTypeObjectsImport(d, nil); err != nil {
+			t.Errorf("Importing '%s' failed: %v", id, err)
+			continue
+		}
+		if d.Get("event_type") != expected[0] || d.Get("category") != expected[1] || d.Id() != expected[2] {
+			t.Errorf("Importing '%s' gave event type '%v', category '%v' and ID '%s', expected %v", id, d.Get("event_type"), d.Get("category"), d.Id(), expected)
+		}
+	}
+	for _, id := range []string{"Service failures", "Service failures/pools", "Service failures/pools/", "/pools/web", "Service failures/nodes/web"} {
+		d := schema.TestResourceDataRaw(t, getResourceEventTypeObjectsSchema(), map[string]interface{}{})
+		d.SetId(id)
+		if _, err := resourceEventTypeObjectsImport(d, nil); err == nil {
+			t.Errorf("Importing '%s' did not fail", id)
+		}
+	}
+}
+
+func testAccCheckEventTypePoolEventTags(eventTypeName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		eventType, err := tm.GetEventType(eventTypeName)
+		if err != nil {
+			return fmt.Errorf("EventType %s does not exist: %#v", eventTypeName, err)
+		}
+		if fmt.Sprint(*eventType.Pools.EventTags) != fmt.Sprint(expected) {
+			return fmt.Errorf("EventType %s has pool event tags %v, expected %v", eventTypeName, *eventType.Pools.EventTags, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckEventTypePools(eventTypeName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		eventType, err := tm.GetEventType(eventTypeName)
+		if err != nil {
+			return fmt.Errorf("EventType %s does not exist: %#v", eventTypeName, err)
+		}
+		pools := append([]string{}, *eventType.Pools.Objects...)
+		sort.Strings(pools)
+		if fmt.Sprint(pools) != fmt.Sprint(expected) {
+			return fmt.Errorf("EventType %s has pools %v, expected %v", eventTypeName, pools, expected)
+		}
+		return nil
+	}
+}
+
+func getEventTypeObjectsConfig(eventTypeName, app1Pools string) string {
+	return fmt.Sprintf(`
+        resource "vtm_event_type" "test_vtm_event_type" {
+			name = "%s"
+			ignore_external_subscriptions = true
+			pools_event_tags = ["pooldied"]
+		}
+
+		resource "vtm_event_type_objects" "app1" {
+			event_type = "${vtm_event_type.test_vtm_event_type.name}"
+			category = "pools"
+			objects = %s
+		}
+
+		resource "vtm_event_type_objects" "app2" {
+			event_type = "${vtm_event_type.test_vtm_event_type.name}"
+			category = "pools"
+			objects = ["app2-web"]
+		}`,
+		eventTypeName, app1Pools,
+	)
+}
+
+func getEventTypeOnlyConfig(eventTypeName string) string {
+	return fmt.Sprintf(`
+        resource "vtm_event_type" "test_vtm_event_type" {
+			name = "%s"
+			ignore_external_subscriptions = true
+			pools_event_tags = ["pooldied"]
+		}`,
+		eventTypeName,
+	)
+}
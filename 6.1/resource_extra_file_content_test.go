@@ -0,0 +1,122 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Binary content given as content_base64 is uploaded byte for byte, and
+ *     only its hash is kept in the state
+ *   - Content given as a local source file is uploaded byte for byte
+ *   - Changing the source file plans an update through source_hash
+ *   - Content changed outside Terraform is uploaded again
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestResourceExtraFileContent(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestExtraFileContent")
+	binary := []byte{0x05, 0x02, 0x00, 0xff, 0x0d, 0x0a}
+	changed := []byte{0x05, 0x02, 0x00, 0xfe}
+
+	source, err := ioutil.TempFile("", "TestExtraFileContent")
+	if err != nil {
+		t.Fatalf("Creating temporary file failed: %v", err)
+	}
+	source.Close()
+	defer os.Remove(source.Name())
+	writeSource := func(content []byte) func() {
+		return func() {
+			if err := ioutil.WriteFile(source.Name(), content, 0644); err != nil {
+				t.Fatalf("Writing temporary file failed: %v", err)
+			}
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckExtraFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getExtraFileContentConfig(objName, `content_base64 = "BQIA/w0K"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExtraFileBytes(objName, binary),
+					resource.TestCheckResourceAttr("vtm_extra_file.binary", "content_base64", fileContentHash(binary)),
+					resource.TestCheckResourceAttr("vtm_extra_file.binary", "source_hash", fileContentHash(binary)),
+					resource.TestCheckResourceAttr("vtm_extra_file.binary", "content", ""),
+				),
+			},
+			{
+				Config:             getExtraFileContentConfig(objName, `content_base64 = "BQIA/w0K"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				// Change the file behind Terraform's back
+				PreConfig: func() {
+					client, _ := getRestClient(testAccProvider.Meta())
+					if err := client.putFile(configPath("extra_files", objName), changed); err != nil {
+						t.Fatalf("Changing extra file failed: %v", err)
+					}
+				},
+				Config: getExtraFileContentConfig(objName, `content_base64 = "BQIA/w0K"`),
+				Check:  testAccCheckExtraFileBytes(objName, binary),
+			},
+			{
+				PreConfig: writeSource(binary),
+				Config:    getExtraFileContentConfig(objName, fmt.Sprintf("source = %q", source.Name())),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExtraFileBytes(objName, binary),
+					resource.TestCheckResourceAttr("vtm_extra_file.binary", "content_base64", ""),
+				),
+			},
+			{
+				PreConfig: writeSource(changed),
+				Config:    getExtraFileContentConfig(objName, fmt.Sprintf("source = %q", source.Name())),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExtraFileBytes(objName, changed),
+					resource.TestCheckResourceAttr("vtm_extra_file.binary", "source_hash", fileContentHash(changed)),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckExtraFileBytes checks the raw content of an extra file.
+func testAccCheckExtraFileBytes(objName string, expected []byte) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := getRestClient(testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+		content, readErr := client.getFile(configPath("extra_files", objName))
+		if readErr != nil {
+			return fmt.Errorf("ExtraFile %s does not exist: %v", objName, readErr)
+		}
+		if !bytes.Equal(content, expected) {
+			return fmt.Errorf("ExtraFile %s has content %v, expected %v", objName, content, expected)
+		}
+		return nil
+	}
+}
+
+func getExtraFileContentConfig(name, content string) string {
+	return fmt.Sprintf(`
+        resource "vtm_extra_file" "binary" {
+			name = "%s"
+			%s
+		}`,
+		name, content,
+	)
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_extra_file_directory keeps the extra files under a prefix in step with
+// a local directory. Files are compared by their SHA-256 hash, so only new
+// and changed files are uploaded, and files that have been removed locally
+// are deleted.
+func resourceExtraFileDirectory() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceExtraFileDirectoryRead,
+		Create: resourceExtraFileDirectoryCreate,
+		Update: resourceExtraFileDirectoryUpdate,
+		Delete: resourceExtraFileDirectoryDelete,
+
+		CustomizeDiff: resourceExtraFileDirectoryCustomizeDiff,
+
+		Schema: getResourceExtraFileDirectorySchema(),
+	}
+}
+
+func getResourceExtraFileDirectorySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The local directory holding the files. Files in
+		//  subdirectories are included, with "/" in their path replaced
+		//  by "_".
+		"source_dir": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+
+		// Prepended to the path of each file to give its extra file
+		//  name. Extra files under a non-empty prefix that are not in the
+		//  directory are deleted.
+		"prefix": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "",
+		},
+
+		// Glob patterns for the files to upload, matched against the
+		//  path relative to "source_dir" or the file name. All files are
+		//  uploaded if this is empty.
+		"include": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateExtraFilePattern,
+			},
+		},
+
+		// Glob patterns for files not to upload.
+		"exclude": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateExtraFilePattern,
+			},
+		},
+
+		// The SHA-256 hash of each extra file, by name.
+		"files": &schema.Schema{
+			Type:     schema.TypeMap,
+			Computed: true,
+		},
+	}
+}
+
+// getExtraFileDirectoryFiles scans the directory using the attributes
+// returned by get, which is the Get method of the resource data or diff.
+func getExtraFileDirectoryFiles(get func(string) interface{}) (map[string]extraFileDirectoryFile, error) {
+	return scanExtraFileDirectory(
+		get("source_dir").(string),
+		get("prefix").(string),
+		expandStringList(get("include").([]interface{})),
+		expandStringList(get("exclude").([]interface{})),
+	)
+}
+
+// resourceExtraFileDirectoryCustomizeDiff plans an update when the local
+// files no longer match the extra files. A source directory that is not
+// known until apply reads as an empty string.
+func resourceExtraFileDirectoryCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	if d.Get("source_dir").(string) == "" {
+		return d.SetNewComputed("files")
+	}
+	files, err := getExtraFileDirectoryFiles(d.Get)
+	if err != nil {
+		return err
+	}
+	hashes := extraFileDirectoryHashes(files)
+	current := map[string]string{}
+	for name, hash := range d.Get("files").(map[string]interface{}) {
+		current[name] = hash.(string)
+	}
+	if !reflect.DeepEqual(hashes, current) {
+		return d.SetNew("files", hashes)
+	}
+	return nil
+}
+
+// readExtraFileHashes returns the hashes of the extra files under prefix,
+// if it is not empty, and of the named files.
+func readExtraFileHashes(tm interface{}, prefix string, names map[string]interface{}) (map[string]string, error) {
+	wanted := map[string]bool{}
+	for name := range names {
+		wanted[name] = true
+	}
+	if prefix != "" {
+		objectList, err := tm.(*vtm.VirtualTrafficManager).ListExtraFiles()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read vtm_extra_file_list: %v", err.ErrorText)
+		}
+		for _, name := range *objectList {
+			if strings.HasPrefix(name, prefix) {
+				wanted[name] = true
+			}
+		}
+	}
+
+	client, err := getRestClient(tm)
+	if err != nil {
+		return nil, err
+	}
+	hashes := map[string]string{}
+	for name := range wanted {
+		content, readErr := client.getFile(configPath("extra_files", name))
+		if readErr != nil {
+			if readErr.ErrorId == "resource.not_found" {
+				continue
+			}
+			return nil, fmt.Errorf("Failed to read vtm_extra_file '%v': %v", name, readErr.ErrorText)
+		}
+		hashes[name] = fileContentHash(content)
+	}
+	return hashes, nil
+}
+
+func resourceExtraFileDirectoryRead(d *schema.ResourceData, tm interface{}) error {
+	hashes, err := readExtraFileHashes(tm, d.Get("prefix").(string), d.Get("files").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	d.Set("files", hashes)
+	return nil
+}
+
+func resourceExtraFileDirectoryCreate(d *schema.ResourceData, tm interface{}) error {
+	if err := syncExtraFileDirectory(d, tm); err != nil {
+		return fmt.Errorf("Error creating vtm_extra_file_directory '%s': %v", d.Get("source_dir").(string), err)
+	}
+	d.SetId("extra_file_directory/" + d.Get("prefix").(string))
+	return nil
+}
+
+func resourceExtraFileDirectoryUpdate(d *schema.ResourceData, tm interface{}) error {
+	if err := syncExtraFileDirectory(d, tm); err != nil {
+		return fmt.Errorf("Error updating vtm_extra_file_directory '%s': %v", d.Get("source_dir").(string), err)
+	}
+	d.SetId("extra_file_directory/" + d.Get("prefix").(string))
+	return nil
+}
+
+// syncExtraFileDirectory uploads the local files that are missing or
+// different, and deletes the extra files that were managed by this
+// resource, or are under its prefix, but are no longer in the directory.
+func syncExtraFileDirectory(d *schema.ResourceData, tm interface{}) error {
+	files, err := getExtraFileDirectoryFiles(d.Get)
+	if err != nil {
+		return err
+	}
+	managed, _ := d.GetChange("files")
+	remote, err := readExtraFileHashes(tm, d.Get("prefix").(string), managed.(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+
+	client, err := getRestClient(tm)
+	if err != nil {
+		return err
+	}
+	hashes := extraFileDirectoryHashes(files)
+	upload, remove := planExtraFileSync(hashes, remote)
+	for _, name := range upload {
+		content, err := ioutil.ReadFile(files[name].path)
+		if err != nil {
+			return err
+		}
+		if writeErr := client.putFile(configPath("extra_files", name), content); writeErr != nil {
+			return fmt.Errorf("Failed to upload vtm_extra_file '%v': %v", name, writeErr.ErrorText)
+		}
+		hashes[name] = fileContentHash(content)
+	}
+	for _, name := range remove {
+		if err := tm.(*vtm.VirtualTrafficManager).DeleteExtraFile(name); err != nil && err.ErrorId != "resource.not_found" {
+			return fmt.Errorf("Failed to delete vtm_extra_file '%v': %v", name, err.ErrorText)
+		}
+	}
+	d.Set("files", hashes)
+	return nil
+}
+
+func resourceExtraFileDirectoryDelete(d *schema.ResourceData, tm interface{}) error {
+	for name := range d.Get("files").(map[string]interface{}) {
+		if err := tm.(*vtm.VirtualTrafficManager).DeleteExtraFile(name); err != nil && err.ErrorId != "resource.not_found" {
+			return fmt.Errorf("Failed to delete vtm_extra_file '%v': %v", name, err.ErrorText)
+		}
+	}
+	d.SetId("")
+	return nil
+}
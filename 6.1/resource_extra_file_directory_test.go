@@ -0,0 +1,112 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Every file in a local directory is uploaded as an extra file under a
+ *     prefix, and its hash is shown in "files"
+ *   - Changing a local file uploads it again
+ *   - Removing a local file deletes its extra file
+ *   - Deleting the resource deletes its extra files
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceExtraFileDirectory(t *testing.T) {
+	prefix := acctest.RandomWithPrefix("TestExtraFileDirectory") + "_"
+	dir, err := ioutil.TempDir("", "TestExtraFileDirectory")
+	if err != nil {
+		t.Fatalf("Creating temporary directory failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Writing %s failed: %v", name, err)
+		}
+	}
+	writeFile("maintenance.html", "<html>down</html>")
+	writeFile("lookup.csv", "a,b")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckExtraFileDirectoryDestroy(prefix),
+		Steps: []resource.TestStep{
+			{
+				Config: getExtraFileDirectoryConfig(dir, prefix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_extra_file_directory.site", "files.%", "2"),
+					resource.TestCheckResourceAttr("vtm_extra_file_directory.site", "files."+prefix+"lookup.csv", fileContentHash([]byte("a,b"))),
+					testAccCheckExtraFileBytes(prefix+"maintenance.html", []byte("<html>down</html>")),
+				),
+			},
+			{
+				Config:             getExtraFileDirectoryConfig(dir, prefix),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				PreConfig: func() {
+					writeFile("maintenance.html", "<html>back soon</html>")
+					os.Remove(filepath.Join(dir, "lookup.csv"))
+				},
+				Config: getExtraFileDirectoryConfig(dir, prefix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_extra_file_directory.site", "files.%", "1"),
+					testAccCheckExtraFileBytes(prefix+"maintenance.html", []byte("<html>back soon</html>")),
+					testAccCheckExtraFileDirectoryMissing(prefix+"lookup.csv"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckExtraFileDirectoryMissing(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetExtraFile(name); err == nil {
+			return fmt.Errorf("ExtraFile %s still exists", name)
+		}
+		return nil
+	}
+}
+
+func testAccCheckExtraFileDirectoryDestroy(prefix string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		objectList, err := tm.ListExtraFiles()
+		if err != nil {
+			return fmt.Errorf("Failed to list extra files: %v", err.ErrorText)
+		}
+		for _, name := range *objectList {
+			if strings.HasPrefix(name, prefix) {
+				return fmt.Errorf("ExtraFile %s still exists", name)
+			}
+		}
+		return nil
+	}
+}
+
+func getExtraFileDirectoryConfig(dir, prefix string) string {
+	return fmt.Sprintf(`
+        resource "vtm_extra_file_directory" "site" {
+			source_dir = "%s"
+			prefix = "%s"
+		}`,
+		filepath.ToSlash(dir), prefix,
+	)
+}
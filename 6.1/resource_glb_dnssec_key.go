@@ -0,0 +1,464 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func resourceGlbDnssecKey() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceGlbDnssecKeyRead,
+		Create: resourceGlbDnssecKeyCreate,
+		Update: resourceGlbDnssecKeyUpdate,
+		Delete: resourceGlbDnssecKeyDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceGlbDnssecKeyImport,
+		},
+
+		CustomizeDiff: resourceGlbDnssecKeyCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+
+			// The name of the vtm_ssl_server_key that holds the generated
+			//  key pair.
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// The domain that the key signs.
+			"domain": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// The DNSSEC signing algorithm.
+			"algorithm": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"ECDSAP256SHA256", "ECDSAP384SHA384", "RSASHA256", "RSASHA512"}, false),
+				Default:      "ECDSAP256SHA256",
+			},
+
+			// The size in bits of an RSA key: 2048, 3072 or 4096. ECDSA
+			//  keys have the size of the algorithm's curve.
+			"key_size": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// Whether this is a key signing key, whose DS record is
+			//  published in the parent zone, or a zone signing key.
+			"key_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"ksk", "zsk"}, false),
+				Default:      "zsk",
+			},
+
+			// The vtm_glb_service whose "dnssec_keys" the key is added to
+			//  for the domain while it is published or active.
+			"glb_service": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// When the key is added to the GLB service, so that its DNSKEY
+			//  record is published, as an RFC 3339 timestamp. Defaults to
+			//  immediately.
+			"publish": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// When a key signing key becomes active, so that its DS
+			//  record should be published in the parent zone, as an RFC
+			//  3339 timestamp. Defaults to the publish time. The traffic
+			//  manager signs with a zone signing key as soon as it is
+			//  published, so this cannot be set for one.
+			"activate": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// When the key is removed from the GLB service, as an RFC 3339
+			//  timestamp. Defaults to never.
+			"retire": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// The current stage of the key's rollover: "generated",
+			//  "published", "active" or "retired".
+			"stage": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// Whether the GLB service's "dnssec_keys" listed the key for
+			//  the domain when it was last read.
+			"in_glb_service": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			// The key tag that identifies the key in RRSIG and DS records.
+			"key_tag": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			// The DNSKEY record for the key.
+			"dnskey_record": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			// The DS record, with a SHA-256 digest, to publish in the
+			//  parent zone. Only set while the key is active.
+			"ds_record": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceGlbDnssecKeyRead(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	domain := d.Get("domain").(string)
+	object, err := tm.(*vtm.VirtualTrafficManager).GetSslServerKey(objectName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_glb_dnssec_key '%v': %v", objectName, err.ErrorText)
+	}
+
+	chain, parseErr := parseSslCertificates(string(*object.Basic.Public))
+	if parseErr != nil {
+		return fmt.Errorf("Failed to read vtm_glb_dnssec_key '%v': %v", objectName, parseErr)
+	}
+	key, keyErr := newDnssecKey(chain[0].PublicKey, d.Get("algorithm").(string), d.Get("key_type") == "ksk")
+	if keyErr != nil {
+		return fmt.Errorf("Failed to read vtm_glb_dnssec_key '%v': %v", objectName, keyErr)
+	}
+
+	// Whether a published key is also active is not kept on the traffic
+	// manager, so the stage is the one last applied. Only an imported key
+	// has its stage taken from the GLB service. If another client has
+	// added or removed the key, in_glb_service shows it, and the plan puts
+	// the key back in the service to match its stage.
+	stage := d.Get("stage").(string)
+	listed := false
+	if serviceName := d.Get("glb_service").(string); serviceName != "" {
+		listedDomain, ok, err := glbServiceDnssecKeyDomain(tm, serviceName, objectName)
+		if err != nil {
+			return fmt.Errorf("Failed to read vtm_glb_dnssec_key '%v': %v", objectName, err)
+		}
+		listed = ok && dnsFqdn(listedDomain) == dnsFqdn(domain)
+		if stage != "" && listed != (stage == "published" || stage == "active") {
+			log.Printf("[WARN] vtm_glb_dnssec_key '%s' is %s, but another client has changed whether vtm_glb_service '%s' lists it", objectName, stage, serviceName)
+		}
+	}
+	if stage == "" {
+		stage = "generated"
+		if listed {
+			stage = "published"
+		}
+	}
+
+	d.Set("stage", stage)
+	d.Set("in_glb_service", listed)
+	d.Set("key_tag", key.keyTag())
+	d.Set("dnskey_record", key.dnskeyRecord(domain))
+	if stage == "active" {
+		d.Set("ds_record", key.dsRecord(domain))
+	} else {
+		d.Set("ds_record", "")
+	}
+	return nil
+}
+
+// resourceGlbDnssecKeyCustomizeDiff checks the key's size and timestamps, and
+// plans the change to the stage that the key should be in now.
+func resourceGlbDnssecKeyCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	algorithmName := d.Get("algorithm").(string)
+	algorithm := dnssecAlgorithms[algorithmName]
+	if size := d.Get("key_size").(int); size != 0 {
+		if algorithm.keyAlgorithm != "rsa" {
+			return fmt.Errorf("key_size cannot be set for %s, whose keys are %d bits", algorithmName, algorithm.size)
+		}
+		if size != 2048 && size != 3072 && size != 4096 {
+			return fmt.Errorf("key_size must be 2048, 3072 or 4096, not %d", size)
+		}
+	}
+	if d.Get("key_type") != "ksk" && d.Get("activate").(string) != "" {
+		return fmt.Errorf("activate can only be set for a key signing key; a zone signing key is used as soon as it is published")
+	}
+
+	stage, err := dnssecKeyStage(d.Get("publish").(string), d.Get("activate").(string), d.Get("retire").(string), time.Now())
+	if err != nil {
+		return err
+	}
+	oldStage := d.Get("stage").(string)
+	if stage != oldStage {
+		if err := d.SetNew("stage", stage); err != nil {
+			return err
+		}
+		if (stage == "active") != (oldStage == "active") {
+			if err := d.SetNewComputed("ds_record"); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A new key, or one moved to another service, is only read once it has
+	// been added.
+	if d.Id() == "" || d.HasChange("glb_service") {
+		return nil
+	}
+	listed := d.Get("glb_service").(string) != "" && (stage == "published" || stage == "active")
+	if listed != d.Get("in_glb_service").(bool) {
+		return d.SetNew("in_glb_service", listed)
+	}
+	return nil
+}
+
+func resourceGlbDnssecKeyCreate(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	domain := d.Get("domain").(string)
+	if _, err := dnssecKeyStage(d.Get("publish").(string), d.Get("activate").(string), d.Get("retire").(string), time.Now()); err != nil {
+		return fmt.Errorf("Error creating vtm_glb_dnssec_key '%s': %v", objectName, err)
+	}
+
+	algorithm := dnssecAlgorithms[d.Get("algorithm").(string)]
+	size := algorithm.size
+	if algorithm.keyAlgorithm == "rsa" && d.Get("key_size").(int) != 0 {
+		size = d.Get("key_size").(int)
+	}
+	note := dnssecKeyNote(d.Get("key_type").(string), d.Get("algorithm").(string), domain)
+	if existing, err := tm.(*vtm.VirtualTrafficManager).GetSslServerKey(objectName); err == nil {
+		// A key left by a create that failed to add it to the GLB
+		// service is used again, so that its key tag does not change.
+		if !isDnssecKeyFor(existing, note, size) {
+			return fmt.Errorf("Error creating vtm_glb_dnssec_key '%s': a vtm_ssl_server_key with that name already exists", objectName)
+		}
+		log.Printf("[INFO] Using the existing vtm_ssl_server_key '%s' for vtm_glb_dnssec_key", objectName)
+	} else {
+		// The vTM stores the key pair as an SSL key, which needs a
+		// certificate; the certificate's validity is not used by DNSSEC.
+		generated, err := generateSslKey(sslKeyOptions{
+			algorithm:    algorithm.keyAlgorithm,
+			size:         size,
+			subject:      "CN=" + strings.Replace(strings.TrimSuffix(domain, "."), ",", "\\,", -1),
+			validityDays: 3650,
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating vtm_glb_dnssec_key '%s': %v", objectName, err)
+		}
+		object := tm.(*vtm.VirtualTrafficManager).NewSslServerKey(objectName, note, generated.private, generated.certificate, generated.request)
+		_, applyErr := object.Apply()
+		if applyErr != nil {
+			info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
+			return fmt.Errorf("Error creating vtm_glb_dnssec_key '%s': %s %s", objectName, applyErr.ErrorText, info)
+		}
+	}
+	// The ID is only set once the key is in its stage, so that a failure
+	// leaves no tainted resource whose replacement would generate a new
+	// key; the next apply uses the key that was stored.
+	if err := setGlbDnssecKeyStage(d, tm); err != nil {
+		return fmt.Errorf("Error creating vtm_glb_dnssec_key '%s': %v", objectName, err)
+	}
+	d.SetId(objectName)
+	return resourceGlbDnssecKeyRead(d, tm)
+}
+
+// isDnssecKeyFor reports whether an SSL server key was generated for a
+// DNSSEC key with this note, and so for the same key type, algorithm and
+// domain, and has the given size.
+func isDnssecKeyFor(object *vtm.SslServerKey, note string, size int) bool {
+	if string(*object.Basic.Note) != note {
+		return false
+	}
+	chain, err := parseSslCertificates(string(*object.Basic.Public))
+	if err != nil {
+		return false
+	}
+	if rsaKey, ok := chain[0].PublicKey.(*rsa.PublicKey); ok {
+		return rsaKey.N.BitLen() == size
+	}
+	return true
+}
+
+func resourceGlbDnssecKeyUpdate(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	if err := setGlbDnssecKeyStage(d, tm); err != nil {
+		return fmt.Errorf("Error updating vtm_glb_dnssec_key '%s': %v", objectName, err)
+	}
+	return resourceGlbDnssecKeyRead(d, tm)
+}
+
+// resourceGlbDnssecKeyImport imports a key generated by this resource, with
+// the ID "<name>" or "<glb_service>/<name>". The key type, algorithm and
+// domain are recovered from the key's note; the rollover timestamps are not
+// kept on the traffic manager, and must be configured again.
+func resourceGlbDnssecKeyImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	objectName := d.Id()
+	serviceName := ""
+	if separator := strings.LastIndex(objectName, "/"); separator >= 0 {
+		serviceName, objectName = objectName[:separator], objectName[separator+1:]
+	}
+	object, err := tm.(*vtm.VirtualTrafficManager).GetSslServerKey(objectName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to import vtm_glb_dnssec_key '%s': %v", objectName, err.ErrorText)
+	}
+	keyType, algorithm, domain, ok := parseDnssecKeyNote(string(*object.Basic.Note))
+	if !ok {
+		return nil, fmt.Errorf("Failed to import vtm_glb_dnssec_key '%s': the vtm_ssl_server_key was not generated as a DNSSEC key", objectName)
+	}
+	chain, parseErr := parseSslCertificates(string(*object.Basic.Public))
+	if parseErr != nil {
+		return nil, fmt.Errorf("Failed to import vtm_glb_dnssec_key '%s': %v", objectName, parseErr)
+	}
+	if algorithm == "" {
+		if algorithm, parseErr = dnssecPublicKeyAlgorithm(chain[0].PublicKey); parseErr != nil {
+			return nil, fmt.Errorf("Failed to import vtm_glb_dnssec_key '%s': %v", objectName, parseErr)
+		}
+	}
+	if rsaKey, ok := chain[0].PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() != dnssecAlgorithms[algorithm].size {
+		d.Set("key_size", rsaKey.N.BitLen())
+	}
+
+	// The note has the domain as a fully qualified name; the service's
+	// table has it as it was configured.
+	domain = strings.TrimSuffix(domain, ".")
+	if serviceName != "" {
+		listedDomain, listed, err := glbServiceDnssecKeyDomain(tm, serviceName, objectName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to import vtm_glb_dnssec_key '%s': %v", objectName, err)
+		}
+		if listed {
+			domain = listedDomain
+		}
+		d.Set("glb_service", serviceName)
+	}
+
+	d.Set("name", objectName)
+	d.Set("domain", domain)
+	d.Set("algorithm", algorithm)
+	d.Set("key_type", keyType)
+	d.SetId(objectName)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceGlbDnssecKeyDelete(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	if serviceName := d.Get("glb_service").(string); serviceName != "" {
+		// There is nothing to remove if the service has already been
+		// deleted.
+		if _, err := tm.(*vtm.VirtualTrafficManager).GetGlbService(serviceName); err == nil {
+			err := modifyGlbServiceDnssecKeys(tm, serviceName, func(rows []interface{}) []interface{} {
+				return removeTableRowEntry(rows, "domain", "ssl_key", d.Get("domain").(string), objectName)
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to delete vtm_glb_dnssec_key '%v': %v", objectName, err)
+			}
+		}
+	}
+	err := tm.(*vtm.VirtualTrafficManager).DeleteSslServerKey(objectName)
+	if err != nil && err.ErrorId != "resource.not_found" {
+		return fmt.Errorf("Failed to delete vtm_glb_dnssec_key '%v': %v", objectName, err.ErrorText)
+	}
+	d.SetId("")
+	return nil
+}
+
+// setGlbDnssecKeyStage records the key's current rollover stage, and adds it
+// to or removes it from the GLB service to match.
+func setGlbDnssecKeyStage(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	domain := d.Get("domain").(string)
+	stage, err := dnssecKeyStage(d.Get("publish").(string), d.Get("activate").(string), d.Get("retire").(string), time.Now())
+	if err != nil {
+		return err
+	}
+	if serviceName := d.Get("glb_service").(string); serviceName != "" {
+		err := modifyGlbServiceDnssecKeys(tm, serviceName, func(rows []interface{}) []interface{} {
+			if stage == "published" || stage == "active" {
+				row := map[string]interface{}{"domain": domain, "ssl_key": []string{objectName}}
+				return mergeTableRows(rows, []interface{}{row}, "domain", "ssl_key")
+			}
+			return removeTableRowEntry(rows, "domain", "ssl_key", domain, objectName)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	d.Set("stage", stage)
+	d.Set("in_glb_service", d.Get("glb_service").(string) != "" && (stage == "published" || stage == "active"))
+	return nil
+}
+
+// glbServiceDnssecKeyDomain returns the domain that a GLB service's
+// dnssec_keys table lists a key for, if any. A service that does not exist
+// lists no keys.
+func glbServiceDnssecKeyDomain(tm interface{}, serviceName, keyName string) (string, bool, error) {
+	object, err := tm.(*vtm.VirtualTrafficManager).GetGlbService(serviceName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("vtm_glb_service '%s': %v", serviceName, err.ErrorText)
+	}
+	if object.Basic.DnssecKeys != nil {
+		for _, row := range *object.Basic.DnssecKeys {
+			if row.Domain != nil && row.SslKey != nil && indexOfString(*row.SslKey, keyName) >= 0 {
+				return *row.Domain, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// modifyGlbServiceDnssecKeys applies update to the decoded dnssec_keys table
+// of a GLB service, and writes the service back if that changed anything.
+func modifyGlbServiceDnssecKeys(tm interface{}, serviceName string, update func([]interface{}) []interface{}) error {
+	read := func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetGlbService(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &object.Basic.DnssecKeys, func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	}
+	err := modifyConfigObjectField(tm, "vtm_glb_service", serviceName, read, nil, func(value interface{}) (interface{}, error) {
+		return update(jsonRows(value)), nil
+	})
+	if err != nil {
+		return fmt.Errorf("vtm_glb_service '%s': %v", serviceName, err)
+	}
+	return nil
+}
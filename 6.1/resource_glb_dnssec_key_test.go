@@ -0,0 +1,176 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Generation of a DNSSEC key signing key, with DNSKEY and DS records
+ *   - A published key is added to a vtm_glb_service with
+ *     ignore_external_dnssec_keys, without being reported as drift
+ *   - An active key removed from the service by another client is added
+ *     back, and stays active with the same DS record
+ *   - A published key signing key is in the service, but has no DS record
+ *     until it is active
+ *   - Import of the key, with its service
+ *   - Retiring the key removes it from the service
+ *   - Deleting the key removes its vtm_ssl_server_key
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceGlbDnssecKey(t *testing.T) {
+	serviceName := acctest.RandomWithPrefix("TestGlbDnssecKey")
+	keyName := serviceName + "_ksk"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckGlbDnssecKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getGlbDnssecKeyConfig(serviceName, keyName, `retire = "2099-01-01T00:00:00Z"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "stage", "active"),
+					resource.TestMatchResourceAttr("vtm_glb_dnssec_key.ksk", "dnskey_record", regexp.MustCompile(`^example\.com\. IN DNSKEY 257 3 13 `)),
+					resource.TestMatchResourceAttr("vtm_glb_dnssec_key.ksk", "ds_record", regexp.MustCompile(`^example\.com\. IN DS [0-9]+ 13 2 [0-9A-F]{64}$`)),
+					testAccCheckGlbDnssecKeyCount(serviceName, 2),
+				),
+			},
+			{
+				// The service must not report the key as drift
+				Config:             getGlbDnssecKeyConfig(serviceName, keyName, `retire = "2099-01-01T00:00:00Z"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				PreConfig: testAccRemoveGlbDnssecKey(serviceName, keyName),
+				Config:    getGlbDnssecKeyConfig(serviceName, keyName, `retire = "2099-01-01T00:00:00Z"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "stage", "active"),
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "in_glb_service", "true"),
+					resource.TestMatchResourceAttr("vtm_glb_dnssec_key.ksk", "ds_record", regexp.MustCompile(`^example\.com\. IN DS [0-9]+ 13 2 [0-9A-F]{64}$`)),
+					testAccCheckGlbDnssecKeyCount(serviceName, 2),
+				),
+			},
+			{
+				ResourceName:      "vtm_glb_dnssec_key.ksk",
+				ImportState:       true,
+				ImportStateId:     serviceName + "/" + keyName,
+				ImportStateVerify: true,
+				// The timestamps are only in the configuration, so the
+				// imported key's stage is the one its service shows
+				ImportStateVerifyIgnore: []string{"retire", "stage", "ds_record"},
+			},
+			{
+				Config: getGlbDnssecKeyConfig(serviceName, keyName, `publish = "2000-01-01T00:00:00Z"
+			activate = "2099-01-01T00:00:00Z"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "stage", "published"),
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "ds_record", ""),
+					testAccCheckGlbDnssecKeyCount(serviceName, 2),
+				),
+			},
+			{
+				Config: getGlbDnssecKeyConfig(serviceName, keyName, `retire = "2000-01-01T00:00:00Z"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "stage", "retired"),
+					testAccCheckGlbDnssecKeyCount(serviceName, 1),
+					resource.TestCheckResourceAttr("vtm_glb_dnssec_key.ksk", "ds_record", ""),
+				),
+			},
+			{
+				Config:      getGlbDnssecKeyConfig(serviceName, keyName, `key_size = 2048`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("key_size cannot be set for ECDSAP256SHA256"),
+			},
+		},
+	})
+}
+
+// testAccRemoveGlbDnssecKey removes a key from a GLB service, as another
+// client would.
+func testAccRemoveGlbDnssecKey(serviceName, keyName string) func() {
+	return func() {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		err := modifyGlbServiceDnssecKeys(tm, serviceName, func(rows []interface{}) []interface{} {
+			return removeTableRowEntry(rows, "domain", "ssl_key", "example.com", keyName)
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func testAccCheckGlbDnssecKeyCount(serviceName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		service, err := tm.GetGlbService(serviceName)
+		if err != nil {
+			return fmt.Errorf("GlbService %s does not exist: %#v", serviceName, err)
+		}
+		count := 0
+		for _, row := range *service.Basic.DnssecKeys {
+			count += len(*row.SslKey)
+		}
+		if count != expected {
+			return fmt.Errorf("GlbService %s has %d DNSSEC keys, expected %d", serviceName, count, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckGlbDnssecKeyDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_glb_dnssec_key" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetSslServerKey(objectName); err == nil {
+			return fmt.Errorf("SslServerKey %s still exists", objectName)
+		}
+	}
+
+	return nil
+}
+
+func getGlbDnssecKeyConfig(serviceName, keyName, timestamps string) string {
+	return fmt.Sprintf(`
+        resource "vtm_ssl_server_key" "zsk" {
+			name = "%s_zsk"
+			note = "TEST_TEXT"
+			key_algorithm = "ecdsa"
+			subject = "CN=example.com"
+			self_signed_validity = 30
+		}
+
+		resource "vtm_glb_service" "test_vtm_glb_service" {
+			name = "%s"
+			domains = ["example.com"]
+			ignore_external_dnssec_keys = true
+			dnssec_keys {
+				domain = "example.com"
+				ssl_key = ["${vtm_ssl_server_key.zsk.name}"]
+			}
+		}
+
+		resource "vtm_glb_dnssec_key" "ksk" {
+			name = "%s"
+			domain = "example.com"
+			key_type = "ksk"
+			glb_service = "${vtm_glb_service.test_vtm_glb_service.name}"
+			%s
+		}`,
+		serviceName, serviceName, keyName, timestamps,
+	)
+}
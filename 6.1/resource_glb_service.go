@@ -24,7 +24,9 @@ func resourceGlbService() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceGlbServiceSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_glb_service"),
+
+		Schema: addLicensedFeatureSchema(getResourceGlbServiceSchema()),
 	}
 }
 
@@ -109,9 +111,19 @@ func getResourceGlbServiceSchema() map[string]*schema.Schema {
 
 		// JSON representation of dnssec_keys
 		"dnssec_keys_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceGlbServiceSchema, "dnssec_keys"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceGlbServiceSchema, "dnssec_keys"),
+		},
+
+		// Only manage the keys listed in dnssec_keys or dnssec_keys_json,
+		//  leaving any others, such as those added by vtm_glb_dnssec_key
+		//  resources, in place. This setting is not stored on the vTM.
+		"ignore_external_dnssec_keys": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
 		},
 
 		// The domains shown here should be a list of Fully Qualified Domain
@@ -202,9 +214,10 @@ func getResourceGlbServiceSchema() map[string]*schema.Schema {
 
 		// JSON representation of location_settings
 		"location_settings_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceGlbServiceSchema, "location_settings"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceGlbServiceSchema, "location_settings"),
 		},
 
 		// Return all or none of the IPs under complete failure.
@@ -290,8 +303,21 @@ func resourceGlbServiceRead(d *schema.ResourceData, tm interface{}) (readError e
 	lastAssignedField = "disable_on_failure"
 	d.Set("disable_on_failure", bool(*object.Basic.DisableOnFailure))
 	lastAssignedField = "dnssec_keys"
+	managedDnssecKeys := tableRowKeys(d.Get("dnssec_keys"), d.Get("dnssec_keys_json"), "domain", "ssl_key")
 	dnssecKeys := make([]map[string]interface{}, 0, len(*object.Basic.DnssecKeys))
 	for _, item := range *object.Basic.DnssecKeys {
+		if d.Get("ignore_external_dnssec_keys") == true && item.Domain != nil && item.SslKey != nil {
+			keys := []string{}
+			for _, key := range *item.SslKey {
+				if managedDnssecKeys[*item.Domain+"/"+key] {
+					keys = append(keys, key)
+				}
+			}
+			if len(keys) == 0 {
+				continue
+			}
+			item = vtm.GlbServiceDnssecKeys{Domain: item.Domain, SslKey: &keys}
+		}
 		itemTerraform := make(map[string]interface{})
 		if item.Domain != nil {
 			itemTerraform["domain"] = string(*item.Domain)
@@ -301,9 +327,12 @@ func resourceGlbServiceRead(d *schema.ResourceData, tm interface{}) (readError e
 		}
 		dnssecKeys = append(dnssecKeys, itemTerraform)
 	}
-	d.Set("dnssec_keys", dnssecKeys)
-	dnssecKeysJson, _ := json.Marshal(dnssecKeys)
-	d.Set("dnssec_keys_json", dnssecKeysJson)
+	if _, ok := d.GetOk("dnssec_keys_json"); ok {
+		dnssecKeysJson, _ := json.Marshal(dnssecKeys)
+		d.Set("dnssec_keys_json", string(dnssecKeysJson))
+	} else {
+		d.Set("dnssec_keys", dnssecKeys)
+	}
 	lastAssignedField = "domains"
 	d.Set("domains", []string(*object.Basic.Domains))
 	lastAssignedField = "enabled"
@@ -332,9 +361,12 @@ func resourceGlbServiceRead(d *schema.ResourceData, tm interface{}) (readError e
 		}
 		locationSettings = append(locationSettings, itemTerraform)
 	}
-	d.Set("location_settings", locationSettings)
-	locationSettingsJson, _ := json.Marshal(locationSettings)
-	d.Set("location_settings_json", locationSettingsJson)
+	if _, ok := d.GetOk("location_settings_json"); ok {
+		locationSettingsJson, _ := json.Marshal(locationSettings)
+		d.Set("location_settings_json", string(locationSettingsJson))
+	} else {
+		d.Set("location_settings", locationSettings)
+	}
 	lastAssignedField = "return_ips_on_fail"
 	d.Set("return_ips_on_fail", bool(*object.Basic.ReturnIpsOnFail))
 	lastAssignedField = "rules"
@@ -347,6 +379,7 @@ func resourceGlbServiceRead(d *schema.ResourceData, tm interface{}) (readError e
 	d.Set("log_filename", string(*object.Log.Filename))
 	lastAssignedField = "log_format"
 	d.Set("log_format", string(*object.Log.Format))
+	d.Set("license_warning", licenseWarning(tm, "vtm_glb_service"))
 	d.SetId(objectName)
 	return nil
 }
@@ -368,8 +401,12 @@ func resourceGlbServiceExists(d *schema.ResourceData, tm interface{}) (bool, err
 
 func resourceGlbServiceCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_glb_service", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewGlbService(objectName)
-	resourceGlbServiceObjectFieldAssignments(d, object)
+	if err := resourceGlbServiceObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_glb_service '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -381,11 +418,15 @@ func resourceGlbServiceCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceGlbServiceUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_glb_service", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetGlbService(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_glb_service '%v': %v", objectName, err)
 	}
-	resourceGlbServiceObjectFieldAssignments(d, object)
+	if err := resourceGlbServiceObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_glb_service '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -395,7 +436,7 @@ func resourceGlbServiceUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceGlbServiceObjectFieldAssignments(d *schema.ResourceData, object *vtm.GlbService) {
+func resourceGlbServiceObjectFieldAssignments(d *schema.ResourceData, object *vtm.GlbService) error {
 	setString(&object.Basic.Algorithm, d, "algorithm")
 	setBool(&object.Basic.AllMonitorsNeeded, d, "all_monitors_needed")
 	setBool(&object.Basic.Autorecovery, d, "autorecovery")
@@ -441,9 +482,12 @@ func resourceGlbServiceObjectFieldAssignments(d *schema.ResourceData, object *vt
 	}
 	setInt(&object.Basic.Ttl, d, "ttl")
 
+	externalDnssecKeys := externalTableRows(d, "ignore_external_dnssec_keys", "dnssec_keys", "domain", "ssl_key", object.Basic.DnssecKeys)
 	object.Basic.DnssecKeys = &vtm.GlbServiceDnssecKeysTable{}
 	if dnssecKeysJson, ok := d.GetOk("dnssec_keys_json"); ok {
-		_ = json.Unmarshal([]byte(dnssecKeysJson.(string)), object.Basic.DnssecKeys)
+		if err := json.Unmarshal([]byte(dnssecKeysJson.(string)), object.Basic.DnssecKeys); err != nil {
+			return fmt.Errorf("Invalid dnssec_keys_json: %v", err)
+		}
 	} else if dnssecKeys, ok := d.GetOk("dnssec_keys"); ok {
 		for _, row := range dnssecKeys.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -456,10 +500,15 @@ func resourceGlbServiceObjectFieldAssignments(d *schema.ResourceData, object *vt
 	} else {
 		d.Set("dnssec_keys", make([]map[string]interface{}, 0, len(*object.Basic.DnssecKeys)))
 	}
+	if err := addTableRows(&object.Basic.DnssecKeys, externalDnssecKeys, "domain", "ssl_key"); err != nil {
+		return fmt.Errorf("Failed to keep external DNSSEC keys: %v", err)
+	}
 
 	object.Basic.LocationSettings = &vtm.GlbServiceLocationSettingsTable{}
 	if locationSettingsJson, ok := d.GetOk("location_settings_json"); ok {
-		_ = json.Unmarshal([]byte(locationSettingsJson.(string)), object.Basic.LocationSettings)
+		if err := json.Unmarshal([]byte(locationSettingsJson.(string)), object.Basic.LocationSettings); err != nil {
+			return fmt.Errorf("Invalid location_settings_json: %v", err)
+		}
 	} else if locationSettings, ok := d.GetOk("location_settings"); ok {
 		for _, row := range locationSettings.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -477,6 +526,7 @@ func resourceGlbServiceObjectFieldAssignments(d *schema.ResourceData, object *vt
 	setBool(&object.Log.Enabled, d, "log_enabled")
 	setString(&object.Log.Filename, d, "log_filename")
 	setString(&object.Log.Format, d, "log_format")
+	return nil
 }
 
 func resourceGlbServiceDelete(d *schema.ResourceData, tm interface{}) error {
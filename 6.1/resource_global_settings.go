@@ -28,7 +28,7 @@ func resourceGlobalSettings() *schema.Resource {
 }
 
 func getResourceGlobalSettingsSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	fields := map[string]*schema.Schema{
 
 		// How often, in milliseconds, each traffic manager child process
 		//  (that isn't listening for new connections) checks to see whether
@@ -294,22 +294,33 @@ func getResourceGlobalSettingsSchema() map[string]*schema.Schema {
 			Default:  true,
 		},
 
-		// The password used to protect the bootloader. An empty string
-		//  means there will be no protection.
-		"appliance_bootloader_password": &schema.Schema{
-			Type:     schema.TypeString,
+		// Settings from the "appliance" section of the global settings.
+		"appliance": &schema.Schema{
+			Type:     schema.TypeList,
 			Optional: true,
-		},
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
 
-		// Whether or not the traffic manager will attempt to route response
-		//  packets back to clients via the same route on which the corresponding
-		//  request arrived.   Note that this applies only to the last hop
-		//  of the route - the behaviour of upstream routers cannot be altered
-		//  by the traffic manager.
-		"appliance_return_path_routing_enabled": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
+					// The password used to protect the bootloader. An empty string
+					//  means there will be no protection.
+					"bootloader_password": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+
+					// Whether or not the traffic manager will attempt to route response
+					//  packets back to clients via the same route on which the corresponding
+					//  request arrived.   Note that this applies only to the last hop
+					//  of the route - the behaviour of upstream routers cannot be altered
+					//  by the traffic manager.
+					"return_path_routing_enabled": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
+				},
+			},
 		},
 
 		// The maximum size of a dependent resource that can undergo Web
@@ -2085,6 +2096,8 @@ func getResourceGlobalSettingsSchema() map[string]*schema.Schema {
 			Default:  false,
 		},
 	}
+	addSectionAliases(fields, "appliance", "appliance")
+	return fields
 }
 
 func resourceGlobalSettingsRead(d *schema.ResourceData, tm interface{}) (readError error) {
@@ -2162,10 +2175,12 @@ func resourceGlobalSettingsRead(d *schema.ResourceData, tm interface{}) (readErr
 	d.Set("admin_support_tls1_2", bool(*object.Admin.SupportTls12))
 	lastAssignedField = "admin_support_tls1_3"
 	d.Set("admin_support_tls1_3", bool(*object.Admin.SupportTls13))
-	lastAssignedField = "appliance_bootloader_password"
-	d.Set("appliance_bootloader_password", string(*object.Appliance.BootloaderPassword))
-	lastAssignedField = "appliance_return_path_routing_enabled"
-	d.Set("appliance_return_path_routing_enabled", bool(*object.Appliance.ReturnPathRoutingEnabled))
+	appliance := make(map[string]interface{})
+	lastAssignedField = "appliance.bootloader_password"
+	appliance["bootloader_password"] = string(*object.Appliance.BootloaderPassword)
+	lastAssignedField = "appliance.return_path_routing_enabled"
+	appliance["return_path_routing_enabled"] = bool(*object.Appliance.ReturnPathRoutingEnabled)
+	setSection(d, "appliance", "appliance", appliance, getResourceGlobalSettingsSchema()["appliance"].Elem.(*schema.Resource).Schema)
 	lastAssignedField = "aptimizer_max_dependent_fetch_size"
 	d.Set("aptimizer_max_dependent_fetch_size", string(*object.Aptimizer.MaxDependentFetchSize))
 	lastAssignedField = "aptimizer_max_original_content_buffer_size"
@@ -2575,6 +2590,8 @@ func resourceGlobalSettingsRead(d *schema.ResourceData, tm interface{}) (readErr
 }
 
 func resourceGlobalSettingsUpdate(d *schema.ResourceData, tm interface{}) error {
+	defer setSectionDefaults(d, "appliance", "appliance", getResourceGlobalSettingsSchema()["appliance"].Elem.(*schema.Resource).Schema)()
+
 	object, err := tm.(*vtm.VirtualTrafficManager).GetGlobalSettings()
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_global_setting: %v", err)
@@ -2621,8 +2638,8 @@ func resourceGlobalSettingsUpdate(d *schema.ResourceData, tm interface{}) error
 	setBool(&object.Admin.SupportTls11, d, "admin_support_tls1_1")
 	setBool(&object.Admin.SupportTls12, d, "admin_support_tls1_2")
 	setBool(&object.Admin.SupportTls13, d, "admin_support_tls1_3")
-	setString(&object.Appliance.BootloaderPassword, d, "appliance_bootloader_password")
-	setBool(&object.Appliance.ReturnPathRoutingEnabled, d, "appliance_return_path_routing_enabled")
+	setString(&object.Appliance.BootloaderPassword, d, "appliance.0.bootloader_password")
+	setBool(&object.Appliance.ReturnPathRoutingEnabled, d, "appliance.0.return_path_routing_enabled")
 	setString(&object.Aptimizer.MaxDependentFetchSize, d, "aptimizer_max_dependent_fetch_size")
 	setString(&object.Aptimizer.MaxOriginalContentBufferSize, d, "aptimizer_max_original_content_buffer_size")
 	setInt(&object.Aptimizer.WatchdogInterval, d, "aptimizer_watchdog_interval")
@@ -2689,7 +2706,9 @@ func resourceGlobalSettingsUpdate(d *schema.ResourceData, tm interface{}) error
 
 	object.Ip.ApplianceReturnpath = &vtm.GlobalSettingsApplianceReturnpathTable{}
 	if ipApplianceReturnpathJson, ok := d.GetOk("ip_appliance_returnpath_json"); ok {
-		_ = json.Unmarshal([]byte(ipApplianceReturnpathJson.(string)), object.Ip.ApplianceReturnpath)
+		if err := json.Unmarshal([]byte(ipApplianceReturnpathJson.(string)), object.Ip.ApplianceReturnpath); err != nil {
+			return fmt.Errorf("Error updating vtm_global_setting: Invalid ip_appliance_returnpath_json: %v", err)
+		}
 	} else if ipApplianceReturnpath, ok := d.GetOk("ip_appliance_returnpath"); ok {
 		for _, row := range ipApplianceReturnpath.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -37,7 +37,7 @@ func TestResourceGlobalSettingsEnhanced(t *testing.T) {
 					// Check that a string default is overridden
 					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "socket_optimizations", "yes"),
 					// Check that a boolean default is overridden
-					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "appliance_return_path_routing_enabled", "true"),
+					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "appliance.0.return_path_routing_enabled", "true"),
 				),
 			},
 			{
@@ -47,8 +47,8 @@ func TestResourceGlobalSettingsEnhanced(t *testing.T) {
 					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "accepting_delay", "50"),
 					// Check that a removed string integer field reverts to default value
 					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "socket_optimizations", "auto"),
-					// Check that a removed boolean integer field reverts to default value
-					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "appliance_return_path_routing_enabled", "false"),
+					// Check that a removed block reverts to its default values
+					resource.TestCheckResourceAttr("vtm_global_settings.global_settings", "appliance.#", "0"),
 				),
 			},
 		},
@@ -64,7 +64,9 @@ func getBasicGlobalSettingsEnhancedConfig() string {
 		resource "vtm_global_settings" "global_settings" {
 			accepting_delay = 100
 			socket_optimizations = "yes"
-			appliance_return_path_routing_enabled = true
+			appliance {
+				return_path_routing_enabled = true
+			}
 		}`,
 	)
 }
@@ -12,6 +12,8 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// vtm_kerberos_keytab reads the principal, key version and encryption type
+// of each key in the keytab, and warns about content that is not a keytab.
 func resourceKerberosKeytab() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceKerberosKeytabRead,
@@ -20,6 +22,8 @@ func resourceKerberosKeytab() *schema.Resource {
 		Update: resourceKerberosKeytabUpdate,
 		Delete: resourceKerberosKeytabDelete,
 
+		CustomizeDiff: resourceKerberosKeytabCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -29,7 +33,7 @@ func resourceKerberosKeytab() *schema.Resource {
 }
 
 func getResourceKerberosKeytabSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	fields := addFileContentSchema(map[string]*schema.Schema{
 
 		"name": &schema.Schema{
 			Type:         schema.TypeString,
@@ -40,37 +44,136 @@ func getResourceKerberosKeytabSchema() map[string]*schema.Schema {
 
 		// Object text
 		"content": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateKeytab,
 		},
+
+		// The keys in the keytab
+		"entries": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+
+					// The principal the key is for, as "<service>@<realm>"
+					"principal": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+
+					// The key version number
+					"kvno": &schema.Schema{
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+
+					// The encryption type of the key
+					"enctype": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+
+		// The principals with keys in the keytab
+		"principals": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	})
+	fields["content_base64"].ValidateFunc = validateKeytabBase64
+	return fields
+}
+
+// setKeytabEntries sets the attributes read from the keys in a keytab.
+func setKeytabEntries(set func(string, interface{}) error, entries []keytabEntry) error {
+	entryList := []map[string]interface{}{}
+	for _, entry := range entries {
+		entryList = append(entryList, map[string]interface{}{
+			"principal": entry.principal,
+			"kvno":      entry.kvno,
+			"enctype":   entry.enctype,
+		})
+	}
+	if err := set("entries", entryList); err != nil {
+		return err
 	}
+	return set("principals", keytabPrincipals(entries))
 }
 
-func resourceKerberosKeytabRead(d *schema.ResourceData, tm interface{}) (readError error) {
+// resourceKerberosKeytabCustomizeDiff plans the keys held by new content,
+// and checks that they include a key for each principal on the traffic
+// manager that uses the keytab. Unchanged content_base64 is only a hash in
+// the state, so the keys read from the keytab when it was uploaded are
+// kept. Content that is not a keytab has been warned about by its
+// ValidateFunc, and is planned as having no keys.
+func resourceKerberosKeytabCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	if err := fileContentCustomizeDiff(d, tm); err != nil {
+		return err
+	}
 	objectName := d.Get("name").(string)
-	if objectName == "" {
-		objectName = d.Id()
-		d.Set("name", objectName)
+	content := d.Get("content").(string)
+	contentBase64 := d.Get("content_base64").(string)
+	source := d.Get("source").(string)
+	if !d.HasChange("source_hash") {
+		return nil
 	}
-	object, err := tm.(*vtm.VirtualTrafficManager).GetKerberosKeytab(objectName)
+	if contentBase64 != "" && !d.HasChange("content_base64") {
+		return nil
+	}
+	if content == "" && contentBase64 == "" && source == "" {
+		if err := d.SetNewComputed("entries"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("principals")
+	}
+	data, err := getFileContentBytes(content, contentBase64, source)
 	if err != nil {
-		if err.ErrorId == "resource.not_found" {
-			d.SetId("")
+		return err
+	}
+	entries, err := parseKeytab(data)
+	if err != nil {
+		entries = []keytabEntry{}
+	}
+	if err := setKeytabEntries(d.SetNew, entries); err != nil {
+		return err
+	}
+	return checkKerberosPrincipalsOnTm(tm, func(name string, principal *vtm.KerberosPrincipal) error {
+		service, realm, keytab, krb5conf := kerberosPrincipalFields(principal)
+		if keytab != objectName || service == "" {
 			return nil
 		}
-		return fmt.Errorf("Failed to read vtm_keytab '%v': %v", objectName, err.ErrorText)
-	}
-
-	var lastAssignedField string
-
-	defer func() {
-		r := recover()
-		if r != nil {
-			readError = fmt.Errorf("Field '%s' missing from vTM configuration", lastAssignedField)
+		conf, err := readKerberosKrb5Conf(tm, krb5conf)
+		if err != nil {
+			return err
 		}
-	}()
+		if err := checkKeytabEntriesForPrincipal(entries, objectName, service, realm, conf); err != nil {
+			return fmt.Errorf("vtm_kerberos_principal '%s': %v", name, err)
+		}
+		return nil
+	})
+}
 
-	d.Set("content", object)
+func resourceKerberosKeytabRead(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get("name").(string)
+	if objectName == "" {
+		objectName = d.Id()
+		d.Set("name", objectName)
+	}
+	data, readErr := readFileContent(d, tm, "kerberos/keytabs", objectName)
+	if readErr != nil {
+		return fmt.Errorf("Failed to read vtm_keytab '%v': %v", objectName, readErr)
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	// Keytabs uploaded before they were checked may not be valid, and are
+	// read as having no keys.
+	entries, _ := parseKeytab(data)
+	setKeytabEntries(d.Set, entries)
 	d.SetId(objectName)
 	return nil
 }
@@ -100,10 +203,8 @@ func resourceKerberosKeytabCreate(d *schema.ResourceData, tm interface{}) error
 
 func resourceKerberosKeytabUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
-	objectContent := d.Get("content").(string)
-	err := tm.(*vtm.VirtualTrafficManager).SetKerberosKeytab(objectName, objectContent)
-	if err != nil {
-		return fmt.Errorf("Failed to create vtm_keytab '%v': %v", objectName, err.ErrorText)
+	if err := writeFileContent(d, tm, "kerberos/keytabs", objectName); err != nil {
+		return fmt.Errorf("Failed to create vtm_keytab '%v': %v", objectName, err)
 	}
 	d.SetId(objectName)
 	return nil
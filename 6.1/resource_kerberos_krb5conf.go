@@ -12,6 +12,9 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// vtm_kerberos_krb5conf checks the krb5.conf file it uploads and reads its
+// realms from it. The file can also be built from "libdefaults", "realm"
+// and "domain_realm" instead of being given as "content".
 func resourceKerberosKrb5Conf() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceKerberosKrb5ConfRead,
@@ -24,6 +27,8 @@ func resourceKerberosKrb5Conf() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceKerberosKrb5ConfCustomizeDiff,
+
 		Schema: getResourceKerberosKrb5ConfSchema(),
 	}
 }
@@ -40,12 +45,227 @@ func getResourceKerberosKrb5ConfSchema() map[string]*schema.Schema {
 
 		// Object text
 		"content": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validateKrb5Conf,
+		},
+
+		// Settings for the [libdefaults] section, used to build the file
+		//  in place of "content"
+		"libdefaults": &schema.Schema{
+			Type:          schema.TypeMap,
+			Optional:      true,
+			ConflictsWith: []string{"content"},
+		},
+
+		// Realms for the [realms] section, used to build the file in place
+		//  of "content"
+		"realm": &schema.Schema{
+			Type:          schema.TypeList,
+			Optional:      true,
+			ConflictsWith: []string{"content"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+
+					// The name of the realm
+					"name": &schema.Schema{
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.NoZeroValues,
+					},
+
+					// The "<hostname/ip>:<port>" of each KDC for the realm
+					"kdcs": &schema.Schema{
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+
+					// The admin servers for the realm
+					"admin_servers": &schema.Schema{
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+
+					// The domain used to qualify host names in the realm
+					"default_domain": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+
+		// Domains and the realms they map to, for the [domain_realm]
+		//  section, used to build the file in place of "content"
+		"domain_realm": &schema.Schema{
+			Type:          schema.TypeMap,
+			Optional:      true,
+			ConflictsWith: []string{"content"},
+		},
+
+		// The default_realm from the [libdefaults] section
+		"default_realm": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Computed: true,
+		},
+
+		// The realms in the [realms] section
+		"realms": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+
+					// The name of the realm
+					"name": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+
+					// The KDCs for the realm
+					"kdcs": &schema.Schema{
+						Type:     schema.TypeList,
+						Computed: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+
+					// The admin servers for the realm
+					"admin_servers": &schema.Schema{
+						Type:     schema.TypeList,
+						Computed: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+
+					// The domain used to qualify host names in the realm
+					"default_domain": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+
+		// The domains in the [domain_realm] section and the realms they
+		//  map to
+		"domain_realms": &schema.Schema{
+			Type:     schema.TypeMap,
+			Computed: true,
 		},
 	}
 }
 
+// krb5ConfDetailFields are the attributes read from the file.
+var krb5ConfDetailFields = []string{"default_realm", "realms", "domain_realms"}
+
+// getKrb5ConfFromBlocks builds the file from "libdefaults", "realm" and
+// "domain_realm", returning false if none of them are set. get is the Get
+// method of the resource data or diff.
+func getKrb5ConfFromBlocks(get func(string) interface{}) (string, bool) {
+	libdefaults := map[string]string{}
+	for tag, value := range get("libdefaults").(map[string]interface{}) {
+		libdefaults[tag] = value.(string)
+	}
+	realms := []*krb5Realm{}
+	for _, item := range get("realm").([]interface{}) {
+		realm := item.(map[string]interface{})
+		realms = append(realms, &krb5Realm{
+			name:          realm["name"].(string),
+			kdcs:          expandStringList(realm["kdcs"].([]interface{})),
+			adminServers:  expandStringList(realm["admin_servers"].([]interface{})),
+			defaultDomain: realm["default_domain"].(string),
+		})
+	}
+	domainRealm := map[string]string{}
+	for domain, realm := range get("domain_realm").(map[string]interface{}) {
+		domainRealm[domain] = realm.(string)
+	}
+	if len(libdefaults) == 0 && len(realms) == 0 && len(domainRealm) == 0 {
+		return "", false
+	}
+	return generateKrb5Conf(libdefaults, realms, domainRealm), true
+}
+
+// setKrb5ConfDetails sets the attributes read from the file.
+func setKrb5ConfDetails(set func(string, interface{}) error, conf *krb5Conf) error {
+	realms := []map[string]interface{}{}
+	for _, realm := range conf.realms {
+		realms = append(realms, map[string]interface{}{
+			"name":           realm.name,
+			"kdcs":           realm.kdcs,
+			"admin_servers":  realm.adminServers,
+			"default_domain": realm.defaultDomain,
+		})
+	}
+	values := map[string]interface{}{
+		"default_realm": conf.libdefaults["default_realm"],
+		"realms":        realms,
+		"domain_realms": conf.domainRealm,
+	}
+	for _, field := range krb5ConfDetailFields {
+		if err := set(field, values[field]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceKerberosKrb5ConfCustomizeDiff builds the file from its sections
+// if they are set, and plans the realms read from the file when it changes,
+// checking the principals on the traffic manager that take their default
+// realm from it. Values not known until
+// apply read as empty, so the file is built again when it is uploaded.
+// A file built from its sections must be valid, but "content" that is not
+// has only been warned about by its ValidateFunc, and is planned as having
+// no realms.
+func resourceKerberosKrb5ConfCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	content, fromBlocks := getKrb5ConfFromBlocks(d.Get)
+	if fromBlocks && content != d.Get("content").(string) {
+		if err := d.SetNew("content", content); err != nil {
+			return err
+		}
+	}
+	if !d.HasChange("content") {
+		return nil
+	}
+	objectName := d.Get("name").(string)
+	content = d.Get("content").(string)
+	if content == "" {
+		for _, field := range krb5ConfDetailFields {
+			if err := d.SetNewComputed(field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	conf, err := parseKrb5Conf(content)
+	if err != nil {
+		if fromBlocks {
+			return fmt.Errorf("vtm_kerberos_krb5conf '%s' is not valid: %v", objectName, err)
+		}
+		conf, _ = parseKrb5Conf("")
+	}
+	if err := setKrb5ConfDetails(d.SetNew, conf); err != nil {
+		return err
+	}
+	return checkKerberosPrincipalsOnTm(tm, func(name string, principal *vtm.KerberosPrincipal) error {
+		service, realm, keytab, krb5conf := kerberosPrincipalFields(principal)
+		if krb5conf != objectName || realm != "" || service == "" || keytab == "" {
+			return nil
+		}
+		entries, err := readKerberosKeytabEntries(tm, keytab)
+		if err != nil || entries == nil {
+			return err
+		}
+		if err := checkKeytabEntriesForPrincipal(entries, keytab, service, realm, conf); err != nil {
+			return fmt.Errorf("vtm_kerberos_principal '%s': %v", name, err)
+		}
+		return nil
+	})
+}
+
 func resourceKerberosKrb5ConfRead(d *schema.ResourceData, tm interface{}) (readError error) {
 	objectName := d.Get("name").(string)
 	if objectName == "" {
@@ -71,6 +291,13 @@ func resourceKerberosKrb5ConfRead(d *schema.ResourceData, tm interface{}) (readE
 	}()
 
 	d.Set("content", object)
+	// Files uploaded before they were checked may not be valid, and are
+	// read as having no realms.
+	conf, parseErr := parseKrb5Conf(object)
+	if parseErr != nil {
+		conf, _ = parseKrb5Conf("")
+	}
+	setKrb5ConfDetails(d.Set, conf)
 	d.SetId(objectName)
 	return nil
 }
@@ -101,6 +328,13 @@ func resourceKerberosKrb5ConfCreate(d *schema.ResourceData, tm interface{}) erro
 func resourceKerberosKrb5ConfUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	objectContent := d.Get("content").(string)
+	if content, ok := getKrb5ConfFromBlocks(d.Get); ok {
+		objectContent = content
+		d.Set("content", content)
+	}
+	if objectContent == "" {
+		return fmt.Errorf("Failed to create vtm_krb5conf '%v': content or one of libdefaults, realm and domain_realm must be set", objectName)
+	}
 	err := tm.(*vtm.VirtualTrafficManager).SetKerberosKrb5Conf(objectName, objectContent)
 	if err != nil {
 		return fmt.Errorf("Failed to create vtm_krb5conf '%v': %v", objectName, err.ErrorText)
@@ -11,6 +11,8 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// vtm_kerberos_principal checks when planning that its keytab has a key for
+// the principal.
 func resourceKerberosPrincipal() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceKerberosPrincipalRead,
@@ -23,6 +25,8 @@ func resourceKerberosPrincipal() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceKerberosPrincipalCustomizeDiff,
+
 		Schema: getResourceKerberosPrincipalSchema(),
 	}
 }
@@ -75,6 +79,134 @@ func getResourceKerberosPrincipalSchema() map[string]*schema.Schema {
 	}
 }
 
+// The Kerberos checks compare the planned content of one resource with the
+// other Kerberos objects as they are on the traffic manager. Terraform plans
+// resources in parallel, so the planned content of other resources cannot be
+// relied on; a keytab and the principals that use it that change in the
+// same apply are each checked against the other's current configuration.
+
+// checkKeytabEntriesForPrincipal checks that entries, the keys of a keytab,
+// include one for the principal of a service. Without a realm, the default
+// realm of conf is used, or any realm if conf is nil.
+func checkKeytabEntriesForPrincipal(entries []keytabEntry, keytab, service, realm string, conf *krb5Conf) error {
+	if realm == "" && conf != nil {
+		realm = conf.libdefaults["default_realm"]
+	}
+	principal := kerberosPrincipalName(service, realm)
+	if !keytabHasPrincipal(entries, principal) {
+		return fmt.Errorf("vtm_kerberos_keytab '%s' has no key for '%s', it has keys for %v", keytab, principal, keytabPrincipals(entries))
+	}
+	return nil
+}
+
+// readKerberosKeytabEntries returns the keys of a keytab on the traffic
+// manager, or nil if it is not there or is not a valid keytab, which its
+// resource warns about.
+func readKerberosKeytabEntries(tm interface{}, keytab string) ([]keytabEntry, error) {
+	client, err := getRestClient(tm)
+	if err != nil {
+		return nil, err
+	}
+	data, readErr := client.getFile(configPath("kerberos/keytabs", keytab))
+	if readErr != nil {
+		if readErr.ErrorId == "resource.not_found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to read vtm_keytab '%v': %v", keytab, readErr.ErrorText)
+	}
+	entries, err := parseKeytab(data)
+	if err != nil {
+		return nil, nil
+	}
+	return entries, nil
+}
+
+// readKerberosKrb5Conf returns a krb5.conf file on the traffic manager, or
+// nil if there is none, it is not there or it is not valid.
+func readKerberosKrb5Conf(tm interface{}, krb5conf string) (*krb5Conf, error) {
+	if krb5conf == "" {
+		return nil, nil
+	}
+	content, err := tm.(*vtm.VirtualTrafficManager).GetKerberosKrb5Conf(krb5conf)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to read vtm_krb5conf '%v': %v", krb5conf, err.ErrorText)
+	}
+	conf, parseErr := parseKrb5Conf(content)
+	if parseErr != nil {
+		return nil, nil
+	}
+	return conf, nil
+}
+
+// checkKerberosPrincipalKey checks that a keytab on the traffic manager has
+// a key for the principal of a service, with the default realm of the
+// krb5.conf file on the traffic manager. Keytabs that are not on the
+// traffic manager, or are not valid, are not checked.
+func checkKerberosPrincipalKey(tm interface{}, service, realm, keytab, krb5conf string) error {
+	if service == "" || keytab == "" {
+		return nil
+	}
+	entries, err := readKerberosKeytabEntries(tm, keytab)
+	if err != nil || entries == nil {
+		return err
+	}
+	conf, err := readKerberosKrb5Conf(tm, krb5conf)
+	if err != nil {
+		return err
+	}
+	return checkKeytabEntriesForPrincipal(entries, keytab, service, realm, conf)
+}
+
+// checkKerberosPrincipalsOnTm calls check with each principal on the
+// traffic manager, and returns the first error.
+func checkKerberosPrincipalsOnTm(tm interface{}, check func(name string, principal *vtm.KerberosPrincipal) error) error {
+	names, err := tm.(*vtm.VirtualTrafficManager).ListKerberosPrincipals()
+	if err != nil {
+		return fmt.Errorf("Failed to read vtm_kerberos_principal_list: %v", err.ErrorText)
+	}
+	for _, name := range *names {
+		principal, err := tm.(*vtm.VirtualTrafficManager).GetKerberosPrincipal(name)
+		if err != nil {
+			if err.ErrorId == "resource.not_found" {
+				continue
+			}
+			return fmt.Errorf("Failed to read vtm_principal '%v': %v", name, err.ErrorText)
+		}
+		if checkErr := check(name, principal); checkErr != nil {
+			return checkErr
+		}
+	}
+	return nil
+}
+
+// kerberosPrincipalFields returns the service, realm, keytab and krb5.conf
+// of a principal on the traffic manager.
+func kerberosPrincipalFields(object *vtm.KerberosPrincipal) (string, string, string, string) {
+	value := func(field *string) string {
+		if field == nil {
+			return ""
+		}
+		return *field
+	}
+	return value(object.Basic.Service), value(object.Basic.Realm), value(object.Basic.Keytab), value(object.Basic.Krb5Conf)
+}
+
+// resourceKerberosPrincipalCustomizeDiff checks that the keytab of a new or
+// changed principal has a key for it.
+func resourceKerberosPrincipalCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	if d.Id() != "" && !d.HasChange("service") && !d.HasChange("realm") && !d.HasChange("keytab") && !d.HasChange("krb5conf") {
+		return nil
+	}
+	err := checkKerberosPrincipalKey(tm, d.Get("service").(string), d.Get("realm").(string), d.Get("keytab").(string), d.Get("krb5conf").(string))
+	if err != nil {
+		return fmt.Errorf("vtm_kerberos_principal '%s': %v", d.Get("name").(string), err)
+	}
+	return nil
+}
+
 func resourceKerberosPrincipalRead(d *schema.ResourceData, tm interface{}) (readError error) {
 	objectName := d.Get("name").(string)
 	if objectName == "" {
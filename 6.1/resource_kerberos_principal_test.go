@@ -10,6 +10,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -80,3 +81,26 @@ func getBasicKerberosPrincipalConfig(name string) string {
 		name,
 	)
 }
+
+func TestCheckKeytabEntriesForPrincipal(t *testing.T) {
+	entries, _ := parseKeytab(testKeytab("HTTP/www.example.com@EXAMPLE.COM"))
+	conf, _ := parseKrb5Conf(testKrb5Conf)
+	if err := checkKeytabEntriesForPrincipal(entries, "web", "HTTP/www.example.com", "", conf); err != nil {
+		t.Errorf("Checking a principal in the keytab failed: %v", err)
+	}
+	err := checkKeytabEntriesForPrincipal(entries, "web", "HTTP/other.example.com", "", conf)
+	if err == nil || !strings.Contains(err.Error(), "has no key for 'HTTP/other.example.com@EXAMPLE.COM'") {
+		t.Errorf("Checking a principal missing from the keytab gave %v", err)
+	}
+	if err := checkKeytabEntriesForPrincipal(entries, "web", "HTTP/www.example.com", "CORP.EXAMPLE.COM", conf); err == nil {
+		t.Errorf("Checking a principal in the wrong realm succeeded")
+	}
+
+	// Without a krb5.conf file, a principal without a realm matches any realm
+	if err := checkKeytabEntriesForPrincipal(entries, "web", "HTTP/www.example.com", "", nil); err != nil {
+		t.Errorf("Checking a principal without a krb5.conf failed: %v", err)
+	}
+	if err := checkKeytabEntriesForPrincipal([]keytabEntry{}, "web", "HTTP/www.example.com", "", nil); err == nil {
+		t.Errorf("Checking a principal in a keytab without keys succeeded")
+	}
+}
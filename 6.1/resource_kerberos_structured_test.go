@@ -0,0 +1,136 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - A vtm_kerberos_krb5conf built from libdefaults, realm and domain_realm,
+ *     with the realms read back from the generated file
+ *   - The keys of a vtm_kerberos_keytab are read into entries and principals
+ *   - A vtm_kerberos_principal whose key is in its keytab is created, with
+ *     its realm taken from the krb5.conf file
+ *   - A vtm_kerberos_principal whose key is not in its keytab fails to plan
+ *   - A vtm_kerberos_principal and the keytab with its key changed in the
+ *     same apply are each checked against the other on the traffic manager,
+ *     so the keytab has to gain the new key before the principal moves to it
+ */
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestResourceKerberosStructured(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestKerberosStructured")
+	keytab := base64.StdEncoding.EncodeToString(testKeytab("HTTP/www.example.com@EXAMPLE.COM", "vtm@CORP.EXAMPLE.COM"))
+	otherKeytab := base64.StdEncoding.EncodeToString(testKeytab("HTTP/other.example.com@EXAMPLE.COM"))
+	bothKeytab := base64.StdEncoding.EncodeToString(testKeytab("HTTP/www.example.com@EXAMPLE.COM", "HTTP/other.example.com@EXAMPLE.COM"))
+	otherPrincipal := `
+        resource "vtm_kerberos_principal" "structured" {
+			name = "%[1]s"
+			service = "HTTP/other.example.com"
+			keytab = "${vtm_kerberos_keytab.structured.name}"
+			krb5conf = "${vtm_kerberos_krb5conf.structured.name}"
+		}`
+	wwwPrincipal := `
+        resource "vtm_kerberos_principal" "structured" {
+			name = "%[1]s"
+			service = "HTTP/www.example.com"
+			keytab = "${vtm_kerberos_keytab.structured.name}"
+			krb5conf = "${vtm_kerberos_krb5conf.structured.name}"
+		}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKerberosPrincipalDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getKerberosStructuredConfig(objName, keytab, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_kerberos_krb5conf.structured", "default_realm", "EXAMPLE.COM"),
+					resource.TestCheckResourceAttr("vtm_kerberos_krb5conf.structured", "realms.#", "2"),
+					resource.TestCheckResourceAttr("vtm_kerberos_krb5conf.structured", "realms.0.kdcs.1", "kdc2.example.com:88"),
+					resource.TestCheckResourceAttr("vtm_kerberos_krb5conf.structured", "domain_realms..corp.example.com", "CORP.EXAMPLE.COM"),
+					resource.TestCheckResourceAttr("vtm_kerberos_keytab.structured", "entries.#", "2"),
+					resource.TestCheckResourceAttr("vtm_kerberos_keytab.structured", "entries.1.kvno", "300"),
+					resource.TestCheckResourceAttr("vtm_kerberos_keytab.structured", "entries.1.enctype", "aes256-cts-hmac-sha1-96"),
+					resource.TestCheckResourceAttr("vtm_kerberos_keytab.structured", "principals.0", "HTTP/www.example.com@EXAMPLE.COM"),
+				),
+			},
+			{
+				Config: getKerberosStructuredConfig(objName, keytab, wwwPrincipal),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKerberosPrincipalExists,
+				),
+			},
+			{
+				Config:      getKerberosStructuredConfig(objName, keytab, otherPrincipal),
+				ExpectError: regexp.MustCompile("has no key for 'HTTP/other.example.com@EXAMPLE.COM'"),
+			},
+			{
+				// The keytab no longer has a key for the principal on the
+				// traffic manager
+				Config:      getKerberosStructuredConfig(objName, otherKeytab, otherPrincipal),
+				ExpectError: regexp.MustCompile("has no key for 'HTTP/www.example.com@EXAMPLE.COM'"),
+			},
+			{
+				Config: getKerberosStructuredConfig(objName, bothKeytab, wwwPrincipal),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKerberosPrincipalExists,
+				),
+			},
+			{
+				Config: getKerberosStructuredConfig(objName, bothKeytab, otherPrincipal),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKerberosPrincipalExists,
+				),
+			},
+			{
+				Config: getKerberosStructuredConfig(objName, otherKeytab, otherPrincipal),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKerberosPrincipalExists,
+					resource.TestCheckResourceAttr("vtm_kerberos_keytab.structured", "principals.0", "HTTP/other.example.com@EXAMPLE.COM"),
+				),
+			},
+		},
+	})
+}
+
+func getKerberosStructuredConfig(name, keytab, principal string) string {
+	return fmt.Sprintf(`
+        resource "vtm_kerberos_krb5conf" "structured" {
+			name = "%[1]s"
+			libdefaults = {
+				default_realm = "EXAMPLE.COM"
+				dns_lookup_kdc = "false"
+			}
+			realm {
+				name = "EXAMPLE.COM"
+				kdcs = ["kdc1.example.com:88", "kdc2.example.com:88"]
+				admin_servers = ["kdc1.example.com"]
+			}
+			realm {
+				name = "CORP.EXAMPLE.COM"
+				kdcs = ["dc.corp.example.com"]
+			}
+			domain_realm = {
+				".example.com" = "EXAMPLE.COM"
+				".corp.example.com" = "CORP.EXAMPLE.COM"
+			}
+		}
+
+        resource "vtm_kerberos_keytab" "structured" {
+			name = "%[1]s"
+			content_base64 = "%[2]s"
+		}
+		`+principal,
+		name, keytab,
+	)
+}
@@ -5,13 +5,20 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// vtm_license_key also reports the details read from the key, whether it is
+// the license in use, and which licensed features are configured without a
+// license that enables them.
 func resourceLicenseKey() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceLicenseKeyRead,
@@ -24,6 +31,8 @@ func resourceLicenseKey() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceLicenseKeyCustomizeDiff,
+
 		Schema: getResourceLicenseKeySchema(),
 	}
 }
@@ -43,6 +52,247 @@ func getResourceLicenseKeySchema() map[string]*schema.Schema {
 			Type:     schema.TypeString,
 			Required: true,
 		},
+
+		// The serial number of the license
+		"serial": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+
+		// The date the license expires, as "YYYY-MM-DD", or "" if it does
+		//  not expire
+		"expiry_date": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+
+		// Whether the license has expired
+		"expired": &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+
+		// The bandwidth limit of the license in Mbit/s, or 0 if there is
+		//  no limit
+		"bandwidth_limit": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+
+		// The features the license enables
+		"features": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// All the fields of the license, by name
+		"fields": &schema.Schema{
+			Type:     schema.TypeMap,
+			Computed: true,
+		},
+
+		// Whether this is the license the traffic manager is using
+		"is_active": &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+
+		// The licensed features that are configured but not enabled by
+		//  any of the license keys on the traffic manager
+		"unlicensed_features": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// setLicenseKeyDetails sets the attributes read from a license key.
+func setLicenseKeyDetails(set func(string, interface{}) error, key *licenseKey, now time.Time) error {
+	values := map[string]interface{}{
+		"serial":          key.serial,
+		"expiry_date":     key.expiryDate(),
+		"expired":         key.expired(now),
+		"bandwidth_limit": key.bandwidth,
+		"features":        key.features,
+		"fields":          key.fields,
+	}
+	for _, field := range sortedMapKeys(values) {
+		if err := set(field, values[field]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The license keys on each traffic manager are read the first time they
+// are needed, and then kept up to date as vtm_license_key objects change,
+// rather than read again for every resource that needs a licensed feature.
+var (
+	licenseKeysMutex sync.Mutex
+	licenseKeys      = map[*vtm.VirtualTrafficManager]map[string]*licenseKey{}
+)
+
+// readLicenseKeys returns a copy of the license keys on the traffic manager
+// by name.
+func readLicenseKeys(tm interface{}) (map[string]*licenseKey, error) {
+	licenseKeysMutex.Lock()
+	defer licenseKeysMutex.Unlock()
+	keys, ok := licenseKeys[tm.(*vtm.VirtualTrafficManager)]
+	if !ok {
+		objectList, err := tm.(*vtm.VirtualTrafficManager).ListLicenseKeys()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read vtm_license_key_list: %v", err.ErrorText)
+		}
+		keys = map[string]*licenseKey{}
+		for _, name := range *objectList {
+			content, err := tm.(*vtm.VirtualTrafficManager).GetLicenseKey(name)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read vtm_license_key '%v': %v", name, err.ErrorText)
+			}
+			keys[name] = parseLicenseKey(content)
+		}
+		licenseKeys[tm.(*vtm.VirtualTrafficManager)] = keys
+	}
+	copied := make(map[string]*licenseKey, len(keys))
+	for name, key := range keys {
+		copied[name] = key
+	}
+	return copied, nil
+}
+
+// cacheLicenseKey records a license key read from or written to the
+// traffic manager, or one that has been deleted if key is nil.
+func cacheLicenseKey(tm interface{}, name string, key *licenseKey) {
+	licenseKeysMutex.Lock()
+	defer licenseKeysMutex.Unlock()
+	keys, ok := licenseKeys[tm.(*vtm.VirtualTrafficManager)]
+	if !ok {
+		return
+	}
+	if key == nil {
+		delete(keys, name)
+	} else {
+		keys[name] = key
+	}
+}
+
+// licensedResourceTypesInUse returns the types of resource that need a
+// licensed feature and have at least one object on the traffic manager.
+func licensedResourceTypesInUse(tm interface{}) ([]string, error) {
+	inUse := []string{}
+	for _, exportable := range exportableResources {
+		if _, ok := licensedFeatureResources[exportable.resourceType]; !ok || exportable.list == nil {
+			continue
+		}
+		objectList, err := exportable.list(tm.(*vtm.VirtualTrafficManager))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list %s objects: %v", exportable.resourceType, err.ErrorText)
+		}
+		if len(*objectList) > 0 {
+			inUse = append(inUse, exportable.resourceType)
+		}
+	}
+	return inUse, nil
+}
+
+// findUnlicensedFeatures returns the licensed features in use that none of
+// the keys enable.
+func findUnlicensedFeatures(tm interface{}, keys map[string]*licenseKey, now time.Time) ([]string, error) {
+	inUse, err := licensedResourceTypesInUse(tm)
+	if err != nil {
+		return nil, err
+	}
+	keyList := []*licenseKey{}
+	for _, key := range keys {
+		keyList = append(keyList, key)
+	}
+	return unlicensedFeatures(keyList, inUse, now), nil
+}
+
+// resourceLicenseKeyCustomizeDiff plans the details of changed license
+// content, and plans unlicensed_features for the license keys with this one
+// changed.
+func resourceLicenseKeyCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	if !d.HasChange("content") {
+		return nil
+	}
+	content := d.Get("content").(string)
+	if content == "" {
+		for _, field := range []string{"serial", "expiry_date", "expired", "bandwidth_limit", "features", "fields", "unlicensed_features"} {
+			if err := d.SetNewComputed(field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	now := time.Now()
+	key := parseLicenseKey(content)
+	if err := setLicenseKeyDetails(d.SetNew, key, now); err != nil {
+		return err
+	}
+
+	keys, err := readLicenseKeys(tm)
+	if err != nil {
+		return err
+	}
+	keys[d.Get("name").(string)] = key
+	features, err := findUnlicensedFeatures(tm, keys, now)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(features, expandStringList(d.Get("unlicensed_features").([]interface{}))) {
+		return d.SetNew("unlicensed_features", features)
+	}
+	return nil
+}
+
+// addLicensedFeatureSchema adds "license_warning" to the schema of a
+// resource type that needs a licensed feature.
+func addLicensedFeatureSchema(fields map[string]*schema.Schema) map[string]*schema.Schema {
+	// Why the feature this object uses may not work: none of the license
+	//  keys on the traffic manager enable it. Empty if one does.
+	fields["license_warning"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	return fields
+}
+
+// licenseWarning returns the license_warning of an object of a resource
+// type that needs a licensed feature. Failures to read the license keys are
+// only logged, as they must not stop a plan or a refresh.
+func licenseWarning(tm interface{}, resourceType string) string {
+	keys, err := readLicenseKeys(tm)
+	if err != nil {
+		log.Printf("[DEBUG] Unable to check the license for %s: %v", resourceType, err)
+		return ""
+	}
+	keyList := []*licenseKey{}
+	for _, key := range keys {
+		keyList = append(keyList, key)
+	}
+	if features := unlicensedFeatures(keyList, []string{resourceType}, time.Now()); len(features) > 0 {
+		return fmt.Sprintf("%s is not enabled by any license key on the traffic manager", licensedFeatureNames[features[0]])
+	}
+	return ""
+}
+
+// licensedFeatureCustomizeDiff returns a CustomizeDiff function for a
+// resource type that needs a licensed feature, which plans license_warning
+// for a new object so that a missing license shows in the plan that adds
+// it. The warning of an existing object is only refreshed by Read, so a
+// change of license never plans an update of the objects that use it.
+func licensedFeatureCustomizeDiff(resourceType string) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, tm interface{}) error {
+		if d.Id() != "" {
+			return nil
+		}
+		if warning := licenseWarning(tm, resourceType); warning != "" {
+			return d.SetNew("license_warning", warning)
+		}
+		return nil
 	}
 }
 
@@ -71,6 +321,29 @@ func resourceLicenseKeyRead(d *schema.ResourceData, tm interface{}) (readError e
 	}()
 
 	d.Set("content", object)
+
+	now := time.Now()
+	keys, keysErr := readLicenseKeys(tm)
+	if keysErr != nil {
+		return keysErr
+	}
+	keys[objectName] = parseLicenseKey(object)
+	cacheLicenseKey(tm, objectName, keys[objectName])
+	if err := setLicenseKeyDetails(d.Set, keys[objectName], now); err != nil {
+		return err
+	}
+	features, featuresErr := findUnlicensedFeatures(tm, keys, now)
+	if featuresErr != nil {
+		return featuresErr
+	}
+	d.Set("unlicensed_features", features)
+
+	state, stateErr := tm.(*vtm.VirtualTrafficManager).GetSystemState()
+	if stateErr != nil {
+		return fmt.Errorf("Failed to read vtm_state: %v", stateErr.ErrorText)
+	}
+	d.Set("is_active", state.State.License != nil && string(*state.State.License) == objectName)
+
 	d.SetId(objectName)
 	return nil
 }
@@ -105,8 +378,9 @@ func resourceLicenseKeyUpdate(d *schema.ResourceData, tm interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed to create vtm_license_key '%v': %v", objectName, err.ErrorText)
 	}
+	cacheLicenseKey(tm, objectName, parseLicenseKey(objectContent))
 	d.SetId(objectName)
-	return nil
+	return resourceLicenseKeyRead(d, tm)
 }
 
 func resourceLicenseKeyDelete(d *schema.ResourceData, tm interface{}) error {
@@ -115,6 +389,7 @@ func resourceLicenseKeyDelete(d *schema.ResourceData, tm interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed to delete vtm_license_key '%v': %v", objectName, err.ErrorText)
 	}
+	cacheLicenseKey(tm, objectName, nil)
 	d.SetId("")
 	return nil
 }
@@ -6,12 +6,15 @@ package main
 /*
  * This test covers the following cases:
  *   - Creation and deletion of a vtm_license_key object with minimal configuration
+ *   - Planning license_warning from the cached license keys
+ *   - Planning license_warning only for new objects
  */
 
 import (
 	"fmt"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
@@ -76,3 +79,72 @@ func getBasicLicenseKeyConfig(name string) string {
 		name,
 	)
 }
+
+func TestLicenseKeyCache(t *testing.T) {
+	// The cached keys are used without contacting the traffic manager
+	tm := &vtm.VirtualTrafficManager{}
+	licenseKeys[tm] = map[string]*licenseKey{"main": parseLicenseKey("features: glb")}
+	defer delete(licenseKeys, tm)
+
+	keys, err := readLicenseKeys(tm)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("Reading cached license keys gave %v, %v", keys, err)
+	}
+	keys["other"] = parseLicenseKey("features: all")
+	if warning := licenseWarning(tm, "vtm_protection"); warning != "Service Protection is not enabled by any license key on the traffic manager" {
+		t.Errorf("License warning for vtm_protection was %q", warning)
+	}
+
+	cacheLicenseKey(tm, "spare", parseLicenseKey("features: protection"))
+	if warning := licenseWarning(tm, "vtm_protection"); warning != "" {
+		t.Errorf("License warning for vtm_protection was %q after adding a key", warning)
+	}
+	if warning := licenseWarning(tm, "vtm_glb_service"); warning != "" {
+		t.Errorf("License warning for vtm_glb_service was %q", warning)
+	}
+	cacheLicenseKey(tm, "main", nil)
+	if warning := licenseWarning(tm, "vtm_glb_service"); warning == "" {
+		t.Errorf("No license warning for vtm_glb_service after deleting its key")
+	}
+}
+
+func TestLicensedFeatureDiff(t *testing.T) {
+	tm := &vtm.VirtualTrafficManager{}
+	licenseKeys[tm] = map[string]*licenseKey{"main": parseLicenseKey("features: glb")}
+	defer delete(licenseKeys, tm)
+
+	raw, err := config.NewRawConfig(map[string]interface{}{"name": "gold", "maximum": 100})
+	if err != nil {
+		t.Fatalf("Failed to make config: %v", err)
+	}
+	resourceConfig := terraform.NewResourceConfig(raw)
+
+	// A new object plans the warning with the rest of its creation
+	diff, err := resourceBandwidth().Diff(nil, resourceConfig, tm)
+	if err != nil {
+		t.Fatalf("Failed to plan new vtm_bandwidth: %v", err)
+	}
+	if attr, ok := diff.Attributes["license_warning"]; !ok || attr.New != "Bandwidth Management is not enabled by any license key on the traffic manager" {
+		t.Errorf("New vtm_bandwidth planned license_warning %#v", attr)
+	}
+
+	// An existing object is not updated because the license changed
+	state := &terraform.InstanceState{
+		ID: "gold",
+		Attributes: map[string]string{
+			"id":              "gold",
+			"name":            "gold",
+			"maximum":         "100",
+			"note":            "",
+			"sharing":         "cluster",
+			"license_warning": "",
+		},
+	}
+	diff, err = resourceBandwidth().Diff(state, resourceConfig, tm)
+	if err != nil {
+		t.Fatalf("Failed to plan existing vtm_bandwidth: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("Existing vtm_bandwidth planned %#v", diff.Attributes)
+	}
+}
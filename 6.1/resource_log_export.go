@@ -101,9 +101,10 @@ func getResourceLogExportSchema() map[string]*schema.Schema {
 
 		// JSON representation of metadata
 		"metadata_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceLogExportSchema, "metadata"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceLogExportSchema, "metadata"),
 		},
 
 		// A description of this category of log files.
@@ -160,9 +161,12 @@ func resourceLogExportRead(d *schema.ResourceData, tm interface{}) (readError er
 		}
 		metadata = append(metadata, itemTerraform)
 	}
-	d.Set("metadata", metadata)
-	metadataJson, _ := json.Marshal(metadata)
-	d.Set("metadata_json", metadataJson)
+	if _, ok := d.GetOk("metadata_json"); ok {
+		metadataJson, _ := json.Marshal(metadata)
+		d.Set("metadata_json", string(metadataJson))
+	} else {
+		d.Set("metadata", metadata)
+	}
 	lastAssignedField = "note"
 	d.Set("note", string(*object.Basic.Note))
 	d.SetId(objectName)
@@ -187,7 +191,9 @@ func resourceLogExportExists(d *schema.ResourceData, tm interface{}) (bool, erro
 func resourceLogExportCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	object := tm.(*vtm.VirtualTrafficManager).NewLogExport(objectName)
-	resourceLogExportObjectFieldAssignments(d, object)
+	if err := resourceLogExportObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_log_export '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -203,7 +209,9 @@ func resourceLogExportUpdate(d *schema.ResourceData, tm interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_log_export '%v': %v", objectName, err)
 	}
-	resourceLogExportObjectFieldAssignments(d, object)
+	if err := resourceLogExportObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_log_export '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -213,7 +221,7 @@ func resourceLogExportUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceLogExportObjectFieldAssignments(d *schema.ResourceData, object *vtm.LogExport) {
+func resourceLogExportObjectFieldAssignments(d *schema.ResourceData, object *vtm.LogExport) error {
 	setBool(&object.Basic.ApplianceOnly, d, "appliance_only")
 	setBool(&object.Basic.Enabled, d, "enabled")
 
@@ -229,7 +237,9 @@ func resourceLogExportObjectFieldAssignments(d *schema.ResourceData, object *vtm
 
 	object.Basic.Metadata = &vtm.LogExportMetadataTable{}
 	if metadataJson, ok := d.GetOk("metadata_json"); ok {
-		_ = json.Unmarshal([]byte(metadataJson.(string)), object.Basic.Metadata)
+		if err := json.Unmarshal([]byte(metadataJson.(string)), object.Basic.Metadata); err != nil {
+			return fmt.Errorf("Invalid metadata_json: %v", err)
+		}
 	} else if metadata, ok := d.GetOk("metadata"); ok {
 		for _, row := range metadata.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -242,6 +252,7 @@ func resourceLogExportObjectFieldAssignments(d *schema.ResourceData, object *vtm
 	} else {
 		d.Set("metadata", make([]map[string]interface{}, 0, len(*object.Basic.Metadata)))
 	}
+	return nil
 }
 
 func resourceLogExportDelete(d *schema.ResourceData, tm interface{}) error {
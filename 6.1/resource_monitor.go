@@ -404,7 +404,9 @@ func resourceMonitorExists(d *schema.ResourceData, tm interface{}) (bool, error)
 func resourceMonitorCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	object := tm.(*vtm.VirtualTrafficManager).NewMonitor(objectName)
-	resourceMonitorObjectFieldAssignments(d, object)
+	if err := resourceMonitorObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_monitor '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -420,7 +422,9 @@ func resourceMonitorUpdate(d *schema.ResourceData, tm interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_monitor '%v': %v", objectName, err)
 	}
-	resourceMonitorObjectFieldAssignments(d, object)
+	if err := resourceMonitorObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_monitor '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -430,7 +434,7 @@ func resourceMonitorUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceMonitorObjectFieldAssignments(d *schema.ResourceData, object *vtm.Monitor) {
+func resourceMonitorObjectFieldAssignments(d *schema.ResourceData, object *vtm.Monitor) error {
 	setBool(&object.Basic.BackOff, d, "back_off")
 	setInt(&object.Basic.Delay, d, "delay")
 	setInt(&object.Basic.Failures, d, "failures")
@@ -453,7 +457,9 @@ func resourceMonitorObjectFieldAssignments(d *schema.ResourceData, object *vtm.M
 
 	object.Script.Arguments = &vtm.MonitorArgumentsTable{}
 	if scriptArgumentsJson, ok := d.GetOk("script_arguments_json"); ok {
-		_ = json.Unmarshal([]byte(scriptArgumentsJson.(string)), object.Script.Arguments)
+		if err := json.Unmarshal([]byte(scriptArgumentsJson.(string)), object.Script.Arguments); err != nil {
+			return fmt.Errorf("Invalid script_arguments_json: %v", err)
+		}
 	} else if scriptArguments, ok := d.GetOk("script_arguments"); ok {
 		for _, row := range scriptArguments.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -476,6 +482,7 @@ func resourceMonitorObjectFieldAssignments(d *schema.ResourceData, object *vtm.M
 	setString(&object.Tcp.ResponseRegex, d, "tcp_response_regex")
 	setString(&object.Tcp.WriteString, d, "tcp_write_string")
 	setBool(&object.Udp.AcceptAll, d, "udp_accept_all")
+	return nil
 }
 
 func resourceMonitorDelete(d *schema.ResourceData, tm interface{}) error {
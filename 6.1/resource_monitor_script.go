@@ -20,6 +20,8 @@ func resourceMonitorScript() *schema.Resource {
 		Update: resourceMonitorScriptUpdate,
 		Delete: resourceMonitorScriptDelete,
 
+		CustomizeDiff: fileContentCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -29,7 +31,7 @@ func resourceMonitorScript() *schema.Resource {
 }
 
 func getResourceMonitorScriptSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	return addFileContentSchema(map[string]*schema.Schema{
 
 		"name": &schema.Schema{
 			Type:         schema.TypeString,
@@ -41,36 +43,23 @@ func getResourceMonitorScriptSchema() map[string]*schema.Schema {
 		// Object text
 		"content": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
 		},
-	}
+	})
 }
 
-func resourceMonitorScriptRead(d *schema.ResourceData, tm interface{}) (readError error) {
+func resourceMonitorScriptRead(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	if objectName == "" {
 		objectName = d.Id()
 		d.Set("name", objectName)
 	}
-	object, err := tm.(*vtm.VirtualTrafficManager).GetMonitorScript(objectName)
-	if err != nil {
-		if err.ErrorId == "resource.not_found" {
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("Failed to read vtm_monitor_script '%v': %v", objectName, err.ErrorText)
+	if _, err := readFileContent(d, tm, "monitor_scripts", objectName); err != nil {
+		return fmt.Errorf("Failed to read vtm_monitor_script '%v': %v", objectName, err)
+	}
+	if d.Id() == "" {
+		return nil
 	}
-
-	var lastAssignedField string
-
-	defer func() {
-		r := recover()
-		if r != nil {
-			readError = fmt.Errorf("Field '%s' missing from vTM configuration", lastAssignedField)
-		}
-	}()
-
-	d.Set("content", object)
 	d.SetId(objectName)
 	return nil
 }
@@ -100,10 +89,8 @@ func resourceMonitorScriptCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceMonitorScriptUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
-	objectContent := d.Get("content").(string)
-	err := tm.(*vtm.VirtualTrafficManager).SetMonitorScript(objectName, objectContent)
-	if err != nil {
-		return fmt.Errorf("Failed to create vtm_monitor_script '%v': %v", objectName, err.ErrorText)
+	if err := writeFileContent(d, tm, "monitor_scripts", objectName); err != nil {
+		return fmt.Errorf("Failed to create vtm_monitor_script '%v': %v", objectName, err)
 	}
 	d.SetId(objectName)
 	return nil
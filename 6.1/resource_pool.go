@@ -12,16 +12,36 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// Nodes in an auto-scaled pool are managed by the traffic manager itself.
 func suppressNodesTableDiffs(k, old, new string, d *schema.ResourceData) bool {
-	if d.Get("auto_scaling_enabled") == true {
-		return true
-	}
-	if _, ok := d.GetOk("nodes_table_json"); ok {
+	if d.Get("auto_scaling.0.enabled") == true || d.Get("auto_scaling_enabled") == true {
 		return true
 	}
 	return false
 }
 
+// resourcePoolCustomizeDiff checks that a newly set Kerberos principal for
+// protocol transition has a key in its keytab. Principals that are not on
+// the traffic manager yet are checked by their own resource.
+func resourcePoolCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	principalName := d.Get("kerberos_protocol_transition_principal").(string)
+	if principalName == "" || (d.Id() != "" && !d.HasChange("kerberos_protocol_transition_principal")) {
+		return nil
+	}
+	principal, err := tm.(*vtm.VirtualTrafficManager).GetKerberosPrincipal(principalName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_principal '%v': %v", principalName, err.ErrorText)
+	}
+	service, realm, keytab, krb5conf := kerberosPrincipalFields(principal)
+	if err := checkKerberosPrincipalKey(tm, service, realm, keytab, krb5conf); err != nil {
+		return fmt.Errorf("vtm_pool '%s' kerberos_protocol_transition_principal '%s': %v", d.Get("name").(string), principalName, err)
+	}
+	return nil
+}
+
 func resourcePool() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourcePoolRead,
@@ -34,12 +54,14 @@ func resourcePool() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourcePoolCustomizeDiff,
+
 		Schema: getResourcePoolSchema(),
 	}
 }
 
 func getResourcePoolSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	fields := map[string]*schema.Schema{
 
 		"name": &schema.Schema{
 			Type:         schema.TypeString,
@@ -54,6 +76,15 @@ func getResourcePoolSchema() map[string]*schema.Schema {
 			Optional: true,
 		},
 
+		// Only manage the nodes listed in nodes_table or nodes_table_json,
+		//  leaving any others, such as those added by vtm_pool_node
+		//  resources, in place. This setting is not stored on the vTM.
+		"ignore_external_nodes": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
 		// If all of the nodes in this pool have failed, then requests can
 		//  be diverted to another pool.
 		"failure_pool": &schema.Schema{
@@ -141,8 +172,8 @@ func getResourcePoolSchema() map[string]*schema.Schema {
 		// A table of all nodes in this pool. A node should be specified
 		//  as a "<ip>:<port>" pair, and has a state, weight and priority.
 		"nodes_table": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
+			Type:             schema.TypeSet,
+			Optional:         true,
 			DiffSuppressFunc: suppressNodesTableDiffs,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
@@ -188,9 +219,10 @@ func getResourcePoolSchema() map[string]*schema.Schema {
 
 		// JSON representation of nodes_table
 		"nodes_table_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourcePoolSchema, "nodes_table"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourcePoolSchema, "nodes_table"),
 		},
 
 		// A description of the pool.
@@ -228,203 +260,214 @@ func getResourcePoolSchema() map[string]*schema.Schema {
 			Default:  false,
 		},
 
-		// The time in seconds from the creation of the node which the traffic
-		//  manager should wait before adding the node to the autoscaled
-		//  pool. Set this to allow applications on the newly created node
-		//  time to intialize before being sent traffic.
-		"auto_scaling_addnode_delaytime": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      0,
-		},
-
-		// The Cloud Credentials object containing authentication credentials
-		//  to use in cloud API calls.
-		"auto_scaling_cloud_credentials": &schema.Schema{
-			Type:     schema.TypeString,
+		// Settings from the "auto_scaling" section of the pool configuration.
+		"auto_scaling": &schema.Schema{
+			Type:     schema.TypeList,
 			Optional: true,
-		},
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
 
-		// The ESX host or ESX cluster name to put the new virtual machine
-		//  instances on.
-		"auto_scaling_cluster": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The time in seconds from the creation of the node which the traffic
+					//  manager should wait before adding the node to the autoscaled
+					//  pool. Set this to allow applications on the newly created node
+					//  time to intialize before being sent traffic.
+					"addnode_delaytime": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      0,
+					},
 
-		// The name of the logical datacenter on the vCenter server. Virtual
-		//  machines will be scaled up and down under the datacenter root
-		//  folder.
-		"auto_scaling_data_center": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The Cloud Credentials object containing authentication credentials
+					//  to use in cloud API calls.
+					"cloud_credentials": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// The name of the datastore to be used by the newly created virtual
-		//  machine.
-		"auto_scaling_data_store": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The ESX host or ESX cluster name to put the new virtual machine
+					//  instances on.
+					"cluster": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Are the nodes of this pool subject to autoscaling?  If yes, nodes
-		//  will be automatically added and removed from the pool by the
-		//  chosen autoscaling mechanism.
-		"auto_scaling_enabled": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
+					// The name of the logical datacenter on the vCenter server. Virtual
+					//  machines will be scaled up and down under the datacenter root
+					//  folder.
+					"data_center": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Whether or not autoscaling is being handled by an external system.
-		//  Set this value to Yes if all aspects of autoscaling are handled
-		//  by an external system, such as RightScale. If set to No, the
-		//  traffic manager will determine when to scale the pool and will
-		//  communicate with the cloud provider to create and destroy nodes
-		//  as necessary.
-		"auto_scaling_external": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  true,
-		},
+					// The name of the datastore to be used by the newly created virtual
+					//  machine.
+					"data_store": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Any extra arguments to the autoscaling API. Each argument can be
-		//  separated by comma. E.g in case of EC2, it can take extra parameters
-		//  to the Amazon's RunInstance API say
-		//  DisableApiTermination=false,Placement.Tenancy=default.
-		"auto_scaling_extraargs": &schema.Schema{
-			Type:       schema.TypeString,
-			Optional:   true,
-		},
+					// Are the nodes of this pool subject to autoscaling?  If yes, nodes
+					//  will be automatically added and removed from the pool by the
+					//  chosen autoscaling mechanism.
+					"enabled": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
 
-		// The time period in seconds for which a change condition must
-		//  persist before the change is actually instigated.
-		"auto_scaling_hysteresis": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      20,
-		},
+					// Whether or not autoscaling is being handled by an external system.
+					//  Set this value to Yes if all aspects of autoscaling are handled
+					//  by an external system, such as RightScale. If set to No, the
+					//  traffic manager will determine when to scale the pool and will
+					//  communicate with the cloud provider to create and destroy nodes
+					//  as necessary.
+					"external": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
 
-		// The identifier for the image of the instances to create.
-		"auto_scaling_imageid": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// Any extra arguments to the autoscaling API. Each argument can be
+					//  separated by comma. E.g in case of EC2, it can take extra parameters
+					//  to the Amazon's RunInstance API say
+					//  DisableApiTermination=false,Placement.Tenancy=default.
+					"extraargs": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Which type of IP addresses on the node to use.  Choose private
-		//  IPs if the traffic manager is in the same cloud as the nodes,
-		//  otherwise choose public IPs.
-		"auto_scaling_ips_to_use": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"private_ips", "publicips"}, false),
-			Default:      "publicips",
-		},
+					// The time period in seconds for which a change condition must
+					//  persist before the change is actually instigated.
+					"hysteresis": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      20,
+					},
 
-		// The time in seconds for which the last node in an autoscaled
-		//  pool must have been idle before it is destroyed.  This is only
-		//  relevant if min_nodes is 0.
-		"auto_scaling_last_node_idle_time": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      3600,
-		},
+					// The identifier for the image of the instances to create.
+					"imageid": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// The maximum number of nodes in this autoscaled pool.
-		"auto_scaling_max_nodes": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      4,
-		},
+					// Which type of IP addresses on the node to use.  Choose private
+					//  IPs if the traffic manager is in the same cloud as the nodes,
+					//  otherwise choose public IPs.
+					"ips_to_use": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"private_ips", "publicips"}, false),
+						Default:      "publicips",
+					},
 
-		// The minimum number of nodes in this autoscaled pool.
-		"auto_scaling_min_nodes": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      1,
-		},
+					// The time in seconds for which the last node in an autoscaled
+					//  pool must have been idle before it is destroyed.  This is only
+					//  relevant if min_nodes is 0.
+					"last_node_idle_time": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      3600,
+					},
 
-		// The beginning of the name of nodes in the cloud that are part
-		//  of this autoscaled pool.
-		"auto_scaling_name": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The maximum number of nodes in this autoscaled pool.
+					"max_nodes": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      4,
+					},
 
-		// The port number to use for each node in this autoscaled pool.
-		"auto_scaling_port": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(1, 65535),
-			Default:      80,
-		},
+					// The minimum number of nodes in this autoscaled pool.
+					"min_nodes": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      1,
+					},
 
-		// The time period in seconds after the instigation of a re-size
-		//  during which no further changes will be made to the pool size.
-		"auto_scaling_refractory": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      180,
-		},
+					// The beginning of the name of nodes in the cloud that are part
+					//  of this autoscaled pool.
+					"name": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// The expected response time of the nodes in ms.  This time is
-		//  used as a reference when deciding whether a node's response time
-		//  is conforming.  All responses from all the nodes will be compared
-		//  to this reference and the percentage of conforming responses
-		//  is the base for decisions about scaling the pool up or down.
-		"auto_scaling_response_time": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      1000,
-		},
+					// The port number to use for each node in this autoscaled pool.
+					"port": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(1, 65535),
+						Default:      80,
+					},
 
-		// The fraction, in percent, of conforming requests above which
-		//  the pool size is decreased.  If the percentage of conforming
-		//  requests exceeds this value, the pool is scaled down.
-		"auto_scaling_scale_down_level": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      95,
-		},
+					// The time period in seconds after the instigation of a re-size
+					//  during which no further changes will be made to the pool size.
+					"refractory": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      180,
+					},
 
-		// The fraction, in percent, of conforming requests below which
-		//  the pool size is increased.  If the percentage of conforming
-		//  requests drops below this value, the pool is scaled up.
-		"auto_scaling_scale_up_level": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      40,
-		},
+					// The expected response time of the nodes in ms.  This time is
+					//  used as a reference when deciding whether a node's response time
+					//  is conforming.  All responses from all the nodes will be compared
+					//  to this reference and the percentage of conforming responses
+					//  is the base for decisions about scaling the pool up or down.
+					"response_time": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      1000,
+					},
 
-		// List of security group IDs to associate to the new EC2 instance.
-		"auto_scaling_securitygroupids": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
+					// The fraction, in percent, of conforming requests above which
+					//  the pool size is decreased.  If the percentage of conforming
+					//  requests exceeds this value, the pool is scaled down.
+					"scale_down_level": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      95,
+					},
 
-		// The identifier for the size of the instances to create.
-		"auto_scaling_size_id": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The fraction, in percent, of conforming requests below which
+					//  the pool size is increased.  If the percentage of conforming
+					//  requests drops below this value, the pool is scaled up.
+					"scale_up_level": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      40,
+					},
 
-		// List of subnet IDs where the new EC2-VPC instance(s) will be
-		//  launched. Instances will be evenly distributed among the subnets.
-		//  If the list is empty, instances will be launched inside EC2-Classic.
-		"auto_scaling_subnetids": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+					// List of security group IDs to associate to the new EC2 instance.
+					"securitygroupids": &schema.Schema{
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+
+					// The identifier for the size of the instances to create.
+					"size_id": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+
+					// List of subnet IDs where the new EC2-VPC instance(s) will be
+					//  launched. Instances will be evenly distributed among the subnets.
+					//  If the list is empty, instances will be launched inside EC2-Classic.
+					"subnetids": &schema.Schema{
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
 		},
 
 		// How long the pool should wait for a connection to a node to be
@@ -829,6 +872,8 @@ func getResourcePoolSchema() map[string]*schema.Schema {
 			Default:      0,
 		},
 	}
+	addSectionAliases(fields, "auto_scaling", "auto_scaling")
+	return fields
 }
 
 func resourcePoolRead(d *schema.ResourceData, tm interface{}) (readError error) {
@@ -876,8 +921,12 @@ func resourcePoolRead(d *schema.ResourceData, tm interface{}) (readError error)
 	lastAssignedField = "node_drain_to_delete_timeout"
 	d.Set("node_drain_to_delete_timeout", int(*object.Basic.NodeDrainToDeleteTimeout))
 	lastAssignedField = "nodes_table"
+	managedNodes := tableRowKeys(d.Get("nodes_table"), d.Get("nodes_table_json"), "node", "")
 	nodesTable := make([]map[string]interface{}, 0, len(*object.Basic.NodesTable))
 	for _, item := range *object.Basic.NodesTable {
+		if d.Get("ignore_external_nodes") == true && (item.Node == nil || !managedNodes[string(*item.Node)]) {
+			continue
+		}
 		itemTerraform := make(map[string]interface{})
 		if item.Node != nil {
 			itemTerraform["node"] = string(*item.Node)
@@ -900,9 +949,12 @@ func resourcePoolRead(d *schema.ResourceData, tm interface{}) (readError error)
 		}
 		nodesTable = append(nodesTable, itemTerraform)
 	}
-	d.Set("nodes_table", nodesTable)
-	nodesTableJson, _ := json.Marshal(nodesTable)
-	d.Set("nodes_table_json", nodesTableJson)
+	if _, ok := d.GetOk("nodes_table_json"); ok {
+		nodesTableJson, _ := json.Marshal(nodesTable)
+		d.Set("nodes_table_json", string(nodesTableJson))
+	} else {
+		d.Set("nodes_table", nodesTable)
+	}
 	lastAssignedField = "note"
 	d.Set("note", string(*object.Basic.Note))
 	lastAssignedField = "passive_monitoring"
@@ -911,52 +963,54 @@ func resourcePoolRead(d *schema.ResourceData, tm interface{}) (readError error)
 	d.Set("persistence_class", string(*object.Basic.PersistenceClass))
 	lastAssignedField = "transparent"
 	d.Set("transparent", bool(*object.Basic.Transparent))
-	lastAssignedField = "auto_scaling_addnode_delaytime"
-	d.Set("auto_scaling_addnode_delaytime", int(*object.AutoScaling.AddnodeDelaytime))
-	lastAssignedField = "auto_scaling_cloud_credentials"
-	d.Set("auto_scaling_cloud_credentials", string(*object.AutoScaling.CloudCredentials))
-	lastAssignedField = "auto_scaling_cluster"
-	d.Set("auto_scaling_cluster", string(*object.AutoScaling.Cluster))
-	lastAssignedField = "auto_scaling_data_center"
-	d.Set("auto_scaling_data_center", string(*object.AutoScaling.DataCenter))
-	lastAssignedField = "auto_scaling_data_store"
-	d.Set("auto_scaling_data_store", string(*object.AutoScaling.DataStore))
-	lastAssignedField = "auto_scaling_enabled"
-	d.Set("auto_scaling_enabled", bool(*object.AutoScaling.Enabled))
-	lastAssignedField = "auto_scaling_external"
-	d.Set("auto_scaling_external", bool(*object.AutoScaling.External))
-	lastAssignedField = "auto_scaling_extraargs"
-	d.Set("auto_scaling_extraargs", string(*object.AutoScaling.Extraargs))
-	lastAssignedField = "auto_scaling_hysteresis"
-	d.Set("auto_scaling_hysteresis", int(*object.AutoScaling.Hysteresis))
-	lastAssignedField = "auto_scaling_imageid"
-	d.Set("auto_scaling_imageid", string(*object.AutoScaling.Imageid))
-	lastAssignedField = "auto_scaling_ips_to_use"
-	d.Set("auto_scaling_ips_to_use", string(*object.AutoScaling.IpsToUse))
-	lastAssignedField = "auto_scaling_last_node_idle_time"
-	d.Set("auto_scaling_last_node_idle_time", int(*object.AutoScaling.LastNodeIdleTime))
-	lastAssignedField = "auto_scaling_max_nodes"
-	d.Set("auto_scaling_max_nodes", int(*object.AutoScaling.MaxNodes))
-	lastAssignedField = "auto_scaling_min_nodes"
-	d.Set("auto_scaling_min_nodes", int(*object.AutoScaling.MinNodes))
-	lastAssignedField = "auto_scaling_name"
-	d.Set("auto_scaling_name", string(*object.AutoScaling.Name))
-	lastAssignedField = "auto_scaling_port"
-	d.Set("auto_scaling_port", int(*object.AutoScaling.Port))
-	lastAssignedField = "auto_scaling_refractory"
-	d.Set("auto_scaling_refractory", int(*object.AutoScaling.Refractory))
-	lastAssignedField = "auto_scaling_response_time"
-	d.Set("auto_scaling_response_time", int(*object.AutoScaling.ResponseTime))
-	lastAssignedField = "auto_scaling_scale_down_level"
-	d.Set("auto_scaling_scale_down_level", int(*object.AutoScaling.ScaleDownLevel))
-	lastAssignedField = "auto_scaling_scale_up_level"
-	d.Set("auto_scaling_scale_up_level", int(*object.AutoScaling.ScaleUpLevel))
-	lastAssignedField = "auto_scaling_securitygroupids"
-	d.Set("auto_scaling_securitygroupids", []string(*object.AutoScaling.Securitygroupids))
-	lastAssignedField = "auto_scaling_size_id"
-	d.Set("auto_scaling_size_id", string(*object.AutoScaling.SizeId))
-	lastAssignedField = "auto_scaling_subnetids"
-	d.Set("auto_scaling_subnetids", []string(*object.AutoScaling.Subnetids))
+	autoScaling := make(map[string]interface{})
+	lastAssignedField = "auto_scaling.addnode_delaytime"
+	autoScaling["addnode_delaytime"] = int(*object.AutoScaling.AddnodeDelaytime)
+	lastAssignedField = "auto_scaling.cloud_credentials"
+	autoScaling["cloud_credentials"] = string(*object.AutoScaling.CloudCredentials)
+	lastAssignedField = "auto_scaling.cluster"
+	autoScaling["cluster"] = string(*object.AutoScaling.Cluster)
+	lastAssignedField = "auto_scaling.data_center"
+	autoScaling["data_center"] = string(*object.AutoScaling.DataCenter)
+	lastAssignedField = "auto_scaling.data_store"
+	autoScaling["data_store"] = string(*object.AutoScaling.DataStore)
+	lastAssignedField = "auto_scaling.enabled"
+	autoScaling["enabled"] = bool(*object.AutoScaling.Enabled)
+	lastAssignedField = "auto_scaling.external"
+	autoScaling["external"] = bool(*object.AutoScaling.External)
+	lastAssignedField = "auto_scaling.extraargs"
+	autoScaling["extraargs"] = string(*object.AutoScaling.Extraargs)
+	lastAssignedField = "auto_scaling.hysteresis"
+	autoScaling["hysteresis"] = int(*object.AutoScaling.Hysteresis)
+	lastAssignedField = "auto_scaling.imageid"
+	autoScaling["imageid"] = string(*object.AutoScaling.Imageid)
+	lastAssignedField = "auto_scaling.ips_to_use"
+	autoScaling["ips_to_use"] = string(*object.AutoScaling.IpsToUse)
+	lastAssignedField = "auto_scaling.last_node_idle_time"
+	autoScaling["last_node_idle_time"] = int(*object.AutoScaling.LastNodeIdleTime)
+	lastAssignedField = "auto_scaling.max_nodes"
+	autoScaling["max_nodes"] = int(*object.AutoScaling.MaxNodes)
+	lastAssignedField = "auto_scaling.min_nodes"
+	autoScaling["min_nodes"] = int(*object.AutoScaling.MinNodes)
+	lastAssignedField = "auto_scaling.name"
+	autoScaling["name"] = string(*object.AutoScaling.Name)
+	lastAssignedField = "auto_scaling.port"
+	autoScaling["port"] = int(*object.AutoScaling.Port)
+	lastAssignedField = "auto_scaling.refractory"
+	autoScaling["refractory"] = int(*object.AutoScaling.Refractory)
+	lastAssignedField = "auto_scaling.response_time"
+	autoScaling["response_time"] = int(*object.AutoScaling.ResponseTime)
+	lastAssignedField = "auto_scaling.scale_down_level"
+	autoScaling["scale_down_level"] = int(*object.AutoScaling.ScaleDownLevel)
+	lastAssignedField = "auto_scaling.scale_up_level"
+	autoScaling["scale_up_level"] = int(*object.AutoScaling.ScaleUpLevel)
+	lastAssignedField = "auto_scaling.securitygroupids"
+	autoScaling["securitygroupids"] = []string(*object.AutoScaling.Securitygroupids)
+	lastAssignedField = "auto_scaling.size_id"
+	autoScaling["size_id"] = string(*object.AutoScaling.SizeId)
+	lastAssignedField = "auto_scaling.subnetids"
+	autoScaling["subnetids"] = []string(*object.AutoScaling.Subnetids)
+	setSection(d, "auto_scaling", "auto_scaling", autoScaling, getResourcePoolSchema()["auto_scaling"].Elem.(*schema.Resource).Schema)
 	lastAssignedField = "connection_max_connect_time"
 	d.Set("connection_max_connect_time", int(*object.Connection.MaxConnectTime))
 	lastAssignedField = "connection_max_connections_per_node"
@@ -1066,8 +1120,12 @@ func resourcePoolExists(d *schema.ResourceData, tm interface{}) (bool, error) {
 
 func resourcePoolCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_pool", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewPool(objectName)
-	resourcePoolObjectFieldAssignments(d, object)
+	if err := resourcePoolObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_pool '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -1079,11 +1137,15 @@ func resourcePoolCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourcePoolUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_pool", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetPool(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_pool '%v': %v", objectName, err)
 	}
-	resourcePoolObjectFieldAssignments(d, object)
+	if err := resourcePoolObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_pool '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -1093,7 +1155,9 @@ func resourcePoolUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourcePoolObjectFieldAssignments(d *schema.ResourceData, object *vtm.Pool) {
+func resourcePoolObjectFieldAssignments(d *schema.ResourceData, object *vtm.Pool) error {
+	defer setSectionDefaults(d, "auto_scaling", "auto_scaling", getResourcePoolSchema()["auto_scaling"].Elem.(*schema.Resource).Schema)()
+
 	setString(&object.Basic.BandwidthClass, d, "bandwidth_class")
 	setString(&object.Basic.FailurePool, d, "failure_pool")
 	setInt(&object.Basic.MaxConnectionAttempts, d, "max_connection_attempts")
@@ -1114,45 +1178,46 @@ func resourcePoolObjectFieldAssignments(d *schema.ResourceData, object *vtm.Pool
 	setBool(&object.Basic.PassiveMonitoring, d, "passive_monitoring")
 	setString(&object.Basic.PersistenceClass, d, "persistence_class")
 	setBool(&object.Basic.Transparent, d, "transparent")
-	setInt(&object.AutoScaling.AddnodeDelaytime, d, "auto_scaling_addnode_delaytime")
-	setString(&object.AutoScaling.CloudCredentials, d, "auto_scaling_cloud_credentials")
-	setString(&object.AutoScaling.Cluster, d, "auto_scaling_cluster")
-	setString(&object.AutoScaling.DataCenter, d, "auto_scaling_data_center")
-	setString(&object.AutoScaling.DataStore, d, "auto_scaling_data_store")
-	setBool(&object.AutoScaling.Enabled, d, "auto_scaling_enabled")
-	setBool(&object.AutoScaling.External, d, "auto_scaling_external")
-	setString(&object.AutoScaling.Extraargs, d, "auto_scaling_extraargs")
-	setInt(&object.AutoScaling.Hysteresis, d, "auto_scaling_hysteresis")
-	setString(&object.AutoScaling.Imageid, d, "auto_scaling_imageid")
-	setString(&object.AutoScaling.IpsToUse, d, "auto_scaling_ips_to_use")
-	setInt(&object.AutoScaling.LastNodeIdleTime, d, "auto_scaling_last_node_idle_time")
-	setInt(&object.AutoScaling.MaxNodes, d, "auto_scaling_max_nodes")
-	setInt(&object.AutoScaling.MinNodes, d, "auto_scaling_min_nodes")
-	setString(&object.AutoScaling.Name, d, "auto_scaling_name")
-	setInt(&object.AutoScaling.Port, d, "auto_scaling_port")
-	setInt(&object.AutoScaling.Refractory, d, "auto_scaling_refractory")
-	setInt(&object.AutoScaling.ResponseTime, d, "auto_scaling_response_time")
-	setInt(&object.AutoScaling.ScaleDownLevel, d, "auto_scaling_scale_down_level")
-	setInt(&object.AutoScaling.ScaleUpLevel, d, "auto_scaling_scale_up_level")
-
-	if _, ok := d.GetOk("auto_scaling_securitygroupids"); ok {
-		setStringSet(&object.AutoScaling.Securitygroupids, d, "auto_scaling_securitygroupids")
+	setInt(&object.AutoScaling.AddnodeDelaytime, d, "auto_scaling.0.addnode_delaytime")
+	setString(&object.AutoScaling.CloudCredentials, d, "auto_scaling.0.cloud_credentials")
+	setString(&object.AutoScaling.Cluster, d, "auto_scaling.0.cluster")
+	setString(&object.AutoScaling.DataCenter, d, "auto_scaling.0.data_center")
+	setString(&object.AutoScaling.DataStore, d, "auto_scaling.0.data_store")
+	setBool(&object.AutoScaling.Enabled, d, "auto_scaling.0.enabled")
+	setBool(&object.AutoScaling.External, d, "auto_scaling.0.external")
+	setString(&object.AutoScaling.Extraargs, d, "auto_scaling.0.extraargs")
+	setInt(&object.AutoScaling.Hysteresis, d, "auto_scaling.0.hysteresis")
+	setString(&object.AutoScaling.Imageid, d, "auto_scaling.0.imageid")
+	setString(&object.AutoScaling.IpsToUse, d, "auto_scaling.0.ips_to_use")
+	setInt(&object.AutoScaling.LastNodeIdleTime, d, "auto_scaling.0.last_node_idle_time")
+	setInt(&object.AutoScaling.MaxNodes, d, "auto_scaling.0.max_nodes")
+	setInt(&object.AutoScaling.MinNodes, d, "auto_scaling.0.min_nodes")
+	setString(&object.AutoScaling.Name, d, "auto_scaling.0.name")
+	setInt(&object.AutoScaling.Port, d, "auto_scaling.0.port")
+	setInt(&object.AutoScaling.Refractory, d, "auto_scaling.0.refractory")
+	setInt(&object.AutoScaling.ResponseTime, d, "auto_scaling.0.response_time")
+	setInt(&object.AutoScaling.ScaleDownLevel, d, "auto_scaling.0.scale_down_level")
+	setInt(&object.AutoScaling.ScaleUpLevel, d, "auto_scaling.0.scale_up_level")
+
+	if _, ok := d.GetOk("auto_scaling.0.securitygroupids"); ok {
+		setStringSet(&object.AutoScaling.Securitygroupids, d, "auto_scaling.0.securitygroupids")
 	} else {
 		object.AutoScaling.Securitygroupids = &[]string{}
-		d.Set("auto_scaling_securitygroupids", []string(*object.AutoScaling.Securitygroupids))
 	}
-	setString(&object.AutoScaling.SizeId, d, "auto_scaling_size_id")
+	setString(&object.AutoScaling.SizeId, d, "auto_scaling.0.size_id")
 
-	if _, ok := d.GetOk("auto_scaling_subnetids"); ok {
-		setStringSet(&object.AutoScaling.Subnetids, d, "auto_scaling_subnetids")
+	if _, ok := d.GetOk("auto_scaling.0.subnetids"); ok {
+		setStringSet(&object.AutoScaling.Subnetids, d, "auto_scaling.0.subnetids")
 	} else {
 		object.AutoScaling.Subnetids = &[]string{}
-		d.Set("auto_scaling_subnetids", []string(*object.AutoScaling.Subnetids))
 	}
 
+	externalNodes := externalTableRows(d, "ignore_external_nodes", "nodes_table", "node", "", object.Basic.NodesTable)
 	object.Basic.NodesTable = &vtm.PoolNodesTableTable{}
 	if nodesTableJson, ok := d.GetOk("nodes_table_json"); ok {
-		_ = json.Unmarshal([]byte(nodesTableJson.(string)), object.Basic.NodesTable)
+		if err := json.Unmarshal([]byte(nodesTableJson.(string)), object.Basic.NodesTable); err != nil {
+			return fmt.Errorf("Invalid nodes_table_json: %v", err)
+		}
 	} else if nodesTable, ok := d.GetOk("nodes_table"); ok {
 		for _, row := range nodesTable.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1168,6 +1233,9 @@ func resourcePoolObjectFieldAssignments(d *schema.ResourceData, object *vtm.Pool
 	} else {
 		d.Set("nodes_table", make([]map[string]interface{}, 0, len(*object.Basic.NodesTable)))
 	}
+	if err := addTableRows(&object.Basic.NodesTable, externalNodes, "node", ""); err != nil {
+		return fmt.Errorf("Failed to keep external nodes: %v", err)
+	}
 	setInt(&object.Connection.MaxConnectTime, d, "connection_max_connect_time")
 	setInt(&object.Connection.MaxConnectionsPerNode, d, "connection_max_connections_per_node")
 	setInt(&object.Connection.MaxQueueSize, d, "connection_max_queue_size")
@@ -1230,6 +1298,7 @@ func resourcePoolObjectFieldAssignments(d *schema.ResourceData, object *vtm.Pool
 	setString(&object.Udp.AcceptFrom, d, "udp_accept_from")
 	setString(&object.Udp.AcceptFromMask, d, "udp_accept_from_mask")
 	setInt(&object.Udp.ResponseTimeout, d, "udp_response_timeout")
+	return nil
 }
 
 func resourcePoolDelete(d *schema.ResourceData, tm interface{}) error {
@@ -5,10 +5,12 @@ package main
 
 /*
  * This test covers the following cases:
- *   - Suppression of changes to nodes_table when auto_scaling_enabled is true
+ *   - Suppression of changes to nodes_table when auto_scaling.enabled is true
  *   - Suppression of changes to nodes_table when nodes_table_json is specified
  *   - Nodes specified in nodes_table_json are configured on the vTM
- *   - auto_scaling_extraargs field is present and working
+ *   - Changes to nodes made outside Terraform are detected when nodes_table_json is specified
+ *   - auto_scaling.extraargs field is present and working
+ *   - Removing the auto_scaling block resets its settings to their defaults
  */
 
 import (
@@ -37,7 +39,7 @@ func TestResourcePoolEnhanced(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckPoolEnhancedExists,
 					// Check that autoscaling is disabled
-					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling_enabled", "false"),
+					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling.#", "0"),
 					// Check that nodes_table is empty
 					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "nodes_table.#", "0"),
 				),
@@ -54,6 +56,13 @@ func TestResourcePoolEnhanced(t *testing.T) {
 				// Test that diff suppression on nodes_table works
 				ExpectNonEmptyPlan: false,
 			},
+			{
+				PreConfig: initPoolConfig,
+				Config: getBasicPoolEnhancedNodesJsonConfig(objName),
+				PlanOnly: true,
+				// Check that nodes changed outside Terraform are detected through nodes_table_json
+				ExpectNonEmptyPlan: true,
+			},
 			{
 				PreConfig: initPoolConfig,
 				Config: getBasicPoolEnhancedConfig(objName),
@@ -66,7 +75,7 @@ func TestResourcePoolEnhanced(t *testing.T) {
 				Config: getBasicPoolEnhancedAsEnabledConfig(objName),
 				Check: resource.ComposeTestCheckFunc(
 					// Check that autoscaling is enabled
-					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling_enabled", "true"),
+					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling.0.enabled", "true"),
 					// Check that nodes_table has the correct number of entries (ie. hasn't overwritten them with an empty set)
 					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "nodes_table.#", "2"),
 				),
@@ -74,8 +83,16 @@ func TestResourcePoolEnhanced(t *testing.T) {
 			{
 				Config: getBasicPoolEnhancedExtraargsConfig(objName),
 				Check: resource.ComposeTestCheckFunc(
-					// Check that auto_scaling_extraargs is present and has expected value
-					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling_extraargs", "--foo=bar"),
+					// Check that auto_scaling.extraargs is present and has expected value
+					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling.0.extraargs", "--foo=bar"),
+				),
+			},
+			{
+				Config: getBasicPoolEnhancedConfig(objName),
+				Check: resource.ComposeTestCheckFunc(
+					// Check that removing the auto_scaling block resets its settings
+					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "auto_scaling.#", "0"),
+					testAccCheckPoolEnhancedAutoScalingReset,
 				),
 			},
 		},
@@ -114,6 +131,41 @@ func testAccCheckPoolEnhancedDestroy(s *terraform.State) error {
 	return nil
 }
 
+func testAccCheckPoolEnhancedAutoScalingReset(s *terraform.State) error {
+
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_pool" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		pool, err := tm.GetPool(objectName)
+		if err != nil {
+			return fmt.Errorf("Pool %s does not exist: %#v", objectName, err)
+		}
+		if *pool.AutoScaling.Extraargs != "" {
+			return fmt.Errorf("Pool auto_scaling.extraargs was not reset: '%s'", *pool.AutoScaling.Extraargs)
+		}
+		if *pool.AutoScaling.Port != 80 {
+			return fmt.Errorf("Pool auto_scaling.port was not reset: %d", *pool.AutoScaling.Port)
+		}
+		if *pool.AutoScaling.External != true {
+			return fmt.Errorf("Pool auto_scaling.external was not reset: %t", *pool.AutoScaling.External)
+		}
+		if *pool.AutoScaling.Hysteresis != 20 {
+			return fmt.Errorf("Pool auto_scaling.hysteresis was not reset: %d", *pool.AutoScaling.Hysteresis)
+		}
+		if *pool.AutoScaling.IpsToUse != "publicips" {
+			return fmt.Errorf("Pool auto_scaling.ips_to_use was not reset: '%s'", *pool.AutoScaling.IpsToUse)
+		}
+		if _, ok := tfResource.Primary.Attributes["auto_scaling.0.port"]; ok {
+			return fmt.Errorf("Pool auto_scaling defaults were kept in the state")
+		}
+	}
+
+	return nil
+}
+
 func testAccCheckPoolEnhancedHasNodes(s *terraform.State) error {
 
 	for _, tfResource := range s.RootModule().Resources {
@@ -158,8 +210,10 @@ func getBasicPoolEnhancedAsEnabledConfig(name string) string {
 	return fmt.Sprintf(`
 		resource "vtm_pool" "test_vtm_pool" {
 			name = "%s"
-			auto_scaling_enabled = true
-			auto_scaling_external = true
+			auto_scaling {
+				enabled = true
+				external = true
+			}
 		}`,
 		name,
 	)
@@ -169,7 +223,13 @@ func getBasicPoolEnhancedExtraargsConfig(name string) string {
 	return fmt.Sprintf(`
 		resource "vtm_pool" "test_vtm_pool" {
 			name = "%s"
-			auto_scaling_extraargs = "--foo=bar"
+			auto_scaling {
+				extraargs = "--foo=bar"
+				port = 8080
+				external = false
+				hysteresis = 30
+				ips_to_use = "private_ips"
+			}
 		}`,
 		name,
 	)
@@ -0,0 +1,97 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_pool_node manages one row of a pool's nodes table, leaving the other
+// rows alone. A vtm_pool resource for the same pool should set
+// ignore_external_nodes so that it does not remove the row again.
+func resourcePoolNode() *schema.Resource {
+	return &schema.Resource{
+		Read:   poolNodeAttachment.Read,
+		Create: poolNodeAttachment.Create,
+		Update: poolNodeAttachment.Update,
+		Delete: poolNodeAttachment.Delete,
+
+		Importer: &schema.ResourceImporter{
+			State: poolNodeAttachment.Import,
+		},
+
+		Schema: getResourcePoolNodeSchema(),
+	}
+}
+
+func getResourcePoolNodeSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the pool the node belongs to.
+		"pool": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The node, as an "<ip>:<port>" pair.
+		"node": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// priority
+		"priority": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+			Default:      1,
+		},
+
+		// source_ip
+		"source_ip": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		// state
+		"state": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"active", "disabled", "draining"}, false),
+			Default:      "active",
+		},
+
+		// weight
+		"weight": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntBetween(1, 100),
+			Default:      1,
+		},
+	}
+}
+
+var poolNodeAttachment = &tableRowAttachment{
+	resourceType: "vtm_pool_node",
+	objectType:   "vtm_pool",
+	objectAttr:   "pool",
+	keyAttr:      "node",
+	schema:       getResourcePoolNodeSchema,
+	read: func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetPool(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &object.Basic.NodesTable, func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	},
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Nodes added to a pool by vtm_pool_node resources
+ *   - A vtm_pool with ignore_external_nodes keeps those nodes
+ *   - Changing the weight of one node leaves the others untouched
+ *   - Deleting a vtm_pool_node only removes its own row
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourcePoolNode(t *testing.T) {
+	poolName := acctest.RandomWithPrefix("TestPoolNode")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPoolNodeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getPoolNodeConfig(poolName, 10, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_pool.test_vtm_pool", "nodes_table.#", "1"),
+					resource.TestCheckResourceAttr("vtm_pool_node.app1", "weight", "10"),
+					resource.TestCheckResourceAttr("vtm_pool_node.app1", "id", poolName+"/192.168.10.11:80"),
+					testAccCheckPoolNodeCount(poolName, 3),
+				),
+			},
+			{
+				// The pool resource must not report the other nodes as drift
+				Config:             getPoolNodeConfig(poolName, 10, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				Config: getPoolNodeConfig(poolName, 20, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_pool_node.app1", "weight", "20"),
+					testAccCheckPoolNodeCount(poolName, 3),
+				),
+			},
+			{
+				Config: getPoolNodeConfig(poolName, 20, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPoolNodeCount(poolName, 2),
+				),
+			},
+			{
+				ResourceName:      "vtm_pool_node.app1",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckPoolNodeCount(poolName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		pool, err := tm.GetPool(poolName)
+		if err != nil {
+			return fmt.Errorf("Pool %s does not exist: %#v", poolName, err)
+		}
+		if len(*pool.Basic.NodesTable) != expected {
+			return fmt.Errorf("Pool %s has %d nodes, expected %d", poolName, len(*pool.Basic.NodesTable), expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckPoolNodeDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_pool" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetPool(objectName); err == nil {
+			return fmt.Errorf("Pool %s still exists", objectName)
+		}
+	}
+
+	return nil
+}
+
+func getPoolNodeConfig(poolName string, weight int, withSecondNode bool) string {
+	config := fmt.Sprintf(`
+        resource "vtm_pool" "test_vtm_pool" {
+			name = "%s"
+			ignore_external_nodes = true
+			nodes_table {
+				node = "192.168.10.10:80"
+			}
+		}
+
+		resource "vtm_pool_node" "app1" {
+			pool = "${vtm_pool.test_vtm_pool.name}"
+			node = "192.168.10.11:80"
+			weight = %d
+		}`,
+		poolName, weight,
+	)
+	if withSecondNode {
+		config += `
+
+		resource "vtm_pool_node" "app2" {
+			pool = "${vtm_pool.test_vtm_pool.name}"
+			node = "192.168.10.12:80"
+			state = "draining"
+		}`
+	}
+	return config
+}
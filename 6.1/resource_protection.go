@@ -23,7 +23,9 @@ func resourceProtection() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceProtectionSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_protection"),
+
+		Schema: addLicensedFeatureSchema(getResourceProtectionSchema()),
 	}
 }
 
@@ -304,6 +306,7 @@ func resourceProtectionRead(d *schema.ResourceData, tm interface{}) (readError e
 	d.Set("http_reject_binary", bool(*object.Http.RejectBinary))
 	lastAssignedField = "http_send_error_page"
 	d.Set("http_send_error_page", bool(*object.Http.SendErrorPage))
+	d.Set("license_warning", licenseWarning(tm, "vtm_protection"))
 	d.SetId(objectName)
 	return nil
 }
@@ -20,6 +20,8 @@ func resourceRule() *schema.Resource {
 		Update: resourceRuleUpdate,
 		Delete: resourceRuleDelete,
 
+		CustomizeDiff: resourceRuleCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -38,12 +40,178 @@ func getResourceRuleSchema() map[string]*schema.Schema {
 			ValidateFunc: validation.NoZeroValues,
 		},
 
-		// Object text
+		// Object text, which must be valid TrafficScript
 		"content": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateTrafficScript,
+		},
+
+		// Whether to fail when the rule refers to a pool, rule, extra
+		//  file, rate class, bandwidth class or service level class that
+		//  does not exist
+		"check_references": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
+		// The pools named in the rule
+		"referenced_pools": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// The rules imported by the rule
+		"referenced_rules": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
 		},
+
+		// The extra files read by the rule
+		"referenced_extra_files": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// The rate classes named in the rule
+		"referenced_rate_classes": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// The bandwidth classes named in the rule
+		"referenced_bandwidth_classes": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// The service level classes named in the rule
+		"referenced_service_level_classes": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// Calls that refer to an object whose name is not a literal
+		//  string, so cannot be checked, as "line L, column C: function"
+		"unresolved_references": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// ruleReferenceAttributes are the attributes holding the objects of each
+// type that a rule refers to.
+var ruleReferenceAttributes = map[string]string{
+	"vtm_pool":                  "referenced_pools",
+	"vtm_rule":                  "referenced_rules",
+	"vtm_extra_file":            "referenced_extra_files",
+	"vtm_rate":                  "referenced_rate_classes",
+	"vtm_bandwidth":             "referenced_bandwidth_classes",
+	"vtm_service_level_monitor": "referenced_service_level_classes",
+}
+
+// getRuleReferences finds the objects a rule refers to, returning nil if
+// the rule cannot be parsed.
+func getRuleReferences(content string) (map[string][]string, []string) {
+	program, err := parseTrafficScript(content)
+	if err != nil {
+		return nil, nil
+	}
+	return findTrafficScriptReferences(program)
+}
+
+func setRuleReferences(d *schema.ResourceData, content string) {
+	references, unresolved := getRuleReferences(content)
+	for objectType, attribute := range ruleReferenceAttributes {
+		d.Set(attribute, references[objectType])
+	}
+	d.Set("unresolved_references", unresolved)
+}
+
+// checkRuleReferences returns an error naming each object the rule refers
+// to that does not exist.
+func checkRuleReferences(tm *vtm.VirtualTrafficManager, references map[string][]string) error {
+	missing := []string{}
+	for _, objectType := range tsReferenceTypes {
+		for _, name := range references[objectType] {
+			var errorId, errorText string
+			switch objectType {
+			case "vtm_pool":
+				if _, err := tm.GetPool(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			case "vtm_rule":
+				if _, err := tm.GetRule(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			case "vtm_extra_file":
+				if _, err := tm.GetExtraFile(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			case "vtm_rate":
+				if _, err := tm.GetRate(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			case "vtm_bandwidth":
+				if _, err := tm.GetBandwidth(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			case "vtm_service_level_monitor":
+				if _, err := tm.GetServiceLevelMonitor(name); err != nil {
+					errorId, errorText = err.ErrorId, err.ErrorText
+				}
+			}
+			if errorId != "" {
+				if errorId != "resource.not_found" {
+					return fmt.Errorf("Failed to read %s '%v': %v", objectType, name, errorText)
+				}
+				missing = append(missing, fmt.Sprintf("%s '%s'", objectType, name))
+			}
+		}
 	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the rule refers to objects that do not exist: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// resourceRuleCustomizeDiff shows the objects the rule refers to in the
+// plan, and checks they exist if "check_references" is set. Content that is
+// not known until apply reads as an empty string, and is checked then.
+func resourceRuleCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	content := d.Get("content").(string)
+	if content == "" {
+		for _, attribute := range ruleReferenceAttributes {
+			d.SetNewComputed(attribute)
+		}
+		d.SetNewComputed("unresolved_references")
+		return nil
+	}
+	references, unresolved := getRuleReferences(content)
+	if references == nil {
+		return nil
+	}
+	for objectType, attribute := range ruleReferenceAttributes {
+		if err := d.SetNew(attribute, references[objectType]); err != nil {
+			return err
+		}
+	}
+	if err := d.SetNew("unresolved_references", unresolved); err != nil {
+		return err
+	}
+	if d.Get("check_references").(bool) {
+		return checkRuleReferences(tm.(*vtm.VirtualTrafficManager), references)
+	}
+	return nil
 }
 
 func resourceRuleRead(d *schema.ResourceData, tm interface{}) (readError error) {
@@ -71,6 +239,7 @@ func resourceRuleRead(d *schema.ResourceData, tm interface{}) (readError error)
 	}()
 
 	d.Set("content", object)
+	setRuleReferences(d, object)
 	d.SetId(objectName)
 	return nil
 }
@@ -101,10 +270,17 @@ func resourceRuleCreate(d *schema.ResourceData, tm interface{}) error {
 func resourceRuleUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	objectContent := d.Get("content").(string)
+	if d.Get("check_references").(bool) {
+		references, _ := getRuleReferences(objectContent)
+		if err := checkRuleReferences(tm.(*vtm.VirtualTrafficManager), references); err != nil {
+			return fmt.Errorf("Failed to update vtm_rule '%v': %v", objectName, err)
+		}
+	}
 	err := tm.(*vtm.VirtualTrafficManager).SetRule(objectName, objectContent)
 	if err != nil {
 		return fmt.Errorf("Failed to create vtm_rule '%v': %v", objectName, err.ErrorText)
 	}
+	setRuleReferences(d, objectContent)
 	d.SetId(objectName)
 	return nil
 }
@@ -0,0 +1,79 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - The pools and extra files named in a vtm_rule are shown in its
+ *     computed reference attributes
+ *   - A name that is not a literal string is reported as unresolved
+ *   - With check_references set, a rule naming a pool that does not exist
+ *     fails to plan
+ *   - With check_references set, a rule naming a vtm_pool through
+ *     interpolation is planned and applied without drift
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestResourceRuleReferences(t *testing.T) {
+	ruleName := acctest.RandomWithPrefix("TestRuleReferences")
+	poolName := acctest.RandomWithPrefix("TestRuleReferencesPool")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getRuleReferencesConfig(ruleName, poolName, false, `pool.use(\"NoSuchPool\"); resource.get(\"page.html\"); pool.select($other);`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_rule.references", "referenced_pools.#", "1"),
+					resource.TestCheckResourceAttr("vtm_rule.references", "referenced_pools.0", "NoSuchPool"),
+					resource.TestCheckResourceAttr("vtm_rule.references", "referenced_extra_files.0", "page.html"),
+					resource.TestCheckResourceAttr("vtm_rule.references", "referenced_rules.#", "0"),
+					resource.TestCheckResourceAttr("vtm_rule.references", "unresolved_references.0", "line 1, column 52: pool.select"),
+				),
+			},
+			{
+				Config:      getRuleReferencesConfig(ruleName, poolName, true, `pool.use(\"NoSuchPool\");`),
+				ExpectError: regexp.MustCompile("refers to objects that do not exist: vtm_pool 'NoSuchPool'"),
+			},
+			{
+				Config: getRuleReferencesConfig(ruleName, poolName, true, `pool.use(\"${vtm_pool.referenced.name}\");`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_rule.references", "referenced_pools.0", poolName),
+				),
+			},
+			{
+				Config:             getRuleReferencesConfig(ruleName, poolName, true, `pool.use(\"${vtm_pool.referenced.name}\");`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+// getRuleReferencesConfig returns a rule with content, which must be quoted
+// for HCL, alongside a pool.
+func getRuleReferencesConfig(ruleName, poolName string, check bool, content string) string {
+	return fmt.Sprintf(`
+        resource "vtm_pool" "referenced" {
+			name = "%s"
+		}
+
+		resource "vtm_rule" "references" {
+			name = "%s"
+			check_references = %t
+			content = "%s"
+		}`,
+		poolName, ruleName, check, content,
+	)
+}
@@ -5,12 +5,18 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// vtm_saml_trustedidp can take its entity ID, URL and certificate from the
+// identity provider's SAML 2.0 metadata, so that they follow the metadata
+// when the identity provider changes its signing certificate.
 func resourceSamlTrustedidp() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceSamlTrustedidpRead,
@@ -23,6 +29,8 @@ func resourceSamlTrustedidp() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceSamlTrustedidpCustomizeDiff,
+
 		Schema: getResourceSamlTrustedidpSchema(),
 	}
 }
@@ -47,14 +55,41 @@ func getResourceSamlTrustedidpSchema() map[string]*schema.Schema {
 		// The certificate used to verify Assertions signed by the identity
 		//  provider
 		"certificate": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:          schema.TypeString,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"metadata_xml", "metadata_file"},
 		},
 
 		// The entity id of the IDP
 		"entity_id": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"metadata_xml", "metadata_file"},
+		},
+
+		// The SAML 2.0 metadata of the identity provider, from which
+		//  "certificate", "entity_id" and "url" are set
+		"metadata_xml": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"metadata_file"},
+		},
+
+		// The path of a local file holding the SAML 2.0 metadata of the
+		//  identity provider, which is read again at each plan
+		"metadata_file": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"metadata_xml"},
+		},
+
+		// The certificate, in PEM or as base64 DER, that the metadata must
+		//  be signed with
+		"metadata_signing_certificate": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
 		},
 
 		// Whether or not SAML responses will be verified strictly
@@ -66,12 +101,91 @@ func getResourceSamlTrustedidpSchema() map[string]*schema.Schema {
 
 		// The IDP URL to which Authentication Requests should be sent
 		"url": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:          schema.TypeString,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"metadata_xml", "metadata_file"},
 		},
 	}
 }
 
+// getSamlTrustedidpMetadata reads the metadata of the identity provider, or
+// returns nil if the resource does not use metadata. get is the Get method
+// of the resource data or diff.
+func getSamlTrustedidpMetadata(get func(string) interface{}) (*samlMetadata, error) {
+	data := []byte(get("metadata_xml").(string))
+	if metadataFile := get("metadata_file").(string); metadataFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(metadataFile); err != nil {
+			return nil, fmt.Errorf("Failed to read metadata_file '%s': %v", metadataFile, err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return parseSamlMetadata(data, get("metadata_signing_certificate").(string), time.Now())
+}
+
+// samlTrustedidpMetadataValues returns the attributes set from metadata.
+func samlTrustedidpMetadataValues(metadata *samlMetadata) map[string]interface{} {
+	return map[string]interface{}{
+		"certificate": metadata.certificate,
+		"entity_id":   metadata.entityId,
+		"url":         metadata.url,
+	}
+}
+
+// resourceSamlTrustedidpCustomizeDiff plans changes to the attributes set
+// from the metadata. Metadata that is not known until apply reads as empty,
+// and is used when the resource is created or updated.
+func resourceSamlTrustedidpCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	metadata, err := getSamlTrustedidpMetadata(d.Get)
+	if err != nil || metadata == nil {
+		return err
+	}
+	values := samlTrustedidpMetadataValues(metadata)
+	for _, field := range sortedMapKeys(values) {
+		current := d.Get(field).(string)
+		if field == "certificate" && samlCertificatesEqual(current, metadata.certificate) {
+			continue
+		}
+		if current != values[field] {
+			if err := d.SetNew(field, values[field]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applySamlTrustedidpMetadata sets the attributes from the metadata, if it
+// is used, and checks that they are all set.
+func applySamlTrustedidpMetadata(d *schema.ResourceData) error {
+	metadata, err := getSamlTrustedidpMetadata(d.Get)
+	if err != nil {
+		return err
+	}
+	if metadata != nil {
+		values := samlTrustedidpMetadataValues(metadata)
+		for _, field := range sortedMapKeys(values) {
+			if field == "certificate" && samlCertificatesEqual(d.Get(field).(string), metadata.certificate) {
+				continue
+			}
+			d.Set(field, values[field])
+		}
+	}
+	missing := []string{}
+	for _, field := range []string{"certificate", "entity_id", "url"} {
+		if d.Get(field).(string) == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s must be set unless metadata_xml or metadata_file is set", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func resourceSamlTrustedidpRead(d *schema.ResourceData, tm interface{}) (readError error) {
 	objectName := d.Get("name").(string)
 	if objectName == "" {
@@ -127,6 +241,9 @@ func resourceSamlTrustedidpExists(d *schema.ResourceData, tm interface{}) (bool,
 
 func resourceSamlTrustedidpCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if err := applySamlTrustedidpMetadata(d); err != nil {
+		return fmt.Errorf("Error creating vtm_trustedidp '%s': %v", objectName, err)
+	}
 	object := tm.(*vtm.VirtualTrafficManager).NewSamlTrustedidp(objectName, d.Get("certificate").(string), d.Get("entity_id").(string), d.Get("url").(string))
 	resourceSamlTrustedidpObjectFieldAssignments(d, object)
 	_, applyErr := object.Apply()
@@ -140,6 +257,9 @@ func resourceSamlTrustedidpCreate(d *schema.ResourceData, tm interface{}) error
 
 func resourceSamlTrustedidpUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if err := applySamlTrustedidpMetadata(d); err != nil {
+		return fmt.Errorf("Error updating vtm_trustedidp '%s': %v", objectName, err)
+	}
 	object, err := tm.(*vtm.VirtualTrafficManager).GetSamlTrustedidp(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_trustedidp '%v': %v", objectName, err)
@@ -0,0 +1,80 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Creation of a vtm_saml_trustedidp object from signed metadata_xml,
+ *     which sets entity_id, url and certificate
+ *   - No changes are planned while the metadata is unchanged
+ *   - Changed metadata in metadata_file updates the derived fields
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestResourceSamlTrustedidpMetadata(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestSamlTrustedidpMetadata")
+	changed := strings.Replace(testSamlMetadata, "sso/redirect", "sso/elsewhere", 1)
+	unsigned := regexp.MustCompile(`<ds:Signature>.*</ds:Signature>`).ReplaceAllString(changed, "")
+
+	metadataFile, err := ioutil.TempFile("", "TestSamlTrustedidpMetadata")
+	if err != nil {
+		t.Fatalf("Creating temporary file failed: %v", err)
+	}
+	defer os.Remove(metadataFile.Name())
+	metadataFile.WriteString(unsigned)
+	metadataFile.Close()
+
+	signedConfig := getSamlTrustedidpMetadataConfig(objName, fmt.Sprintf(`
+			metadata_xml = <<EOF
+%sEOF
+			metadata_signing_certificate = <<EOF
+%sEOF`, testSamlMetadata, testSamlSigningCertificate))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckSamlTrustedidpDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: signedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSamlTrustedidpExists,
+					resource.TestCheckResourceAttr("vtm_saml_trustedidp.metadata", "entity_id", "https://idp.example.com/saml"),
+					resource.TestCheckResourceAttr("vtm_saml_trustedidp.metadata", "url", "https://idp.example.com/sso/redirect?a=1&b=2"),
+				),
+			},
+			{
+				Config:   signedConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: getSamlTrustedidpMetadataConfig(objName, fmt.Sprintf(`metadata_file = "%s"`, metadataFile.Name())),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_saml_trustedidp.metadata", "url", "https://idp.example.com/sso/elsewhere?a=1&b=2"),
+				),
+			},
+		},
+	})
+}
+
+func getSamlTrustedidpMetadataConfig(name, metadata string) string {
+	return fmt.Sprintf(`
+        resource "vtm_saml_trustedidp" "metadata" {
+			name = "%s"
+			%s
+		}`,
+		name, metadata,
+	)
+}
@@ -23,7 +23,9 @@ func resourceServiceLevelMonitor() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
-		Schema: getResourceServiceLevelMonitorSchema(),
+		CustomizeDiff: licensedFeatureCustomizeDiff("vtm_service_level_monitor"),
+
+		Schema: addLicensedFeatureSchema(getResourceServiceLevelMonitorSchema()),
 	}
 }
 
@@ -104,6 +106,7 @@ func resourceServiceLevelMonitorRead(d *schema.ResourceData, tm interface{}) (re
 	d.Set("serious_threshold", int(*object.Basic.SeriousThreshold))
 	lastAssignedField = "warning_threshold"
 	d.Set("warning_threshold", int(*object.Basic.WarningThreshold))
+	d.Set("license_warning", licenseWarning(tm, "vtm_service_level_monitor"))
 	d.SetId(objectName)
 	return nil
 }
@@ -23,6 +23,8 @@ func resourceSslServerKey() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceSslServerKeyCustomizeDiff,
+
 		Schema: getResourceSslServerKeySchema(),
 	}
 }
@@ -43,23 +45,74 @@ func getResourceSslServerKeySchema() map[string]*schema.Schema {
 			Required: true,
 		},
 
-		// Private key for certificate
+		// Private key for certificate. Generated by the provider when
+		//  key_algorithm is set.
 		"private": &schema.Schema{
-			Type:     schema.TypeString,
-			Required: true,
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
 			DiffSuppressFunc: suppressHashedDiffs("private"),
 		},
 
-		// Public certificate
+		// Public certificate. When the key is generated, this is the
+		//  self-signed certificate until a signed one is supplied.
 		"public": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
+			Computed: true,
 		},
 
-		// Certificate Signing Request for certificate
+		// Certificate Signing Request for certificate. Generated by the
+		//  provider when key_algorithm is set.
 		"request": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
+			Computed: true,
+		},
+
+		// Generate the private key and certificate signing request with
+		//  this algorithm, rather than taking them from "private" and
+		//  "request".
+		"key_algorithm": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      true,
+			ValidateFunc:  validation.StringInSlice([]string{"rsa", "ecdsa"}, false),
+			ConflictsWith: []string{"private", "request"},
+		},
+
+		// The size of the generated key in bits: 2048, 3072 or 4096 for
+		//  RSA (default 2048), 256, 384 or 521 for ECDSA (default 256).
+		"key_size": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			ForceNew: true,
+		},
+
+		// The subject of the generated request, for example
+		//  "CN=www.example.com, O=Example Ltd, C=GB".
+		"subject": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: true,
+		},
+
+		// Subject alternative names for the generated request: host
+		//  names, IP addresses or email addresses.
+		"sans": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			ForceNew: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// Also generate a self-signed certificate, valid for this number
+		//  of days, to use until "public" is set to a signed certificate.
+		"self_signed_validity": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IntAtLeast(0),
 		},
 	}
 }
@@ -117,6 +170,9 @@ func resourceSslServerKeyExists(d *schema.ResourceData, tm interface{}) (bool, e
 
 func resourceSslServerKeyCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if err := generateSslServerKey(d); err != nil {
+		return fmt.Errorf("Error creating vtm_server_key '%s': %v", objectName, err)
+	}
 	object := tm.(*vtm.VirtualTrafficManager).NewSslServerKey(objectName, d.Get("note").(string), d.Get("private").(string), d.Get("public").(string), d.Get("request").(string))
 	resourceSslServerKeyObjectFieldAssignments(d, object)
 	_, applyErr := object.Apply()
@@ -128,6 +184,60 @@ func resourceSslServerKeyCreate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
+// checkSslServerKeySources checks that a key is either generated, with a
+// self-signed certificate unless a signed one is supplied, or that both its
+// private key and certificate are supplied.
+func checkSslServerKeySources(algorithm, private, public string, selfSignedValidity int) error {
+	if algorithm == "" {
+		if private == "" || public == "" {
+			return fmt.Errorf("private and public must be set unless key_algorithm is set")
+		}
+		return nil
+	}
+	if public == "" && selfSignedValidity == 0 {
+		return fmt.Errorf("self_signed_validity must be set when no signed public certificate is supplied")
+	}
+	return nil
+}
+
+// resourceSslServerKeyCustomizeDiff checks the sources of a new key, or of
+// one that is replaced because key_algorithm changed, when the plan is made.
+// Values that are not known until apply read as empty strings.
+func resourceSslServerKeyCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	if d.Id() != "" && !d.HasChange("key_algorithm") {
+		return nil
+	}
+	return checkSslServerKeySources(d.Get("key_algorithm").(string), d.Get("private").(string), d.Get("public").(string), d.Get("self_signed_validity").(int))
+}
+
+// generateSslServerKey fills in the private key and request, and the
+// self-signed certificate if one is wanted, when key_algorithm is set.
+func generateSslServerKey(d *schema.ResourceData) error {
+	algorithm := d.Get("key_algorithm").(string)
+	if err := checkSslServerKeySources(algorithm, d.Get("private").(string), d.Get("public").(string), d.Get("self_signed_validity").(int)); err != nil {
+		return err
+	}
+	if algorithm == "" {
+		return nil
+	}
+	generated, err := generateSslKey(sslKeyOptions{
+		algorithm:    algorithm,
+		size:         d.Get("key_size").(int),
+		subject:      d.Get("subject").(string),
+		sans:         expandStringList(d.Get("sans").([]interface{})),
+		validityDays: d.Get("self_signed_validity").(int),
+	})
+	if err != nil {
+		return err
+	}
+	d.Set("private", generated.private)
+	d.Set("request", generated.request)
+	if d.Get("public").(string) == "" {
+		d.Set("public", generated.certificate)
+	}
+	return nil
+}
+
 func resourceSslServerKeyUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
 	object, err := tm.(*vtm.VirtualTrafficManager).GetSslServerKey(objectName)
@@ -146,9 +256,17 @@ func resourceSslServerKeyUpdate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceSslServerKeyObjectFieldAssignments(d *schema.ResourceData, object *vtm.SslServerKey) {
 	setString(&object.Basic.Note, d, "note")
-	setString(&object.Basic.Private, d, "private")
 	setString(&object.Basic.Public, d, "public")
-	setString(&object.Basic.Request, d, "request")
+
+	// The traffic manager only returns a hash of the private key, so the
+	// private key and request are only sent when they change rather than
+	// being written back from the state.
+	if d.HasChange("private") {
+		setString(&object.Basic.Private, d, "private")
+	}
+	if d.HasChange("request") {
+		setString(&object.Basic.Request, d, "request")
+	}
 }
 
 func resourceSslServerKeyDelete(d *schema.ResourceData, tm interface{}) error {
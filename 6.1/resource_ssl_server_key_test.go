@@ -6,11 +6,23 @@ package main
 /*
  * This test covers the following cases:
  *   - Creation and deletion of a vtm_ssl_server_key object with minimal configuration
+ *   - A key with public but not private failing the plan
+ *   - A key, request and self-signed certificate generated by the provider
+ *   - A generated key whose request is then signed, and public set to the
+ *     signed certificate
  */
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
@@ -26,6 +38,11 @@ func TestResourceSslServerKey(t *testing.T) {
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckSslServerKeyDestroy,
 		Steps: []resource.TestStep{
+			{
+				Config:      getPublicOnlySslServerKeyConfig(objName),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("private and public must be set unless key_algorithm is set"),
+			},
 			{
 				Config: getBasicSslServerKeyConfig(objName),
 				Check: resource.ComposeTestCheckFunc(
@@ -36,6 +53,108 @@ func TestResourceSslServerKey(t *testing.T) {
 	})
 }
 
+func TestResourceSslServerKeyGenerated(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestSslServerKeyGenerated")
+	signedFile, err := ioutil.TempFile("", "vtm_ssl_server_key")
+	if err != nil {
+		t.Fatalf("Fatal error: %+v", err)
+	}
+	signedFile.Close()
+	defer os.Remove(signedFile.Name())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckSslServerKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getGeneratedSslServerKeyConfig(objName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSslServerKeyExists,
+					resource.TestMatchResourceAttr("vtm_ssl_server_key.test_vtm_ssl_server_key", "request", regexp.MustCompile("BEGIN CERTIFICATE REQUEST")),
+					resource.TestMatchResourceAttr("vtm_ssl_server_key.test_vtm_ssl_server_key", "public", regexp.MustCompile("BEGIN CERTIFICATE")),
+					testAccSignSslServerKeyRequest(signedFile.Name()),
+				),
+			},
+			{
+				// The generated values must not cause a new key to be generated
+				Config:             getGeneratedSslServerKeyConfig(objName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				// Supplying the signed certificate must keep the generated
+				// private key and request
+				Config: getSignedSslServerKeyConfig(objName, signedFile.Name()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSslServerKeyExists,
+					resource.TestCheckResourceAttrPtr("vtm_ssl_server_key.test_vtm_ssl_server_key", "request", &generatedRequest),
+					resource.TestMatchResourceAttr("vtm_ssl_server_key.test_vtm_ssl_server_key", "public", regexp.MustCompile("BEGIN CERTIFICATE")),
+				),
+			},
+			{
+				Config:             getSignedSslServerKeyConfig(objName, signedFile.Name()),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+// generatedRequest is the request generated for TestResourceSslServerKeyGenerated,
+// recorded when it is signed.
+var generatedRequest string
+
+// testAccSignSslServerKeyRequest signs the generated request with a test CA,
+// and writes the signed certificate to path.
+func testAccSignSslServerKeyRequest(path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tfResource, ok := s.RootModule().Resources["vtm_ssl_server_key.test_vtm_ssl_server_key"]
+		if !ok {
+			return fmt.Errorf("vtm_ssl_server_key.test_vtm_ssl_server_key not found")
+		}
+		generatedRequest = tfResource.Primary.Attributes["request"]
+		requestBlock, _ := pem.Decode([]byte(generatedRequest))
+		if requestBlock == nil {
+			return fmt.Errorf("Generated request is not PEM encoded")
+		}
+		request, err := x509.ParseCertificateRequest(requestBlock.Bytes)
+		if err != nil {
+			return err
+		}
+
+		ca, err := generateSslKey(sslKeyOptions{algorithm: "ecdsa", subject: "CN=Test CA", validityDays: 1})
+		if err != nil {
+			return err
+		}
+		caKeyBlock, _ := pem.Decode([]byte(ca.private))
+		caKey, err := x509.ParseECPrivateKey(caKeyBlock.Bytes)
+		if err != nil {
+			return err
+		}
+		caCertificateBlock, _ := pem.Decode([]byte(ca.certificate))
+		caCertificate, err := x509.ParseCertificate(caCertificateBlock.Bytes)
+		if err != nil {
+			return err
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      request.Subject,
+			DNSNames:     request.DNSNames,
+			NotBefore:    time.Now().Add(-5 * time.Minute),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCertificate, request.PublicKey, caKey)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	}
+}
+
 func testAccCheckSslServerKeyExists(s *terraform.State) error {
 	for _, tfResource := range s.RootModule().Resources {
 		if tfResource.Type != "vtm_ssl_server_key" {
@@ -79,3 +198,64 @@ func getBasicSslServerKeyConfig(name string) string {
 		name,
 	)
 }
+
+func getPublicOnlySslServerKeyConfig(name string) string {
+	return fmt.Sprintf(`
+        resource "vtm_ssl_server_key" "test_vtm_ssl_server_key" {
+			name = "%s"
+			note = "TEST_TEXT"
+			public = "TEST_TEXT"
+        }`,
+		name,
+	)
+}
+
+func getGeneratedSslServerKeyConfig(name string) string {
+	return fmt.Sprintf(`
+        resource "vtm_ssl_server_key" "test_vtm_ssl_server_key" {
+			name = "%s"
+			note = "TEST_TEXT"
+			key_algorithm = "ecdsa"
+			subject = "CN=www.example.com, O=Example"
+			sans = ["www.example.com", "example.com"]
+			self_signed_validity = 30
+        }`,
+		name,
+	)
+}
+
+func getSignedSslServerKeyConfig(name, signedFile string) string {
+	return fmt.Sprintf(`
+        resource "vtm_ssl_server_key" "test_vtm_ssl_server_key" {
+			name = "%s"
+			note = "TEST_TEXT"
+			key_algorithm = "ecdsa"
+			subject = "CN=www.example.com, O=Example"
+			sans = ["www.example.com", "example.com"]
+			self_signed_validity = 30
+			public = "${file("%s")}"
+        }`,
+		name, signedFile,
+	)
+}
+
+func TestCheckSslServerKeySources(t *testing.T) {
+	for _, test := range []struct {
+		algorithm, private, public string
+		selfSignedValidity         int
+		valid                      bool
+	}{
+		{"", "key", "cert", 0, true},
+		{"", "", "cert", 0, false},
+		{"", "key", "", 0, false},
+		{"", "", "", 30, false},
+		{"ecdsa", "", "", 30, true},
+		{"ecdsa", "", "cert", 0, true},
+		{"rsa", "", "", 0, false},
+	} {
+		err := checkSslServerKeySources(test.algorithm, test.private, test.public, test.selfSignedValidity)
+		if (err == nil) != test.valid {
+			t.Errorf("Checking %+v gave %v", test, err)
+		}
+	}
+}
@@ -5,6 +5,8 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -23,6 +25,8 @@ func resourceSslTicketKey() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceSslTicketKeyCustomizeDiff,
+
 		Schema: getResourceSslTicketKeySchema(),
 	}
 }
@@ -54,7 +58,8 @@ func getResourceSslTicketKeySchema() map[string]*schema.Schema {
 		//  unique across the set of SSL ticket encryption keys.
 		"identifier": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
+			Computed: true,
 		},
 
 		// The session ticket encryption key, with each byte encoded as
@@ -63,14 +68,16 @@ func getResourceSslTicketKeySchema() map[string]*schema.Schema {
 		//  field for more details.
 		"key": &schema.Schema{
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
+			Computed: true,
 		},
 
 		// The latest time at which this key may be used to encrypt new
 		//  session tickets. Given as number of seconds since the epoch (1970-01-01T00:00:00Z).
 		"validity_end": &schema.Schema{
 			Type:         schema.TypeInt,
-			Required:     true,
+			Optional:     true,
+			Computed:     true,
 			ValidateFunc: validation.IntAtLeast(0),
 		},
 
@@ -78,9 +85,75 @@ func getResourceSslTicketKeySchema() map[string]*schema.Schema {
 		//  session tickets. Given as number of seconds since the epoch (1970-01-01T00:00:00Z).
 		"validity_start": &schema.Schema{
 			Type:         schema.TypeInt,
-			Required:     true,
+			Optional:     true,
+			Computed:     true,
 			ValidateFunc: validation.IntAtLeast(0),
 		},
+
+		// Generate and rotate the key automatically instead of taking a
+		//  static "identifier", "key" and validity period. Each key may
+		//  encrypt new session tickets for this many seconds. The keys are
+		//  named "<name>~<validity_start>" and only a hash of each is kept
+		//  in the state.
+		"rotation_interval": &schema.Schema{
+			Type:          schema.TypeInt,
+			Optional:      true,
+			ForceNew:      true,
+			ValidateFunc:  validation.IntAtLeast(0),
+			ConflictsWith: []string{"identifier", "key", "validity_end", "validity_start"},
+		},
+
+		// The number of seconds before the current key expires that the
+		//  next key is created, and after it expires that it is kept to
+		//  decrypt tickets it issued. Must be less than
+		//  "rotation_interval", and defaults to a quarter of it.
+		"overlap": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+
+		// The keys currently managed by a rotating vtm_ssl_ticket_key.
+		"managed_keys": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+
+					// The name of the vtm_ssl_ticket_key object.
+					"name": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+
+					// The key identifier.
+					"identifier": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+
+					// The SHA-256 hash of the hex encoded key.
+					"key_hash": &schema.Schema{
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+
+					// The earliest time at which the key may encrypt new
+					//  session tickets.
+					"validity_start": &schema.Schema{
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+
+					// The latest time at which the key may encrypt new
+					//  session tickets.
+					"validity_end": &schema.Schema{
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -90,6 +163,9 @@ func resourceSslTicketKeyRead(d *schema.ResourceData, tm interface{}) (readError
 		objectName = d.Id()
 		d.Set("name", objectName)
 	}
+	if d.Get("rotation_interval").(int) > 0 {
+		return rotateSslTicketKeys(d, tm.(*vtm.VirtualTrafficManager))
+	}
 	object, err := tm.(*vtm.VirtualTrafficManager).GetSslTicketKey(objectName)
 	if err != nil {
 		if err.ErrorId == "resource.not_found" {
@@ -127,6 +203,10 @@ func resourceSslTicketKeyExists(d *schema.ResourceData, tm interface{}) (bool, e
 	if objectName == "" {
 		objectName = d.Id()
 	}
+	if d.Get("rotation_interval").(int) > 0 {
+		// Missing keys are recreated when the resource is read.
+		return true, nil
+	}
 	_, err := tm.(*vtm.VirtualTrafficManager).GetSslTicketKey(objectName)
 	if err != nil {
 		if err.ErrorId == "resource.not_found" {
@@ -139,6 +219,15 @@ func resourceSslTicketKeyExists(d *schema.ResourceData, tm interface{}) (bool, e
 
 func resourceSslTicketKeyCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if d.Get("rotation_interval").(int) > 0 {
+		d.SetId(objectName)
+		return rotateSslTicketKeys(d, tm.(*vtm.VirtualTrafficManager))
+	}
+	for _, field := range []string{"identifier", "key", "validity_end", "validity_start"} {
+		if _, ok := d.GetOkExists(field); !ok {
+			return fmt.Errorf("Error creating vtm_ticket_key '%s': identifier, key, validity_end and validity_start must be set unless rotation_interval is set", objectName)
+		}
+	}
 	object := tm.(*vtm.VirtualTrafficManager).NewSslTicketKey(objectName, d.Get("identifier").(string), d.Get("key").(string), d.Get("validity_end").(int), d.Get("validity_start").(int))
 	resourceSslTicketKeyObjectFieldAssignments(d, object)
 	_, applyErr := object.Apply()
@@ -152,6 +241,9 @@ func resourceSslTicketKeyCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceSslTicketKeyUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if d.Get("rotation_interval").(int) > 0 {
+		return rotateSslTicketKeys(d, tm.(*vtm.VirtualTrafficManager))
+	}
 	object, err := tm.(*vtm.VirtualTrafficManager).GetSslTicketKey(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_ticket_key '%v': %v", objectName, err)
@@ -176,6 +268,9 @@ func resourceSslTicketKeyObjectFieldAssignments(d *schema.ResourceData, object *
 
 func resourceSslTicketKeyDelete(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	if d.Get("rotation_interval").(int) > 0 {
+		return deleteRotatedSslTicketKeys(d, tm.(*vtm.VirtualTrafficManager))
+	}
 	err := tm.(*vtm.VirtualTrafficManager).DeleteSslTicketKey(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to delete vtm_ticket_key '%v': %v", objectName, err.ErrorText)
@@ -183,3 +278,157 @@ func resourceSslTicketKeyDelete(d *schema.ResourceData, tm interface{}) error {
 	d.SetId("")
 	return nil
 }
+
+func sslTicketKeyOverlap(d *schema.ResourceData) int {
+	if overlap, ok := d.GetOkExists("overlap"); ok {
+		return overlap.(int)
+	}
+	return d.Get("rotation_interval").(int) / 4
+}
+
+// resourceSslTicketKeyCustomizeDiff checks the rotation settings when the
+// plan is made, as keys are also rotated when the resource is read.
+func resourceSslTicketKeyCustomizeDiff(d *schema.ResourceDiff, tm interface{}) error {
+	interval := d.Get("rotation_interval").(int)
+	if interval <= 0 {
+		return nil
+	}
+	overlap := interval / 4
+	if value, ok := d.GetOk("overlap"); ok {
+		overlap = value.(int)
+	}
+	return validateSslTicketKeyRotation(interval, overlap, d.Get("algorithm").(string))
+}
+
+// getRotatedSslTicketKeys reads the keys managed by a rotating
+// vtm_ssl_ticket_key: those named with the reserved separator, and those in
+// managedKeys, the resource's managed_keys, that still have the identifier
+// recorded there. The latter covers keys named "<name>_<validity_start>" by
+// earlier versions, without taking static keys of that form.
+func getRotatedSslTicketKeys(tm *vtm.VirtualTrafficManager, name string, managedKeys []interface{}) ([]sslTicketKeyWindow, error) {
+	objectList, err := tm.ListSslTicketKeys()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read vtm_ssl_ticket_key_list: %v", err.ErrorText)
+	}
+	managedIdentifiers := make(map[string]string)
+	for _, managedKey := range managedKeys {
+		managedKeyMap := managedKey.(map[string]interface{})
+		managedIdentifiers[managedKeyMap["name"].(string)] = managedKeyMap["identifier"].(string)
+	}
+	pattern := rotatedSslTicketKeyPattern(name)
+	keys := []sslTicketKeyWindow{}
+	for _, keyName := range *objectList {
+		managedIdentifier, managed := managedIdentifiers[keyName]
+		if !pattern.MatchString(keyName) && !managed {
+			continue
+		}
+		object, err := tm.GetSslTicketKey(keyName)
+		if err != nil {
+			if err.ErrorId == "resource.not_found" {
+				continue
+			}
+			return nil, fmt.Errorf("Failed to read vtm_ticket_key '%v': %v", keyName, err.ErrorText)
+		}
+		if !pattern.MatchString(keyName) && string(*object.Basic.Id) != managedIdentifier {
+			continue
+		}
+		keys = append(keys, sslTicketKeyWindow{
+			name:          keyName,
+			identifier:    string(*object.Basic.Id),
+			keyHash:       hashSslTicketKey(string(*object.Basic.Key)),
+			validityStart: int(*object.Basic.ValidityStart),
+			validityEnd:   int(*object.Basic.ValidityEnd),
+		})
+	}
+	return keys, nil
+}
+
+// rotateSslTicketKeys creates and deletes the keys of a rotating
+// vtm_ssl_ticket_key as planned by planSslTicketKeyRotation, and records the
+// resulting keys in the state.
+func rotateSslTicketKeys(d *schema.ResourceData, tm *vtm.VirtualTrafficManager) error {
+	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_ssl_ticket_key", objectName)
+	defer unlock()
+
+	// The settings are checked when the plan is made, but a state that was
+	// never planned, such as an imported one, is read first.
+	if err := validateSslTicketKeyRotation(d.Get("rotation_interval").(int), sslTicketKeyOverlap(d), d.Get("algorithm").(string)); err != nil {
+		return fmt.Errorf("Failed to rotate vtm_ticket_key '%s': %v", objectName, err)
+	}
+	existing, err := getRotatedSslTicketKeys(tm, objectName, d.Get("managed_keys").([]interface{}))
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	create, remove := planSslTicketKeyRotation(existing, objectName, d.Get("rotation_interval").(int), sslTicketKeyOverlap(d), now)
+
+	removed := make(map[string]bool)
+	for _, keyName := range remove {
+		if err := tm.DeleteSslTicketKey(keyName); err != nil && err.ErrorId != "resource.not_found" {
+			return fmt.Errorf("Failed to delete vtm_ticket_key '%v': %v", keyName, err.ErrorText)
+		}
+		removed[keyName] = true
+	}
+	keys := []sslTicketKeyWindow{}
+	for _, key := range existing {
+		if !removed[key.name] {
+			keys = append(keys, key)
+		}
+	}
+
+	algorithm := d.Get("algorithm").(string)
+	for _, key := range create {
+		identifier, keyHex, err := generateSslTicketKey(algorithm)
+		if err != nil {
+			return fmt.Errorf("Error creating vtm_ticket_key '%s': %v", key.name, err)
+		}
+		object := tm.NewSslTicketKey(key.name, identifier, keyHex, key.validityEnd, key.validityStart)
+		setString(&object.Basic.Algorithm, d, "algorithm")
+		if _, applyErr := object.Apply(); applyErr != nil {
+			info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
+			return fmt.Errorf("Error creating vtm_ticket_key '%s': %s %s", key.name, applyErr.ErrorText, info)
+		}
+		key.identifier = identifier
+		key.keyHash = hashSslTicketKey(keyHex)
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].validityStart < keys[j].validityStart })
+
+	managedKeys := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		managedKeys = append(managedKeys, map[string]interface{}{
+			"name":           key.name,
+			"identifier":     key.identifier,
+			"key_hash":       key.keyHash,
+			"validity_start": key.validityStart,
+			"validity_end":   key.validityEnd,
+		})
+		if key.validityStart <= now && now < key.validityEnd {
+			d.Set("identifier", key.identifier)
+			d.Set("validity_start", key.validityStart)
+			d.Set("validity_end", key.validityEnd)
+		}
+	}
+	d.Set("managed_keys", managedKeys)
+	d.SetId(objectName)
+	return nil
+}
+
+func deleteRotatedSslTicketKeys(d *schema.ResourceData, tm *vtm.VirtualTrafficManager) error {
+	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_ssl_ticket_key", objectName)
+	defer unlock()
+
+	keys, err := getRotatedSslTicketKeys(tm, objectName, d.Get("managed_keys").([]interface{}))
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := tm.DeleteSslTicketKey(key.name); err != nil && err.ErrorId != "resource.not_found" {
+			return fmt.Errorf("Failed to delete vtm_ticket_key '%v': %v", key.name, err.ErrorText)
+		}
+	}
+	d.SetId("")
+	return nil
+}
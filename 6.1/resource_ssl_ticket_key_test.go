@@ -6,10 +6,15 @@ package main
 /*
  * This test covers the following cases:
  *   - Creation and deletion of a vtm_ssl_ticket_key object with minimal configuration
+ *   - Creation and deletion of a rotating vtm_ssl_ticket_key, whose keys are
+ *     generated and stored in the state only as hashes
+ *   - Static keys named like the old rotated keys are left alone
+ *   - Invalid rotation settings are rejected by the plan
  */
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -36,6 +41,57 @@ func TestResourceSslTicketKey(t *testing.T) {
 	})
 }
 
+func TestResourceSslTicketKeyRotation(t *testing.T) {
+	objName := acctest.RandomWithPrefix("TestSslTicketKeyRotation")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRotatedSslTicketKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getRotatingSslTicketKeyConfig(objName, 600),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRotatedSslTicketKeyExists,
+					resource.TestCheckResourceAttr("vtm_ssl_ticket_key.test_vtm_ssl_ticket_key", "managed_keys.#", "1"),
+					resource.TestCheckResourceAttr("vtm_ssl_ticket_key.test_vtm_ssl_ticket_key", "key", ""),
+					resource.TestMatchResourceAttr("vtm_ssl_ticket_key.test_vtm_ssl_ticket_key", "managed_keys.0.key_hash", regexp.MustCompile("^[0-9a-f]{64}$")),
+					resource.TestMatchResourceAttr("vtm_ssl_ticket_key.test_vtm_ssl_ticket_key", "identifier", regexp.MustCompile("^[0-9a-f]{32}$")),
+				),
+			},
+			{
+				// The current key must not be rotated again
+				Config:             getRotatingSslTicketKeyConfig(objName, 600),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				// An expired static key whose name starts with the
+				// resource's name is not taken for a rotated key
+				PreConfig: func() {
+					tm, _ := getTestVtm()
+					tm.NewSslTicketKey(objName+"_10", "01234567890123456789012345678901", "abc123", 10, 10).Apply()
+				},
+				Config: getRotatingSslTicketKeyConfig(objName, 600),
+				Check: func(s *terraform.State) error {
+					tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+					if _, err := tm.GetSslTicketKey(objName + "_10"); err != nil {
+						return fmt.Errorf("Static SslTicketKey %s_10 was deleted", objName)
+					}
+					tm.DeleteSslTicketKey(objName + "_10")
+					return nil
+				},
+			},
+			{
+				// Invalid rotation settings fail the plan
+				Config:      getRotatingSslTicketKeyConfig(objName, 7200),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("overlap must be less than rotation_interval"),
+			},
+		},
+	})
+}
+
 func testAccCheckSslTicketKeyExists(s *terraform.State) error {
 	for _, tfResource := range s.RootModule().Resources {
 		if tfResource.Type != "vtm_ssl_ticket_key" {
@@ -66,6 +122,39 @@ func testAccCheckSslTicketKeyDestroy(s *terraform.State) error {
 	return nil
 }
 
+func testAccCheckRotatedSslTicketKeyExists(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_ssl_ticket_key" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["managed_keys.0.name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetSslTicketKey(objectName); err != nil {
+			return fmt.Errorf("SslTicketKey %s does not exist: %#v", objectName, err)
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckRotatedSslTicketKeyDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_ssl_ticket_key" {
+			continue
+		}
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		keys, err := getRotatedSslTicketKeys(tm, tfResource.Primary.Attributes["name"], nil)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			return fmt.Errorf("SslTicketKey %s still exists", keys[0].name)
+		}
+	}
+
+	return nil
+}
+
 func getBasicSslTicketKeyConfig(name string) string {
 	return fmt.Sprintf(`
         resource "vtm_ssl_ticket_key" "test_vtm_ssl_ticket_key" {
@@ -79,3 +168,14 @@ func getBasicSslTicketKeyConfig(name string) string {
 		name,
 	)
 }
+
+func getRotatingSslTicketKeyConfig(name string, overlap int) string {
+	return fmt.Sprintf(`
+        resource "vtm_ssl_ticket_key" "test_vtm_ssl_ticket_key" {
+			name = "%s"
+			rotation_interval = 7200
+			overlap = %d
+        }`,
+		name, overlap,
+	)
+}
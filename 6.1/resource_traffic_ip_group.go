@@ -12,6 +12,44 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// trafficIpGroupManagedAddresses returns the addresses listed in ipaddresses
+// or in either form of ip_mapping.
+func trafficIpGroupManagedAddresses(ipaddresses, ipMapping, ipMappingJson interface{}) map[string]bool {
+	managed := tableRowKeys(ipMapping, ipMappingJson, "ip", "")
+	if set, ok := ipaddresses.(*schema.Set); ok {
+		for _, address := range expandStringSet(set) {
+			managed[address] = true
+		}
+	}
+	return managed
+}
+
+// trafficIpGroupExternalAddresses returns the addresses and ip_mapping rows
+// that an ignore_external_addresses group should keep: those that neither
+// were nor will be managed by the group resource itself.
+func trafficIpGroupExternalAddresses(d *schema.ResourceData, object *vtm.TrafficIpGroup) ([]string, []interface{}) {
+	if d.Get("ignore_external_addresses") != true {
+		return nil, nil
+	}
+	managed := managedTableRowKeys(d, "ip_mapping", "ip", "")
+	oldAddresses, newAddresses := d.GetChange("ipaddresses")
+	for _, addresses := range []interface{}{oldAddresses, newAddresses} {
+		for address := range trafficIpGroupManagedAddresses(addresses, nil, nil) {
+			managed[address] = true
+		}
+	}
+
+	addresses := []string{}
+	if object.Basic.Ipaddresses != nil {
+		for _, address := range *object.Basic.Ipaddresses {
+			if !managed[address] {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses, unmanagedTableRows(object.Basic.IpMapping, "ip", "", managed)
+}
+
 func resourceTrafficIpGroup() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceTrafficIpGroupRead,
@@ -89,9 +127,10 @@ func getResourceTrafficIpGroupSchema() map[string]*schema.Schema {
 
 		// JSON representation of ip_mapping
 		"ip_mapping_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceTrafficIpGroupSchema, "ip_mapping"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceTrafficIpGroupSchema, "ip_mapping"),
 		},
 
 		// The IP addresses that belong to the Traffic IP group.
@@ -101,6 +140,16 @@ func getResourceTrafficIpGroupSchema() map[string]*schema.Schema {
 			Elem:     &schema.Schema{Type: schema.TypeString},
 		},
 
+		// Only manage the addresses listed in ipaddresses and ip_mapping,
+		//  leaving any others, such as those added by
+		//  vtm_traffic_ip_group_address resources, in place. This setting
+		//  is not stored on the vTM.
+		"ignore_external_addresses": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
 		// If set to "Yes" then all the traffic IPs will be raised on a
 		//  single traffic manager.  By default they're distributed across
 		//  all active traffic managers in the traffic IP group.
@@ -238,9 +287,14 @@ func resourceTrafficIpGroupRead(d *schema.ResourceData, tm interface{}) (readErr
 	d.Set("hash_source_port", bool(*object.Basic.HashSourcePort))
 	lastAssignedField = "ip_assignment_mode"
 	d.Set("ip_assignment_mode", string(*object.Basic.IpAssignmentMode))
+	managedAddresses := trafficIpGroupManagedAddresses(d.Get("ipaddresses"), d.Get("ip_mapping"), d.Get("ip_mapping_json"))
+	ignoreExternal := d.Get("ignore_external_addresses") == true
 	lastAssignedField = "ip_mapping"
 	ipMapping := make([]map[string]interface{}, 0, len(*object.Basic.IpMapping))
 	for _, item := range *object.Basic.IpMapping {
+		if ignoreExternal && (item.Ip == nil || !managedAddresses[string(*item.Ip)]) {
+			continue
+		}
 		itemTerraform := make(map[string]interface{})
 		if item.Ip != nil {
 			itemTerraform["ip"] = string(*item.Ip)
@@ -250,11 +304,20 @@ func resourceTrafficIpGroupRead(d *schema.ResourceData, tm interface{}) (readErr
 		}
 		ipMapping = append(ipMapping, itemTerraform)
 	}
-	d.Set("ip_mapping", ipMapping)
-	ipMappingJson, _ := json.Marshal(ipMapping)
-	d.Set("ip_mapping_json", ipMappingJson)
+	if _, ok := d.GetOk("ip_mapping_json"); ok {
+		ipMappingJson, _ := json.Marshal(ipMapping)
+		d.Set("ip_mapping_json", string(ipMappingJson))
+	} else {
+		d.Set("ip_mapping", ipMapping)
+	}
 	lastAssignedField = "ipaddresses"
-	d.Set("ipaddresses", []string(*object.Basic.Ipaddresses))
+	ipaddresses := make([]string, 0, len(*object.Basic.Ipaddresses))
+	for _, address := range *object.Basic.Ipaddresses {
+		if !ignoreExternal || managedAddresses[address] {
+			ipaddresses = append(ipaddresses, address)
+		}
+	}
+	d.Set("ipaddresses", ipaddresses)
 	lastAssignedField = "keeptogether"
 	d.Set("keeptogether", bool(*object.Basic.Keeptogether))
 	lastAssignedField = "location"
@@ -300,8 +363,12 @@ func resourceTrafficIpGroupExists(d *schema.ResourceData, tm interface{}) (bool,
 
 func resourceTrafficIpGroupCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_traffic_ip_group", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewTrafficIpGroup(objectName)
-	resourceTrafficIpGroupObjectFieldAssignments(d, object)
+	if err := resourceTrafficIpGroupObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_traffic_ip_group '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -313,11 +380,15 @@ func resourceTrafficIpGroupCreate(d *schema.ResourceData, tm interface{}) error
 
 func resourceTrafficIpGroupUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_traffic_ip_group", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetTrafficIpGroup(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_traffic_ip_group '%v': %v", objectName, err)
 	}
-	resourceTrafficIpGroupObjectFieldAssignments(d, object)
+	if err := resourceTrafficIpGroupObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_traffic_ip_group '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -327,7 +398,9 @@ func resourceTrafficIpGroupUpdate(d *schema.ResourceData, tm interface{}) error
 	return nil
 }
 
-func resourceTrafficIpGroupObjectFieldAssignments(d *schema.ResourceData, object *vtm.TrafficIpGroup) {
+func resourceTrafficIpGroupObjectFieldAssignments(d *schema.ResourceData, object *vtm.TrafficIpGroup) error {
+	externalAddresses, externalMappings := trafficIpGroupExternalAddresses(d, object)
+
 	setBool(&object.Basic.Enabled, d, "enabled")
 	setBool(&object.Basic.HashSourcePort, d, "hash_source_port")
 	setString(&object.Basic.IpAssignmentMode, d, "ip_assignment_mode")
@@ -365,7 +438,9 @@ func resourceTrafficIpGroupObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Basic.IpMapping = &vtm.TrafficIpGroupIpMappingTable{}
 	if ipMappingJson, ok := d.GetOk("ip_mapping_json"); ok {
-		_ = json.Unmarshal([]byte(ipMappingJson.(string)), object.Basic.IpMapping)
+		if err := json.Unmarshal([]byte(ipMappingJson.(string)), object.Basic.IpMapping); err != nil {
+			return fmt.Errorf("Invalid ip_mapping_json: %v", err)
+		}
 	} else if ipMapping, ok := d.GetOk("ip_mapping"); ok {
 		for _, row := range ipMapping.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -378,6 +453,11 @@ func resourceTrafficIpGroupObjectFieldAssignments(d *schema.ResourceData, object
 	} else {
 		d.Set("ip_mapping", make([]map[string]interface{}, 0, len(*object.Basic.IpMapping)))
 	}
+	*object.Basic.Ipaddresses = append(*object.Basic.Ipaddresses, externalAddresses...)
+	if err := addTableRows(&object.Basic.IpMapping, externalMappings, "ip", ""); err != nil {
+		return fmt.Errorf("Failed to keep external IP mappings: %v", err)
+	}
+	return nil
 }
 
 func resourceTrafficIpGroupDelete(d *schema.ResourceData, tm interface{}) error {
@@ -0,0 +1,238 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_traffic_ip_group_address manages one address of a traffic IP group,
+// together with its ip_mapping row, leaving the other addresses alone. A
+// vtm_traffic_ip_group resource for the same group should set
+// ignore_external_addresses so that it does not remove the address again.
+func resourceTrafficIpGroupAddress() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceTrafficIpGroupAddressRead,
+		Create: resourceTrafficIpGroupAddressCreate,
+		Update: resourceTrafficIpGroupAddressUpdate,
+		Delete: resourceTrafficIpGroupAddressDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceTrafficIpGroupAddressImport,
+		},
+
+		Schema: getResourceTrafficIpGroupAddressSchema(),
+	}
+}
+
+func getResourceTrafficIpGroupAddressSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the traffic IP group.
+		"traffic_ip_group": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The traffic IP address.
+		"ip_address": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The traffic manager that should host the address in
+		//  Single-Hosted mode. If not set, the address has no ip_mapping
+		//  row and is assigned automatically.
+		"traffic_manager": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
+
+func resourceTrafficIpGroupAddressRead(d *schema.ResourceData, tm interface{}) error {
+	groupName := d.Get("traffic_ip_group").(string)
+	address := d.Get("ip_address").(string)
+	object, err := tm.(*vtm.VirtualTrafficManager).GetTrafficIpGroup(groupName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_traffic_ip_group_address '%v/%v': %v", groupName, address, err.ErrorText)
+	}
+	addresses, mapping := trafficIpGroupAddressLists(object)
+	if indexOfString(addresses, address) < 0 {
+		d.SetId("")
+		return nil
+	}
+	trafficManager := ""
+	if index := findTrafficIpGroupMapping(mapping, address); index >= 0 && mapping[index].TrafficManager != nil {
+		trafficManager = string(*mapping[index].TrafficManager)
+	}
+	d.Set("traffic_manager", trafficManager)
+	d.SetId(groupName + "/" + address)
+	return nil
+}
+
+func resourceTrafficIpGroupAddressCreate(d *schema.ResourceData, tm interface{}) error {
+	groupName := d.Get("traffic_ip_group").(string)
+	address := d.Get("ip_address").(string)
+
+	// Only the first pass may find the address already present; later
+	// passes see the address this resource added itself.
+	written := false
+	err := modifyTrafficIpGroupAddresses(tm, groupName, func(addresses []string, mapping vtm.TrafficIpGroupIpMappingTable) ([]string, vtm.TrafficIpGroupIpMappingTable, error) {
+		if !written && indexOfString(addresses, address) >= 0 {
+			return nil, nil, fmt.Errorf("address already belongs to the group; import it with the ID '%s/%s'", groupName, address)
+		}
+		written = true
+		return setTrafficIpGroupAddress(addresses, mapping, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating vtm_traffic_ip_group_address '%s/%s': %v", groupName, address, err)
+	}
+	d.SetId(groupName + "/" + address)
+	return nil
+}
+
+func resourceTrafficIpGroupAddressUpdate(d *schema.ResourceData, tm interface{}) error {
+	groupName := d.Get("traffic_ip_group").(string)
+	address := d.Get("ip_address").(string)
+	err := modifyTrafficIpGroupAddresses(tm, groupName, func(addresses []string, mapping vtm.TrafficIpGroupIpMappingTable) ([]string, vtm.TrafficIpGroupIpMappingTable, error) {
+		return setTrafficIpGroupAddress(addresses, mapping, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating vtm_traffic_ip_group_address '%s/%s': %v", groupName, address, err)
+	}
+	return nil
+}
+
+func resourceTrafficIpGroupAddressDelete(d *schema.ResourceData, tm interface{}) error {
+	groupName := d.Get("traffic_ip_group").(string)
+	address := d.Get("ip_address").(string)
+	err := modifyTrafficIpGroupAddresses(tm, groupName, func(addresses []string, mapping vtm.TrafficIpGroupIpMappingTable) ([]string, vtm.TrafficIpGroupIpMappingTable, error) {
+		return removeString(addresses, address), removeTrafficIpGroupMapping(mapping, address), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete vtm_traffic_ip_group_address '%v/%v': %v", groupName, address, err)
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceTrafficIpGroupAddressImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	separator := strings.LastIndex(d.Id(), "/")
+	if separator <= 0 || separator == len(d.Id())-1 {
+		return nil, fmt.Errorf("Invalid vtm_traffic_ip_group_address ID '%s', expected '<traffic_ip_group>/<ip_address>'", d.Id())
+	}
+	d.Set("traffic_ip_group", d.Id()[:separator])
+	d.Set("ip_address", d.Id()[separator+1:])
+	return []*schema.ResourceData{d}, nil
+}
+
+// findTrafficIpGroupMapping returns the index of an address's row in an
+// ip_mapping table, or -1.
+func findTrafficIpGroupMapping(mapping vtm.TrafficIpGroupIpMappingTable, address string) int {
+	for i, row := range mapping {
+		if row.Ip != nil && string(*row.Ip) == address {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeTrafficIpGroupMapping returns a copy of the ip_mapping table without
+// the address's row.
+func removeTrafficIpGroupMapping(mapping vtm.TrafficIpGroupIpMappingTable, address string) vtm.TrafficIpGroupIpMappingTable {
+	updated := append(vtm.TrafficIpGroupIpMappingTable{}, mapping...)
+	if index := findTrafficIpGroupMapping(updated, address); index >= 0 {
+		updated = append(updated[:index:index], updated[index+1:]...)
+	}
+	return updated
+}
+
+// setTrafficIpGroupAddress adds the resource's address to the group, and
+// sets or removes its ip_mapping row. An existing row is changed in place,
+// so the order and other fields of the table are kept.
+func setTrafficIpGroupAddress(addresses []string, mapping vtm.TrafficIpGroupIpMappingTable, d *schema.ResourceData) ([]string, vtm.TrafficIpGroupIpMappingTable, error) {
+	address := d.Get("ip_address").(string)
+	if indexOfString(addresses, address) < 0 {
+		addresses = append(append([]string{}, addresses...), address)
+	}
+	trafficManager := d.Get("traffic_manager").(string)
+	if trafficManager == "" {
+		return addresses, removeTrafficIpGroupMapping(mapping, address), nil
+	}
+	updated := append(vtm.TrafficIpGroupIpMappingTable{}, mapping...)
+	if index := findTrafficIpGroupMapping(updated, address); index >= 0 {
+		row := updated[index]
+		row.TrafficManager = getStringAddr(trafficManager)
+		updated[index] = row
+	} else {
+		updated = append(updated, vtm.TrafficIpGroupIpMapping{
+			Ip:             getStringAddr(address),
+			TrafficManager: getStringAddr(trafficManager),
+		})
+	}
+	return addresses, updated, nil
+}
+
+// trafficIpGroupAddressLists returns the group's addresses and its
+// ip_mapping table.
+func trafficIpGroupAddressLists(object *vtm.TrafficIpGroup) ([]string, vtm.TrafficIpGroupIpMappingTable) {
+	addresses := []string{}
+	if object.Basic.Ipaddresses != nil {
+		addresses = append(addresses, *object.Basic.Ipaddresses...)
+	}
+	mapping := vtm.TrafficIpGroupIpMappingTable{}
+	if object.Basic.IpMapping != nil {
+		mapping = append(mapping, *object.Basic.IpMapping...)
+	}
+	return addresses, mapping
+}
+
+// modifyTrafficIpGroupAddresses applies update to the group's current
+// addresses and ip_mapping, and writes the group back if that changed
+// anything.
+func modifyTrafficIpGroupAddresses(tm interface{}, groupName string, update func([]string, vtm.TrafficIpGroupIpMappingTable) ([]string, vtm.TrafficIpGroupIpMappingTable, error)) error {
+	read := func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetTrafficIpGroup(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &object.Basic, func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	}
+	return modifyConfigObjectField(tm, "vtm_traffic_ip_group", groupName, read, nil, func(value interface{}) (interface{}, error) {
+		basic, _ := value.(map[string]interface{})
+		addresses := jsonStringList(basic["ipaddresses"])
+		mapping := vtm.TrafficIpGroupIpMappingTable{}
+		if err := setJsonValue(&mapping, jsonRows(basic["ip_mapping"])); err != nil {
+			return nil, err
+		}
+		updatedAddresses, updatedMapping, err := update(addresses, mapping)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.DeepEqual(updatedAddresses, addresses) && reflect.DeepEqual(updatedMapping, mapping) {
+			return value, nil
+		}
+		basic["ipaddresses"] = updatedAddresses
+		basic["ip_mapping"] = updatedMapping
+		return basic, nil
+	})
+}
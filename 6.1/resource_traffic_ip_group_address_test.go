@@ -0,0 +1,152 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Addresses added to a traffic IP group by vtm_traffic_ip_group_address
+ *   - A vtm_traffic_ip_group with ignore_external_addresses keeps them
+ *   - The ip_mapping row follows traffic_manager
+ *   - Other ip_mapping rows keep their order and fields
+ *   - Deleting an address resource only removes its own address
+ */
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceTrafficIpGroupAddress(t *testing.T) {
+	groupName := acctest.RandomWithPrefix("TestTipAddress")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTrafficIpGroupAddressDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getTrafficIpGroupAddressConfig(groupName, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_traffic_ip_group.test_vtm_traffic_ip_group", "ipaddresses.#", "1"),
+					resource.TestCheckResourceAttr("vtm_traffic_ip_group_address.service1", "id", groupName+"/10.20.0.11"),
+					testAccCheckTrafficIpGroupAddresses(groupName, []string{"10.20.0.10", "10.20.0.11", "10.20.0.12"}),
+				),
+			},
+			{
+				// The group resource must not report the other addresses as drift
+				Config:             getTrafficIpGroupAddressConfig(groupName, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				Config: getTrafficIpGroupAddressConfig(groupName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTrafficIpGroupAddresses(groupName, []string{"10.20.0.10", "10.20.0.11"}),
+				),
+			},
+			{
+				ResourceName:      "vtm_traffic_ip_group_address.service1",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestSetTrafficIpGroupAddress(t *testing.T) {
+	mapping := vtm.TrafficIpGroupIpMappingTable{
+		vtm.TrafficIpGroupIpMapping{Ip: getStringAddr("10.20.0.12"), TrafficManager: getStringAddr("tm2")},
+		vtm.TrafficIpGroupIpMapping{Ip: getStringAddr("10.20.0.13")},
+		vtm.TrafficIpGroupIpMapping{Ip: getStringAddr("10.20.0.11"), TrafficManager: getStringAddr("tm1")},
+	}
+	d := schema.TestResourceDataRaw(t, getResourceTrafficIpGroupAddressSchema(), map[string]interface{}{
+		"traffic_ip_group": "group",
+		"ip_address":       "10.20.0.12",
+		"traffic_manager":  "tm3",
+	})
+	addresses, updated, err := setTrafficIpGroupAddress([]string{"10.20.0.11", "10.20.0.12"}, mapping, d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(addresses) != "[10.20.0.11 10.20.0.12]" {
+		t.Errorf("Unexpected addresses %v", addresses)
+	}
+	if len(updated) != 3 || *updated[0].Ip != "10.20.0.12" || *updated[0].TrafficManager != "tm3" ||
+		updated[1].TrafficManager != nil || *updated[2].TrafficManager != "tm1" {
+		t.Errorf("Expected the row to be changed in place, got %#v", updated)
+	}
+	if *mapping[0].TrafficManager != "tm2" {
+		t.Errorf("The original table was changed")
+	}
+
+	d.Set("traffic_manager", "")
+	_, updated, _ = setTrafficIpGroupAddress(addresses, mapping, d)
+	if len(updated) != 2 || *updated[0].Ip != "10.20.0.13" || *updated[1].Ip != "10.20.0.11" {
+		t.Errorf("Expected only the row to be removed, got %#v", updated)
+	}
+}
+
+func testAccCheckTrafficIpGroupAddresses(groupName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		group, err := tm.GetTrafficIpGroup(groupName)
+		if err != nil {
+			return fmt.Errorf("TrafficIpGroup %s does not exist: %#v", groupName, err)
+		}
+		addresses := append([]string{}, *group.Basic.Ipaddresses...)
+		sort.Strings(addresses)
+		if fmt.Sprint(addresses) != fmt.Sprint(expected) {
+			return fmt.Errorf("TrafficIpGroup %s has addresses %v, expected %v", groupName, addresses, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckTrafficIpGroupAddressDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_traffic_ip_group" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetTrafficIpGroup(objectName); err == nil {
+			return fmt.Errorf("TrafficIpGroup %s still exists", objectName)
+		}
+	}
+
+	return nil
+}
+
+func getTrafficIpGroupAddressConfig(groupName string, withSecondAddress bool) string {
+	config := fmt.Sprintf(`
+        resource "vtm_traffic_ip_group" "test_vtm_traffic_ip_group" {
+			name = "%s"
+			enabled = false
+			ignore_external_addresses = true
+			ipaddresses = ["10.20.0.10"]
+		}
+
+		resource "vtm_traffic_ip_group_address" "service1" {
+			traffic_ip_group = "${vtm_traffic_ip_group.test_vtm_traffic_ip_group.name}"
+			ip_address = "10.20.0.11"
+		}`,
+		groupName,
+	)
+	if withSecondAddress {
+		config += `
+
+		resource "vtm_traffic_ip_group_address" "service2" {
+			traffic_ip_group = "${vtm_traffic_ip_group.test_vtm_traffic_ip_group.name}"
+			ip_address = "10.20.0.12"
+		}`
+	}
+	return config
+}
@@ -83,9 +83,10 @@ func getResourceTrafficManagerSchema() map[string]*schema.Schema {
 
 		// JSON representation of appliance_card
 		"appliance_card_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceTrafficManagerSchema, "appliance_card"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceTrafficManagerSchema, "appliance_card"),
 		},
 
 		// Custom kernel parameters applied by the user with sysctl interface
@@ -118,9 +119,10 @@ func getResourceTrafficManagerSchema() map[string]*schema.Schema {
 
 		// JSON representation of appliance_sysctl
 		"appliance_sysctl_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceTrafficManagerSchema, "appliance_sysctl"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceTrafficManagerSchema, "appliance_sysctl"),
 		},
 
 		// The Application Firewall Authentication Server IP.
@@ -220,9 +222,10 @@ func getResourceTrafficManagerSchema() map[string]*schema.Schema {
 
 		// JSON representation of trafficip
 		"trafficip_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceTrafficManagerSchema, "trafficip"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceTrafficManagerSchema, "trafficip"),
 		},
 
 		// The Application Firewall Updater IP.
@@ -905,9 +908,12 @@ func resourceTrafficManagerRead(d *schema.ResourceData, tm interface{}) (readErr
 		}
 		applianceCard = append(applianceCard, itemTerraform)
 	}
-	d.Set("appliance_card", applianceCard)
-	applianceCardJson, _ := json.Marshal(applianceCard)
-	d.Set("appliance_card_json", applianceCardJson)
+	if _, ok := d.GetOk("appliance_card_json"); ok {
+		applianceCardJson, _ := json.Marshal(applianceCard)
+		d.Set("appliance_card_json", string(applianceCardJson))
+	} else {
+		d.Set("appliance_card", applianceCard)
+	}
 	lastAssignedField = "appliance_sysctl"
 	applianceSysctl := make([]map[string]interface{}, 0, len(*object.Basic.ApplianceSysctl))
 	for _, item := range *object.Basic.ApplianceSysctl {
@@ -923,9 +929,12 @@ func resourceTrafficManagerRead(d *schema.ResourceData, tm interface{}) (readErr
 		}
 		applianceSysctl = append(applianceSysctl, itemTerraform)
 	}
-	d.Set("appliance_sysctl", applianceSysctl)
-	applianceSysctlJson, _ := json.Marshal(applianceSysctl)
-	d.Set("appliance_sysctl_json", applianceSysctlJson)
+	if _, ok := d.GetOk("appliance_sysctl_json"); ok {
+		applianceSysctlJson, _ := json.Marshal(applianceSysctl)
+		d.Set("appliance_sysctl_json", string(applianceSysctlJson))
+	} else {
+		d.Set("appliance_sysctl", applianceSysctl)
+	}
 	lastAssignedField = "authenticationserverip"
 	d.Set("authenticationserverip", string(*object.Basic.Authenticationserverip))
 	lastAssignedField = "cloud_platform"
@@ -956,9 +965,12 @@ func resourceTrafficManagerRead(d *schema.ResourceData, tm interface{}) (readErr
 		}
 		trafficip = append(trafficip, itemTerraform)
 	}
-	d.Set("trafficip", trafficip)
-	trafficipJson, _ := json.Marshal(trafficip)
-	d.Set("trafficip_json", trafficipJson)
+	if _, ok := d.GetOk("trafficip_json"); ok {
+		trafficipJson, _ := json.Marshal(trafficip)
+		d.Set("trafficip_json", string(trafficipJson))
+	} else {
+		d.Set("trafficip", trafficip)
+	}
 	lastAssignedField = "updaterip"
 	d.Set("updaterip", string(*object.Basic.Updaterip))
 	lastAssignedField = "appliance_disable_kpti"
@@ -1173,7 +1185,9 @@ func resourceTrafficManagerUpdate(d *schema.ResourceData, tm interface{}) error
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_traffic_manager '%v': %v", objectName, err)
 	}
-	resourceTrafficManagerObjectFieldAssignments(d, object)
+	if err := resourceTrafficManagerObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_traffic_manager '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -1183,7 +1197,7 @@ func resourceTrafficManagerUpdate(d *schema.ResourceData, tm interface{}) error
 	return nil
 }
 
-func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object *vtm.TrafficManager) {
+func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object *vtm.TrafficManager) error {
 	setString(&object.Basic.Adminmasterxmlip, d, "adminmasterxmlip")
 	setString(&object.Basic.Adminslavexmlip, d, "adminslavexmlip")
 	setString(&object.Basic.Authenticationserverip, d, "authenticationserverip")
@@ -1205,7 +1219,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Appliance.Hosts = &vtm.TrafficManagerHostsTable{}
 	if applianceHostsJson, ok := d.GetOk("appliance_hosts_json"); ok {
-		_ = json.Unmarshal([]byte(applianceHostsJson.(string)), object.Appliance.Hosts)
+		if err := json.Unmarshal([]byte(applianceHostsJson.(string)), object.Appliance.Hosts); err != nil {
+			return fmt.Errorf("Invalid appliance_hosts_json: %v", err)
+		}
 	} else if applianceHosts, ok := d.GetOk("appliance_hosts"); ok {
 		for _, row := range applianceHosts.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1221,7 +1237,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Appliance.If = &vtm.TrafficManagerIfTable{}
 	if applianceIfJson, ok := d.GetOk("appliance_if_json"); ok {
-		_ = json.Unmarshal([]byte(applianceIfJson.(string)), object.Appliance.If)
+		if err := json.Unmarshal([]byte(applianceIfJson.(string)), object.Appliance.If); err != nil {
+			return fmt.Errorf("Invalid appliance_if_json: %v", err)
+		}
 	} else if applianceIf, ok := d.GetOk("appliance_if"); ok {
 		for _, row := range applianceIf.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1243,7 +1261,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Appliance.Ip = &vtm.TrafficManagerIpTable{}
 	if applianceIpJson, ok := d.GetOk("appliance_ip_json"); ok {
-		_ = json.Unmarshal([]byte(applianceIpJson.(string)), object.Appliance.Ip)
+		if err := json.Unmarshal([]byte(applianceIpJson.(string)), object.Appliance.Ip); err != nil {
+			return fmt.Errorf("Invalid appliance_ip_json: %v", err)
+		}
 	} else if applianceIp, ok := d.GetOk("appliance_ip"); ok {
 		for _, row := range applianceIp.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1290,7 +1310,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Appliance.Routes = &vtm.TrafficManagerRoutesTable{}
 	if applianceRoutesJson, ok := d.GetOk("appliance_routes_json"); ok {
-		_ = json.Unmarshal([]byte(applianceRoutesJson.(string)), object.Appliance.Routes)
+		if err := json.Unmarshal([]byte(applianceRoutesJson.(string)), object.Appliance.Routes); err != nil {
+			return fmt.Errorf("Invalid appliance_routes_json: %v", err)
+		}
 	} else if applianceRoutes, ok := d.GetOk("appliance_routes"); ok {
 		for _, row := range applianceRoutes.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1326,7 +1348,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Basic.ApplianceCard = &vtm.TrafficManagerApplianceCardTable{}
 	if applianceCardJson, ok := d.GetOk("appliance_card_json"); ok {
-		_ = json.Unmarshal([]byte(applianceCardJson.(string)), object.Basic.ApplianceCard)
+		if err := json.Unmarshal([]byte(applianceCardJson.(string)), object.Basic.ApplianceCard); err != nil {
+			return fmt.Errorf("Invalid appliance_card_json: %v", err)
+		}
 	} else if applianceCard, ok := d.GetOk("appliance_card"); ok {
 		for _, row := range applianceCard.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1343,7 +1367,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Basic.ApplianceSysctl = &vtm.TrafficManagerApplianceSysctlTable{}
 	if applianceSysctlJson, ok := d.GetOk("appliance_sysctl_json"); ok {
-		_ = json.Unmarshal([]byte(applianceSysctlJson.(string)), object.Basic.ApplianceSysctl)
+		if err := json.Unmarshal([]byte(applianceSysctlJson.(string)), object.Basic.ApplianceSysctl); err != nil {
+			return fmt.Errorf("Invalid appliance_sysctl_json: %v", err)
+		}
 	} else if applianceSysctl, ok := d.GetOk("appliance_sysctl"); ok {
 		for _, row := range applianceSysctl.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1360,7 +1386,9 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Basic.Trafficip = &vtm.TrafficManagerTrafficipTable{}
 	if trafficipJson, ok := d.GetOk("trafficip_json"); ok {
-		_ = json.Unmarshal([]byte(trafficipJson.(string)), object.Basic.Trafficip)
+		if err := json.Unmarshal([]byte(trafficipJson.(string)), object.Basic.Trafficip); err != nil {
+			return fmt.Errorf("Invalid trafficip_json: %v", err)
+		}
 	} else if trafficip, ok := d.GetOk("trafficip"); ok {
 		for _, row := range trafficip.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -1424,6 +1452,7 @@ func resourceTrafficManagerObjectFieldAssignments(d *schema.ResourceData, object
 	setString(&object.Snmp.PrivPassword, d, "snmp_priv_password")
 	setString(&object.Snmp.SecurityLevel, d, "snmp_security_level")
 	setString(&object.Snmp.Username, d, "snmp_username")
+	return nil
 }
 
 func resourceTrafficManagerDelete(d *schema.ResourceData, tm interface{}) error {
@@ -44,6 +44,16 @@ func getResourceUserGroupSchema() map[string]*schema.Schema {
 			Optional: true,
 		},
 
+		// Only manage the permissions listed in permissions or
+		//  permissions_json, leaving any others, such as those added by
+		//  vtm_user_group_permission resources, in place. This setting is
+		//  not stored on the vTM.
+		"ignore_external_permissions": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
 		// Members of this group must renew their passwords after this number
 		//  of days. To disable password expiry for the group set this to
 		//  "0" (zero). Note that this setting applies only to local users.
@@ -79,9 +89,10 @@ func getResourceUserGroupSchema() map[string]*schema.Schema {
 
 		// JSON representation of permissions
 		"permissions_json": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.ValidateJsonString,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateTableJson(getResourceUserGroupSchema, "permissions"),
+			DiffSuppressFunc: suppressEquivalentTableJsonDiffs(getResourceUserGroupSchema, "permissions"),
 		},
 
 		// Inactive UI sessions will timeout after this number of seconds.
@@ -126,7 +137,11 @@ func resourceUserGroupRead(d *schema.ResourceData, tm interface{}) (readError er
 	d.Set("password_expire_time", int(*object.Basic.PasswordExpireTime))
 	lastAssignedField = "permissions"
 	permissions := make([]map[string]interface{}, 0, len(*object.Basic.Permissions))
+	managedPermissions := tableRowKeys(d.Get("permissions"), d.Get("permissions_json"), "name", "")
 	for _, item := range *object.Basic.Permissions {
+		if d.Get("ignore_external_permissions") == true && (item.Name == nil || !managedPermissions[string(*item.Name)]) {
+			continue
+		}
 		itemTerraform := make(map[string]interface{})
 		if item.AccessLevel != nil {
 			itemTerraform["access_level"] = string(*item.AccessLevel)
@@ -136,9 +151,12 @@ func resourceUserGroupRead(d *schema.ResourceData, tm interface{}) (readError er
 		}
 		permissions = append(permissions, itemTerraform)
 	}
-	d.Set("permissions", permissions)
-	permissionsJson, _ := json.Marshal(permissions)
-	d.Set("permissions_json", permissionsJson)
+	if _, ok := d.GetOk("permissions_json"); ok {
+		permissionsJson, _ := json.Marshal(permissions)
+		d.Set("permissions_json", string(permissionsJson))
+	} else {
+		d.Set("permissions", permissions)
+	}
 	lastAssignedField = "timeout"
 	d.Set("timeout", int(*object.Basic.Timeout))
 	d.SetId(objectName)
@@ -162,8 +180,12 @@ func resourceUserGroupExists(d *schema.ResourceData, tm interface{}) (bool, erro
 
 func resourceUserGroupCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_user_group", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewUserGroup(objectName)
-	resourceUserGroupObjectFieldAssignments(d, object)
+	if err := resourceUserGroupObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_user_group '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -175,11 +197,15 @@ func resourceUserGroupCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceUserGroupUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_user_group", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetUserGroup(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_user_group '%v': %v", objectName, err)
 	}
-	resourceUserGroupObjectFieldAssignments(d, object)
+	if err := resourceUserGroupObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_user_group '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -189,14 +215,17 @@ func resourceUserGroupUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceUserGroupObjectFieldAssignments(d *schema.ResourceData, object *vtm.UserGroup) {
+func resourceUserGroupObjectFieldAssignments(d *schema.ResourceData, object *vtm.UserGroup) error {
 	setString(&object.Basic.Description, d, "description")
 	setInt(&object.Basic.PasswordExpireTime, d, "password_expire_time")
 	setInt(&object.Basic.Timeout, d, "timeout")
 
+	externalPermissions := externalTableRows(d, "ignore_external_permissions", "permissions", "name", "", object.Basic.Permissions)
 	object.Basic.Permissions = &vtm.UserGroupPermissionsTable{}
 	if permissionsJson, ok := d.GetOk("permissions_json"); ok {
-		_ = json.Unmarshal([]byte(permissionsJson.(string)), object.Basic.Permissions)
+		if err := json.Unmarshal([]byte(permissionsJson.(string)), object.Basic.Permissions); err != nil {
+			return fmt.Errorf("Invalid permissions_json: %v", err)
+		}
 	} else if permissions, ok := d.GetOk("permissions"); ok {
 		for _, row := range permissions.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -209,6 +238,10 @@ func resourceUserGroupObjectFieldAssignments(d *schema.ResourceData, object *vtm
 	} else {
 		d.Set("permissions", make([]map[string]interface{}, 0, len(*object.Basic.Permissions)))
 	}
+	if err := addTableRows(&object.Basic.Permissions, externalPermissions, "name", ""); err != nil {
+		return fmt.Errorf("Failed to keep external permissions: %v", err)
+	}
+	return nil
 }
 
 func resourceUserGroupDelete(d *schema.ResourceData, tm interface{}) error {
@@ -0,0 +1,76 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// vtm_user_group_permission manages one row of a user group's permissions
+// table, leaving the other rows alone. A vtm_user_group resource for the
+// same group should set ignore_external_permissions so that it does not
+// remove the row again.
+func resourceUserGroupPermission() *schema.Resource {
+	return &schema.Resource{
+		Read:   userGroupPermissionAttachment.Read,
+		Create: userGroupPermissionAttachment.Create,
+		Update: userGroupPermissionAttachment.Update,
+		Delete: userGroupPermissionAttachment.Delete,
+
+		Importer: &schema.ResourceImporter{
+			State: userGroupPermissionAttachment.Import,
+		},
+
+		Schema: getResourceUserGroupPermissionSchema(),
+	}
+}
+
+func getResourceUserGroupPermissionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the user group.
+		"group": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The name of the permission, for example "Pools" or
+		//  "Virtual_Servers".
+		"name": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The access the group has: "none", "ro" or "full".
+		"access_level": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringInSlice([]string{"none", "ro", "full"}, false),
+		},
+	}
+}
+
+var userGroupPermissionAttachment = &tableRowAttachment{
+	resourceType: "vtm_user_group_permission",
+	objectType:   "vtm_user_group",
+	objectAttr:   "group",
+	keyAttr:      "name",
+	schema:       getResourceUserGroupPermissionSchema,
+	read: func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetUserGroup(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &object.Basic.Permissions, func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	},
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - A permission added to a group owned by a vtm_user_group resource
+ *   - A vtm_user_group with ignore_external_permissions keeps it
+ *   - Changing the access level of the permission
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceUserGroupPermission(t *testing.T) {
+	groupName := acctest.RandomWithPrefix("TestGroupPermission")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckUserGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getUserGroupPermissionConfig(groupName, "ro"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_user_group.test_vtm_user_group", "permissions.#", "1"),
+					resource.TestCheckResourceAttr("vtm_user_group_permission.pools", "id", groupName+"/Pools"),
+					testAccCheckUserGroupPermission(groupName, "Pools", "ro"),
+				),
+			},
+			{
+				Config: getUserGroupPermissionConfig(groupName, "full"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserGroupPermission(groupName, "Pools", "full"),
+					testAccCheckUserGroupPermission(groupName, "Virtual_Servers", "ro"),
+				),
+			},
+			{
+				Config:             getUserGroupPermissionConfig(groupName, "full"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				ResourceName:      "vtm_user_group_permission.pools",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckUserGroupPermission(groupName, name, accessLevel string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		group, err := tm.GetUserGroup(groupName)
+		if err != nil {
+			return fmt.Errorf("UserGroup %s does not exist: %#v", groupName, err)
+		}
+		for _, permission := range *group.Basic.Permissions {
+			if permission.Name == nil || *permission.Name != name {
+				continue
+			}
+			if actual := *permission.AccessLevel; actual != accessLevel {
+				return fmt.Errorf("UserGroup %s has %s access to %s, expected %s", groupName, actual, name, accessLevel)
+			}
+			return nil
+		}
+		return fmt.Errorf("UserGroup %s has no %s permission", groupName, name)
+	}
+}
+
+func getUserGroupPermissionConfig(groupName, accessLevel string) string {
+	return fmt.Sprintf(`
+        resource "vtm_user_group" "test_vtm_user_group" {
+			name = "%s"
+			ignore_external_permissions = true
+			permissions {
+				name = "Virtual_Servers"
+				access_level = "ro"
+			}
+		}
+
+		resource "vtm_user_group_permission" "pools" {
+			group = "${vtm_user_group.test_vtm_user_group.name}"
+			name = "Pools"
+			access_level = "%s"
+		}`,
+		groupName, accessLevel,
+	)
+}
@@ -12,6 +12,16 @@ import (
 	vtm "github.com/pulse-vadc/go-vtm/6.1"
 )
 
+// virtualServerRuleLists returns the rule lists of a virtual server, keyed by
+// the name of their field.
+func virtualServerRuleLists(object *vtm.VirtualServer) map[string]**[]string {
+	return map[string]**[]string{
+		"completion_rules": &object.Basic.CompletionRules,
+		"request_rules":    &object.Basic.RequestRules,
+		"response_rules":   &object.Basic.ResponseRules,
+	}
+}
+
 func resourceVirtualServer() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceVirtualServerRead,
@@ -29,7 +39,7 @@ func resourceVirtualServer() *schema.Resource {
 }
 
 func getResourceVirtualServerSchema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	fields := map[string]*schema.Schema{
 
 		"name": &schema.Schema{
 			Type:         schema.TypeString,
@@ -53,6 +63,16 @@ func getResourceVirtualServerSchema() map[string]*schema.Schema {
 			Elem:     &schema.Schema{Type: schema.TypeString},
 		},
 
+		// Only manage the rules listed in request_rules, response_rules
+		//  and completion_rules, leaving any others, such as those added
+		//  by vtm_virtual_server_rule_attachment resources, in place. This
+		//  setting is not stored on the vTM.
+		"ignore_external_rules": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
 		// The time, in seconds, for which an established connection can
 		//  remain idle waiting for some initial data to be received from
 		//  the client. The initial data is defined as a complete set of
@@ -328,80 +348,93 @@ func getResourceVirtualServerSchema() map[string]*schema.Schema {
 			Default:  false,
 		},
 
-		// Whether or not the virtual server should use keepalive connections
-		//  with the remote clients.
-		"connection_keepalive": &schema.Schema{
-			Type:     schema.TypeBool,
+		// Settings from the "connection" section of the virtual server
+		//  configuration. Terraform reserves the name "connection", so the
+		//  block is called "connection_settings".
+		"connection_settings": &schema.Schema{
+			Type:     schema.TypeList,
 			Optional: true,
-			Default:  true,
-		},
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
 
-		// The length of time that the virtual server should keep an idle
-		//  keepalive connection before discarding it.  A value of "0" (zero)
-		//  will mean that the keepalives are never closed by the traffic
-		//  manager.
-		"connection_keepalive_timeout": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(0, 99999),
-			Default:      10,
-		},
+					// Whether or not the virtual server should use keepalive connections
+					//  with the remote clients.
+					"keepalive": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
 
-		// The amount of memory, in bytes, that the virtual server should
-		//  use to store data sent by the client. Larger values will use
-		//  more memory, but will minimise the number of "read()" and "write()"
-		//  system calls that the traffic manager must perform.
-		"connection_max_client_buffer": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(1024, 16777216),
-			Default:      65536,
-		},
+					// The length of time that the virtual server should keep an idle
+					//  keepalive connection before discarding it.  A value of "0" (zero)
+					//  will mean that the keepalives are never closed by the traffic
+					//  manager.
+					"keepalive_timeout": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(0, 99999),
+						Default:      10,
+					},
 
-		// The amount of memory, in bytes, that the virtual server should
-		//  use to store data returned by the server.  Larger values will
-		//  use more memory, but will minimise the number of "read()" and
-		//  "write()" system calls that the traffic manager must perform.
-		"connection_max_server_buffer": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(1024, 16777216),
-			Default:      65536,
-		},
+					// The amount of memory, in bytes, that the virtual server should
+					//  use to store data sent by the client. Larger values will use
+					//  more memory, but will minimise the number of "read()" and "write()"
+					//  system calls that the traffic manager must perform.
+					"max_client_buffer": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(1024, 16777216),
+						Default:      65536,
+					},
 
-		// The total amount of time a transaction can take, counted from
-		//  the first byte being received until the transaction is complete.
-		//   For HTTP, this can mean all data has been written in both directions,
-		//  or the connection has been closed; in most other cases it is
-		//  the same as the connection being closed.<br> The default value
-		//  of "0" means there is no maximum duration, i.e., transactions
-		//  can take arbitrarily long if none of the other timeouts occur.
-		"connection_max_transaction_duration": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(0, 99999),
-			Default:      0,
-		},
+					// The amount of memory, in bytes, that the virtual server should
+					//  use to store data returned by the server.  Larger values will
+					//  use more memory, but will minimise the number of "read()" and
+					//  "write()" system calls that the traffic manager must perform.
+					"max_server_buffer": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(1024, 16777216),
+						Default:      65536,
+					},
 
-		// If specified, the traffic manager will use the value as the banner
-		//  to send for server-first protocols such as FTP, POP, SMTP and
-		//  IMAP. This allows rules to use the first part of the client data
-		//  (such as the username) to select a pool. The banner should be
-		//  in the correct format for the protocol, e.g. for FTP it should
-		//  start with "220 "
-		"connection_server_first_banner": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The total amount of time a transaction can take, counted from
+					//  the first byte being received until the transaction is complete.
+					//   For HTTP, this can mean all data has been written in both directions,
+					//  or the connection has been closed; in most other cases it is
+					//  the same as the connection being closed.<br> The default value
+					//  of "0" means there is no maximum duration, i.e., transactions
+					//  can take arbitrarily long if none of the other timeouts occur.
+					"max_transaction_duration": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(0, 99999),
+						Default:      0,
+					},
 
-		// A connection should be closed if no additional data has been
-		//  received for this period of time.  A value of "0" (zero) will
-		//  disable this timeout.
-		"connection_timeout": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(0, 99999),
-			Default:      300,
+					// If specified, the traffic manager will use the value as the banner
+					//  to send for server-first protocols such as FTP, POP, SMTP and
+					//  IMAP. This allows rules to use the first part of the client data
+					//  (such as the username) to select a pool. The banner should be
+					//  in the correct format for the protocol, e.g. for FTP it should
+					//  start with "220 "
+					"server_first_banner": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+
+					// A connection should be closed if no additional data has been
+					//  received for this period of time.  A value of "0" (zero) will
+					//  disable this timeout.
+					"timeout": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(0, 99999),
+						Default:      300,
+					},
+				},
+			},
 		},
 
 		// The error message to be sent to the client when the traffic manager
@@ -611,102 +644,113 @@ func getResourceVirtualServerSchema() map[string]*schema.Schema {
 			Default:  true,
 		},
 
-		// Whether or not the virtual server should add an "X-Cluster-Client-Ip"
-		//  header to the request that contains the remote client's IP address.
-		"http_add_cluster_ip": &schema.Schema{
-			Type:     schema.TypeBool,
+		// Settings from the "http" section of the virtual server configuration.
+		"http": &schema.Schema{
+			Type:     schema.TypeList,
 			Optional: true,
-			Default:  true,
-		},
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
 
-		// Whether or not the virtual server should append the remote client's
-		//  IP address to the X-Forwarded-For header. If the header does
-		//  not exist, it will be added.
-		"http_add_x_forwarded_for": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
+					// Whether or not the virtual server should add an "X-Cluster-Client-Ip"
+					//  header to the request that contains the remote client's IP address.
+					"add_cluster_ip": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
 
-		// Whether or not the virtual server should add an "X-Forwarded-Proto"
-		//  header to the request that contains the original protocol used
-		//  by the client to connect to the traffic manager.
-		"http_add_x_forwarded_proto": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
+					// Whether or not the virtual server should append the remote client's
+					//  IP address to the X-Forwarded-For header. If the header does
+					//  not exist, it will be added.
+					"add_x_forwarded_for": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
 
-		// Whether the traffic manager should check for HTTP responses that
-		//  confirm an HTTP connection is transitioning to the WebSockets
-		//  protocol.  If that such a response is detected, the traffic manager
-		//  will cease any protocol-specific processing on the connection
-		//  and just pass incoming data to the client/server as appropriate.
-		"http_autodetect_upgrade_headers": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  true,
-		},
+					// Whether or not the virtual server should add an "X-Forwarded-Proto"
+					//  header to the request that contains the original protocol used
+					//  by the client to connect to the traffic manager.
+					"add_x_forwarded_proto": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
 
-		// Handling of HTTP chunk overhead.  When vTM receives data from
-		//  a server or client that consists purely of protocol overhead
-		//  (contains no payload), forwarding of such segments is delayed
-		//  until useful payload data arrives (setting "lazy").  Changing
-		//  this key to "eager" will make vTM incur the overhead of immediately
-		//  passing such data on; it should only be used with HTTP peers
-		//  whose chunk handling requires it.
-		"http_chunk_overhead_forwarding": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"eager", "lazy"}, false),
-			Default:      "lazy",
-		},
+					// Whether the traffic manager should check for HTTP responses that
+					//  confirm an HTTP connection is transitioning to the WebSockets
+					//  protocol.  If that such a response is detected, the traffic manager
+					//  will cease any protocol-specific processing on the connection
+					//  and just pass incoming data to the client/server as appropriate.
+					"autodetect_upgrade_headers": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
 
-		// If the 'Location' header matches this regular expression, rewrite
-		//  the header using the 'location_replace' pattern.
-		"http_location_regex": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// Handling of HTTP chunk overhead.  When vTM receives data from
+					//  a server or client that consists purely of protocol overhead
+					//  (contains no payload), forwarding of such segments is delayed
+					//  until useful payload data arrives (setting "lazy").  Changing
+					//  this key to "eager" will make vTM incur the overhead of immediately
+					//  passing such data on; it should only be used with HTTP peers
+					//  whose chunk handling requires it.
+					"chunk_overhead_forwarding": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"eager", "lazy"}, false),
+						Default:      "lazy",
+					},
 
-		// If the 'Location' header matches the 'location_regex' regular
-		//  expression, rewrite the header with this pattern (parameters
-		//  such as $1-$9 can be used to match parts of the regular expression):
-		"http_location_replace": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// If the 'Location' header matches this regular expression, rewrite
+					//  the header using the 'location_replace' pattern.
+					"location_regex": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// The action the virtual server should take if the "Location" header
-		//  does not match the "location_regex" regular expression.
-		"http_location_rewrite": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"always", "if_host_matches", "never"}, false),
-			Default:      "if_host_matches",
-		},
+					// If the 'Location' header matches the 'location_regex' regular
+					//  expression, rewrite the header with this pattern (parameters
+					//  such as $1-$9 can be used to match parts of the regular expression):
+					"location_replace": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Auto-correct MIME types if the server sends the "default" MIME
-		//  type for files.
-		"http_mime_default": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-			Default:  "text/plain",
-		},
+					// The action the virtual server should take if the "Location" header
+					//  does not match the "location_regex" regular expression.
+					"location_rewrite": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"always", "if_host_matches", "never"}, false),
+						Default:      "if_host_matches",
+					},
 
-		// Auto-detect MIME types if the server does not provide them.
-		"http_mime_detect": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
+					// Auto-correct MIME types if the server sends the "default" MIME
+					//  type for files.
+					"mime_default": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+						Default:  "text/plain",
+					},
 
-		// Whether or not the virtual server should strip the 'X-Forwarded-Proto'
-		//  header from incoming requests.
-		"http_strip_x_forwarded_proto": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  true,
+					// Auto-detect MIME types if the server does not provide them.
+					"mime_detect": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
+
+					// Whether or not the virtual server should strip the 'X-Forwarded-Proto'
+					//  header from incoming requests.
+					"strip_x_forwarded_proto": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
+				},
+			},
 		},
 
 		// The time, in seconds, to wait for a request on a new HTTP/2 connection.
@@ -1127,358 +1171,369 @@ func getResourceVirtualServerSchema() map[string]*schema.Schema {
 			Default:  true,
 		},
 
-		// Whether or not the virtual server should add HTTP headers to
-		//  each request to show the SSL connection parameters.
-		"ssl_add_http_headers": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
-
-		// The SSL/TLS cipher suites to allow for connections to this virtual
-		//  server.  Leaving this empty will make the virtual server use
-		//  the globally configured cipher suites, see configuration key
-		//  <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!cipher_suites">
-		//  "ssl!cipher_suites"</a> in the Global Settings section of the
-		//  System tab.  See there for how to specify SSL/TLS cipher suites.
-		"ssl_cipher_suites": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
-
-		// The certificate authorities that this virtual server should trust
-		//  to validate client certificates. If no certificate authorities
-		//  are selected, and client certificates are requested, then all
-		//  client certificates will be accepted.
-		"ssl_client_cert_cas": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-
-		// What HTTP headers the virtual server should add to each request
-		//  to show the data in the client certificate.
-		"ssl_client_cert_headers": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"all", "none", "simple"}, false),
-			Default:      "none",
-		},
-
-		// The SSL elliptic curve preference list for SSL connections to
-		//  this virtual server using TLS version 1.0 or higher. Leaving
-		//  this empty will make the virtual server use the globally configured
-		//  curve preference list. The named curves P256, P384 and P521 may
-		//  be configured.
-		"ssl_elliptic_curves": &schema.Schema{
+		// Settings from the "ssl" section of the virtual server configuration.
+		"ssl": &schema.Schema{
 			Type:     schema.TypeList,
 			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-
-		// Whether or not the Fallback SCSV sent by TLS clients is honored
-		//  by this virtual server. Choosing the global setting means the
-		//  value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!honor_fallback_scsv">
-		//  "ssl!honor_fallback_scsv"</a> from the Global Settings section
-		//  of the System tab will be enforced.
-		"ssl_honor_fallback_scsv": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
-
-		// When the virtual server verifies certificates signed by these
-		//  certificate authorities, it doesn't check the 'not after' date,
-		//  i.e., they are considered valid even after their expiration date
-		//  has passed (but not if they have been revoked).
-		"ssl_issued_certs_never_expire": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
-
-		// This setting gives the number of certificates in a certificate
-		//  chain beyond those listed as issued_certs_never_expire whose
-		//  certificate expiry will not be checked. For example "0" will
-		//  result in the expiry checks being made for certificates issued
-		//  by issued_certs_never_expire certificates, "1" will result in
-		//  no expiry checks being performed for the certificates directly
-		//  issued by issued_certs_never_expire certificates, "2" will avoid
-		//  checking expiry for certificates issued by certificates issued
-		//  by the issued_certs_never_expire certificates as well, and so
-		//  on.
-		"ssl_issued_certs_never_expire_depth": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntBetween(0, 255),
-			Default:      1,
-		},
-
-		// Whether or not the traffic manager should use OCSP to check the
-		//  revocation status of client certificates.
-		"ssl_ocsp_enable": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
-
-		// A table of certificate issuer specific OCSP settings.
-		"ssl_ocsp_issuers": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
+			MaxItems: 1,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 
-					// aia
-					"aia": &schema.Schema{
+					// Whether or not the virtual server should add HTTP headers to
+					//  each request to show the SSL connection parameters.
+					"add_http_headers": &schema.Schema{
 						Type:     schema.TypeBool,
 						Optional: true,
-						Default:  true,
+						Default:  false,
 					},
 
-					// issuer
-					"issuer": &schema.Schema{
+					// The SSL/TLS cipher suites to allow for connections to this virtual
+					//  server.  Leaving this empty will make the virtual server use
+					//  the globally configured cipher suites, see configuration key
+					//  <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!cipher_suites">
+					//  "ssl!cipher_suites"</a> in the Global Settings section of the
+					//  System tab.  See there for how to specify SSL/TLS cipher suites.
+					"cipher_suites": &schema.Schema{
 						Type:     schema.TypeString,
-						Required: true,
+						Optional: true,
 					},
 
-					// nonce
-					"nonce": &schema.Schema{
-						Type:         schema.TypeString,
-						Optional:     true,
-						ValidateFunc: validation.StringInSlice([]string{"off", "on", "strict"}, false),
-						Default:      "off",
+					// The certificate authorities that this virtual server should trust
+					//  to validate client certificates. If no certificate authorities
+					//  are selected, and client certificates are requested, then all
+					//  client certificates will be accepted.
+					"client_cert_cas": &schema.Schema{
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
 					},
 
-					// required
-					"required": &schema.Schema{
+					// What HTTP headers the virtual server should add to each request
+					//  to show the data in the client certificate.
+					"client_cert_headers": &schema.Schema{
 						Type:         schema.TypeString,
 						Optional:     true,
-						ValidateFunc: validation.StringInSlice([]string{"none", "optional", "strict"}, false),
-						Default:      "optional",
+						ValidateFunc: validation.StringInSlice([]string{"all", "none", "simple"}, false),
+						Default:      "none",
 					},
 
-					// responder_cert
-					"responder_cert": &schema.Schema{
-						Type:     schema.TypeString,
+					// The SSL elliptic curve preference list for SSL connections to
+					//  this virtual server using TLS version 1.0 or higher. Leaving
+					//  this empty will make the virtual server use the globally configured
+					//  curve preference list. The named curves P256, P384 and P521 may
+					//  be configured.
+					"elliptic_curves": &schema.Schema{
+						Type:     schema.TypeList,
 						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
 					},
 
-					// signer
-					"signer": &schema.Schema{
-						Type:     schema.TypeString,
-						Optional: true,
+					// Whether or not the Fallback SCSV sent by TLS clients is honored
+					//  by this virtual server. Choosing the global setting means the
+					//  value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!honor_fallback_scsv">
+					//  "ssl!honor_fallback_scsv"</a> from the Global Settings section
+					//  of the System tab will be enforced.
+					"honor_fallback_scsv": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
 					},
 
-					// url
-					"url": &schema.Schema{
-						Type:     schema.TypeString,
+					// When the virtual server verifies certificates signed by these
+					//  certificate authorities, it doesn't check the 'not after' date,
+					//  i.e., they are considered valid even after their expiration date
+					//  has passed (but not if they have been revoked).
+					"issued_certs_never_expire": &schema.Schema{
+						Type:     schema.TypeSet,
 						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
 					},
-				},
-			},
-		},
 
-		// The number of seconds for which an OCSP response is considered
-		//  valid if it has not yet exceeded the time specified in the 'nextUpdate'
-		//  field. If set to "0" (zero) then OCSP responses are considered
-		//  valid until the time specified in their 'nextUpdate' field.
-		"ssl_ocsp_max_response_age": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      0,
-		},
+					// This setting gives the number of certificates in a certificate
+					//  chain beyond those listed as issued_certs_never_expire whose
+					//  certificate expiry will not be checked. For example "0" will
+					//  result in the expiry checks being made for certificates issued
+					//  by issued_certs_never_expire certificates, "1" will result in
+					//  no expiry checks being performed for the certificates directly
+					//  issued by issued_certs_never_expire certificates, "2" will avoid
+					//  checking expiry for certificates issued by certificates issued
+					//  by the issued_certs_never_expire certificates as well, and so
+					//  on.
+					"issued_certs_never_expire_depth": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntBetween(0, 255),
+						Default:      1,
+					},
 
-		// If OCSP URIs are present in certificates used by this virtual
-		//  server, then enabling this option will allow the traffic manager
-		//  to provide OCSP responses for these certificates as part of the
-		//  handshake, if the client sends a TLS status_request extension
-		//  in the ClientHello.
-		"ssl_ocsp_stapling": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
-		},
+					// Whether or not the traffic manager should use OCSP to check the
+					//  revocation status of client certificates.
+					"ocsp_enable": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
 
-		// The number of seconds outside the permitted range for which the
-		//  'thisUpdate' and 'nextUpdate' fields of an OCSP response are
-		//  still considered valid.
-		"ssl_ocsp_time_tolerance": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      30,
-		},
+					// A table of certificate issuer specific OCSP settings.
+					"ocsp_issuers": &schema.Schema{
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+
+								// aia
+								"aia": &schema.Schema{
+									Type:     schema.TypeBool,
+									Optional: true,
+									Default:  true,
+								},
+
+								// issuer
+								"issuer": &schema.Schema{
+									Type:     schema.TypeString,
+									Required: true,
+								},
+
+								// nonce
+								"nonce": &schema.Schema{
+									Type:         schema.TypeString,
+									Optional:     true,
+									ValidateFunc: validation.StringInSlice([]string{"off", "on", "strict"}, false),
+									Default:      "off",
+								},
+
+								// required
+								"required": &schema.Schema{
+									Type:         schema.TypeString,
+									Optional:     true,
+									ValidateFunc: validation.StringInSlice([]string{"none", "optional", "strict"}, false),
+									Default:      "optional",
+								},
+
+								// responder_cert
+								"responder_cert": &schema.Schema{
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+
+								// signer
+								"signer": &schema.Schema{
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+
+								// url
+								"url": &schema.Schema{
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+							},
+						},
+					},
 
-		// The number of seconds after which OCSP requests will be timed
-		//  out.
-		"ssl_ocsp_timeout": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			ValidateFunc: validation.IntAtLeast(0),
-			Default:      10,
-		},
+					// The number of seconds for which an OCSP response is considered
+					//  valid if it has not yet exceeded the time specified in the 'nextUpdate'
+					//  field. If set to "0" (zero) then OCSP responses are considered
+					//  valid until the time specified in their 'nextUpdate' field.
+					"ocsp_max_response_age": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      0,
+					},
 
-		// Whether or not the virtual server should request an identifying
-		//  SSL certificate from each client.
-		"ssl_request_client_cert": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"dont_request", "request", "require"}, false),
-			Default:      "dont_request",
-		},
+					// If OCSP URIs are present in certificates used by this virtual
+					//  server, then enabling this option will allow the traffic manager
+					//  to provide OCSP responses for these certificates as part of the
+					//  handshake, if the client sends a TLS status_request extension
+					//  in the ClientHello.
+					"ocsp_stapling": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
 
-		// Whether or not to send an SSL/TLS "close alert" when the traffic
-		//  manager is initiating an SSL socket disconnection.
-		"ssl_send_close_alerts": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  true,
-		},
+					// The number of seconds outside the permitted range for which the
+					//  'thisUpdate' and 'nextUpdate' fields of an OCSP response are
+					//  still considered valid.
+					"ocsp_time_tolerance": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      30,
+					},
 
-		// The SSL certificates and corresponding private keys.
-		"ssl_server_cert_alt_certificates": &schema.Schema{
-			Type:     schema.TypeList,
-			Optional: true,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
+					// The number of seconds after which OCSP requests will be timed
+					//  out.
+					"ocsp_timeout": &schema.Schema{
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+						Default:      10,
+					},
 
-		// The default SSL certificate to use for this virtual server.
-		"ssl_server_cert_default": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// Whether or not the virtual server should request an identifying
+					//  SSL certificate from each client.
+					"request_client_cert": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"dont_request", "request", "require"}, false),
+						Default:      "dont_request",
+					},
 
-		// Host specific SSL server certificate mappings.
-		"ssl_server_cert_host_mapping": &schema.Schema{
-			Type:     schema.TypeSet,
-			Optional: true,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
+					// Whether or not to send an SSL/TLS "close alert" when the traffic
+					//  manager is initiating an SSL socket disconnection.
+					"send_close_alerts": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
 
-					// alt_certificates
-					"alt_certificates": &schema.Schema{
+					// The SSL certificates and corresponding private keys.
+					"server_cert_alt_certificates": &schema.Schema{
 						Type:     schema.TypeList,
 						Optional: true,
 						Elem:     &schema.Schema{Type: schema.TypeString},
-						Default:  nil,
 					},
 
-					// certificate
-					"certificate": &schema.Schema{
+					// The default SSL certificate to use for this virtual server.
+					"server_cert_default": &schema.Schema{
 						Type:     schema.TypeString,
-						Required: true,
+						Optional: true,
 					},
 
-					// host
-					"host": &schema.Schema{
-						Type:     schema.TypeString,
-						Required: true,
+					// Host specific SSL server certificate mappings.
+					"server_cert_host_mapping": &schema.Schema{
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+
+								// alt_certificates
+								"alt_certificates": &schema.Schema{
+									Type:     schema.TypeList,
+									Optional: true,
+									Elem:     &schema.Schema{Type: schema.TypeString},
+									Default:  nil,
+								},
+
+								// certificate
+								"certificate": &schema.Schema{
+									Type:     schema.TypeString,
+									Required: true,
+								},
+
+								// host
+								"host": &schema.Schema{
+									Type:     schema.TypeString,
+									Required: true,
+								},
+							},
+						},
 					},
-				},
-			},
-		},
 
-		// Whether or not use of the session cache is enabled for this virtual
-		//  server. Choosing the global setting means the value of configuration
-		//  key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!cache!enabled">
-		//  "ssl!session_cache_enabled"</a> from the Global Settings section
-		//  of the System tab will be enforced.
-		"ssl_session_cache_enabled": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not use of the session cache is enabled for this virtual
+					//  server. Choosing the global setting means the value of configuration
+					//  key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!cache!enabled">
+					//  "ssl!session_cache_enabled"</a> from the Global Settings section
+					//  of the System tab will be enforced.
+					"session_cache_enabled": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// Whether or not use of session tickets is enabled for this virtual
-		//  server. Choosing the global setting means the value of configuration
-		//  key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!tickets!enabled">
-		//  "ssl!tickets!enabled"</a> from the Global Settings section of
-		//  the System tab will be enforced.
-		"ssl_session_tickets_enabled": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not use of session tickets is enabled for this virtual
+					//  server. Choosing the global setting means the value of configuration
+					//  key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!tickets!enabled">
+					//  "ssl!tickets!enabled"</a> from the Global Settings section of
+					//  the System tab will be enforced.
+					"session_tickets_enabled": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// The SSL signature algorithms preference list for SSL connections
-		//  to this virtual server using TLS version 1.2 or higher. Leaving
-		//  this empty will make the virtual server use the globally configured
-		//  preference list, "signature_algorithms" in the "ssl" section
-		//  of the "global_settings" resource.  See there and in the online
-		//  help for how to specify SSL signature algorithms.
-		"ssl_signature_algorithms": &schema.Schema{
-			Type:     schema.TypeString,
-			Optional: true,
-		},
+					// The SSL signature algorithms preference list for SSL connections
+					//  to this virtual server using TLS version 1.2 or higher. Leaving
+					//  this empty will make the virtual server use the globally configured
+					//  preference list, "signature_algorithms" in the "ssl" section
+					//  of the "global_settings" resource.  See there and in the online
+					//  help for how to specify SSL signature algorithms.
+					"signature_algorithms": &schema.Schema{
+						Type:     schema.TypeString,
+						Optional: true,
+					},
 
-		// Whether or not SSLv3 is enabled for this virtual server.  Choosing
-		//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_ssl3">
-		//  "ssl!support_ssl3"</a> from the Global Settings section of the
-		//  System tab will be enforced.
-		"ssl_support_ssl3": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not SSLv3 is enabled for this virtual server.  Choosing
+					//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_ssl3">
+					//  "ssl!support_ssl3"</a> from the Global Settings section of the
+					//  System tab will be enforced.
+					"support_ssl3": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// Whether or not TLSv1.0 is enabled for this virtual server. Choosing
-		//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1">
-		//  "ssl!support_tls1"</a> from the Global Settings section of the
-		//  System tab will be enforced.
-		"ssl_support_tls1": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not TLSv1.0 is enabled for this virtual server. Choosing
+					//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1">
+					//  "ssl!support_tls1"</a> from the Global Settings section of the
+					//  System tab will be enforced.
+					"support_tls1": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// Whether or not TLSv1.1 is enabled for this virtual server. Choosing
-		//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_1">
-		//  "ssl!support_tls1_1"</a> from the Global Settings section of
-		//  the System tab will be enforced.
-		"ssl_support_tls1_1": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not TLSv1.1 is enabled for this virtual server. Choosing
+					//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_1">
+					//  "ssl!support_tls1_1"</a> from the Global Settings section of
+					//  the System tab will be enforced.
+					"support_tls1_1": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// Whether or not TLSv1.2 is enabled for this virtual server. Choosing
-		//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_2">
-		//  "ssl!support_tls1_2"</a> from the Global Settings section of
-		//  the System tab will be enforced.
-		"ssl_support_tls1_2": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not TLSv1.2 is enabled for this virtual server. Choosing
+					//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_2">
+					//  "ssl!support_tls1_2"</a> from the Global Settings section of
+					//  the System tab will be enforced.
+					"support_tls1_2": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// Whether or not TLSv1.3 is enabled for this virtual server. Choosing
-		//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_3">
-		//  "ssl!support_tls1_3"</a> from the Global Settings section of
-		//  the System tab will be enforced.
-		"ssl_support_tls1_3": &schema.Schema{
-			Type:         schema.TypeString,
-			Optional:     true,
-			ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
-			Default:      "use_default",
-		},
+					// Whether or not TLSv1.3 is enabled for this virtual server. Choosing
+					//  the global setting means the value of configuration key <a href="?fold_open=SSL%20Configuration&section=Global%20Settings#a_ssl!support_tls1_3">
+					//  "ssl!support_tls1_3"</a> from the Global Settings section of
+					//  the System tab will be enforced.
+					"support_tls1_3": &schema.Schema{
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice([]string{"disabled", "enabled", "use_default"}, false),
+						Default:      "use_default",
+					},
 
-		// If the traffic manager is receiving traffic sent from another
-		//  traffic manager, then enabling this option will allow it to decode
-		//  extra information on the true origin of the SSL connection. This
-		//  information is supplied by the first traffic manager.
-		"ssl_trust_magic": &schema.Schema{
-			Type:     schema.TypeBool,
-			Optional: true,
-			Default:  false,
+					// If the traffic manager is receiving traffic sent from another
+					//  traffic manager, then enabling this option will allow it to decode
+					//  extra information on the true origin of the SSL connection. This
+					//  information is supplied by the first traffic manager.
+					"trust_magic": &schema.Schema{
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  false,
+					},
+				},
+			},
 		},
 
 		// Whether or not to log connections to the virtual server to a
@@ -1669,6 +1724,10 @@ func getResourceVirtualServerSchema() map[string]*schema.Schema {
 			Default:      2,
 		},
 	}
+	addSectionAliases(fields, "connection", "connection_settings")
+	addSectionAliases(fields, "http", "http")
+	addSectionAliases(fields, "ssl", "ssl")
+	return fields
 }
 
 func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readError error) {
@@ -1698,7 +1757,7 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 	lastAssignedField = "bandwidth_class"
 	d.Set("bandwidth_class", string(*object.Basic.BandwidthClass))
 	lastAssignedField = "completion_rules"
-	d.Set("completion_rules", []string(*object.Basic.CompletionRules))
+	d.Set("completion_rules", managedListEntries(d, "ignore_external_rules", "completion_rules", []string(*object.Basic.CompletionRules)))
 	lastAssignedField = "connect_timeout"
 	d.Set("connect_timeout", int(*object.Basic.ConnectTimeout))
 	lastAssignedField = "enabled"
@@ -1726,9 +1785,9 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 	lastAssignedField = "proxy_protocol"
 	d.Set("proxy_protocol", bool(*object.Basic.ProxyProtocol))
 	lastAssignedField = "request_rules"
-	d.Set("request_rules", []string(*object.Basic.RequestRules))
+	d.Set("request_rules", managedListEntries(d, "ignore_external_rules", "request_rules", []string(*object.Basic.RequestRules)))
 	lastAssignedField = "response_rules"
-	d.Set("response_rules", []string(*object.Basic.ResponseRules))
+	d.Set("response_rules", managedListEntries(d, "ignore_external_rules", "response_rules", []string(*object.Basic.ResponseRules)))
 	lastAssignedField = "slm_class"
 	d.Set("slm_class", string(*object.Basic.SlmClass))
 	lastAssignedField = "ssl_decrypt"
@@ -1774,20 +1833,22 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 	d.Set("auth_type", string(*object.Auth.Type))
 	lastAssignedField = "auth_verbose"
 	d.Set("auth_verbose", bool(*object.Auth.Verbose))
-	lastAssignedField = "connection_keepalive"
-	d.Set("connection_keepalive", bool(*object.Connection.Keepalive))
-	lastAssignedField = "connection_keepalive_timeout"
-	d.Set("connection_keepalive_timeout", int(*object.Connection.KeepaliveTimeout))
-	lastAssignedField = "connection_max_client_buffer"
-	d.Set("connection_max_client_buffer", int(*object.Connection.MaxClientBuffer))
-	lastAssignedField = "connection_max_server_buffer"
-	d.Set("connection_max_server_buffer", int(*object.Connection.MaxServerBuffer))
-	lastAssignedField = "connection_max_transaction_duration"
-	d.Set("connection_max_transaction_duration", int(*object.Connection.MaxTransactionDuration))
-	lastAssignedField = "connection_server_first_banner"
-	d.Set("connection_server_first_banner", string(*object.Connection.ServerFirstBanner))
-	lastAssignedField = "connection_timeout"
-	d.Set("connection_timeout", int(*object.Connection.Timeout))
+	connectionSettings := make(map[string]interface{})
+	lastAssignedField = "connection_settings.keepalive"
+	connectionSettings["keepalive"] = bool(*object.Connection.Keepalive)
+	lastAssignedField = "connection_settings.keepalive_timeout"
+	connectionSettings["keepalive_timeout"] = int(*object.Connection.KeepaliveTimeout)
+	lastAssignedField = "connection_settings.max_client_buffer"
+	connectionSettings["max_client_buffer"] = int(*object.Connection.MaxClientBuffer)
+	lastAssignedField = "connection_settings.max_server_buffer"
+	connectionSettings["max_server_buffer"] = int(*object.Connection.MaxServerBuffer)
+	lastAssignedField = "connection_settings.max_transaction_duration"
+	connectionSettings["max_transaction_duration"] = int(*object.Connection.MaxTransactionDuration)
+	lastAssignedField = "connection_settings.server_first_banner"
+	connectionSettings["server_first_banner"] = string(*object.Connection.ServerFirstBanner)
+	lastAssignedField = "connection_settings.timeout"
+	connectionSettings["timeout"] = int(*object.Connection.Timeout)
+	setSection(d, "connection_settings", "connection", connectionSettings, getResourceVirtualServerSchema()["connection_settings"].Elem.(*schema.Resource).Schema)
 	lastAssignedField = "connection_errors_error_file"
 	d.Set("connection_errors_error_file", string(*object.ConnectionErrors.ErrorFile))
 	lastAssignedField = "cookie_domain"
@@ -1838,28 +1899,30 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 	d.Set("gzip_min_size", int(*object.Gzip.MinSize))
 	lastAssignedField = "gzip_no_size"
 	d.Set("gzip_no_size", bool(*object.Gzip.NoSize))
-	lastAssignedField = "http_add_cluster_ip"
-	d.Set("http_add_cluster_ip", bool(*object.Http.AddClusterIp))
-	lastAssignedField = "http_add_x_forwarded_for"
-	d.Set("http_add_x_forwarded_for", bool(*object.Http.AddXForwardedFor))
-	lastAssignedField = "http_add_x_forwarded_proto"
-	d.Set("http_add_x_forwarded_proto", bool(*object.Http.AddXForwardedProto))
-	lastAssignedField = "http_autodetect_upgrade_headers"
-	d.Set("http_autodetect_upgrade_headers", bool(*object.Http.AutodetectUpgradeHeaders))
-	lastAssignedField = "http_chunk_overhead_forwarding"
-	d.Set("http_chunk_overhead_forwarding", string(*object.Http.ChunkOverheadForwarding))
-	lastAssignedField = "http_location_regex"
-	d.Set("http_location_regex", string(*object.Http.LocationRegex))
-	lastAssignedField = "http_location_replace"
-	d.Set("http_location_replace", string(*object.Http.LocationReplace))
-	lastAssignedField = "http_location_rewrite"
-	d.Set("http_location_rewrite", string(*object.Http.LocationRewrite))
-	lastAssignedField = "http_mime_default"
-	d.Set("http_mime_default", string(*object.Http.MimeDefault))
-	lastAssignedField = "http_mime_detect"
-	d.Set("http_mime_detect", bool(*object.Http.MimeDetect))
-	lastAssignedField = "http_strip_x_forwarded_proto"
-	d.Set("http_strip_x_forwarded_proto", bool(*object.Http.StripXForwardedProto))
+	http := make(map[string]interface{})
+	lastAssignedField = "http.add_cluster_ip"
+	http["add_cluster_ip"] = bool(*object.Http.AddClusterIp)
+	lastAssignedField = "http.add_x_forwarded_for"
+	http["add_x_forwarded_for"] = bool(*object.Http.AddXForwardedFor)
+	lastAssignedField = "http.add_x_forwarded_proto"
+	http["add_x_forwarded_proto"] = bool(*object.Http.AddXForwardedProto)
+	lastAssignedField = "http.autodetect_upgrade_headers"
+	http["autodetect_upgrade_headers"] = bool(*object.Http.AutodetectUpgradeHeaders)
+	lastAssignedField = "http.chunk_overhead_forwarding"
+	http["chunk_overhead_forwarding"] = string(*object.Http.ChunkOverheadForwarding)
+	lastAssignedField = "http.location_regex"
+	http["location_regex"] = string(*object.Http.LocationRegex)
+	lastAssignedField = "http.location_replace"
+	http["location_replace"] = string(*object.Http.LocationReplace)
+	lastAssignedField = "http.location_rewrite"
+	http["location_rewrite"] = string(*object.Http.LocationRewrite)
+	lastAssignedField = "http.mime_default"
+	http["mime_default"] = string(*object.Http.MimeDefault)
+	lastAssignedField = "http.mime_detect"
+	http["mime_detect"] = bool(*object.Http.MimeDetect)
+	lastAssignedField = "http.strip_x_forwarded_proto"
+	http["strip_x_forwarded_proto"] = bool(*object.Http.StripXForwardedProto)
+	setSection(d, "http", "http", http, getResourceVirtualServerSchema()["http"].Elem.(*schema.Resource).Schema)
 	lastAssignedField = "http2_connect_timeout"
 	d.Set("http2_connect_timeout", int(*object.Http2.ConnectTimeout))
 	lastAssignedField = "http2_data_frame_size"
@@ -1950,25 +2013,26 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 	d.Set("sip_transaction_timeout", int(*object.Sip.TransactionTimeout))
 	lastAssignedField = "smtp_expect_starttls"
 	d.Set("smtp_expect_starttls", bool(*object.Smtp.ExpectStarttls))
-	lastAssignedField = "ssl_add_http_headers"
-	d.Set("ssl_add_http_headers", bool(*object.Ssl.AddHttpHeaders))
-	lastAssignedField = "ssl_cipher_suites"
-	d.Set("ssl_cipher_suites", string(*object.Ssl.CipherSuites))
-	lastAssignedField = "ssl_client_cert_cas"
-	d.Set("ssl_client_cert_cas", []string(*object.Ssl.ClientCertCas))
-	lastAssignedField = "ssl_client_cert_headers"
-	d.Set("ssl_client_cert_headers", string(*object.Ssl.ClientCertHeaders))
-	lastAssignedField = "ssl_elliptic_curves"
-	d.Set("ssl_elliptic_curves", []string(*object.Ssl.EllipticCurves))
-	lastAssignedField = "ssl_honor_fallback_scsv"
-	d.Set("ssl_honor_fallback_scsv", string(*object.Ssl.HonorFallbackScsv))
-	lastAssignedField = "ssl_issued_certs_never_expire"
-	d.Set("ssl_issued_certs_never_expire", []string(*object.Ssl.IssuedCertsNeverExpire))
-	lastAssignedField = "ssl_issued_certs_never_expire_depth"
-	d.Set("ssl_issued_certs_never_expire_depth", int(*object.Ssl.IssuedCertsNeverExpireDepth))
-	lastAssignedField = "ssl_ocsp_enable"
-	d.Set("ssl_ocsp_enable", bool(*object.Ssl.OcspEnable))
-	lastAssignedField = "ssl_ocsp_issuers"
+	ssl := make(map[string]interface{})
+	lastAssignedField = "ssl.add_http_headers"
+	ssl["add_http_headers"] = bool(*object.Ssl.AddHttpHeaders)
+	lastAssignedField = "ssl.cipher_suites"
+	ssl["cipher_suites"] = string(*object.Ssl.CipherSuites)
+	lastAssignedField = "ssl.client_cert_cas"
+	ssl["client_cert_cas"] = []string(*object.Ssl.ClientCertCas)
+	lastAssignedField = "ssl.client_cert_headers"
+	ssl["client_cert_headers"] = string(*object.Ssl.ClientCertHeaders)
+	lastAssignedField = "ssl.elliptic_curves"
+	ssl["elliptic_curves"] = []string(*object.Ssl.EllipticCurves)
+	lastAssignedField = "ssl.honor_fallback_scsv"
+	ssl["honor_fallback_scsv"] = string(*object.Ssl.HonorFallbackScsv)
+	lastAssignedField = "ssl.issued_certs_never_expire"
+	ssl["issued_certs_never_expire"] = []string(*object.Ssl.IssuedCertsNeverExpire)
+	lastAssignedField = "ssl.issued_certs_never_expire_depth"
+	ssl["issued_certs_never_expire_depth"] = int(*object.Ssl.IssuedCertsNeverExpireDepth)
+	lastAssignedField = "ssl.ocsp_enable"
+	ssl["ocsp_enable"] = bool(*object.Ssl.OcspEnable)
+	lastAssignedField = "ssl.ocsp_issuers"
 	sslOcspIssuers := make([]map[string]interface{}, 0, len(*object.Ssl.OcspIssuers))
 	for _, item := range *object.Ssl.OcspIssuers {
 		itemTerraform := make(map[string]interface{})
@@ -1995,26 +2059,26 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 		}
 		sslOcspIssuers = append(sslOcspIssuers, itemTerraform)
 	}
-	d.Set("ssl_ocsp_issuers", sslOcspIssuers)
+	ssl["ocsp_issuers"] = sslOcspIssuers
 	sslOcspIssuersJson, _ := json.Marshal(sslOcspIssuers)
 	d.Set("ssl_ocsp_issuers_json", sslOcspIssuersJson)
-	lastAssignedField = "ssl_ocsp_max_response_age"
-	d.Set("ssl_ocsp_max_response_age", int(*object.Ssl.OcspMaxResponseAge))
-	lastAssignedField = "ssl_ocsp_stapling"
-	d.Set("ssl_ocsp_stapling", bool(*object.Ssl.OcspStapling))
-	lastAssignedField = "ssl_ocsp_time_tolerance"
-	d.Set("ssl_ocsp_time_tolerance", int(*object.Ssl.OcspTimeTolerance))
-	lastAssignedField = "ssl_ocsp_timeout"
-	d.Set("ssl_ocsp_timeout", int(*object.Ssl.OcspTimeout))
-	lastAssignedField = "ssl_request_client_cert"
-	d.Set("ssl_request_client_cert", string(*object.Ssl.RequestClientCert))
-	lastAssignedField = "ssl_send_close_alerts"
-	d.Set("ssl_send_close_alerts", bool(*object.Ssl.SendCloseAlerts))
-	lastAssignedField = "ssl_server_cert_alt_certificates"
-	d.Set("ssl_server_cert_alt_certificates", []string(*object.Ssl.ServerCertAltCertificates))
-	lastAssignedField = "ssl_server_cert_default"
-	d.Set("ssl_server_cert_default", string(*object.Ssl.ServerCertDefault))
-	lastAssignedField = "ssl_server_cert_host_mapping"
+	lastAssignedField = "ssl.ocsp_max_response_age"
+	ssl["ocsp_max_response_age"] = int(*object.Ssl.OcspMaxResponseAge)
+	lastAssignedField = "ssl.ocsp_stapling"
+	ssl["ocsp_stapling"] = bool(*object.Ssl.OcspStapling)
+	lastAssignedField = "ssl.ocsp_time_tolerance"
+	ssl["ocsp_time_tolerance"] = int(*object.Ssl.OcspTimeTolerance)
+	lastAssignedField = "ssl.ocsp_timeout"
+	ssl["ocsp_timeout"] = int(*object.Ssl.OcspTimeout)
+	lastAssignedField = "ssl.request_client_cert"
+	ssl["request_client_cert"] = string(*object.Ssl.RequestClientCert)
+	lastAssignedField = "ssl.send_close_alerts"
+	ssl["send_close_alerts"] = bool(*object.Ssl.SendCloseAlerts)
+	lastAssignedField = "ssl.server_cert_alt_certificates"
+	ssl["server_cert_alt_certificates"] = []string(*object.Ssl.ServerCertAltCertificates)
+	lastAssignedField = "ssl.server_cert_default"
+	ssl["server_cert_default"] = string(*object.Ssl.ServerCertDefault)
+	lastAssignedField = "ssl.server_cert_host_mapping"
 	sslServerCertHostMapping := make([]map[string]interface{}, 0, len(*object.Ssl.ServerCertHostMapping))
 	for _, item := range *object.Ssl.ServerCertHostMapping {
 		itemTerraform := make(map[string]interface{})
@@ -2029,27 +2093,28 @@ func resourceVirtualServerRead(d *schema.ResourceData, tm interface{}) (readErro
 		}
 		sslServerCertHostMapping = append(sslServerCertHostMapping, itemTerraform)
 	}
-	d.Set("ssl_server_cert_host_mapping", sslServerCertHostMapping)
+	ssl["server_cert_host_mapping"] = sslServerCertHostMapping
 	sslServerCertHostMappingJson, _ := json.Marshal(sslServerCertHostMapping)
 	d.Set("ssl_server_cert_host_mapping_json", sslServerCertHostMappingJson)
-	lastAssignedField = "ssl_session_cache_enabled"
-	d.Set("ssl_session_cache_enabled", string(*object.Ssl.SessionCacheEnabled))
-	lastAssignedField = "ssl_session_tickets_enabled"
-	d.Set("ssl_session_tickets_enabled", string(*object.Ssl.SessionTicketsEnabled))
-	lastAssignedField = "ssl_signature_algorithms"
-	d.Set("ssl_signature_algorithms", string(*object.Ssl.SignatureAlgorithms))
-	lastAssignedField = "ssl_support_ssl3"
-	d.Set("ssl_support_ssl3", string(*object.Ssl.SupportSsl3))
-	lastAssignedField = "ssl_support_tls1"
-	d.Set("ssl_support_tls1", string(*object.Ssl.SupportTls1))
-	lastAssignedField = "ssl_support_tls1_1"
-	d.Set("ssl_support_tls1_1", string(*object.Ssl.SupportTls11))
-	lastAssignedField = "ssl_support_tls1_2"
-	d.Set("ssl_support_tls1_2", string(*object.Ssl.SupportTls12))
-	lastAssignedField = "ssl_support_tls1_3"
-	d.Set("ssl_support_tls1_3", string(*object.Ssl.SupportTls13))
-	lastAssignedField = "ssl_trust_magic"
-	d.Set("ssl_trust_magic", bool(*object.Ssl.TrustMagic))
+	lastAssignedField = "ssl.session_cache_enabled"
+	ssl["session_cache_enabled"] = string(*object.Ssl.SessionCacheEnabled)
+	lastAssignedField = "ssl.session_tickets_enabled"
+	ssl["session_tickets_enabled"] = string(*object.Ssl.SessionTicketsEnabled)
+	lastAssignedField = "ssl.signature_algorithms"
+	ssl["signature_algorithms"] = string(*object.Ssl.SignatureAlgorithms)
+	lastAssignedField = "ssl.support_ssl3"
+	ssl["support_ssl3"] = string(*object.Ssl.SupportSsl3)
+	lastAssignedField = "ssl.support_tls1"
+	ssl["support_tls1"] = string(*object.Ssl.SupportTls1)
+	lastAssignedField = "ssl.support_tls1_1"
+	ssl["support_tls1_1"] = string(*object.Ssl.SupportTls11)
+	lastAssignedField = "ssl.support_tls1_2"
+	ssl["support_tls1_2"] = string(*object.Ssl.SupportTls12)
+	lastAssignedField = "ssl.support_tls1_3"
+	ssl["support_tls1_3"] = string(*object.Ssl.SupportTls13)
+	lastAssignedField = "ssl.trust_magic"
+	ssl["trust_magic"] = bool(*object.Ssl.TrustMagic)
+	setSection(d, "ssl", "ssl", ssl, getResourceVirtualServerSchema()["ssl"].Elem.(*schema.Resource).Schema)
 	lastAssignedField = "syslog_enabled"
 	d.Set("syslog_enabled", bool(*object.Syslog.Enabled))
 	lastAssignedField = "syslog_format"
@@ -2111,8 +2176,12 @@ func resourceVirtualServerExists(d *schema.ResourceData, tm interface{}) (bool,
 
 func resourceVirtualServerCreate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_virtual_server", objectName)
+	defer unlock()
 	object := tm.(*vtm.VirtualTrafficManager).NewVirtualServer(objectName, d.Get("pool").(string), d.Get("port").(int))
-	resourceVirtualServerObjectFieldAssignments(d, object)
+	if err := resourceVirtualServerObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error creating vtm_virtual_server '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -2124,11 +2193,15 @@ func resourceVirtualServerCreate(d *schema.ResourceData, tm interface{}) error {
 
 func resourceVirtualServerUpdate(d *schema.ResourceData, tm interface{}) error {
 	objectName := d.Get("name").(string)
+	unlock := lockConfigObject("vtm_virtual_server", objectName)
+	defer unlock()
 	object, err := tm.(*vtm.VirtualTrafficManager).GetVirtualServer(objectName)
 	if err != nil {
 		return fmt.Errorf("Failed to update vtm_virtual_server '%v': %v", objectName, err)
 	}
-	resourceVirtualServerObjectFieldAssignments(d, object)
+	if err := resourceVirtualServerObjectFieldAssignments(d, object); err != nil {
+		return fmt.Errorf("Error updating vtm_virtual_server '%s': %v", objectName, err)
+	}
 	_, applyErr := object.Apply()
 	if applyErr != nil {
 		info := formatErrorInfo(applyErr.ErrorInfo.(map[string]interface{}))
@@ -2138,7 +2211,17 @@ func resourceVirtualServerUpdate(d *schema.ResourceData, tm interface{}) error {
 	return nil
 }
 
-func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object *vtm.VirtualServer) {
+func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object *vtm.VirtualServer) error {
+	currentRules := map[string][]string{}
+	for field, rules := range virtualServerRuleLists(object) {
+		if *rules != nil {
+			currentRules[field] = **rules
+		}
+	}
+	defer setSectionDefaults(d, "connection_settings", "connection", getResourceVirtualServerSchema()["connection_settings"].Elem.(*schema.Resource).Schema)()
+	defer setSectionDefaults(d, "http", "http", getResourceVirtualServerSchema()["http"].Elem.(*schema.Resource).Schema)()
+	defer setSectionDefaults(d, "ssl", "ssl", getResourceVirtualServerSchema()["ssl"].Elem.(*schema.Resource).Schema)()
+
 	setString(&object.Basic.BandwidthClass, d, "bandwidth_class")
 
 	if _, ok := d.GetOk("completion_rules"); ok {
@@ -2199,7 +2282,9 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 
 	object.Aptimizer.Profile = &vtm.VirtualServerProfileTable{}
 	if aptimizerProfileJson, ok := d.GetOk("aptimizer_profile_json"); ok {
-		_ = json.Unmarshal([]byte(aptimizerProfileJson.(string)), object.Aptimizer.Profile)
+		if err := json.Unmarshal([]byte(aptimizerProfileJson.(string)), object.Aptimizer.Profile); err != nil {
+			return fmt.Errorf("Invalid aptimizer_profile_json: %v", err)
+		}
 	} else if aptimizerProfile, ok := d.GetOk("aptimizer_profile"); ok {
 		for _, row := range aptimizerProfile.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
@@ -2223,13 +2308,13 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 	setInt(&object.Auth.SessionTimeout, d, "auth_session_timeout")
 	setString(&object.Auth.Type, d, "auth_type")
 	setBool(&object.Auth.Verbose, d, "auth_verbose")
-	setBool(&object.Connection.Keepalive, d, "connection_keepalive")
-	setInt(&object.Connection.KeepaliveTimeout, d, "connection_keepalive_timeout")
-	setInt(&object.Connection.MaxClientBuffer, d, "connection_max_client_buffer")
-	setInt(&object.Connection.MaxServerBuffer, d, "connection_max_server_buffer")
-	setInt(&object.Connection.MaxTransactionDuration, d, "connection_max_transaction_duration")
-	setString(&object.Connection.ServerFirstBanner, d, "connection_server_first_banner")
-	setInt(&object.Connection.Timeout, d, "connection_timeout")
+	setBool(&object.Connection.Keepalive, d, "connection_settings.0.keepalive")
+	setInt(&object.Connection.KeepaliveTimeout, d, "connection_settings.0.keepalive_timeout")
+	setInt(&object.Connection.MaxClientBuffer, d, "connection_settings.0.max_client_buffer")
+	setInt(&object.Connection.MaxServerBuffer, d, "connection_settings.0.max_server_buffer")
+	setInt(&object.Connection.MaxTransactionDuration, d, "connection_settings.0.max_transaction_duration")
+	setString(&object.Connection.ServerFirstBanner, d, "connection_settings.0.server_first_banner")
+	setInt(&object.Connection.Timeout, d, "connection_settings.0.timeout")
 	setString(&object.ConnectionErrors.ErrorFile, d, "connection_errors_error_file")
 	setString(&object.Cookie.Domain, d, "cookie_domain")
 	setString(&object.Cookie.NewDomain, d, "cookie_new_domain")
@@ -2267,17 +2352,17 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 	setInt(&object.Gzip.MaxSize, d, "gzip_max_size")
 	setInt(&object.Gzip.MinSize, d, "gzip_min_size")
 	setBool(&object.Gzip.NoSize, d, "gzip_no_size")
-	setBool(&object.Http.AddClusterIp, d, "http_add_cluster_ip")
-	setBool(&object.Http.AddXForwardedFor, d, "http_add_x_forwarded_for")
-	setBool(&object.Http.AddXForwardedProto, d, "http_add_x_forwarded_proto")
-	setBool(&object.Http.AutodetectUpgradeHeaders, d, "http_autodetect_upgrade_headers")
-	setString(&object.Http.ChunkOverheadForwarding, d, "http_chunk_overhead_forwarding")
-	setString(&object.Http.LocationRegex, d, "http_location_regex")
-	setString(&object.Http.LocationReplace, d, "http_location_replace")
-	setString(&object.Http.LocationRewrite, d, "http_location_rewrite")
-	setString(&object.Http.MimeDefault, d, "http_mime_default")
-	setBool(&object.Http.MimeDetect, d, "http_mime_detect")
-	setBool(&object.Http.StripXForwardedProto, d, "http_strip_x_forwarded_proto")
+	setBool(&object.Http.AddClusterIp, d, "http.0.add_cluster_ip")
+	setBool(&object.Http.AddXForwardedFor, d, "http.0.add_x_forwarded_for")
+	setBool(&object.Http.AddXForwardedProto, d, "http.0.add_x_forwarded_proto")
+	setBool(&object.Http.AutodetectUpgradeHeaders, d, "http.0.autodetect_upgrade_headers")
+	setString(&object.Http.ChunkOverheadForwarding, d, "http.0.chunk_overhead_forwarding")
+	setString(&object.Http.LocationRegex, d, "http.0.location_regex")
+	setString(&object.Http.LocationReplace, d, "http.0.location_replace")
+	setString(&object.Http.LocationRewrite, d, "http.0.location_rewrite")
+	setString(&object.Http.MimeDefault, d, "http.0.mime_default")
+	setBool(&object.Http.MimeDetect, d, "http.0.mime_detect")
+	setBool(&object.Http.StripXForwardedProto, d, "http.0.strip_x_forwarded_proto")
 	setInt(&object.Http2.ConnectTimeout, d, "http2_connect_timeout")
 	setInt(&object.Http2.DataFrameSize, d, "http2_data_frame_size")
 	setBool(&object.Http2.Enabled, d, "http2_enabled")
@@ -2335,38 +2420,37 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 	setBool(&object.Sip.TimeoutMessages, d, "sip_timeout_messages")
 	setInt(&object.Sip.TransactionTimeout, d, "sip_transaction_timeout")
 	setBool(&object.Smtp.ExpectStarttls, d, "smtp_expect_starttls")
-	setBool(&object.Ssl.AddHttpHeaders, d, "ssl_add_http_headers")
-	setString(&object.Ssl.CipherSuites, d, "ssl_cipher_suites")
+	setBool(&object.Ssl.AddHttpHeaders, d, "ssl.0.add_http_headers")
+	setString(&object.Ssl.CipherSuites, d, "ssl.0.cipher_suites")
 
-	if _, ok := d.GetOk("ssl_client_cert_cas"); ok {
-		setStringSet(&object.Ssl.ClientCertCas, d, "ssl_client_cert_cas")
+	if _, ok := d.GetOk("ssl.0.client_cert_cas"); ok {
+		setStringSet(&object.Ssl.ClientCertCas, d, "ssl.0.client_cert_cas")
 	} else {
 		object.Ssl.ClientCertCas = &[]string{}
-		d.Set("ssl_client_cert_cas", []string(*object.Ssl.ClientCertCas))
 	}
-	setString(&object.Ssl.ClientCertHeaders, d, "ssl_client_cert_headers")
+	setString(&object.Ssl.ClientCertHeaders, d, "ssl.0.client_cert_headers")
 
-	if _, ok := d.GetOk("ssl_elliptic_curves"); ok {
-		setStringList(&object.Ssl.EllipticCurves, d, "ssl_elliptic_curves")
+	if _, ok := d.GetOk("ssl.0.elliptic_curves"); ok {
+		setStringList(&object.Ssl.EllipticCurves, d, "ssl.0.elliptic_curves")
 	} else {
 		object.Ssl.EllipticCurves = &[]string{}
-		d.Set("ssl_elliptic_curves", []string(*object.Ssl.EllipticCurves))
 	}
-	setString(&object.Ssl.HonorFallbackScsv, d, "ssl_honor_fallback_scsv")
+	setString(&object.Ssl.HonorFallbackScsv, d, "ssl.0.honor_fallback_scsv")
 
-	if _, ok := d.GetOk("ssl_issued_certs_never_expire"); ok {
-		setStringSet(&object.Ssl.IssuedCertsNeverExpire, d, "ssl_issued_certs_never_expire")
+	if _, ok := d.GetOk("ssl.0.issued_certs_never_expire"); ok {
+		setStringSet(&object.Ssl.IssuedCertsNeverExpire, d, "ssl.0.issued_certs_never_expire")
 	} else {
 		object.Ssl.IssuedCertsNeverExpire = &[]string{}
-		d.Set("ssl_issued_certs_never_expire", []string(*object.Ssl.IssuedCertsNeverExpire))
 	}
-	setInt(&object.Ssl.IssuedCertsNeverExpireDepth, d, "ssl_issued_certs_never_expire_depth")
-	setBool(&object.Ssl.OcspEnable, d, "ssl_ocsp_enable")
+	setInt(&object.Ssl.IssuedCertsNeverExpireDepth, d, "ssl.0.issued_certs_never_expire_depth")
+	setBool(&object.Ssl.OcspEnable, d, "ssl.0.ocsp_enable")
 
 	object.Ssl.OcspIssuers = &vtm.VirtualServerOcspIssuersTable{}
 	if sslOcspIssuersJson, ok := d.GetOk("ssl_ocsp_issuers_json"); ok {
-		_ = json.Unmarshal([]byte(sslOcspIssuersJson.(string)), object.Ssl.OcspIssuers)
-	} else if sslOcspIssuers, ok := d.GetOk("ssl_ocsp_issuers"); ok {
+		if err := json.Unmarshal([]byte(sslOcspIssuersJson.(string)), object.Ssl.OcspIssuers); err != nil {
+			return fmt.Errorf("Invalid ssl_ocsp_issuers_json: %v", err)
+		}
+	} else if sslOcspIssuers, ok := d.GetOk("ssl.0.ocsp_issuers"); ok {
 		for _, row := range sslOcspIssuers.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
 			VtmObject := vtm.VirtualServerOcspIssuers{}
@@ -2379,29 +2463,27 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 			VtmObject.Url = getStringAddr(itemTerraform["url"].(string))
 			*object.Ssl.OcspIssuers = append(*object.Ssl.OcspIssuers, VtmObject)
 		}
-		d.Set("ssl_ocsp_issuers", sslOcspIssuers)
-	} else {
-		d.Set("ssl_ocsp_issuers", make([]map[string]interface{}, 0, len(*object.Ssl.OcspIssuers)))
 	}
-	setInt(&object.Ssl.OcspMaxResponseAge, d, "ssl_ocsp_max_response_age")
-	setBool(&object.Ssl.OcspStapling, d, "ssl_ocsp_stapling")
-	setInt(&object.Ssl.OcspTimeTolerance, d, "ssl_ocsp_time_tolerance")
-	setInt(&object.Ssl.OcspTimeout, d, "ssl_ocsp_timeout")
-	setString(&object.Ssl.RequestClientCert, d, "ssl_request_client_cert")
-	setBool(&object.Ssl.SendCloseAlerts, d, "ssl_send_close_alerts")
-
-	if _, ok := d.GetOk("ssl_server_cert_alt_certificates"); ok {
-		setStringList(&object.Ssl.ServerCertAltCertificates, d, "ssl_server_cert_alt_certificates")
+	setInt(&object.Ssl.OcspMaxResponseAge, d, "ssl.0.ocsp_max_response_age")
+	setBool(&object.Ssl.OcspStapling, d, "ssl.0.ocsp_stapling")
+	setInt(&object.Ssl.OcspTimeTolerance, d, "ssl.0.ocsp_time_tolerance")
+	setInt(&object.Ssl.OcspTimeout, d, "ssl.0.ocsp_timeout")
+	setString(&object.Ssl.RequestClientCert, d, "ssl.0.request_client_cert")
+	setBool(&object.Ssl.SendCloseAlerts, d, "ssl.0.send_close_alerts")
+
+	if _, ok := d.GetOk("ssl.0.server_cert_alt_certificates"); ok {
+		setStringList(&object.Ssl.ServerCertAltCertificates, d, "ssl.0.server_cert_alt_certificates")
 	} else {
 		object.Ssl.ServerCertAltCertificates = &[]string{}
-		d.Set("ssl_server_cert_alt_certificates", []string(*object.Ssl.ServerCertAltCertificates))
 	}
-	setString(&object.Ssl.ServerCertDefault, d, "ssl_server_cert_default")
+	setString(&object.Ssl.ServerCertDefault, d, "ssl.0.server_cert_default")
 
 	object.Ssl.ServerCertHostMapping = &vtm.VirtualServerServerCertHostMappingTable{}
 	if sslServerCertHostMappingJson, ok := d.GetOk("ssl_server_cert_host_mapping_json"); ok {
-		_ = json.Unmarshal([]byte(sslServerCertHostMappingJson.(string)), object.Ssl.ServerCertHostMapping)
-	} else if sslServerCertHostMapping, ok := d.GetOk("ssl_server_cert_host_mapping"); ok {
+		if err := json.Unmarshal([]byte(sslServerCertHostMappingJson.(string)), object.Ssl.ServerCertHostMapping); err != nil {
+			return fmt.Errorf("Invalid ssl_server_cert_host_mapping_json: %v", err)
+		}
+	} else if sslServerCertHostMapping, ok := d.GetOk("ssl.0.server_cert_host_mapping"); ok {
 		for _, row := range sslServerCertHostMapping.(*schema.Set).List() {
 			itemTerraform := row.(map[string]interface{})
 			VtmObject := vtm.VirtualServerServerCertHostMapping{}
@@ -2410,19 +2492,16 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 			VtmObject.Host = getStringAddr(itemTerraform["host"].(string))
 			*object.Ssl.ServerCertHostMapping = append(*object.Ssl.ServerCertHostMapping, VtmObject)
 		}
-		d.Set("ssl_server_cert_host_mapping", sslServerCertHostMapping)
-	} else {
-		d.Set("ssl_server_cert_host_mapping", make([]map[string]interface{}, 0, len(*object.Ssl.ServerCertHostMapping)))
 	}
-	setString(&object.Ssl.SessionCacheEnabled, d, "ssl_session_cache_enabled")
-	setString(&object.Ssl.SessionTicketsEnabled, d, "ssl_session_tickets_enabled")
-	setString(&object.Ssl.SignatureAlgorithms, d, "ssl_signature_algorithms")
-	setString(&object.Ssl.SupportSsl3, d, "ssl_support_ssl3")
-	setString(&object.Ssl.SupportTls1, d, "ssl_support_tls1")
-	setString(&object.Ssl.SupportTls11, d, "ssl_support_tls1_1")
-	setString(&object.Ssl.SupportTls12, d, "ssl_support_tls1_2")
-	setString(&object.Ssl.SupportTls13, d, "ssl_support_tls1_3")
-	setBool(&object.Ssl.TrustMagic, d, "ssl_trust_magic")
+	setString(&object.Ssl.SessionCacheEnabled, d, "ssl.0.session_cache_enabled")
+	setString(&object.Ssl.SessionTicketsEnabled, d, "ssl.0.session_tickets_enabled")
+	setString(&object.Ssl.SignatureAlgorithms, d, "ssl.0.signature_algorithms")
+	setString(&object.Ssl.SupportSsl3, d, "ssl.0.support_ssl3")
+	setString(&object.Ssl.SupportTls1, d, "ssl.0.support_tls1")
+	setString(&object.Ssl.SupportTls11, d, "ssl.0.support_tls1_1")
+	setString(&object.Ssl.SupportTls12, d, "ssl.0.support_tls1_2")
+	setString(&object.Ssl.SupportTls13, d, "ssl.0.support_tls1_3")
+	setBool(&object.Ssl.TrustMagic, d, "ssl.0.trust_magic")
 	setBool(&object.Syslog.Enabled, d, "syslog_enabled")
 	setString(&object.Syslog.Format, d, "syslog_format")
 	setString(&object.Syslog.IpEndPoint, d, "syslog_ip_end_point")
@@ -2449,6 +2528,16 @@ func resourceVirtualServerObjectFieldAssignments(d *schema.ResourceData, object
 	setInt(&object.WebCache.ErrorPageTime, d, "web_cache_error_page_time")
 	setInt(&object.WebCache.MaxTime, d, "web_cache_max_time")
 	setInt(&object.WebCache.RefreshTime, d, "web_cache_refresh_time")
+
+	// Rules the vTM has that this resource does not manage keep their place
+	// relative to the managed ones.
+	if d.Get("ignore_external_rules") == true {
+		for field, rules := range virtualServerRuleLists(object) {
+			merged := mergeExternalListEntries(d, field, **rules, currentRules[field])
+			*rules = &merged
+		}
+	}
+	return nil
 }
 
 func resourceVirtualServerDelete(d *schema.ResourceData, tm interface{}) error {
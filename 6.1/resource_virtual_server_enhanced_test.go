@@ -61,7 +61,7 @@ func TestResourceVirtualServerEnhanced(t *testing.T) {
 					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "gzip_include_mime.4008173114", "text/html"),
 					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "gzip_include_mime.2435821618", "text/plain"),
 					// Test that a default-empty table is empty
-					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl_ocsp_issuers.#", "0"),
+					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl.0.ocsp_issuers.#", "0"),
 				),
 			},
 			{
@@ -83,9 +83,9 @@ func TestResourceVirtualServerEnhanced(t *testing.T) {
 					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "gzip_include_mime.#", "1"),
 					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "gzip_include_mime.2372034088", "application/json"),
 					// Test that a table is correctly populated
-					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl_ocsp_issuers.#", "2"),
-					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl_ocsp_issuers.3824273407.issuer", "me"),
-					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl_ocsp_issuers.2238529080.issuer", "DEFAULT"),
+					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl.0.ocsp_issuers.#", "2"),
+					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl.0.ocsp_issuers.3824273407.issuer", "me"),
+					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl.0.ocsp_issuers.2238529080.issuer", "DEFAULT"),
 				),
 			},
 			{
@@ -100,7 +100,7 @@ func TestResourceVirtualServerEnhanced(t *testing.T) {
 					// Check that a removed default-empty list field has been set to empty
 					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "request_rules.#", "0"),
 					// Check that removed table rows are gone
-					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl_ocsp_issuers.#", "0"),
+					resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "ssl.0.ocsp_issuers.#", "0"),
 					// Test that a default-populated list correctly reverts to default when parameter removed
 					// TODO Add this back in when VTMTF-18 is fixed
 					//resource.TestCheckResourceAttr("vtm_virtual_server.my_vs", "gzip_include_mime.#", "2"),
@@ -254,6 +254,7 @@ func getBasicVirtualServerEnhancedConfig(name string) string {
 			name = "%s"
 			pool = "discard"
 			port = 1234
+			ssl {}
 		}`,
 		name,
 	)
@@ -264,6 +265,7 @@ func getMissingVirtualServerEnhancedConfig() string {
 		resource "vtm_virtual_server" "my_vs" {
 			pool = "discard"
 			port = 1234
+			ssl {}
 		}`,
 	)
 }
@@ -274,6 +276,7 @@ func getEmptyVirtualServerEnhancedConfig() string {
 			name = ""
 			pool = "discard"
 			port = 1234
+			ssl {}
 		}`,
 	)
 }
@@ -290,11 +293,13 @@ func getAdvancedVirtualServerEnhancedConfig(name, protocol string, port int) str
 			gzip_include_mime = ["application/json"]
 			web_cache_enabled = true
 
-			ssl_ocsp_issuers {
-				issuer = "me"
-			}
-			ssl_ocsp_issuers {
-				issuer = "DEFAULT"
+			ssl {
+				ocsp_issuers {
+					issuer = "me"
+				}
+				ocsp_issuers {
+					issuer = "DEFAULT"
+				}
 			}
 		}`,
 		name, port, protocol,
@@ -381,10 +386,12 @@ cMbhPHfTIYYM1ijaqFU/LEXOQ6jTieldVIvC0KVSue7+eQtn
 			name = "%s"
 			pool = "discard"
 			port = 80
-			ssl_server_cert_host_mapping {
-				host = "www.testing.com"
-				certificate = "cert1"
-				alt_certificates = ["%s", "%s"]
+			ssl {
+				server_cert_host_mapping {
+					host = "www.testing.com"
+					certificate = "cert1"
+					alt_certificates = ["%s", "%s"]
+				}
 			}
 			aptimizer_profile {
 				name = "test"
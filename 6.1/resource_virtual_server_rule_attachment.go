@@ -0,0 +1,343 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+var ruleAttachmentPhases = []string{"request", "response", "completion"}
+
+// vtm_virtual_server_rule_attachment adds one rule to one of a virtual
+// server's rule lists, leaving the other entries and their order alone. A
+// vtm_virtual_server resource for the same virtual server should set
+// ignore_external_rules so that it does not remove the rule again.
+func resourceVirtualServerRuleAttachment() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceVirtualServerRuleAttachmentRead,
+		Create: resourceVirtualServerRuleAttachmentCreate,
+		Update: resourceVirtualServerRuleAttachmentUpdate,
+		Delete: resourceVirtualServerRuleAttachmentDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceVirtualServerRuleAttachmentImport,
+		},
+
+		Schema: getResourceVirtualServerRuleAttachmentSchema(),
+	}
+}
+
+func getResourceVirtualServerRuleAttachmentSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+
+		// The name of the virtual server to attach the rule to.
+		"virtual_server": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// The name of the rule.
+		"rule": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		// Whether the rule is a request, response or completion rule.
+		"phase": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(ruleAttachmentPhases, false),
+			Default:      "request",
+		},
+
+		// Where the rule is placed in the list: "first", "last", or
+		//  "before" or "after" the rule named in "relative_to". A rule
+		//  that no longer has its configured position is moved back.
+		//  Rules placed in between by other attachments do not count, so
+		//  several attachments can share a position.
+		"position": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"first", "last", "before", "after"}, false),
+			Default:      "last",
+		},
+
+		// The rule that "before" and "after" positions are relative to.
+		"relative_to": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		// The other rules that the rule was placed ahead of as a "first"
+		//  rule. Only these rules break the position if they move ahead of
+		//  it.
+		"placed_ahead_of": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		// The other rules that the rule was placed behind as a "last" rule.
+		"placed_behind": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+func resourceVirtualServerRuleAttachmentRead(d *schema.ResourceData, tm interface{}) error {
+	virtualServer := d.Get("virtual_server").(string)
+	rule := d.Get("rule").(string)
+	phase := d.Get("phase").(string)
+	object, err := tm.(*vtm.VirtualTrafficManager).GetVirtualServer(virtualServer)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read vtm_virtual_server_rule_attachment '%v/%v/%v': %v", virtualServer, phase, rule, err.ErrorText)
+	}
+	rules := virtualServerRuleLists(object)[phase+"_rules"]
+	if *rules == nil || indexOfString(**rules, rule) < 0 {
+		d.SetId("")
+		return nil
+	}
+	aheadOf := expandStringList(d.Get("placed_ahead_of").([]interface{}))
+	behind := expandStringList(d.Get("placed_behind").([]interface{}))
+	position, relativeTo := ruleAttachmentPosition(**rules, rule, d.Get("position").(string), d.Get("relative_to").(string), aheadOf, behind)
+	// Imported attachments have no position yet, and take the one read
+	// back from the list.
+	if d.Get("position") == "" {
+		aheadOf, behind = rulePlacement(**rules, rule, position)
+		d.Set("placed_ahead_of", aheadOf)
+		d.Set("placed_behind", behind)
+	}
+	d.Set("position", position)
+	d.Set("relative_to", relativeTo)
+	d.SetId(virtualServer + "/" + phase + "/" + rule)
+	return nil
+}
+
+// ruleAttachmentPosition returns the position and relative_to values that
+// describe where a rule is in a rule list. The given position is kept while
+// it still holds: a "first" rule is still ahead of the rules it was placed
+// ahead of, a "last" rule still behind the rules it was placed behind, and
+// a "before" or "after" rule still on its side of relative_to. Other rules
+// in between, such as those of other attachments, do not matter. Otherwise
+// the rule is described as first, last, or after the rule before it.
+func ruleAttachmentPosition(rules []string, rule, position, relativeTo string, aheadOf, behind []string) (string, string) {
+	index := indexOfString(rules, rule)
+	relativeIndex := indexOfString(rules, relativeTo)
+	holds := false
+	switch position {
+	case "first":
+		holds = firstRuleIndex(rules, aheadOf) > index
+	case "last":
+		holds = lastRuleIndex(rules, behind) < index
+	case "before":
+		holds = relativeIndex >= 0 && index < relativeIndex
+	case "after":
+		holds = relativeIndex >= 0 && index > relativeIndex
+	}
+	switch {
+	case holds:
+		return position, relativeTo
+	case index == len(rules)-1:
+		return "last", ""
+	case index == 0:
+		return "first", ""
+	}
+	return "after", rules[index-1]
+}
+
+// rulePlacement returns the rules that a rule at position in a rule list is
+// ahead of as a "first" rule, and behind as a "last" rule.
+func rulePlacement(rules []string, rule, position string) ([]string, []string) {
+	index := indexOfString(rules, rule)
+	aheadOf, behind := []string{}, []string{}
+	switch {
+	case index < 0:
+	case position == "first":
+		aheadOf = append(aheadOf, rules[index+1:]...)
+	case position == "last":
+		behind = append(behind, rules[:index]...)
+	}
+	return aheadOf, behind
+}
+
+// firstRuleIndex returns the index of the first of others in the rule list,
+// or the length of the list if none of them is in it.
+func firstRuleIndex(rules, others []string) int {
+	first := len(rules)
+	for _, other := range others {
+		if index := indexOfString(rules, other); index >= 0 && index < first {
+			first = index
+		}
+	}
+	return first
+}
+
+// lastRuleIndex returns the index of the last of others in the rule list,
+// or -1 if none of them is in it.
+func lastRuleIndex(rules, others []string) int {
+	last := -1
+	for _, other := range others {
+		if index := indexOfString(rules, other); index > last {
+			last = index
+		}
+	}
+	return last
+}
+
+func resourceVirtualServerRuleAttachmentCreate(d *schema.ResourceData, tm interface{}) error {
+	virtualServer := d.Get("virtual_server").(string)
+	rule := d.Get("rule").(string)
+	phase := d.Get("phase").(string)
+
+	// The rule is placed on the first pass; later passes only put it back
+	// if another client's write removed it.
+	placed := false
+	err := modifyVirtualServerRules(tm, virtualServer, phase, func(rules []string) ([]string, error) {
+		if !placed && indexOfString(rules, rule) >= 0 {
+			return nil, fmt.Errorf("rule is already attached; import it with the ID '%s/%s/%s'", virtualServer, phase, rule)
+		}
+		if placed && indexOfString(rules, rule) >= 0 {
+			return rules, nil
+		}
+		placed = true
+		return placeRule(rules, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating vtm_virtual_server_rule_attachment '%s/%s/%s': %v", virtualServer, phase, rule, err)
+	}
+	d.SetId(virtualServer + "/" + phase + "/" + rule)
+	return nil
+}
+
+func resourceVirtualServerRuleAttachmentUpdate(d *schema.ResourceData, tm interface{}) error {
+	virtualServer := d.Get("virtual_server").(string)
+	rule := d.Get("rule").(string)
+	phase := d.Get("phase").(string)
+	if !d.HasChange("position") && !d.HasChange("relative_to") {
+		return nil
+	}
+	placed := false
+	err := modifyVirtualServerRules(tm, virtualServer, phase, func(rules []string) ([]string, error) {
+		if placed && indexOfString(rules, rule) >= 0 {
+			return rules, nil
+		}
+		placed = true
+		return placeRule(rules, d)
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating vtm_virtual_server_rule_attachment '%s/%s/%s': %v", virtualServer, phase, rule, err)
+	}
+	return nil
+}
+
+func resourceVirtualServerRuleAttachmentDelete(d *schema.ResourceData, tm interface{}) error {
+	virtualServer := d.Get("virtual_server").(string)
+	rule := d.Get("rule").(string)
+	phase := d.Get("phase").(string)
+	err := modifyVirtualServerRules(tm, virtualServer, phase, func(rules []string) ([]string, error) {
+		return removeString(rules, rule), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete vtm_virtual_server_rule_attachment '%v/%v/%v': %v", virtualServer, phase, rule, err)
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceVirtualServerRuleAttachmentImport(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	for _, phase := range ruleAttachmentPhases {
+		separator := "/" + phase + "/"
+		if index := strings.Index(d.Id(), separator); index > 0 && index+len(separator) < len(d.Id()) {
+			d.Set("virtual_server", d.Id()[:index])
+			d.Set("phase", phase)
+			d.Set("rule", d.Id()[index+len(separator):])
+			return []*schema.ResourceData{d}, nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid vtm_virtual_server_rule_attachment ID '%s', expected '<virtual_server>/<phase>/<rule>'", d.Id())
+}
+
+// placeRule returns a copy of the rule list with the resource's rule moved
+// to, or inserted at, its configured position, and records the rules it was
+// placed ahead of or behind. A "first" or "last" rule that was placed before
+// is only moved ahead of, or behind, the rules it was placed ahead of or
+// behind then, so that attachments sharing the position do not keep
+// swapping places.
+func placeRule(rules []string, d *schema.ResourceData) ([]string, error) {
+	rule := d.Get("rule").(string)
+	position := d.Get("position").(string)
+	relativeTo := d.Get("relative_to").(string)
+	aheadOf := expandStringList(d.Get("placed_ahead_of").([]interface{}))
+	behind := expandStringList(d.Get("placed_behind").([]interface{}))
+	placed := removeString(rules, rule)
+
+	index := len(placed)
+	switch position {
+	case "first":
+		index = 0
+		if first := firstRuleIndex(placed, aheadOf); indexOfString(rules, rule) >= 0 && first < len(placed) {
+			index = first
+		}
+	case "last":
+		if last := lastRuleIndex(placed, behind); indexOfString(rules, rule) >= 0 && last >= 0 {
+			index = last + 1
+		}
+	case "before", "after":
+		if relativeTo == "" {
+			return nil, fmt.Errorf("relative_to must be set when position is '%s'", position)
+		}
+		index = indexOfString(placed, relativeTo)
+		if index < 0 {
+			return nil, fmt.Errorf("rule '%s' is not in the %s rules", relativeTo, d.Get("phase"))
+		}
+		if position == "after" {
+			index++
+		}
+	}
+	placed = append(placed, "")
+	copy(placed[index+1:], placed[index:])
+	placed[index] = rule
+
+	if (position == "first" && len(aheadOf) == 0) || (position == "last" && len(behind) == 0) || (position != "first" && position != "last") {
+		aheadOf, behind = rulePlacement(placed, rule, position)
+	}
+	d.Set("placed_ahead_of", aheadOf)
+	d.Set("placed_behind", behind)
+	return placed, nil
+}
+
+// modifyVirtualServerRules applies update to one of the virtual server's
+// current rule lists, and writes the virtual server back if that changed
+// anything.
+func modifyVirtualServerRules(tm interface{}, virtualServer, phase string, update func([]string) ([]string, error)) error {
+	read := func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+		object, err := tm.GetVirtualServer(objectName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return virtualServerRuleLists(object)[phase+"_rules"], func() *vtm.ReqError {
+			_, applyErr := object.Apply()
+			return applyErr
+		}, nil
+	}
+	return modifyConfigObjectField(tm, "vtm_virtual_server", virtualServer, read, nil, func(value interface{}) (interface{}, error) {
+		return update(jsonStringList(value))
+	})
+}
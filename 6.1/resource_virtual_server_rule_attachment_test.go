@@ -0,0 +1,258 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+/*
+ * This test covers the following cases:
+ *   - Rules attached to a virtual server owned by a vtm_virtual_server resource
+ *   - A vtm_virtual_server with ignore_external_rules keeps those rules
+ *   - "first", "after" and "last" positions
+ *   - Importing attachments reads their position back from the rule list
+ *   - Attachments sharing a position settle instead of swapping places
+ *   - Deleting an attachment leaves the other rules in order
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+func TestResourceVirtualServerRuleAttachment(t *testing.T) {
+	vsName := acctest.RandomWithPrefix("TestRuleAttachment")
+	ruleA := acctest.RandomWithPrefix("TestRuleA")
+	ruleB := acctest.RandomWithPrefix("TestRuleB")
+	waf := acctest.RandomWithPrefix("TestRuleWaf")
+	headers := acctest.RandomWithPrefix("TestRuleHeaders")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVirtualServerRuleAttachmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getVirtualServerRuleAttachmentConfig(vsName, ruleA, ruleB, waf, headers, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vtm_virtual_server.test_vtm_virtual_server", "request_rules.#", "2"),
+					testAccCheckVirtualServerRequestRules(vsName, []string{waf, ruleA, headers, ruleB}),
+				),
+			},
+			{
+				// The virtual server resource must not report the attached rules as drift
+				Config:             getVirtualServerRuleAttachmentConfig(vsName, ruleA, ruleB, waf, headers, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				ResourceName:      "vtm_virtual_server_rule_attachment.headers",
+				ImportState:       true,
+				ImportStateId:     vsName + "/request/" + headers,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "vtm_virtual_server_rule_attachment.waf",
+				ImportState:       true,
+				ImportStateId:     vsName + "/request/" + waf,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getVirtualServerRuleAttachmentConfig(vsName, ruleA, ruleB, waf, headers, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualServerRequestRules(vsName, []string{ruleA, headers, ruleB}),
+				),
+			},
+		},
+	})
+}
+
+func TestRuleAttachmentPosition(t *testing.T) {
+	rules := []string{"waf", "a", "headers", "b"}
+	tables := []struct {
+		rule, position, relativeTo string
+		aheadOf, behind            []string
+		expected                   string
+	}{
+		{"waf", "first", "", []string{"a", "headers", "b"}, nil, "first/"},
+		{"b", "last", "", nil, []string{"waf", "a", "headers"}, "last/"},
+		{"headers", "after", "a", nil, nil, "after/a"},
+		{"headers", "before", "b", nil, nil, "before/b"},
+		// Rules placed in between by other attachments do not matter
+		{"a", "first", "", []string{"headers", "b"}, nil, "first/"},
+		{"headers", "last", "", nil, []string{"a"}, "last/"},
+		{"b", "after", "a", nil, nil, "after/a"},
+		{"waf", "before", "b", nil, nil, "before/b"},
+		// Imported attachments have no position yet
+		{"waf", "", "", nil, nil, "first/"},
+		{"b", "", "", nil, nil, "last/"},
+		{"headers", "", "", nil, nil, "after/a"},
+		// Positions that no longer hold
+		{"a", "first", "", []string{"waf", "b"}, nil, "after/waf"},
+		{"headers", "last", "", nil, []string{"b"}, "after/a"},
+		{"waf", "after", "a", nil, nil, "first/"},
+		{"b", "before", "a", nil, nil, "last/"},
+		{"headers", "after", "missing", nil, nil, "after/a"},
+	}
+	for _, table := range tables {
+		position, relativeTo := ruleAttachmentPosition(rules, table.rule, table.position, table.relativeTo, table.aheadOf, table.behind)
+		if position+"/"+relativeTo != table.expected {
+			t.Errorf("Position of %s (%s %s) is %s/%s, expected %s", table.rule, table.position, table.relativeTo, position, relativeTo, table.expected)
+		}
+	}
+}
+
+func TestPlaceRuleConverges(t *testing.T) {
+	attachments := []*schema.ResourceData{}
+	for _, config := range []map[string]interface{}{
+		{"rule": "first1", "position": "first"},
+		{"rule": "first2", "position": "first"},
+		{"rule": "last1", "position": "last"},
+		{"rule": "last2", "position": "last"},
+		{"rule": "after1", "position": "after", "relative_to": "a"},
+		{"rule": "after2", "position": "after", "relative_to": "a"},
+	} {
+		config["virtual_server"] = "vs"
+		attachments = append(attachments, schema.TestResourceDataRaw(t, getResourceVirtualServerRuleAttachmentSchema(), config))
+	}
+
+	// applyAll reads each attachment back and places the ones whose
+	// position no longer holds, as a plan and apply would.
+	applyAll := func(rules []string) ([]string, int) {
+		moved := 0
+		for _, d := range attachments {
+			if indexOfString(rules, d.Get("rule").(string)) >= 0 {
+				aheadOf := expandStringList(d.Get("placed_ahead_of").([]interface{}))
+				behind := expandStringList(d.Get("placed_behind").([]interface{}))
+				position, relativeTo := ruleAttachmentPosition(rules, d.Get("rule").(string), d.Get("position").(string), d.Get("relative_to").(string), aheadOf, behind)
+				if position == d.Get("position") && relativeTo == d.Get("relative_to") {
+					continue
+				}
+			}
+			placed, err := placeRule(rules, d)
+			if err != nil {
+				t.Fatalf("Placing %s failed: %v", d.Get("rule"), err)
+			}
+			rules = placed
+			moved++
+		}
+		return rules, moved
+	}
+
+	rules, _ := applyAll([]string{"a", "b"})
+	expected := []string{"first2", "first1", "a", "after2", "after1", "b", "last1", "last2"}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Rules are %v, expected %v", rules, expected)
+	}
+	if rules, moved := applyAll(rules); moved != 0 {
+		t.Errorf("A second apply moved %d rules to %v", moved, rules)
+	}
+
+	// Another client moves "b" to the front, and "a" to the end.
+	rules = []string{"b", "first2", "first1", "after2", "after1", "last1", "last2", "a"}
+	for pass := 0; pass < 3; pass++ {
+		var moved int
+		if rules, moved = applyAll(rules); moved == 0 {
+			break
+		}
+	}
+	if rules, moved := applyAll(rules); moved != 0 {
+		t.Errorf("The rules did not settle, the last apply moved %d rules to %v", moved, rules)
+	}
+	for _, d := range attachments {
+		aheadOf := expandStringList(d.Get("placed_ahead_of").([]interface{}))
+		behind := expandStringList(d.Get("placed_behind").([]interface{}))
+		if position, _ := ruleAttachmentPosition(rules, d.Get("rule").(string), d.Get("position").(string), d.Get("relative_to").(string), aheadOf, behind); position != d.Get("position") {
+			t.Errorf("%s is %s in %v, expected %s", d.Get("rule"), position, rules, d.Get("position"))
+		}
+	}
+	if indexOfString(rules, "b") < indexOfString(rules, "first1") || indexOfString(rules, "a") > indexOfString(rules, "last1") {
+		t.Errorf("The first and last rules were not moved back around a and b: %v", rules)
+	}
+}
+
+func testAccCheckVirtualServerRequestRules(vsName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		virtualServer, err := tm.GetVirtualServer(vsName)
+		if err != nil {
+			return fmt.Errorf("VirtualServer %s does not exist: %#v", vsName, err)
+		}
+		if !reflect.DeepEqual(*virtualServer.Basic.RequestRules, expected) {
+			return fmt.Errorf("VirtualServer %s has request rules %v, expected %v", vsName, *virtualServer.Basic.RequestRules, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckVirtualServerRuleAttachmentDestroy(s *terraform.State) error {
+	for _, tfResource := range s.RootModule().Resources {
+		if tfResource.Type != "vtm_virtual_server" {
+			continue
+		}
+		objectName := tfResource.Primary.Attributes["name"]
+		tm := testAccProvider.Meta().(*vtm.VirtualTrafficManager)
+		if _, err := tm.GetVirtualServer(objectName); err == nil {
+			return fmt.Errorf("VirtualServer %s still exists", objectName)
+		}
+	}
+
+	return nil
+}
+
+func getVirtualServerRuleAttachmentConfig(vsName, ruleA, ruleB, waf, headers string, withWaf bool) string {
+	config := fmt.Sprintf(`
+        resource "vtm_rule" "a" {
+			name = "%s"
+			content = "http.setHeader('X-A', '1');"
+		}
+
+		resource "vtm_rule" "b" {
+			name = "%s"
+			content = "http.setHeader('X-B', '1');"
+		}
+
+		resource "vtm_rule" "waf" {
+			name = "%s"
+			content = "http.setHeader('X-WAF', '1');"
+		}
+
+		resource "vtm_rule" "headers" {
+			name = "%s"
+			content = "http.setHeader('X-Headers', '1');"
+		}
+
+		resource "vtm_virtual_server" "test_vtm_virtual_server" {
+			name = "%s"
+			pool = "discard"
+			port = 10
+			ignore_external_rules = true
+			request_rules = ["${vtm_rule.a.name}", "${vtm_rule.b.name}"]
+		}
+
+		resource "vtm_virtual_server_rule_attachment" "headers" {
+			virtual_server = "${vtm_virtual_server.test_vtm_virtual_server.name}"
+			rule = "${vtm_rule.headers.name}"
+			position = "after"
+			relative_to = "${vtm_rule.a.name}"
+		}`,
+		ruleA, ruleB, waf, headers, vsName,
+	)
+	if withWaf {
+		config += `
+
+		resource "vtm_virtual_server_rule_attachment" "waf" {
+			virtual_server = "${vtm_virtual_server.test_vtm_virtual_server.name}"
+			rule = "${vtm_rule.waf.name}"
+			phase = "request"
+			position = "first"
+			depends_on = ["vtm_virtual_server_rule_attachment.headers"]
+		}`
+	}
+	return config
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+const restApiVersion = "6.1"
+
+// restError mirrors the error document returned by the vTM REST API.
+type restError struct {
+	ErrorId   string      `json:"error_id"`
+	ErrorText string      `json:"error_text"`
+	ErrorInfo interface{} `json:"error_info"`
+}
+
+func (e *restError) Error() string {
+	return e.ErrorText
+}
+
+// restClient makes raw requests against the vTM REST API for the features
+// that have no typed equivalent in go-vtm.
+type restClient struct {
+	baseUrl  string
+	username string
+	password string
+	client   *http.Client
+}
+
+var (
+	restClientsMutex sync.Mutex
+	restClients      = map[*vtm.VirtualTrafficManager]*restClient{}
+)
+
+func registerRestClient(tm *vtm.VirtualTrafficManager, baseUrl, username, password string, verifySslCert bool) {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: !verifySslCert},
+	}
+	restClientsMutex.Lock()
+	defer restClientsMutex.Unlock()
+	restClients[tm] = &restClient{
+		baseUrl:  strings.TrimRight(baseUrl, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Transport: transport, Timeout: 60 * time.Second},
+	}
+}
+
+func getRestClient(tm interface{}) (*restClient, error) {
+	restClientsMutex.Lock()
+	defer restClientsMutex.Unlock()
+	client, ok := restClients[tm.(*vtm.VirtualTrafficManager)]
+	if !ok {
+		return nil, fmt.Errorf("No REST connection has been configured for this provider")
+	}
+	return client, nil
+}
+
+// configPath builds the URI of a configuration object. The type may contain
+// slashes (for example "ssl/server_keys"); the object name is escaped so that
+// it may contain any character.
+func configPath(objectType, objectName string) string {
+	path := "config/active/" + strings.Trim(objectType, "/")
+	if objectName != "" {
+		path += "/" + url.PathEscape(objectName)
+	}
+	return path
+}
+
+func (c *restClient) request(method, path, contentType string, body []byte) ([]byte, *restError) {
+	uri := fmt.Sprintf("%s/tm/%s/%s", c.baseUrl, restApiVersion, path)
+	log.Printf("[DEBUG] vTM REST request: %s %s", method, uri)
+	req, err := http.NewRequest(method, uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, &restError{ErrorId: "request.invalid", ErrorText: err.Error()}
+	}
+	req.SetBasicAuth(c.username, c.password)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, &restError{ErrorId: "connection.failed", ErrorText: err.Error()}
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &restError{ErrorId: "connection.failed", ErrorText: err.Error()}
+	}
+	// Bodies are not logged, as they may hold private keys and passwords
+	log.Printf("[DEBUG] vTM REST response: %d (%d bytes)", resp.StatusCode, len(respBody))
+	if resp.StatusCode >= 300 {
+		restErr := &restError{}
+		if json.Unmarshal(respBody, restErr) != nil || restErr.ErrorId == "" {
+			restErr.ErrorId = fmt.Sprintf("http.%d", resp.StatusCode)
+			restErr.ErrorText = strings.TrimSpace(string(respBody))
+		}
+		if resp.StatusCode == http.StatusNotFound && restErr.ErrorId != "resource.not_found" {
+			restErr.ErrorId = "resource.not_found"
+		}
+		return nil, restErr
+	}
+	return respBody, nil
+}
+
+func (c *restClient) getJson(path string) (map[string]interface{}, *restError) {
+	body, err := c.request("GET", path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	document := make(map[string]interface{})
+	if jsonErr := json.Unmarshal(body, &document); jsonErr != nil {
+		return nil, &restError{ErrorId: "response.invalid", ErrorText: jsonErr.Error()}
+	}
+	return document, nil
+}
+
+func (c *restClient) putJson(path string, document interface{}) *restError {
+	body, jsonErr := json.Marshal(document)
+	if jsonErr != nil {
+		return &restError{ErrorId: "request.invalid", ErrorText: jsonErr.Error()}
+	}
+	_, err := c.request("PUT", path, "application/json", body)
+	return err
+}
+
+func (c *restClient) delete(path string) *restError {
+	_, err := c.request("DELETE", path, "", nil)
+	return err
+}
+
+// getFile returns the raw content of a file-like configuration object.
+func (c *restClient) getFile(path string) ([]byte, *restError) {
+	return c.request("GET", path, "", nil)
+}
+
+// putFile uploads the content of a file-like configuration object without
+// any conversion.
+func (c *restClient) putFile(path string, content []byte) *restError {
+	if content == nil {
+		content = []byte{}
+	}
+	_, err := c.request("PUT", path, "application/octet-stream", content)
+	return err
+}
@@ -0,0 +1,585 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SAML 2.0 metadata describes an identity provider in an EntityDescriptor,
+// which gives its entity ID, its single sign-on endpoints and the
+// certificates it signs with. The metadata itself may carry an enveloped XML
+// signature, which is checked with the canonicalization below before the
+// metadata is used.
+
+const (
+	samlMetadataNamespace = "urn:oasis:names:tc:SAML:2.0:metadata"
+	xmlDsigNamespace      = "http://www.w3.org/2000/09/xmldsig#"
+	xmlExcC14nNamespace   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+)
+
+// samlSsoBindings are the bindings of the single sign-on endpoints that can
+// be used, most preferred first.
+var samlSsoBindings = []string{
+	"urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect",
+	"urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST",
+}
+
+// samlMetadata holds the settings of a trusted identity provider read from
+// its metadata. The certificate is base64 DER, as in the metadata.
+type samlMetadata struct {
+	entityId    string
+	url         string
+	certificate string
+}
+
+// xmlElement is an element of a parsed XML document. Names keep the prefix
+// they were written with in Space, so that they can be canonicalized, and
+// the namespaces declared on the element are kept separately in ns.
+type xmlElement struct {
+	parent   *xmlElement
+	name     xml.Name
+	attrs    []xml.Attr
+	ns       map[string]string
+	children []interface{}
+}
+
+// parseXmlDocument returns the document element of an XML document.
+// Comments and processing instructions are dropped, as they are not part of
+// the canonical form that signatures are made over.
+func parseXmlDocument(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *xmlElement
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if root != nil && current == nil {
+				return nil, fmt.Errorf("more than one document element")
+			}
+			element := &xmlElement{parent: current, name: token.Name, ns: map[string]string{}}
+			for _, attr := range token.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					element.ns[""] = attr.Value
+				case attr.Name.Space == "xmlns":
+					element.ns[attr.Name.Local] = attr.Value
+				default:
+					element.attrs = append(element.attrs, attr)
+				}
+			}
+			if current == nil {
+				root = element
+			} else {
+				current.children = append(current.children, element)
+			}
+			current = element
+		case xml.EndElement:
+			if current == nil || current.name != token.Name {
+				return nil, fmt.Errorf("unexpected end element </%s>", xmlQualifiedName(token.Name))
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(token))
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no document element")
+	}
+	if current != nil {
+		return nil, fmt.Errorf("unclosed element <%s>", xmlQualifiedName(current.name))
+	}
+	return root, nil
+}
+
+func xmlQualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// lookup returns the namespace bound to a prefix where the element is.
+func (e *xmlElement) lookup(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return "http://www.w3.org/XML/1998/namespace", true
+	}
+	for element := e; element != nil; element = element.parent {
+		if uri, ok := element.ns[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+// is reports whether the element has a local name in a namespace.
+func (e *xmlElement) is(space, local string) bool {
+	uri, _ := e.lookup(e.name.Space)
+	return e.name.Local == local && uri == space
+}
+
+func (e *xmlElement) attr(local string) (string, bool) {
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// all returns the child elements with a local name in a namespace.
+func (e *xmlElement) all(space, local string) []*xmlElement {
+	found := []*xmlElement{}
+	for _, child := range e.children {
+		if element, ok := child.(*xmlElement); ok && element.is(space, local) {
+			found = append(found, element)
+		}
+	}
+	return found
+}
+
+// first returns the first child element with a local name in a namespace,
+// or nil.
+func (e *xmlElement) first(space, local string) *xmlElement {
+	if found := e.all(space, local); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// text returns the character data directly within the element.
+func (e *xmlElement) text() string {
+	text := ""
+	for _, child := range e.children {
+		if data, ok := child.(string); ok {
+			text += data
+		}
+	}
+	return text
+}
+
+// canonicalizeXml writes an element and its content in canonical form,
+// leaving out skip and its content. In exclusive canonicalization only the
+// namespaces an element uses, and those listed in inclusivePrefixes, are
+// declared; otherwise all the namespaces in scope are.
+func canonicalizeXml(e *xmlElement, exclusive bool, inclusivePrefixes []string, skip *xmlElement) []byte {
+	var buffer bytes.Buffer
+	writeCanonicalXml(&buffer, e, exclusive, inclusivePrefixes, skip, map[string]string{})
+	return buffer.Bytes()
+}
+
+func writeCanonicalXml(buffer *bytes.Buffer, e *xmlElement, exclusive bool, inclusivePrefixes []string, skip *xmlElement, rendered map[string]string) {
+	prefixes := map[string]bool{}
+	if exclusive {
+		prefixes[e.name.Space] = true
+		for _, attr := range e.attrs {
+			if attr.Name.Space != "" {
+				prefixes[attr.Name.Space] = true
+			}
+		}
+		for _, prefix := range inclusivePrefixes {
+			if prefix == "#default" {
+				prefix = ""
+			}
+			prefixes[prefix] = true
+		}
+	} else {
+		for element := e; element != nil; element = element.parent {
+			for prefix := range element.ns {
+				prefixes[prefix] = true
+			}
+		}
+	}
+
+	declared := []string{}
+	inScope := map[string]string{}
+	for prefix, value := range rendered {
+		inScope[prefix] = value
+	}
+	for prefix := range prefixes {
+		if prefix == "xml" {
+			continue
+		}
+		uri, ok := e.lookup(prefix)
+		if (!ok && prefix != "") || rendered[prefix] == uri {
+			continue
+		}
+		inScope[prefix] = uri
+		declared = append(declared, prefix)
+	}
+	sort.Strings(declared)
+
+	attrs := append([]xml.Attr{}, e.attrs...)
+	attrSpace := func(attr xml.Attr) string {
+		if attr.Name.Space == "" {
+			return ""
+		}
+		uri, _ := e.lookup(attr.Name.Space)
+		return uri
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if left, right := attrSpace(attrs[i]), attrSpace(attrs[j]); left != right {
+			return left < right
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	buffer.WriteString("<" + xmlQualifiedName(e.name))
+	for _, prefix := range declared {
+		name := "xmlns"
+		if prefix != "" {
+			name += ":" + prefix
+		}
+		buffer.WriteString(" " + name + "=\"" + escapeCanonicalXmlAttr(inScope[prefix]) + "\"")
+	}
+	for _, attr := range attrs {
+		buffer.WriteString(" " + xmlQualifiedName(attr.Name) + "=\"" + escapeCanonicalXmlAttr(attr.Value) + "\"")
+	}
+	buffer.WriteString(">")
+	for _, child := range e.children {
+		switch child := child.(type) {
+		case string:
+			buffer.WriteString(escapeCanonicalXmlText(child))
+		case *xmlElement:
+			if child != skip {
+				writeCanonicalXml(buffer, child, exclusive, inclusivePrefixes, skip, inScope)
+			}
+		}
+	}
+	buffer.WriteString("</" + xmlQualifiedName(e.name) + ">")
+}
+
+var canonicalXmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var canonicalXmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeCanonicalXmlText(text string) string {
+	return canonicalXmlTextEscaper.Replace(text)
+}
+
+func escapeCanonicalXmlAttr(value string) string {
+	return canonicalXmlAttrEscaper.Replace(value)
+}
+
+// xmlCanonicalization returns whether a canonicalization algorithm is
+// exclusive, and the prefixes it lists to be treated inclusively.
+func xmlCanonicalization(method *xmlElement) (bool, []string, error) {
+	algorithm, _ := method.attr("Algorithm")
+	switch algorithm {
+	case xmlExcC14nNamespace:
+		prefixes := []string{}
+		if inclusive := method.first(xmlExcC14nNamespace, "InclusiveNamespaces"); inclusive != nil {
+			prefixList, _ := inclusive.attr("PrefixList")
+			prefixes = strings.Fields(prefixList)
+		}
+		return true, prefixes, nil
+	case "http://www.w3.org/TR/2001/REC-xml-c14n-20010315":
+		return false, nil, nil
+	}
+	return false, nil, fmt.Errorf("unsupported canonicalization algorithm '%s'", algorithm)
+}
+
+var xmlDigestAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":  crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512": crypto.SHA512,
+}
+
+var xmlSignatureAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1":          crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+func xmlDigest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// decodeXmlBase64 decodes base64 that may be broken over several lines.
+func decodeXmlBase64(text string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+}
+
+// verifyXmlSignature checks an enveloped signature over the element that
+// contains it with the trusted certificate.
+func verifyXmlSignature(signature *xmlElement, trusted *x509.Certificate) error {
+	signed := signature.parent
+	signedInfo := signature.first(xmlDsigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return fmt.Errorf("signature has no SignedInfo")
+	}
+	references := signedInfo.all(xmlDsigNamespace, "Reference")
+	if len(references) != 1 {
+		return fmt.Errorf("signature must have exactly one Reference")
+	}
+	reference := references[0]
+
+	// The reference must be to the signed element itself, so that the
+	// signature cannot be moved to cover some other part of the metadata.
+	uri, _ := reference.attr("URI")
+	if uri != "" {
+		id, ok := signed.attr("ID")
+		if !ok {
+			id, _ = signed.attr("Id")
+		}
+		if uri != "#"+id {
+			return fmt.Errorf("signature reference '%s' is not to the signed element", uri)
+		}
+	}
+
+	exclusive, prefixes, enveloped := false, []string(nil), false
+	if transforms := reference.first(xmlDsigNamespace, "Transforms"); transforms != nil {
+		for _, transform := range transforms.all(xmlDsigNamespace, "Transform") {
+			if algorithm, _ := transform.attr("Algorithm"); algorithm == xmlDsigNamespace+"enveloped-signature" {
+				enveloped = true
+				continue
+			}
+			var err error
+			if exclusive, prefixes, err = xmlCanonicalization(transform); err != nil {
+				return err
+			}
+		}
+	}
+	if !enveloped {
+		return fmt.Errorf("signature is not an enveloped signature")
+	}
+
+	digestMethod := reference.first(xmlDsigNamespace, "DigestMethod")
+	digestValue := reference.first(xmlDsigNamespace, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return fmt.Errorf("signature reference has no digest")
+	}
+	digestAlgorithm, _ := digestMethod.attr("Algorithm")
+	digestHash, ok := xmlDigestAlgorithms[digestAlgorithm]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm '%s'", digestAlgorithm)
+	}
+	expected, err := decodeXmlBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("signature digest is not valid base64: %v", err)
+	}
+	if !bytes.Equal(xmlDigest(digestHash, canonicalizeXml(signed, exclusive, prefixes, signature)), expected) {
+		return fmt.Errorf("digest does not match, the metadata has been changed since it was signed")
+	}
+
+	canonicalizationMethod := signedInfo.first(xmlDsigNamespace, "CanonicalizationMethod")
+	signatureMethod := signedInfo.first(xmlDsigNamespace, "SignatureMethod")
+	signatureValue := signature.first(xmlDsigNamespace, "SignatureValue")
+	if canonicalizationMethod == nil || signatureMethod == nil || signatureValue == nil {
+		return fmt.Errorf("signature is incomplete")
+	}
+	exclusive, prefixes, err = xmlCanonicalization(canonicalizationMethod)
+	if err != nil {
+		return err
+	}
+	signatureAlgorithm, _ := signatureMethod.attr("Algorithm")
+	signatureHash, ok := xmlSignatureAlgorithms[signatureAlgorithm]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm '%s'", signatureAlgorithm)
+	}
+	value, err := decodeXmlBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("signature value is not valid base64: %v", err)
+	}
+
+	digest := xmlDigest(signatureHash, canonicalizeXml(signedInfo, exclusive, prefixes, nil))
+	switch key := trusted.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, signatureHash, digest, value); err != nil {
+			return fmt.Errorf("signature is not valid: %v", err)
+		}
+	case *ecdsa.PublicKey:
+		half := len(value) / 2
+		r, s := new(big.Int).SetBytes(value[:half]), new(big.Int).SetBytes(value[half:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("signature is not valid")
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type %T", trusted.PublicKey)
+	}
+	return nil
+}
+
+// parseXmlCertificate reads a base64 DER certificate from metadata.
+func parseXmlCertificate(text string) (*x509.Certificate, error) {
+	der, err := decodeXmlBase64(text)
+	if err != nil {
+		return nil, fmt.Errorf("certificate is not valid base64: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("certificate is not valid: %v", err)
+	}
+	return certificate, nil
+}
+
+// parseTrustedCertificate reads a certificate in PEM, or as base64 DER in
+// the form used by "certificate".
+func parseTrustedCertificate(text string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(text))
+	if block == nil {
+		return parseXmlCertificate(text)
+	}
+	if block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("PEM block is a %s, not a CERTIFICATE", block.Type)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// checkSamlMetadataValidUntil returns an error if an element's validUntil
+// time has passed.
+func checkSamlMetadataValidUntil(e *xmlElement, now time.Time) error {
+	validUntil, ok := e.attr("validUntil")
+	if !ok {
+		return nil
+	}
+	expiry, err := time.Parse(time.RFC3339, validUntil)
+	if err != nil {
+		return fmt.Errorf("validUntil '%s' is not a valid time", validUntil)
+	}
+	if now.After(expiry) {
+		return fmt.Errorf("metadata expired at %s", validUntil)
+	}
+	return nil
+}
+
+// parseSamlMetadata reads the settings of an identity provider from SAML
+// 2.0 metadata with a single EntityDescriptor, directly or within an
+// EntitiesDescriptor. If a trusted certificate is given, the metadata must
+// be signed with it. Otherwise signatures are not checked, as a certificate
+// taken from the signature itself would prove nothing about who signed it.
+func parseSamlMetadata(data []byte, trustedCertificate string, now time.Time) (*samlMetadata, error) {
+	var trusted *x509.Certificate
+	if trustedCertificate != "" {
+		var err error
+		if trusted, err = parseTrustedCertificate(trustedCertificate); err != nil {
+			return nil, fmt.Errorf("metadata_signing_certificate is not valid: %v", err)
+		}
+	}
+
+	root, err := parseXmlDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("metadata is not valid XML: %v", err)
+	}
+	entity := root
+	if root.is(samlMetadataNamespace, "EntitiesDescriptor") {
+		entities := root.all(samlMetadataNamespace, "EntityDescriptor")
+		if len(entities) != 1 {
+			return nil, fmt.Errorf("metadata describes %d entities, expected one", len(entities))
+		}
+		entity = entities[0]
+	} else if !root.is(samlMetadataNamespace, "EntityDescriptor") {
+		return nil, fmt.Errorf("metadata has no EntityDescriptor")
+	}
+
+	metadata := &samlMetadata{}
+	checked := []*xmlElement{root}
+	if entity != root {
+		checked = append(checked, entity)
+	}
+	for _, element := range checked {
+		if err := checkSamlMetadataValidUntil(element, now); err != nil {
+			return nil, err
+		}
+	}
+	if trusted != nil {
+		signed := false
+		for _, element := range checked {
+			for _, signature := range element.all(xmlDsigNamespace, "Signature") {
+				if err := verifyXmlSignature(signature, trusted); err != nil {
+					return nil, fmt.Errorf("metadata signature: %v", err)
+				}
+				signed = true
+			}
+		}
+		if !signed {
+			return nil, fmt.Errorf("metadata is not signed, but metadata_signing_certificate is set")
+		}
+	}
+
+	metadata.entityId, _ = entity.attr("entityID")
+	if metadata.entityId == "" {
+		return nil, fmt.Errorf("EntityDescriptor has no entityID")
+	}
+	idp := entity.first(samlMetadataNamespace, "IDPSSODescriptor")
+	if idp == nil {
+		return nil, fmt.Errorf("'%s' is not an identity provider, it has no IDPSSODescriptor", metadata.entityId)
+	}
+
+	services := idp.all(samlMetadataNamespace, "SingleSignOnService")
+	for _, binding := range samlSsoBindings {
+		for _, service := range services {
+			if serviceBinding, _ := service.attr("Binding"); serviceBinding == binding && metadata.url == "" {
+				metadata.url, _ = service.attr("Location")
+			}
+		}
+	}
+	if metadata.url == "" {
+		return nil, fmt.Errorf("'%s' has no HTTP-Redirect or HTTP-POST SingleSignOnService", metadata.entityId)
+	}
+
+	// During a key rollover, identity providers list the key they sign
+	// with first, so the first signing certificate is used.
+	for _, keyDescriptor := range idp.all(samlMetadataNamespace, "KeyDescriptor") {
+		if use, ok := keyDescriptor.attr("use"); ok && use != "signing" {
+			continue
+		}
+		keyInfo := keyDescriptor.first(xmlDsigNamespace, "KeyInfo")
+		if keyInfo == nil {
+			continue
+		}
+		x509Data := keyInfo.first(xmlDsigNamespace, "X509Data")
+		if x509Data == nil {
+			continue
+		}
+		x509Certificate := x509Data.first(xmlDsigNamespace, "X509Certificate")
+		if x509Certificate == nil {
+			continue
+		}
+		certificate, err := parseXmlCertificate(x509Certificate.text())
+		if err != nil {
+			return nil, fmt.Errorf("'%s' signing %v", metadata.entityId, err)
+		}
+		metadata.certificate = base64.StdEncoding.EncodeToString(certificate.Raw)
+		break
+	}
+	if metadata.certificate == "" {
+		return nil, fmt.Errorf("'%s' has no signing certificate", metadata.entityId)
+	}
+	return metadata, nil
+}
+
+// samlCertificatesEqual compares certificates ignoring whitespace, as the
+// traffic manager may not keep line breaks.
+func samlCertificatesEqual(left, right string) bool {
+	return strings.Join(strings.Fields(left), "") == strings.Join(strings.Fields(right), "")
+}
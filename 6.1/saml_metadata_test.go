@@ -0,0 +1,295 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSamlMetadata is signed with the key of testSamlSigningCertificate,
+// using exclusive canonicalization and RSA-SHA256.
+const testSamlMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- IdP metadata -->
+<md:EntityDescriptor entityID="https://idp.example.com/saml" validUntil="2099-01-01T00:00:00Z" xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" xmlns:unused="urn:example:unused" ID="_meta1">
+  <ds:Signature><ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/><ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/><ds:Reference URI="#_meta1"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/></ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><ds:DigestValue>FoYayE/GudRBdFiZfiI7jpJNfb+JX3vLIp4o21tbApU=</ds:DigestValue></ds:Reference></ds:SignedInfo><ds:SignatureValue>QGCKN6tOXnnwAH7Up+DpRwASKKK/a/iRltEplQp5grJXUWiEqc3a6xadASvn2/RarE+xznWjdUWs3kGxtVcXmcd1LZ/S9z5kC3kAqRqScyBFDleasCXfYBf28d9UAKE9xjmHpYIsOOr9ROOsIsNQmWk9zn6zBQ0Qr5dmowOSbDs=</ds:SignatureValue><ds:KeyInfo><ds:X509Data><ds:X509Certificate>MIICEjCCAXugAwIBAgIUGZ5lKBJYO8t873qiP4ZpbSl5TmMwDQYJKoZIhvcNAQELBQAwGjEYMBYGA1UEAwwPaWRwLmV4YW1wbGUuY29tMCAXDTI2MTAxODEzMDEwNVoYDzIxMjYwOTI0MTMwMTA1WjAaMRgwFgYDVQQDDA9pZHAuZXhhbXBsZS5jb20wgZ8wDQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAMOmWDXRonbqV4iy7sNmPw45VqIJVD9CSPhlgoLpL99EcNad/jET7QDsugHfOnRBFbSxdSQbJjQ3HkEVJcM+k5uM9TgqhO4Cv9wodkg6St+TxKMXG5iZ3mheEOm0gCyPyw+ITBMM2ec1tkRktVfoCAKQ4AsefEbCWeyKeWuM3W2FAgMBAAGjUzBRMB0GA1UdDgQWBBRjJiEZhJaxE036l0eOZaj6FuilSDAfBgNVHSMEGDAWgBRjJiEZhJaxE036l0eOZaj6FuilSDAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUAA4GBABos63WESgp4xmUslPdLdFqP6cdQY0nwJ9jurWXF4kr9iY+DoAiAUHJcbFEFbighUBa18HvCLdvqPhZZqalzz20ku28EcYOYTwjgS3djQrQ352+d83hNAwJbYoT9DlJpnVAUtFQBQB2ikzIf+d5kIL6gkIwxVDx3o81rcQIOlGLT</ds:X509Certificate></ds:X509Data></ds:KeyInfo></ds:Signature>
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="encryption"><ds:KeyInfo><ds:X509Data><ds:X509Certificate>MIICKDCCAZGgAwIBAgIUeHnm5zDWwdXwia5XYT5/2FBfhtswDQYJKoZIhvcNAQELBQAwJTEjMCEGA1UEAwwaaWRwLWVuY3J5cHRpb24uZXhhbXBsZS5jb20wIBcNMjYxMDE4MTMwMTA1WhgPMjEyNjA5MjQxMzAxMDVaMCUxIzAhBgNVBAMMGmlkcC1lbmNyeXB0aW9uLmV4YW1wbGUuY29tMIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQD6lhLUB2ZYsRUcXExRuJi0tF7xmYr2jeceE8oSmU8h4aPQ92kQVBn1fitdQH90ZfukNdaAefEs5AEXxzLZyjEnXE4Q0SCTvL61WSzG/5D79kxe8Vn1beFJsMDoMp4xsaS/5YseHn/03EePgBnJoOpl3ls3iOUG6Bs+TaI4FlouBQIDAQABo1MwUTAdBgNVHQ4EFgQUjbphlSNxx68wFwq0ZeHZqWmlqyQwHwYDVR0jBBgwFoAUjbphlSNxx68wFwq0ZeHZqWmlqyQwDwYDVR0TAQH/BAUwAwEB/zANBgkqhkiG9w0BAQsFAAOBgQAVbgBIyaiwFEqmGlNgLOTvZZD9wz3TUXZLKlNteHFen/mw2LO68qZJfPFz11JO+9GkKaOLWIufj2XeJNkVgijE7ChMyybWkFhM9qee+p5U/v5gKjYn98CcnoHtQknovoMKWe7Zj78PzhzId5ai8KAXYOVa9GbGjvRv0Ddv9lg2Mw==</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo>
+        <ds:X509Data>
+          <ds:X509Certificate>
+MIICEjCCAXugAwIBAgIUGZ5lKBJYO8t873qiP4ZpbSl5TmMwDQYJKoZIhvcNAQEL
+BQAwGjEYMBYGA1UEAwwPaWRwLmV4YW1wbGUuY29tMCAXDTI2MTAxODEzMDEwNVoY
+DzIxMjYwOTI0MTMwMTA1WjAaMRgwFgYDVQQDDA9pZHAuZXhhbXBsZS5jb20wgZ8w
+DQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAMOmWDXRonbqV4iy7sNmPw45VqIJVD9C
+SPhlgoLpL99EcNad/jET7QDsugHfOnRBFbSxdSQbJjQ3HkEVJcM+k5uM9TgqhO4C
+v9wodkg6St+TxKMXG5iZ3mheEOm0gCyPyw+ITBMM2ec1tkRktVfoCAKQ4AsefEbC
+WeyKeWuM3W2FAgMBAAGjUzBRMB0GA1UdDgQWBBRjJiEZhJaxE036l0eOZaj6Fuil
+SDAfBgNVHSMEGDAWgBRjJiEZhJaxE036l0eOZaj6FuilSDAPBgNVHRMBAf8EBTAD
+AQH/MA0GCSqGSIb3DQEBCwUAA4GBABos63WESgp4xmUslPdLdFqP6cdQY0nwJ9ju
+rWXF4kr9iY+DoAiAUHJcbFEFbighUBa18HvCLdvqPhZZqalzz20ku28EcYOYTwjg
+S3djQrQ352+d83hNAwJbYoT9DlJpnVAUtFQBQB2ikzIf+d5kIL6gkIwxVDx3o81r
+cQIOlGLT
+          </ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso/redirect?a=1&amp;b=2"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>
+`
+
+const testSamlSigningCertificate = `-----BEGIN CERTIFICATE-----
+MIICEjCCAXugAwIBAgIUGZ5lKBJYO8t873qiP4ZpbSl5TmMwDQYJKoZIhvcNAQEL
+BQAwGjEYMBYGA1UEAwwPaWRwLmV4YW1wbGUuY29tMCAXDTI2MTAxODEzMDEwNVoY
+DzIxMjYwOTI0MTMwMTA1WjAaMRgwFgYDVQQDDA9pZHAuZXhhbXBsZS5jb20wgZ8w
+DQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAMOmWDXRonbqV4iy7sNmPw45VqIJVD9C
+SPhlgoLpL99EcNad/jET7QDsugHfOnRBFbSxdSQbJjQ3HkEVJcM+k5uM9TgqhO4C
+v9wodkg6St+TxKMXG5iZ3mheEOm0gCyPyw+ITBMM2ec1tkRktVfoCAKQ4AsefEbC
+WeyKeWuM3W2FAgMBAAGjUzBRMB0GA1UdDgQWBBRjJiEZhJaxE036l0eOZaj6Fuil
+SDAfBgNVHSMEGDAWgBRjJiEZhJaxE036l0eOZaj6FuilSDAPBgNVHRMBAf8EBTAD
+AQH/MA0GCSqGSIb3DQEBCwUAA4GBABos63WESgp4xmUslPdLdFqP6cdQY0nwJ9ju
+rWXF4kr9iY+DoAiAUHJcbFEFbighUBa18HvCLdvqPhZZqalzz20ku28EcYOYTwjg
+S3djQrQ352+d83hNAwJbYoT9DlJpnVAUtFQBQB2ikzIf+d5kIL6gkIwxVDx3o81r
+cQIOlGLT
+-----END CERTIFICATE-----
+`
+
+const testSamlEncryptionCertificate = `-----BEGIN CERTIFICATE-----
+MIICKDCCAZGgAwIBAgIUeHnm5zDWwdXwia5XYT5/2FBfhtswDQYJKoZIhvcNAQEL
+BQAwJTEjMCEGA1UEAwwaaWRwLWVuY3J5cHRpb24uZXhhbXBsZS5jb20wIBcNMjYx
+MDE4MTMwMTA1WhgPMjEyNjA5MjQxMzAxMDVaMCUxIzAhBgNVBAMMGmlkcC1lbmNy
+eXB0aW9uLmV4YW1wbGUuY29tMIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQD6
+lhLUB2ZYsRUcXExRuJi0tF7xmYr2jeceE8oSmU8h4aPQ92kQVBn1fitdQH90Zfuk
+NdaAefEs5AEXxzLZyjEnXE4Q0SCTvL61WSzG/5D79kxe8Vn1beFJsMDoMp4xsaS/
+5YseHn/03EePgBnJoOpl3ls3iOUG6Bs+TaI4FlouBQIDAQABo1MwUTAdBgNVHQ4E
+FgQUjbphlSNxx68wFwq0ZeHZqWmlqyQwHwYDVR0jBBgwFoAUjbphlSNxx68wFwq0
+ZeHZqWmlqyQwDwYDVR0TAQH/BAUwAwEB/zANBgkqhkiG9w0BAQsFAAOBgQAVbgBI
+yaiwFEqmGlNgLOTvZZD9wz3TUXZLKlNteHFen/mw2LO68qZJfPFz11JO+9GkKaOL
+WIufj2XeJNkVgijE7ChMyybWkFhM9qee+p5U/v5gKjYn98CcnoHtQknovoMKWe7Z
+j78PzhzId5ai8KAXYOVa9GbGjvRv0Ddv9lg2Mw==
+-----END CERTIFICATE-----
+`
+
+var testSamlNow = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParseSamlMetadata(t *testing.T) {
+	metadata, err := parseSamlMetadata([]byte(testSamlMetadata), "", testSamlNow)
+	if err != nil {
+		t.Fatalf("Parsing metadata failed: %v", err)
+	}
+	if metadata.entityId != "https://idp.example.com/saml" {
+		t.Errorf("Parsed entity ID %q", metadata.entityId)
+	}
+	if metadata.url != "https://idp.example.com/sso/redirect?a=1&b=2" {
+		t.Errorf("Parsed URL %q, expected the HTTP-Redirect endpoint", metadata.url)
+	}
+	block, _ := pem.Decode([]byte(testSamlSigningCertificate))
+	if !samlCertificatesEqual(metadata.certificate, base64.StdEncoding.EncodeToString(block.Bytes)) {
+		t.Errorf("Parsed certificate %q, expected the signing certificate", metadata.certificate)
+	}
+
+	if _, err := parseSamlMetadata([]byte(testSamlMetadata), testSamlSigningCertificate, testSamlNow); err != nil {
+		t.Errorf("Checking metadata signed with the trusted certificate failed: %v", err)
+	}
+	if _, err := parseSamlMetadata([]byte(testSamlMetadata), metadata.certificate, testSamlNow); err != nil {
+		t.Errorf("Checking metadata signed with the trusted base64 certificate failed: %v", err)
+	}
+	if _, err := parseSamlMetadata([]byte(testSamlMetadata), testSamlEncryptionCertificate, testSamlNow); err == nil {
+		t.Errorf("Metadata signed with another certificate was accepted")
+	}
+	if _, err := parseSamlMetadata([]byte(testSamlMetadata), "", time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("Expired metadata was accepted")
+	}
+}
+
+func TestParseSamlMetadataChanged(t *testing.T) {
+	changed := strings.Replace(testSamlMetadata, "sso/redirect", "sso/elsewhere", 1)
+	if _, err := parseSamlMetadata([]byte(changed), testSamlSigningCertificate, testSamlNow); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("Changed metadata was not rejected: %v", err)
+	}
+
+	// Without a trusted certificate, signatures are not checked
+	metadata, err := parseSamlMetadata([]byte(changed), "", testSamlNow)
+	if err != nil {
+		t.Fatalf("Parsing changed metadata without a trusted certificate failed: %v", err)
+	}
+	if metadata.url != "https://idp.example.com/sso/elsewhere?a=1&b=2" {
+		t.Errorf("Parsed changed metadata as %+v", metadata)
+	}
+
+	unsigned := regexp.MustCompile(`<ds:Signature>.*</ds:Signature>`).ReplaceAllString(changed, "")
+	if _, err := parseSamlMetadata([]byte(unsigned), "", testSamlNow); err != nil {
+		t.Fatalf("Parsing unsigned metadata failed: %v", err)
+	}
+	if _, err := parseSamlMetadata([]byte(unsigned), testSamlSigningCertificate, testSamlNow); err == nil {
+		t.Errorf("Unsigned metadata was accepted with a trusted certificate")
+	}
+}
+
+func TestCanonicalizeXml(t *testing.T) {
+	root, err := parseXmlDocument([]byte(`<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns="urn:default"><child z="1" b:y="2" a="&quot;3&quot;"/><b:other>x &amp; y</b:other></a:root>`))
+	if err != nil {
+		t.Fatalf("Parsing XML failed: %v", err)
+	}
+	child := root.children[0].(*xmlElement)
+	tests := []struct {
+		element   *xmlElement
+		exclusive bool
+		expected  string
+	}{
+		{root, true, `<a:root xmlns:a="urn:a"><child xmlns="urn:default" xmlns:b="urn:b" a="&quot;3&quot;" z="1" b:y="2"></child><b:other xmlns:b="urn:b">x &amp; y</b:other></a:root>`},
+		{child, true, `<child xmlns="urn:default" xmlns:b="urn:b" a="&quot;3&quot;" z="1" b:y="2"></child>`},
+		{child, false, `<child xmlns="urn:default" xmlns:a="urn:a" xmlns:b="urn:b" a="&quot;3&quot;" z="1" b:y="2"></child>`},
+	}
+	for _, test := range tests {
+		if canonical := string(canonicalizeXml(test.element, test.exclusive, nil, nil)); canonical != test.expected {
+			t.Errorf("Canonicalized <%s> as %s, expected %s", test.element.name.Local, canonical, test.expected)
+		}
+	}
+}
+
+// The following metadata is laid out as each identity provider publishes
+// it: AD FS signs the whole EntityDescriptor, mixing default and prefixed
+// XML Signature namespaces; federations sign an aggregate EntitiesDescriptor
+// of Shibboleth identity providers, here with ECDSA; Okta does not sign its
+// metadata. The signatures were made with OpenSSL over the canonical form
+// from an independent implementation, Python's xml.etree.ElementTree.
+
+const testSamlAdfsMetadata = `<?xml version="1.0" encoding="utf-8"?><EntityDescriptor ID="_9c4d2d6c-4e8f-4c52-a1c3-2b0f5d8b7e31" entityID="http://adfs.example.com/adfs/services/trust" xmlns="urn:oasis:names:tc:SAML:2.0:metadata"><ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#" /><ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256" /><ds:Reference URI="#_9c4d2d6c-4e8f-4c52-a1c3-2b0f5d8b7e31"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature" /><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#" /></ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256" /><ds:DigestValue>FZLXme8QpYQV8cF6GA34sPm1SgIedME7LUq8HR4LneM=</ds:DigestValue></ds:Reference></ds:SignedInfo><ds:SignatureValue>JQ6deuMJ2h/0MsEGKpBSJhYKlxJhhIuwgKZdIwHIyULGbiyVnnHLcYEIz4iWga6rABiRxvPKTcMR71SPQ86TDCm54Yi+dosR5J1ahNjusG+mlRCNccDZnB5LIQbMn0tnsR1tKAbBFKKEzPV00l5+0YO5q55ajK20YTvu34gGPKM=</ds:SignatureValue><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQELBQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNTMFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqiT6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeTTp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4=</X509Certificate></X509Data></KeyInfo></ds:Signature><RoleDescriptor xsi:type="fed:ApplicationServiceType" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" protocolSupportEnumeration="http://docs.oasis-open.org/wsfed/federation/200706" ServiceDisplayName="Example ADFS" xmlns:fed="http://docs.oasis-open.org/wsfed/federation/200706"><KeyDescriptor use="signing"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQELBQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNTMFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqiT6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeTTp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4=</X509Certificate></X509Data></KeyInfo></KeyDescriptor><fed:TargetScopes><wsa:EndpointReference xmlns:wsa="http://www.w3.org/2005/08/addressing"><wsa:Address>https://adfs.example.com/adfs/services/trust/2005/issuedtokenmixedasymmetricbasic256</wsa:Address></wsa:EndpointReference></fed:TargetScopes><fed:PassiveRequestorEndpoint><wsa:EndpointReference xmlns:wsa="http://www.w3.org/2005/08/addressing"><wsa:Address>https://adfs.example.com/adfs/ls/</wsa:Address></wsa:EndpointReference></fed:PassiveRequestorEndpoint></RoleDescriptor><SPSSODescriptor WantAssertionsSigned="true" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol"><KeyDescriptor use="signing"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQELBQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNTMFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqiT6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeTTp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4=</X509Certificate></X509Data></KeyInfo></KeyDescriptor><AssertionConsumerService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://adfs.example.com/adfs/ls/" index="0" isDefault="true" /></SPSSODescriptor><IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol"><KeyDescriptor use="encryption"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>MIIBqjCCAVGgAwIBAgIUMZ0+lJThQ+CcSZ5gQHBIuslVtKcwCgYIKoZIzj0EAwIwKjEoMCYGA1UEAwwfbWV0YWRhdGEuZmVkZXJhdGlvbi5leGFtcGxlLm9yZzAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfbWV0YWRhdGEuZmVkZXJhdGlvbi5leGFtcGxlLm9yZzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABKXlHyNuDsSSu9QP7MW14PnNnklpPK7eBTtAihZxKN5hoH/p5rhV8vGdfoDSwPWsjOrvfNGS43kGB9sKsccOz2ujUzBRMB0GA1UdDgQWBBRa5kpLtzAkNlPlL5E8QWwC9XEbyzAfBgNVHSMEGDAWgBRa5kpLtzAkNlPlL5E8QWwC9XEbyzAPBgNVHRMBAf8EBTADAQH/MAoGCCqGSM49BAMCA0cAMEQCICWTXCU+W9lwybo8mE7bCujoAZqtclrejRgb+69TbG9SAiA8yI9COlUYpEtBuSvaiFiwSoUJK8k9m961h7hiQU8HHw==</X509Certificate></X509Data></KeyInfo></KeyDescriptor><KeyDescriptor use="signing"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQELBQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNTMFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqiT6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeTTp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4=</X509Certificate></X509Data></KeyInfo></KeyDescriptor><SingleLogoutService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://adfs.example.com/adfs/ls/" /><SingleLogoutService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://adfs.example.com/adfs/ls/" /><NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</NameIDFormat><NameIDFormat>urn:oasis:names:tc:SAML:2.0:nameid-format:persistent</NameIDFormat><SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://adfs.example.com/adfs/ls/" /><SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://adfs.example.com/adfs/ls/" /><Attribute Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri" FriendlyName="E-Mail Address" xmlns="urn:oasis:names:tc:SAML:2.0:assertion" /></IDPSSODescriptor></EntityDescriptor>`
+
+const testSamlAdfsSigningCertificate = `-----BEGIN CERTIFICATE-----
+MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQEL
+BQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAg
+Fw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURG
+UyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOB
+jQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L
+3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo
+2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNT
+MFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqi
+T6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQEL
+BQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x
+9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeT
+Tp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4=
+-----END CERTIFICATE-----
+`
+
+const testSamlShibbolethMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<!--
+     Federation metadata aggregate with a single Shibboleth identity provider.
+-->
+<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" xmlns:mdrpi="urn:oasis:names:tc:SAML:metadata:rpi" xmlns:shibmd="urn:mace:shibboleth:metadata:1.0" xmlns:mdui="urn:oasis:names:tc:SAML:metadata:ui" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_20261018T120000Z" Name="urn:example:federation" validUntil="2099-12-31T23:59:59Z">
+  <ds:Signature>
+    <ds:SignedInfo>
+      <ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>
+      <ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"/>
+      <ds:Reference URI="#_20261018T120000Z">
+        <ds:Transforms>
+          <ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>
+          <ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>
+        </ds:Transforms>
+        <ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>
+        <ds:DigestValue>NSJxllIj9gnwaQX3ofE8YLUhSsROxfUI11HTzYqjzhg=</ds:DigestValue>
+      </ds:Reference>
+    </ds:SignedInfo>
+    <ds:SignatureValue>WD0XGCZ3Hrdr8quzI8jVFoV/3ouHZO+YfCgHpDE/LLMBSyAhS/5biHyUjG1B/p0E/JFQLk8eMiLEsEr1ugsBBA==</ds:SignatureValue>
+  </ds:Signature>
+  <md:Extensions>
+    <mdrpi:PublicationInfo creationInstant="2026-10-18T12:00:00Z" publisher="urn:example:federation"/>
+  </md:Extensions>
+  <md:EntityDescriptor entityID="https://idp.example.org/idp/shibboleth">
+    <md:Extensions>
+      <mdrpi:RegistrationInfo registrationAuthority="urn:example:federation" registrationInstant="2020-01-01T00:00:00Z"/>
+    </md:Extensions>
+    <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol urn:oasis:names:tc:SAML:1.1:protocol urn:mace:shibboleth:1.0">
+      <md:Extensions>
+        <shibmd:Scope regexp="false">example.org</shibmd:Scope>
+        <mdui:UIInfo>
+          <mdui:DisplayName xml:lang="en">Example University</mdui:DisplayName>
+          <mdui:DisplayName xml:lang="fr">Université Exemple</mdui:DisplayName>
+        </mdui:UIInfo>
+      </md:Extensions>
+      <md:KeyDescriptor>
+        <ds:KeyInfo>
+          <ds:X509Data>
+            <ds:X509Certificate>
+MIICEjCCAXugAwIBAgIUJxNcMaCVg/3upbVc4eHBFSi8aHMwDQYJKoZIhvcNAQEL
+BQAwGjEYMBYGA1UEAwwPaWRwLmV4YW1wbGUub3JnMCAXDTI2MTAxODE0MDgyNVoY
+DzIxMjYwOTI0MTQwODI1WjAaMRgwFgYDVQQDDA9pZHAuZXhhbXBsZS5vcmcwgZ8w
+DQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAKPJjRZrBqZavvWc3APCZxEv8YO9Uomw
+YhO38ddsLTuB2on5Tarjl7/cJpGOXL00bn4bhEwFPSAZanNfJeFvaZWPrKw+ijCi
+J6pImFOQ4FmrNLmUQ8+LwZ3R7sV6/dAnLrforOsmxhFYZwfo/CpHNpZl3JfCFsva
+Jm7CZafjf7nNAgMBAAGjUzBRMB0GA1UdDgQWBBR1crfofincMhjAFwZwyCA+pNsV
+BTAfBgNVHSMEGDAWgBR1crfofincMhjAFwZwyCA+pNsVBTAPBgNVHRMBAf8EBTAD
+AQH/MA0GCSqGSIb3DQEBCwUAA4GBAJU+SjXLf6NuyQsFLFVVuIqM9gxfI5IcPBVW
+Q+q78dnIZRLdrgm7dH3Rqg/pR8weYltdi243tCCSu+ra3Hik80jR8h9FUXqSCvCa
+ZzO/HbLx/FphbCWfVNSeF/j3HtJVlQo/NZ4Hh4gFn/I2Sz23H3wMkUMl8bibU6aG
+4UfEk0qC
+            </ds:X509Certificate>
+          </ds:X509Data>
+        </ds:KeyInfo>
+      </md:KeyDescriptor>
+      <md:ArtifactResolutionService Binding="urn:oasis:names:tc:SAML:2.0:bindings:SOAP" Location="https://idp.example.org:8443/idp/profile/SAML2/SOAP/ArtifactResolution" index="2"/>
+      <md:NameIDFormat>urn:oasis:names:tc:SAML:2.0:nameid-format:transient</md:NameIDFormat>
+      <md:SingleSignOnService Binding="urn:mace:shibboleth:1.0:profiles:AuthnRequest" Location="https://idp.example.org/idp/profile/Shibboleth/SSO"/>
+      <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.org/idp/profile/SAML2/POST/SSO"/>
+      <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST-SimpleSign" Location="https://idp.example.org/idp/profile/SAML2/POST-SimpleSign/SSO"/>
+      <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.org/idp/profile/SAML2/Redirect/SSO"/>
+    </md:IDPSSODescriptor>
+  </md:EntityDescriptor>
+</md:EntitiesDescriptor>`
+
+const testSamlFederationSigningCertificate = `-----BEGIN CERTIFICATE-----
+MIIBqjCCAVGgAwIBAgIUMZ0+lJThQ+CcSZ5gQHBIuslVtKcwCgYIKoZIzj0EAwIw
+KjEoMCYGA1UEAwwfbWV0YWRhdGEuZmVkZXJhdGlvbi5leGFtcGxlLm9yZzAgFw0y
+NjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfbWV0YWRh
+dGEuZmVkZXJhdGlvbi5leGFtcGxlLm9yZzBZMBMGByqGSM49AgEGCCqGSM49AwEH
+A0IABKXlHyNuDsSSu9QP7MW14PnNnklpPK7eBTtAihZxKN5hoH/p5rhV8vGdfoDS
+wPWsjOrvfNGS43kGB9sKsccOz2ujUzBRMB0GA1UdDgQWBBRa5kpLtzAkNlPlL5E8
+QWwC9XEbyzAfBgNVHSMEGDAWgBRa5kpLtzAkNlPlL5E8QWwC9XEbyzAPBgNVHRMB
+Af8EBTADAQH/MAoGCCqGSM49BAMCA0cAMEQCICWTXCU+W9lwybo8mE7bCujoAZqt
+clrejRgb+69TbG9SAiA8yI9COlUYpEtBuSvaiFiwSoUJK8k9m961h7hiQU8HHw==
+-----END CERTIFICATE-----
+`
+
+const testSamlOktaMetadata = `<?xml version="1.0" encoding="UTF-8"?><md:EntityDescriptor entityID="http://www.okta.com/exk1a2b3c4d5e6f7g8h9" xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata"><md:IDPSSODescriptor WantAuthnRequestsSigned="false" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol"><md:KeyDescriptor use="signing"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>MIIC/jCCAmegAwIBAgIUTZzcKVE0iVu4T7AtT6GHgP0jHWswDQYJKoZIhvcNAQELBQAwgY8xCzAJBgNVBAYTAlVTMRMwEQYDVQQIDApDYWxpZm9ybmlhMRYwFAYDVQQHDA1TYW4gRnJhbmNpc2NvMQ0wCwYDVQQKDARPa3RhMRQwEgYDVQQLDAtTU09Qcm92aWRlcjEQMA4GA1UEAwwHZXhhbXBsZTEcMBoGCSqGSIb3DQEJARYNaW5mb0Bva3RhLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowgY8xCzAJBgNVBAYTAlVTMRMwEQYDVQQIDApDYWxpZm9ybmlhMRYwFAYDVQQHDA1TYW4gRnJhbmNpc2NvMQ0wCwYDVQQKDARPa3RhMRQwEgYDVQQLDAtTU09Qcm92aWRlcjEQMA4GA1UEAwwHZXhhbXBsZTEcMBoGCSqGSIb3DQEJARYNaW5mb0Bva3RhLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEAovMP+CC2+9KAcJaym95JKco59OsMzQVD1OoOMTgCc+BgxZf5SMFe5r22O5yyGSQ4aFd8mgIHQm6d475Wpy3ktbBwM21lpL1uoXJkfDoQRfjKESFBjAmn280DOmfc9PwaUSMtVaO0SoVwHW8TUywsdT2AMdWzwQZNSLk5tDWl4QcCAwEAAaNTMFEwHQYDVR0OBBYEFEab+wpWiMEqVYEC8L+Qr0lXnKGdMB8GA1UdIwQYMBaAFEab+wpWiMEqVYEC8L+Qr0lXnKGdMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAeKKn54LqVBHWfZEzlNDlQkOa6iEjiZOJymdrACvdjJABqyoRyaRtZky6jozY1v+QCRtiSDosRLxFt+D9dQT80fYqrf6gI9WKmw4Mpk0a4RgSVFDtQJA99IUY8uvemBYCZ1hKepAMGS9V+eFesgDljBZ5AUelk8jMHDkRv+kHmgE=</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor><md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified</md:NameIDFormat><md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</md:NameIDFormat><md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://example.okta.com/app/example_vtm_1/exk1a2b3c4d5e6f7g8h9/sso/saml"/><md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://example.okta.com/app/example_vtm_1/exk1a2b3c4d5e6f7g8h9/sso/saml"/></md:IDPSSODescriptor></md:EntityDescriptor>`
+
+func TestParseSamlMetadataFromIdentityProviders(t *testing.T) {
+	tests := []struct {
+		name, metadata, trusted, entityId, url, certificate string
+	}{
+		{"AD FS", testSamlAdfsMetadata, testSamlAdfsSigningCertificate, "http://adfs.example.com/adfs/services/trust", "https://adfs.example.com/adfs/ls/", "MIICMjCCAZugAwIBAgIUBGI/GJjEwm/HG14EehSHbwoO5towDQYJKoZIhvcNAQELBQAwKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowKjEoMCYGA1UEAwwfQURGUyBTaWduaW5nIC0gYWRmcy5leGFtcGxlLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEArro3jTzC/Tsc06OMCPeR5O/lIEx70ZyJMn/KpqMfF3gfJ+Jyc64L3P44ThbCNH48VUL/Wx+cRn+EkC4kbUJ4i63APZauP/kvQAv2N1R28bZrhpdtWCZo2Jy/lb5mTz5V5I7381Ibh5VL8E/OLIgIhqIXaoqDugVLLCBkpB/Clq8CAwEAAaNTMFEwHQYDVR0OBBYEFFqiT6HJV2RAfVUktmPDGMKQIHReMB8GA1UdIwQYMBaAFFqiT6HJV2RAfVUktmPDGMKQIHReMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAmyg/5wMpoJ7qaoRi5m5UqB0+eVCRUej42M0Op6DLtI4XOzNuWQcaDr/x9IFyP5jft/6pSFNjRwOzd2lTIoeexZsA1eNCF5kNguXgxnPAdbeBTPjCgQRsHOeTTp1bDz3R0Ti8uBGc+Kw09F0t4wukF98A7Gr4Ro2cCEcSYa+ylv4="},
+		{"Shibboleth", testSamlShibbolethMetadata, testSamlFederationSigningCertificate, "https://idp.example.org/idp/shibboleth", "https://idp.example.org/idp/profile/SAML2/Redirect/SSO", "MIICEjCCAXugAwIBAgIUJxNcMaCVg/3upbVc4eHBFSi8aHMwDQYJKoZIhvcNAQELBQAwGjEYMBYGA1UEAwwPaWRwLmV4YW1wbGUub3JnMCAXDTI2MTAxODE0MDgyNVoYDzIxMjYwOTI0MTQwODI1WjAaMRgwFgYDVQQDDA9pZHAuZXhhbXBsZS5vcmcwgZ8wDQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAKPJjRZrBqZavvWc3APCZxEv8YO9UomwYhO38ddsLTuB2on5Tarjl7/cJpGOXL00bn4bhEwFPSAZanNfJeFvaZWPrKw+ijCiJ6pImFOQ4FmrNLmUQ8+LwZ3R7sV6/dAnLrforOsmxhFYZwfo/CpHNpZl3JfCFsvaJm7CZafjf7nNAgMBAAGjUzBRMB0GA1UdDgQWBBR1crfofincMhjAFwZwyCA+pNsVBTAfBgNVHSMEGDAWgBR1crfofincMhjAFwZwyCA+pNsVBTAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUAA4GBAJU+SjXLf6NuyQsFLFVVuIqM9gxfI5IcPBVWQ+q78dnIZRLdrgm7dH3Rqg/pR8weYltdi243tCCSu+ra3Hik80jR8h9FUXqSCvCaZzO/HbLx/FphbCWfVNSeF/j3HtJVlQo/NZ4Hh4gFn/I2Sz23H3wMkUMl8bibU6aG4UfEk0qC"},
+		{"Okta", testSamlOktaMetadata, "", "http://www.okta.com/exk1a2b3c4d5e6f7g8h9", "https://example.okta.com/app/example_vtm_1/exk1a2b3c4d5e6f7g8h9/sso/saml", "MIIC/jCCAmegAwIBAgIUTZzcKVE0iVu4T7AtT6GHgP0jHWswDQYJKoZIhvcNAQELBQAwgY8xCzAJBgNVBAYTAlVTMRMwEQYDVQQIDApDYWxpZm9ybmlhMRYwFAYDVQQHDA1TYW4gRnJhbmNpc2NvMQ0wCwYDVQQKDARPa3RhMRQwEgYDVQQLDAtTU09Qcm92aWRlcjEQMA4GA1UEAwwHZXhhbXBsZTEcMBoGCSqGSIb3DQEJARYNaW5mb0Bva3RhLmNvbTAgFw0yNjEwMTgxNDA4MjVaGA8yMTI2MDkyNDE0MDgyNVowgY8xCzAJBgNVBAYTAlVTMRMwEQYDVQQIDApDYWxpZm9ybmlhMRYwFAYDVQQHDA1TYW4gRnJhbmNpc2NvMQ0wCwYDVQQKDARPa3RhMRQwEgYDVQQLDAtTU09Qcm92aWRlcjEQMA4GA1UEAwwHZXhhbXBsZTEcMBoGCSqGSIb3DQEJARYNaW5mb0Bva3RhLmNvbTCBnzANBgkqhkiG9w0BAQEFAAOBjQAwgYkCgYEAovMP+CC2+9KAcJaym95JKco59OsMzQVD1OoOMTgCc+BgxZf5SMFe5r22O5yyGSQ4aFd8mgIHQm6d475Wpy3ktbBwM21lpL1uoXJkfDoQRfjKESFBjAmn280DOmfc9PwaUSMtVaO0SoVwHW8TUywsdT2AMdWzwQZNSLk5tDWl4QcCAwEAAaNTMFEwHQYDVR0OBBYEFEab+wpWiMEqVYEC8L+Qr0lXnKGdMB8GA1UdIwQYMBaAFEab+wpWiMEqVYEC8L+Qr0lXnKGdMA8GA1UdEwEB/wQFMAMBAf8wDQYJKoZIhvcNAQELBQADgYEAeKKn54LqVBHWfZEzlNDlQkOa6iEjiZOJymdrACvdjJABqyoRyaRtZky6jozY1v+QCRtiSDosRLxFt+D9dQT80fYqrf6gI9WKmw4Mpk0a4RgSVFDtQJA99IUY8uvemBYCZ1hKepAMGS9V+eFesgDljBZ5AUelk8jMHDkRv+kHmgE="},
+	}
+	for _, test := range tests {
+		metadata, err := parseSamlMetadata([]byte(test.metadata), test.trusted, testSamlNow)
+		if err != nil {
+			t.Errorf("Parsing %s metadata failed: %v", test.name, err)
+			continue
+		}
+		if metadata.entityId != test.entityId || metadata.url != test.url || !samlCertificatesEqual(metadata.certificate, test.certificate) {
+			t.Errorf("Parsed %s metadata as %+v", test.name, metadata)
+		}
+		if _, err := parseSamlMetadata([]byte(test.metadata), "", testSamlNow); err != nil {
+			t.Errorf("Parsing %s metadata without a trusted certificate failed: %v", test.name, err)
+		}
+		if _, err := parseSamlMetadata([]byte(test.metadata), testSamlSigningCertificate, testSamlNow); err == nil {
+			t.Errorf("%s metadata was accepted with a certificate it is not signed with", test.name)
+		}
+		if test.trusted == "" {
+			continue
+		}
+		changed := strings.Replace(test.metadata, test.url, "https://attacker.example.net/", -1)
+		if _, err := parseSamlMetadata([]byte(changed), test.trusted, testSamlNow); err == nil || !strings.Contains(err.Error(), "digest") {
+			t.Errorf("Changed %s metadata was not rejected: %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sslAuditHostMapping is a row of a virtual server's
+// ssl.server_cert_host_mapping table.
+type sslAuditHostMapping struct {
+	host         string
+	certificates []string
+}
+
+// sslAuditVirtualServer holds the SSL settings of a virtual server that are
+// checked by auditVirtualServerCertificates.
+type sslAuditVirtualServer struct {
+	name            string
+	defaultCert     string
+	altCertificates []string
+	hostMappings    []sslAuditHostMapping
+	ocspIssuers     []string
+}
+
+// certificateNames returns the sorted names of the vtm_ssl_server_key objects
+// referenced by the virtual server.
+func (vs sslAuditVirtualServer) certificateNames() []string {
+	referenced := make(map[string]bool)
+	for _, name := range append([]string{vs.defaultCert}, vs.altCertificates...) {
+		referenced[name] = true
+	}
+	for _, mapping := range vs.hostMappings {
+		for _, name := range mapping.certificates {
+			referenced[name] = true
+		}
+	}
+	delete(referenced, "")
+	names := make([]string, 0, len(referenced))
+	for name := range referenced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sslAuditResult lists the problems found with a virtual server's
+// certificates. Certificates are identified by their vtm_ssl_server_key
+// name, and mismatched hosts as "<host> (<certificate>)".
+type sslAuditResult struct {
+	name                 string
+	mismatchedHosts      []string
+	expiredCertificates  []string
+	expiringCertificates []string
+	missingCertificates  []string
+	invalidCertificates  []string
+	missingOcspIssuers   []string
+}
+
+// problems describes every problem in the result, for reporting all virtual
+// servers in a single list.
+func (result sslAuditResult) problems() []string {
+	var problems []string
+	add := func(format string, values []string) {
+		for _, value := range values {
+			problems = append(problems, fmt.Sprintf("%s: "+format, result.name, value))
+		}
+	}
+	add("certificate '%s' is missing", result.missingCertificates)
+	add("certificate '%s' cannot be parsed", result.invalidCertificates)
+	add("certificate '%s' has expired", result.expiredCertificates)
+	add("certificate '%s' expires soon", result.expiringCertificates)
+	add("host mapping %s is not covered by the certificate", result.mismatchedHosts)
+	add("OCSP issuer '%s' is not a vtm_ssl_ca", result.missingOcspIssuers)
+	return problems
+}
+
+// auditVirtualServerCertificates checks the certificates referenced by a
+// virtual server. certificates maps vtm_ssl_server_key names to their
+// parsed public certificate, or to nil if it could not be parsed; keys that
+// do not exist are absent. cas holds the names of the vtm_ssl_ca objects.
+func auditVirtualServerCertificates(vs sslAuditVirtualServer, certificates map[string]*x509.Certificate, cas map[string]bool, now time.Time, warning time.Duration) sslAuditResult {
+	result := sslAuditResult{name: vs.name}
+	for _, name := range vs.certificateNames() {
+		certificate, exists := certificates[name]
+		switch {
+		case !exists:
+			result.missingCertificates = append(result.missingCertificates, name)
+		case certificate == nil:
+			result.invalidCertificates = append(result.invalidCertificates, name)
+		case !now.Before(certificate.NotAfter):
+			result.expiredCertificates = append(result.expiredCertificates, name)
+		case !now.Add(warning).Before(certificate.NotAfter):
+			result.expiringCertificates = append(result.expiringCertificates, name)
+		}
+	}
+
+	for _, mapping := range vs.hostMappings {
+		for _, name := range mapping.certificates {
+			certificate := certificates[name]
+			if certificate != nil && !certificateCoversHost(certificate, mapping.host) {
+				result.mismatchedHosts = append(result.mismatchedHosts, fmt.Sprintf("%s (%s)", mapping.host, name))
+			}
+		}
+	}
+	sort.Strings(result.mismatchedHosts)
+
+	for _, issuer := range vs.ocspIssuers {
+		// The "DEFAULT" row holds the settings for issuers not listed.
+		if issuer != "DEFAULT" && !cas[issuer] {
+			result.missingOcspIssuers = append(result.missingOcspIssuers, issuer)
+		}
+	}
+	sort.Strings(result.missingOcspIssuers)
+	return result
+}
+
+// certificateCoversHost reports whether a client connecting to host would
+// accept the certificate. A wildcard host mapping such as "*.example.com" is
+// only covered by the same wildcard in the certificate.
+func certificateCoversHost(certificate *x509.Certificate, host string) bool {
+	if !strings.HasPrefix(host, "*.") {
+		return certificate.VerifyHostname(host) == nil
+	}
+	names := certificate.DNSNames
+	if len(names) == 0 {
+		names = []string{certificate.Subject.CommonName}
+	}
+	for _, name := range names {
+		if strings.EqualFold(strings.TrimSuffix(name, "."), strings.TrimSuffix(host, ".")) {
+			return true
+		}
+	}
+	return false
+}
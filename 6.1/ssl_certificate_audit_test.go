@@ -0,0 +1,74 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditVirtualServerCertificates(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	makeCertificate := func(serial int64, notAfter time.Time, dnsNames ...string) *x509.Certificate {
+		return makeTestCertificate(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: dnsNames[0]},
+			DNSNames:     dnsNames,
+			NotBefore:    now.AddDate(-1, 0, 0),
+			NotAfter:     notAfter,
+		}, nil).certificate
+	}
+	certificates := map[string]*x509.Certificate{
+		"www":      makeCertificate(1, now.AddDate(1, 0, 0), "www.example.com", "example.com"),
+		"wildcard": makeCertificate(2, now.AddDate(1, 0, 0), "*.example.org"),
+		"expiring": makeCertificate(3, now.AddDate(0, 0, 10), "shop.example.com"),
+		"expired":  makeCertificate(4, now.AddDate(0, 0, -1), "old.example.com"),
+		"garbled":  nil,
+	}
+	cas := map[string]bool{"Example Root CA": true}
+
+	vs := sslAuditVirtualServer{
+		name:            "web",
+		defaultCert:     "www",
+		altCertificates: []string{"garbled"},
+		hostMappings: []sslAuditHostMapping{
+			{host: "example.com", certificates: []string{"www"}},
+			{host: "api.example.org", certificates: []string{"wildcard"}},
+			{host: "*.example.org", certificates: []string{"wildcard"}},
+			{host: "*.example.com", certificates: []string{"www"}},
+			{host: "shop.example.com", certificates: []string{"expiring", "www"}},
+			{host: "old.example.com", certificates: []string{"expired", "unknown"}},
+		},
+		ocspIssuers: []string{"DEFAULT", "Example Root CA", "Retired CA"},
+	}
+	expected := sslAuditResult{
+		name:                 "web",
+		mismatchedHosts:      []string{"*.example.com (www)", "shop.example.com (www)"},
+		expiredCertificates:  []string{"expired"},
+		expiringCertificates: []string{"expiring"},
+		missingCertificates:  []string{"unknown"},
+		invalidCertificates:  []string{"garbled"},
+		missingOcspIssuers:   []string{"Retired CA"},
+	}
+	result := auditVirtualServerCertificates(vs, certificates, cas, now, 30*24*time.Hour)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Auditing %+v gave %+v, expected %+v", vs, result, expected)
+	}
+	if problems := result.problems(); len(problems) != 7 || problems[0] != "web: certificate 'unknown' is missing" {
+		t.Errorf("Auditing %+v reported problems %q", vs, problems)
+	}
+
+	clean := sslAuditVirtualServer{
+		name:         "clean",
+		defaultCert:  "www",
+		hostMappings: []sslAuditHostMapping{{host: "WWW.example.com", certificates: []string{"www"}}},
+	}
+	if result := auditVirtualServerCertificates(clean, certificates, cas, now, 30*24*time.Hour); len(result.problems()) != 0 {
+		t.Errorf("Auditing %+v reported problems %q", clean, result.problems())
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sslCertificateInfo holds the details of a certificate that are exposed by
+// the vtm_ssl_certificate_info data source.
+type sslCertificateInfo struct {
+	subject           string
+	issuer            string
+	sans              []string
+	serial            string
+	notBefore         time.Time
+	notAfter          time.Time
+	keyType           string
+	keySize           int
+	sha256Fingerprint string
+}
+
+// parseSslCertificates decodes every certificate in a PEM bundle, such as the
+// public certificate of an SSL key followed by its intermediates. Blocks
+// other than certificates, for example a private key, are skipped.
+func parseSslCertificates(data string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate %d: %v", len(certificates)+1, err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return certificates, nil
+}
+
+// describeSslCertificate extracts the details of a certificate.
+func describeSslCertificate(certificate *x509.Certificate) sslCertificateInfo {
+	info := sslCertificateInfo{
+		subject:   formatDistinguishedName(certificate.Subject),
+		issuer:    formatDistinguishedName(certificate.Issuer),
+		sans:      []string{},
+		serial:    formatHexBytes(certificate.SerialNumber.Bytes()),
+		notBefore: certificate.NotBefore.UTC(),
+		notAfter:  certificate.NotAfter.UTC(),
+	}
+	info.sans = append(info.sans, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		info.sans = append(info.sans, ip.String())
+	}
+	info.sans = append(info.sans, certificate.EmailAddresses...)
+
+	switch key := certificate.PublicKey.(type) {
+	case *rsa.PublicKey:
+		info.keyType = "rsa"
+		info.keySize = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		info.keyType = "ecdsa"
+		info.keySize = key.Curve.Params().BitSize
+	default:
+		info.keyType = strings.ToLower(certificate.PublicKeyAlgorithm.String())
+	}
+
+	fingerprint := sha256.Sum256(certificate.Raw)
+	info.sha256Fingerprint = formatHexBytes(fingerprint[:])
+	return info
+}
+
+// verifySslCertificateChain checks that the first certificate in chain is
+// signed, through any intermediates that follow it, by one of the PEM
+// encoded CA certificates in cas.
+func verifySslCertificateChain(chain []*x509.Certificate, cas []string, now time.Time) error {
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		certificates, err := parseSslCertificates(ca)
+		if err != nil {
+			return err
+		}
+		for _, certificate := range certificates {
+			roots.AddCert(certificate)
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// formatDistinguishedName renders a name in the form accepted by
+// parseDistinguishedName, most specific attribute first.
+func formatDistinguishedName(name pkix.Name) string {
+	var parts []string
+	add := func(attribute string, values ...string) {
+		for _, value := range values {
+			if value != "" {
+				parts = append(parts, attribute+"="+strings.Replace(value, ",", "\\,", -1))
+			}
+		}
+	}
+	add("CN", name.CommonName)
+	add("SERIALNUMBER", name.SerialNumber)
+	add("OU", name.OrganizationalUnit...)
+	add("O", name.Organization...)
+	add("STREET", name.StreetAddress...)
+	add("L", name.Locality...)
+	add("ST", name.Province...)
+	add("POSTALCODE", name.PostalCode...)
+	add("C", name.Country...)
+	return strings.Join(parts, ", ")
+}
+
+// formatHexBytes renders bytes as colon separated upper case hex, the way
+// certificate serials and fingerprints are usually displayed.
+func formatHexBytes(data []byte) string {
+	var buffer bytes.Buffer
+	for i, b := range data {
+		if i > 0 {
+			buffer.WriteByte(':')
+		}
+		fmt.Fprintf(&buffer, "%02X", b)
+	}
+	return buffer.String()
+}
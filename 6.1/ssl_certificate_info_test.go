@@ -0,0 +1,123 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         string
+}
+
+func makeTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+	parentCertificate, parentKey := template, key
+	if parent != nil {
+		parentCertificate, parentKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCertificate, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Creating certificate failed: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Parsing certificate failed: %v", err)
+	}
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		pem:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+func TestSslCertificateInfo(t *testing.T) {
+	notBefore := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)
+	caTemplate := func(serial int64, cn string) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             notBefore,
+			NotAfter:              notAfter,
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+	root := makeTestCertificate(t, caTemplate(1, "Test Root"), nil)
+	otherRoot := makeTestCertificate(t, caTemplate(2, "Other Root"), nil)
+	intermediate := makeTestCertificate(t, caTemplate(3, "Test Intermediate"), root)
+	leaf := makeTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(0x1234),
+		Subject:      pkix.Name{CommonName: "www.example.com", Organization: []string{"Example, Ltd"}, Country: []string{"GB"}},
+		DNSNames:     []string{"www.example.com", "example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}, intermediate)
+
+	chain, err := parseSslCertificates(leaf.pem + intermediate.pem)
+	if err != nil {
+		t.Fatalf("Parsing chain failed: %v", err)
+	}
+	if len(chain) != 2 {
+		t.Fatalf("Parsing chain gave %d certificates, expected 2", len(chain))
+	}
+
+	info := describeSslCertificate(chain[0])
+	expected := sslCertificateInfo{
+		subject:           "CN=www.example.com, O=Example\\, Ltd, C=GB",
+		issuer:            "CN=Test Intermediate",
+		sans:              []string{"www.example.com", "example.com", "10.0.0.1"},
+		serial:            "12:34",
+		notBefore:         notBefore,
+		notAfter:          notAfter,
+		keyType:           "ecdsa",
+		keySize:           256,
+		sha256Fingerprint: info.sha256Fingerprint,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Describing certificate gave %+v, expected %+v", info, expected)
+	}
+	if len(info.sha256Fingerprint) != 95 {
+		t.Errorf("Fingerprint '%s' is not a colon separated SHA-256 hash", info.sha256Fingerprint)
+	}
+	if subject, err := parseDistinguishedName(info.subject); err != nil || !reflect.DeepEqual(subject.Organization, []string{"Example, Ltd"}) {
+		t.Errorf("Subject '%s' does not parse back to the original name: %+v, %v", info.subject, subject, err)
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := verifySslCertificateChain(chain, []string{otherRoot.pem, root.pem}, now); err != nil {
+		t.Errorf("Verifying chain against its root failed: %v", err)
+	}
+	if err := verifySslCertificateChain(chain, []string{otherRoot.pem}, now); err == nil {
+		t.Errorf("Verifying chain against an unrelated root succeeded")
+	}
+	if err := verifySslCertificateChain(chain[:1], []string{root.pem}, now); err == nil {
+		t.Errorf("Verifying chain without its intermediate succeeded")
+	}
+	if err := verifySslCertificateChain(chain, []string{root.pem}, notAfter.Add(time.Hour)); err == nil {
+		t.Errorf("Verifying expired chain succeeded")
+	}
+
+	if _, err := parseSslCertificates("not a certificate"); err == nil {
+		t.Errorf("Parsing text without a certificate succeeded")
+	}
+}
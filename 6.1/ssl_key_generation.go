@@ -0,0 +1,209 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+// sslKeyOptions describes a private key and certificate to generate for an
+// SSL key resource, instead of having them supplied as PEM.
+type sslKeyOptions struct {
+	algorithm    string
+	size         int
+	subject      string
+	sans         []string
+	validityDays int
+}
+
+// generatedSslKey holds the PEM encoded results of generateSslKey. The
+// certificate is only set when a self-signed one was requested.
+type generatedSslKey struct {
+	private     string
+	request     string
+	certificate string
+}
+
+var sslKeyEcdsaCurves = map[int]elliptic.Curve{
+	256: elliptic.P256(),
+	384: elliptic.P384(),
+	521: elliptic.P521(),
+}
+
+// generateSslKey creates a private key and certificate signing request, and
+// a self-signed certificate if options.validityDays is positive.
+func generateSslKey(options sslKeyOptions) (*generatedSslKey, error) {
+	subject, err := parseDistinguishedName(options.subject)
+	if err != nil {
+		return nil, err
+	}
+	dnsNames, emailAddresses, ipAddresses := splitSubjectAltNames(options.sans)
+	if subject.CommonName == "" && len(dnsNames) == 0 && len(ipAddresses) == 0 {
+		return nil, fmt.Errorf("subject must contain a CN, or sans must be set")
+	}
+
+	var key crypto.Signer
+	var keyBlock *pem.Block
+	switch options.algorithm {
+	case "rsa":
+		size := options.size
+		if size == 0 {
+			size = 2048
+		}
+		if size != 2048 && size != 3072 && size != 4096 {
+			return nil, fmt.Errorf("RSA keys must be 2048, 3072 or 4096 bits, not %d", size)
+		}
+		rsaKey, err := rsa.GenerateKey(rand.Reader, size)
+		if err != nil {
+			return nil, err
+		}
+		key = rsaKey
+		keyBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}
+	case "ecdsa":
+		size := options.size
+		if size == 0 {
+			size = 256
+		}
+		curve, ok := sslKeyEcdsaCurves[size]
+		if !ok {
+			return nil, fmt.Errorf("ECDSA keys must be 256, 384 or 521 bits, not %d", size)
+		}
+		ecdsaKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(ecdsaKey)
+		if err != nil {
+			return nil, err
+		}
+		key = ecdsaKey
+		keyBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm '%s'", options.algorithm)
+	}
+
+	generated := &generatedSslKey{private: string(pem.EncodeToMemory(keyBlock))}
+
+	requestDer, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        subject,
+		DNSNames:       dnsNames,
+		EmailAddresses: emailAddresses,
+		IPAddresses:    ipAddresses,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate signing request: %v", err)
+	}
+	generated.request = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: requestDer}))
+
+	if options.validityDays > 0 {
+		serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return nil, err
+		}
+		notBefore := time.Now().Add(-5 * time.Minute)
+		template := &x509.Certificate{
+			SerialNumber:          serial,
+			Subject:               subject,
+			NotBefore:             notBefore,
+			NotAfter:              notBefore.Add(time.Duration(options.validityDays) * 24 * time.Hour),
+			KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			BasicConstraintsValid: true,
+			DNSNames:              dnsNames,
+			EmailAddresses:        emailAddresses,
+			IPAddresses:           ipAddresses,
+		}
+		certificateDer, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create self-signed certificate: %v", err)
+		}
+		generated.certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDer}))
+	}
+	return generated, nil
+}
+
+// parseDistinguishedName parses a subject such as
+// "CN=www.example.com, O=Example Ltd, C=GB". Commas within a value may be
+// escaped with a backslash.
+func parseDistinguishedName(dn string) (pkix.Name, error) {
+	name := pkix.Name{}
+	var parts []string
+	var current bytes.Buffer
+	for i := 0; i < len(dn); i++ {
+		switch {
+		case dn[i] == '\\' && i+1 < len(dn):
+			i++
+			current.WriteByte(dn[i])
+		case dn[i] == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(dn[i])
+		}
+	}
+	parts = append(parts, current.String())
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		separator := strings.Index(part, "=")
+		if separator <= 0 {
+			return name, fmt.Errorf("invalid subject component '%s', expected '<attribute>=<value>'", part)
+		}
+		value := strings.TrimSpace(part[separator+1:])
+		switch strings.ToUpper(strings.TrimSpace(part[:separator])) {
+		case "CN":
+			name.CommonName = value
+		case "O":
+			name.Organization = append(name.Organization, value)
+		case "OU":
+			name.OrganizationalUnit = append(name.OrganizationalUnit, value)
+		case "L":
+			name.Locality = append(name.Locality, value)
+		case "ST":
+			name.Province = append(name.Province, value)
+		case "C":
+			name.Country = append(name.Country, value)
+		case "STREET":
+			name.StreetAddress = append(name.StreetAddress, value)
+		case "POSTALCODE":
+			name.PostalCode = append(name.PostalCode, value)
+		case "SERIALNUMBER":
+			name.SerialNumber = value
+		default:
+			return name, fmt.Errorf("unsupported subject attribute '%s'", part[:separator])
+		}
+	}
+	return name, nil
+}
+
+// splitSubjectAltNames sorts subject alternative names into IP addresses,
+// email addresses and DNS names.
+func splitSubjectAltNames(sans []string) (dnsNames, emailAddresses []string, ipAddresses []net.IP) {
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			ipAddresses = append(ipAddresses, ip)
+		} else if strings.Contains(san, "@") {
+			emailAddresses = append(emailAddresses, san)
+		} else {
+			dnsNames = append(dnsNames, san)
+		}
+	}
+	return
+}
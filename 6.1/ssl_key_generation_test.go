@@ -0,0 +1,112 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSslKey(t *testing.T) {
+	tables := []struct {
+		options sslKeyOptions
+		keyType string
+		bits    int
+	}{
+		{sslKeyOptions{algorithm: "rsa", subject: "CN=www.example.com", validityDays: 30}, "RSA PRIVATE KEY", 2048},
+		{sslKeyOptions{algorithm: "ecdsa", size: 384, sans: []string{"www.example.com", "10.0.0.1"}, validityDays: 1}, "EC PRIVATE KEY", 384},
+		{sslKeyOptions{algorithm: "ecdsa", subject: "CN=csr-only.example.com"}, "EC PRIVATE KEY", 256},
+	}
+
+	for _, table := range tables {
+		generated, err := generateSslKey(table.options)
+		if err != nil {
+			t.Errorf("Generating %+v failed: %v", table.options, err)
+			continue
+		}
+		keyBlock, _ := pem.Decode([]byte(generated.private))
+		if keyBlock == nil || keyBlock.Type != table.keyType {
+			t.Errorf("Generating %+v gave a private key that is not a %s", table.options, table.keyType)
+		}
+		requestBlock, _ := pem.Decode([]byte(generated.request))
+		if requestBlock == nil {
+			t.Errorf("Generating %+v gave no certificate signing request", table.options)
+			continue
+		}
+		request, err := x509.ParseCertificateRequest(requestBlock.Bytes)
+		if err != nil || request.CheckSignature() != nil {
+			t.Errorf("Generating %+v gave an invalid certificate signing request: %v", table.options, err)
+			continue
+		}
+		switch key := request.PublicKey.(type) {
+		case *rsa.PublicKey:
+			if key.N.BitLen() != table.bits {
+				t.Errorf("Generating %+v gave a %d bit key, expected %d", table.options, key.N.BitLen(), table.bits)
+			}
+		case *ecdsa.PublicKey:
+			if key.Curve.Params().BitSize != table.bits {
+				t.Errorf("Generating %+v gave a %d bit key, expected %d", table.options, key.Curve.Params().BitSize, table.bits)
+			}
+		}
+
+		if table.options.validityDays == 0 {
+			if generated.certificate != "" {
+				t.Errorf("Generating %+v gave an unrequested certificate", table.options)
+			}
+			continue
+		}
+		certificateBlock, _ := pem.Decode([]byte(generated.certificate))
+		if certificateBlock == nil {
+			t.Errorf("Generating %+v gave no certificate", table.options)
+			continue
+		}
+		certificate, err := x509.ParseCertificate(certificateBlock.Bytes)
+		if err != nil || certificate.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature) != nil {
+			t.Errorf("Generating %+v gave an invalid self-signed certificate: %v", table.options, err)
+			continue
+		}
+		if !reflect.DeepEqual(certificate.DNSNames, request.DNSNames) || len(certificate.IPAddresses) != len(table.options.sans)-len(request.DNSNames) {
+			t.Errorf("Generating %+v gave a certificate without the requested names", table.options)
+		}
+	}
+}
+
+func TestGenerateSslKeyErrors(t *testing.T) {
+	tables := []struct {
+		options sslKeyOptions
+		err     string
+	}{
+		{sslKeyOptions{algorithm: "rsa", size: 1024, subject: "CN=a"}, "RSA keys must be 2048, 3072 or 4096 bits"},
+		{sslKeyOptions{algorithm: "ecdsa", size: 2048, subject: "CN=a"}, "ECDSA keys must be 256, 384 or 521 bits"},
+		{sslKeyOptions{algorithm: "dsa", subject: "CN=a"}, "unsupported key algorithm"},
+		{sslKeyOptions{algorithm: "rsa", subject: "O=Example"}, "subject must contain a CN"},
+		{sslKeyOptions{algorithm: "rsa", subject: "CN=a, XX=b"}, "unsupported subject attribute"},
+	}
+
+	for _, table := range tables {
+		_, err := generateSslKey(table.options)
+		if err == nil || !strings.HasPrefix(err.Error(), table.err) {
+			t.Errorf("Generating %+v returned '%v', expected '%s'", table.options, err, table.err)
+		}
+	}
+}
+
+func TestParseDistinguishedName(t *testing.T) {
+	name, err := parseDistinguishedName(`CN=www.example.com, O=Example\, Ltd, OU=Web, OU=Ops, C=GB`)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+	if name.CommonName != "www.example.com" || !reflect.DeepEqual(name.Organization, []string{"Example, Ltd"}) ||
+		!reflect.DeepEqual(name.OrganizationalUnit, []string{"Web", "Ops"}) || !reflect.DeepEqual(name.Country, []string{"GB"}) {
+		t.Errorf("Parsing gave %+v", name)
+	}
+	if _, err := parseDistinguishedName("www.example.com"); err == nil {
+		t.Errorf("Parsing a subject without attributes succeeded")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sslTicketKeyLengths gives the key length in bytes required by each
+// session ticket algorithm.
+var sslTicketKeyLengths = map[string]int{
+	"aes_256_cbc_hmac_sha256": 64,
+}
+
+// sslTicketKeyRotationSeparator separates the name of a rotating
+// vtm_ssl_ticket_key from the validity start in the names of its keys. It
+// is reserved for generated keys, so that static keys such as
+// "<name>_2024" are never taken for them.
+const sslTicketKeyRotationSeparator = "~"
+
+// sslTicketKeyWindow is a key managed by a rotating vtm_ssl_ticket_key. Keys
+// are named "<name>~<validity_start>".
+type sslTicketKeyWindow struct {
+	name          string
+	identifier    string
+	keyHash       string
+	validityStart int
+	validityEnd   int
+}
+
+// rotatedSslTicketKeyPattern matches the names of the keys managed by the
+// rotating vtm_ssl_ticket_key called name.
+func rotatedSslTicketKeyPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(name+sslTicketKeyRotationSeparator) + "[0-9]+$")
+}
+
+// planSslTicketKeyRotation decides which keys must be created and deleted at
+// time now, all times being seconds since the epoch. A key is deleted once
+// it has been unable to encrypt new tickets for overlap seconds, so that
+// tickets it issued can still be decrypted for a while. A new key is made
+// valid from the end of the latest key, and created overlap seconds before
+// it is needed so that it has reached every traffic manager in the cluster.
+// If no key is currently valid, one is created that is valid from now.
+func planSslTicketKeyRotation(existing []sslTicketKeyWindow, name string, interval, overlap, now int) (create []sslTicketKeyWindow, remove []string) {
+	latestEnd := 0
+	for _, key := range existing {
+		if key.validityEnd+overlap <= now {
+			remove = append(remove, key.name)
+		} else if key.validityEnd > latestEnd {
+			latestEnd = key.validityEnd
+		}
+	}
+	if latestEnd <= now {
+		latestEnd = now
+	}
+	for latestEnd-overlap <= now {
+		create = append(create, sslTicketKeyWindow{
+			name:          fmt.Sprintf("%s%s%d", name, sslTicketKeyRotationSeparator, latestEnd),
+			validityStart: latestEnd,
+			validityEnd:   latestEnd + interval,
+		})
+		latestEnd += interval
+	}
+	sort.Strings(remove)
+	return create, remove
+}
+
+// validateSslTicketKeyRotation checks the settings of a rotating
+// vtm_ssl_ticket_key.
+func validateSslTicketKeyRotation(interval, overlap int, algorithm string) error {
+	if overlap >= interval {
+		return fmt.Errorf("overlap must be less than rotation_interval")
+	}
+	if _, ok := sslTicketKeyLengths[algorithm]; !ok {
+		return fmt.Errorf("keys cannot be generated for algorithm '%s'", algorithm)
+	}
+	return nil
+}
+
+// generateSslTicketKey returns a random identifier and key for algorithm,
+// hex encoded as the vTM expects.
+func generateSslTicketKey(algorithm string) (identifier, key string, err error) {
+	length, ok := sslTicketKeyLengths[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported session ticket algorithm '%s'", algorithm)
+	}
+	identifierBytes := make([]byte, 16)
+	keyBytes := make([]byte, length)
+	if _, err := rand.Read(identifierBytes); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(keyBytes); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(identifierBytes), hex.EncodeToString(keyBytes), nil
+}
+
+// hashSslTicketKey returns the SHA-256 hash of a hex encoded key, so that
+// the key itself need not be kept in the Terraform state.
+func hashSslTicketKey(key string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(key)))
+	return hex.EncodeToString(hash[:])
+}
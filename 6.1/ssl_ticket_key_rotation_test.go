@@ -0,0 +1,85 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanSslTicketKeyRotation(t *testing.T) {
+	window := func(start, end int) sslTicketKeyWindow {
+		return sslTicketKeyWindow{name: fmt.Sprintf("tickets~%d", start), validityStart: start, validityEnd: end}
+	}
+	tables := []struct {
+		existing []sslTicketKeyWindow
+		now      int
+		create   []sslTicketKeyWindow
+		remove   []string
+	}{
+		// No keys yet, so one is created that is valid from now.
+		{nil, 10000, []sslTicketKeyWindow{window(10000, 13600)}, nil},
+		// Current key is not near its end.
+		{[]sslTicketKeyWindow{window(10000, 13600)}, 11000, nil, nil},
+		// Current key ends within the overlap, so its successor is
+		// created.
+		{[]sslTicketKeyWindow{window(10000, 13600)}, 13000, []sslTicketKeyWindow{window(13600, 17200)}, nil},
+		// The previous key is kept for the overlap after it expires.
+		{[]sslTicketKeyWindow{window(10000, 13600), window(13600, 17200)}, 14000, nil, nil},
+		// And then deleted.
+		{[]sslTicketKeyWindow{window(10000, 13600), window(13600, 17200)}, 14200, nil, []string{"tickets~10000"}},
+		// Every key has lapsed, so a new one starts now.
+		{[]sslTicketKeyWindow{window(10000, 13600)}, 50000, []sslTicketKeyWindow{window(50000, 53600)}, []string{"tickets~10000"}},
+	}
+
+	for _, table := range tables {
+		create, remove := planSslTicketKeyRotation(table.existing, "tickets", 3600, 600, table.now)
+		if !reflect.DeepEqual(create, table.create) || !reflect.DeepEqual(remove, table.remove) {
+			t.Errorf("Rotating %+v at %d created %+v and removed %v, expected %+v and %v", table.existing, table.now, create, remove, table.create, table.remove)
+		}
+	}
+}
+
+func TestGenerateSslTicketKey(t *testing.T) {
+	identifier, key, err := generateSslTicketKey("aes_256_cbc_hmac_sha256")
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+	if decoded, err := hex.DecodeString(identifier); err != nil || len(decoded) != 16 {
+		t.Errorf("Generated identifier '%s' is not 16 hex encoded bytes", identifier)
+	}
+	if decoded, err := hex.DecodeString(key); err != nil || len(decoded) != 64 {
+		t.Errorf("Generated key '%s' is not 64 hex encoded bytes", key)
+	}
+	if hash := hashSslTicketKey(key); len(hash) != 64 || hash != hashSslTicketKey(strings.ToUpper(key)) {
+		t.Errorf("Hashing key '%s' gave '%s'", key, hashSslTicketKey(key))
+	}
+	if _, _, err := generateSslTicketKey("rc4"); err == nil {
+		t.Errorf("Generating key for an unsupported algorithm succeeded")
+	}
+	pattern := rotatedSslTicketKeyPattern("tickets")
+	if !pattern.MatchString("tickets~1500000000") {
+		t.Errorf("Rotated key pattern does not match generated names")
+	}
+	for _, name := range []string{"tickets_old", "tickets_2024", "tickets_1500000000", "tickets~old", "other~1500000000"} {
+		if pattern.MatchString(name) {
+			t.Errorf("Rotated key pattern matches '%s'", name)
+		}
+	}
+}
+
+func TestValidateSslTicketKeyRotation(t *testing.T) {
+	if err := validateSslTicketKeyRotation(3600, 600, "aes_256_cbc_hmac_sha256"); err != nil {
+		t.Errorf("Valid rotation settings were rejected: %v", err)
+	}
+	if err := validateSslTicketKeyRotation(3600, 3600, "aes_256_cbc_hmac_sha256"); err == nil {
+		t.Errorf("An overlap as long as the rotation interval was accepted")
+	}
+	if err := validateSslTicketKeyRotation(3600, 600, "rc4"); err == nil {
+		t.Errorf("An algorithm without a key length was accepted")
+	}
+}
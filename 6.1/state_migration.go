@@ -0,0 +1,205 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// sectionedResource describes a resource whose sections used to be flattened
+// into prefixed top-level attributes. sections maps each flattened prefix to
+// the name of the nested block that now holds its fields. The flattened
+// fields are kept as deprecated aliases of the block.
+type sectionedResource struct {
+	schema   func() map[string]*schema.Schema
+	sections map[string]string
+}
+
+var sectionedResources = map[string]sectionedResource{
+	"vtm_global_settings": {
+		schema:   getResourceGlobalSettingsSchema,
+		sections: map[string]string{"appliance": "appliance"},
+	},
+	"vtm_pool": {
+		schema:   getResourcePoolSchema,
+		sections: map[string]string{"auto_scaling": "auto_scaling"},
+	},
+	"vtm_virtual_server": {
+		schema: getResourceVirtualServerSchema,
+		sections: map[string]string{
+			"connection": "connection_settings",
+			"http":       "http",
+			"ssl":        "ssl",
+		},
+	},
+}
+
+// jsonTables lists, for each resource, the tables that can be configured
+// either as blocks or as JSON in a "<table>_json" attribute. Version 1 state
+// always holds the table's blocks, read back from the traffic manager, even
+// when it was configured as JSON.
+var jsonTables = map[string][]string{
+	"vtm_appliance_nat":    {"many_to_one_all_ports", "many_to_one_port_locked", "one_to_one", "port_mapping"},
+	"vtm_custom":           {"string_lists"},
+	"vtm_glb_service":      {"dnssec_keys", "location_settings"},
+	"vtm_log_export":       {"metadata"},
+	"vtm_pool":             {"nodes_table"},
+	"vtm_traffic_ip_group": {"ip_mapping"},
+	"vtm_traffic_manager":  {"appliance_card", "appliance_sysctl", "trafficip"},
+	"vtm_user_group":       {"permissions"},
+}
+
+// nestedFieldPath returns the attribute path of a flattened field name, for
+// example "http.add_cluster_ip" for the "http_add_cluster_ip" field of a
+// virtual server.
+func nestedFieldPath(resourceType, field string) string {
+	resource, ok := sectionedResources[resourceType]
+	if !ok {
+		return field
+	}
+	resourceSchema := resource.schema()
+	for prefix, block := range resource.sections {
+		if !strings.HasPrefix(field, prefix+"_") {
+			continue
+		}
+		nestedField := strings.TrimPrefix(field, prefix+"_")
+		if _, ok := resourceSchema[block].Elem.(*schema.Resource).Schema[nestedField]; ok {
+			return block + "." + nestedField
+		}
+	}
+	return field
+}
+
+// withStateMigrations sets up the migration of older state for every
+// resource in the provider's resource map.
+func withStateMigrations(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	for resourceType, resource := range resources {
+		resource.SchemaVersion = 2
+		resource.MigrateState = migrateResourceState(resourceType, resource.Schema)
+	}
+	return resources
+}
+
+// migrateResourceState returns the MigrateState function for a resource.
+// Version 0 state may have been written by this provider before the nested
+// sections were introduced, or by the provider for an older API version;
+// both are brought up to date by renaming fields and dropping anything that
+// this API version no longer has. Flattened section fields are left as they
+// are, since they are still accepted as aliases of the nested blocks.
+func migrateResourceState(resourceType string, resourceSchema map[string]*schema.Schema) schema.StateMigrateFunc {
+	return func(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+		switch v {
+		case 0:
+			log.Printf("[INFO] Found %s state v0; migrating to v1", resourceType)
+			is = migrateStateV0toV1(resourceType, is, resourceSchema)
+			fallthrough
+		case 1:
+			log.Printf("[INFO] Found %s state v1; migrating to v2", resourceType)
+			return migrateStateV1toV2(resourceType, is), nil
+		default:
+			return is, fmt.Errorf("Unexpected schema version: %d", v)
+		}
+	}
+}
+
+func migrateStateV0toV1(resourceType string, is *terraform.InstanceState, resourceSchema map[string]*schema.Schema) *terraform.InstanceState {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is
+	}
+	before := make(map[string]string, len(is.Attributes))
+	for key, value := range is.Attributes {
+		before[key] = value
+	}
+	renameStateFields(is, apiRenamedFields(resourceType))
+	for _, field := range removeUnknownStateFields(is, resourceSchema) {
+		log.Printf("[WARN] %s '%s': dropping '%s' from state, it does not exist in API version %s", resourceType, is.ID, field, restApiVersion)
+	}
+	// Only attribute names are logged, as values may be passwords or keys
+	log.Printf("[DEBUG] %s '%s': migrated attributes %s", resourceType, is.ID, strings.Join(changedStateKeys(before, is.Attributes), ", "))
+	return is
+}
+
+// migrateStateV1toV2 drops the blocks of tables that are configured as JSON.
+// Only one of the two forms is now read back into state, so keeping both
+// would show a one-time diff removing the blocks that were never configured.
+func migrateStateV1toV2(resourceType string, is *terraform.InstanceState) *terraform.InstanceState {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is
+	}
+	for _, table := range jsonTables[resourceType] {
+		if is.Attributes[table+"_json"] == "" {
+			continue
+		}
+		for key := range is.Attributes {
+			if field, _ := splitStateKey(key); field == table {
+				delete(is.Attributes, key)
+			}
+		}
+		log.Printf("[DEBUG] %s '%s': dropping '%s' from state, it is configured as %s_json", resourceType, is.ID, table, table)
+	}
+	return is
+}
+
+// changedStateKeys returns the sorted keys that were added, removed or
+// changed between two sets of attributes.
+func changedStateKeys(before, after map[string]string) []string {
+	changed := []string{}
+	for key, value := range before {
+		if afterValue, ok := after[key]; !ok || afterValue != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// splitStateKey splits a flatmap key such as "nodes_table.1234.node" into
+// its top-level field and the remainder (".1234.node").
+func splitStateKey(key string) (string, string) {
+	if dot := strings.Index(key, "."); dot >= 0 {
+		return key[:dot], key[dot:]
+	}
+	return key, ""
+}
+
+func renameStateFields(is *terraform.InstanceState, renamed map[string]string) {
+	for key, value := range is.Attributes {
+		field, suffix := splitStateKey(key)
+		if newField, ok := renamed[field]; ok {
+			is.Attributes[newField+suffix] = value
+			delete(is.Attributes, key)
+		}
+	}
+}
+
+// removeUnknownStateFields deletes the attributes that have no field in the
+// schema, returning the names of the fields that were removed.
+func removeUnknownStateFields(is *terraform.InstanceState, resourceSchema map[string]*schema.Schema) []string {
+	removed := []string{}
+	for key := range is.Attributes {
+		field, suffix := splitStateKey(key)
+		if _, ok := resourceSchema[field]; ok || field == "id" {
+			continue
+		}
+		delete(is.Attributes, key)
+		if suffix == "" || suffix == ".#" || suffix == ".%" {
+			removed = append(removed, field)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
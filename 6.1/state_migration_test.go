@@ -0,0 +1,310 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testMigratedAttributes(t *testing.T, attributes map[string]string, expected map[string]string) {
+	if len(attributes) != len(expected) {
+		t.Fatalf("Expected %d attributes, got %#v", len(expected), attributes)
+	}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("Expected %s to be %q, got %q", key, value, attributes[key])
+		}
+	}
+}
+
+func TestResourcePoolMigrateState(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "my_pool",
+		Attributes: map[string]string{
+			"id":                              "my_pool",
+			"name":                            "my_pool",
+			"auto_scaling_enabled":            "true",
+			"auto_scaling_securitygroupids.#": "1",
+			"auto_scaling_securitygroupids.0": "sg-1",
+			"max_connection_attempts":         "0",
+		},
+	}
+	is, err := migrateResourceState("vtm_pool", getResourcePoolSchema())(0, is, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"id":                              "my_pool",
+		"name":                            "my_pool",
+		"auto_scaling_enabled":            "true",
+		"auto_scaling_securitygroupids.#": "1",
+		"auto_scaling_securitygroupids.0": "sg-1",
+		"max_connection_attempts":         "0",
+	})
+}
+
+func TestSectionAliases(t *testing.T) {
+	resourceSchema := getResourcePoolSchema()
+	fields := resourceSchema["auto_scaling"].Elem.(*schema.Resource).Schema
+	alias := resourceSchema["auto_scaling_port"]
+	if alias == nil || alias.Deprecated == "" || alias.Default != 80 || len(alias.ConflictsWith) != 1 || alias.ConflictsWith[0] != "auto_scaling" {
+		t.Fatalf("Unexpected auto_scaling_port alias %#v", alias)
+	}
+
+	// Without the block, the fields are assigned from the aliases
+	d := schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{
+		"name":              "my_pool",
+		"auto_scaling_port": 8080,
+	})
+	restore := setSectionDefaults(d, "auto_scaling", "auto_scaling", fields)
+	if port := d.Get("auto_scaling.0.port"); port != 8080 {
+		t.Errorf("Expected auto_scaling.0.port to be 8080, got %v", port)
+	}
+	if hysteresis := d.Get("auto_scaling.0.hysteresis"); hysteresis != 20 {
+		t.Errorf("Expected auto_scaling.0.hysteresis to be 20, got %v", hysteresis)
+	}
+	restore()
+	if sectionInUse(d, "auto_scaling") {
+		t.Errorf("auto_scaling block was kept after its fields were assigned")
+	}
+
+	values := map[string]interface{}{}
+	for field, fieldSchema := range fields {
+		values[field] = sectionFieldDefault(fieldSchema)
+	}
+	values["port"] = 8081
+	values["securitygroupids"] = []string{"sg-1"}
+
+	// Values read back are set in the aliases while the block is not used
+	setSection(d, "auto_scaling", "auto_scaling", values, fields)
+	if port := d.Get("auto_scaling_port"); port != 8081 {
+		t.Errorf("Expected auto_scaling_port to be 8081, got %v", port)
+	}
+	if sectionInUse(d, "auto_scaling") {
+		t.Errorf("auto_scaling block was set while its aliases are used")
+	}
+
+	// and in the block while it is, with the aliases reset
+	d = schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{
+		"name":         "my_pool",
+		"auto_scaling": []interface{}{map[string]interface{}{"port": 8081}},
+	})
+	setSection(d, "auto_scaling", "auto_scaling", values, fields)
+	if port := d.Get("auto_scaling.0.port"); port != 8081 {
+		t.Errorf("Expected auto_scaling.0.port to be 8081, got %v", port)
+	}
+	if groups := d.Get("auto_scaling.0.securitygroupids").(*schema.Set).List(); len(groups) != 1 || groups[0] != "sg-1" {
+		t.Errorf("Expected auto_scaling.0.securitygroupids to be [sg-1], got %v", groups)
+	}
+	if port := d.Get("auto_scaling_port"); port != 80 {
+		t.Errorf("Expected auto_scaling_port to be reset to 80, got %v", port)
+	}
+
+	// Data sources and exports offer the block as well as the aliases
+	d = schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{"name": "my_pool"})
+	setSection(d, "auto_scaling", "auto_scaling", values, fields)
+	setSectionsFromAliases("vtm_pool", d)
+	if port := d.Get("auto_scaling.0.port"); port != 8081 {
+		t.Errorf("Expected auto_scaling.0.port to be 8081, got %v", port)
+	}
+}
+
+func TestResourcePoolMigrateStateTableJson(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "my_pool",
+		Attributes: map[string]string{
+			"id":                        "my_pool",
+			"name":                      "my_pool",
+			"nodes_table.#":             "1",
+			"nodes_table.1234.node":     "10.0.0.1:80",
+			"nodes_table.1234.state":    "active",
+			"nodes_table_json":          `[{"node":"10.0.0.1:80"}]`,
+			"dns_autoscale_hostnames.#": "0",
+		},
+	}
+	is, err := migrateResourceState("vtm_pool", getResourcePoolSchema())(1, is, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"id":                        "my_pool",
+		"name":                      "my_pool",
+		"nodes_table_json":          `[{"node":"10.0.0.1:80"}]`,
+		"dns_autoscale_hostnames.#": "0",
+	})
+
+	// Tables configured as blocks are left alone
+	is = &terraform.InstanceState{
+		ID: "my_pool",
+		Attributes: map[string]string{
+			"id":                    "my_pool",
+			"nodes_table.#":         "1",
+			"nodes_table.1234.node": "10.0.0.1:80",
+		},
+	}
+	is, err = migrateResourceState("vtm_pool", getResourcePoolSchema())(1, is, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"id":                    "my_pool",
+		"nodes_table.#":         "1",
+		"nodes_table.1234.node": "10.0.0.1:80",
+	})
+}
+
+func TestResourceVirtualServerMigrateState(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "my_vs",
+		Attributes: map[string]string{
+			"name":                           "my_vs",
+			"ssl_decrypt":                    "true",
+			"ssl_server_cert_default":        "my_cert",
+			"http_chunk_overhead_forwarding": "eager",
+			"http2_enabled":                  "true",
+			"connection_errors_error_file":   "Default",
+			"connection_timeout":             "40",
+		},
+	}
+	is, err := migrateResourceState("vtm_virtual_server", getResourceVirtualServerSchema())(0, is, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"name":                           "my_vs",
+		"ssl_decrypt":                    "true",
+		"ssl_server_cert_default":        "my_cert",
+		"http_chunk_overhead_forwarding": "eager",
+		"http2_enabled":                  "true",
+		"connection_errors_error_file":   "Default",
+		"connection_timeout":             "40",
+	})
+}
+
+func TestResourceVirtualServerMigrateStateFromOlderApi(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "my_vs",
+		Attributes: map[string]string{
+			"name":                    "my_vs",
+			"add_cluster_ip":          "false",
+			"so_nagle":                "true",
+			"l4accel_timeout":         "1800",
+			"l4accel_state_sync":      "false",
+			"udp_udp_end_transaction": "one_response",
+			"ssl_ssl_support_tls1_2":  "enabled",
+			"ssl_ssl_support_ssl2":    "disabled",
+			"completionrules.#":       "1",
+			"completionrules.0":       "my_rule",
+			"ssl_add_http_headers":    "true",
+		},
+	}
+	is, err := migrateResourceState("vtm_virtual_server", getResourceVirtualServerSchema())(0, is, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"name":                 "my_vs",
+		"http_add_cluster_ip":  "false",
+		"tcp_nagle":            "true",
+		"ssl_support_tls1_2":   "enabled",
+		"ssl_add_http_headers": "true",
+		"completion_rules.#":   "1",
+		"completion_rules.0":   "my_rule",
+	})
+}
+
+func TestResourceGlobalSettingsMigrateStateEmpty(t *testing.T) {
+	is, err := migrateResourceState("vtm_global_settings", getResourceGlobalSettingsSchema())(0, &terraform.InstanceState{}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(is.Attributes) != 0 {
+		t.Fatalf("Expected no attributes, got %#v", is.Attributes)
+	}
+}
+
+func TestRemoveUnknownStateFields(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "my_tip",
+		Attributes: map[string]string{
+			"id":                    "my_tip",
+			"name":                  "my_tip",
+			"backend_traffic_ips.#": "2",
+			"backend_traffic_ips.0": "10.0.0.1",
+			"backend_traffic_ips.1": "10.0.0.2",
+		},
+	}
+	removed := removeUnknownStateFields(is, map[string]*schema.Schema{
+		"name": &schema.Schema{Type: schema.TypeString},
+	})
+	if len(removed) != 1 || removed[0] != "backend_traffic_ips" {
+		t.Fatalf("Expected backend_traffic_ips to be removed, got %v", removed)
+	}
+	testMigratedAttributes(t, is.Attributes, map[string]string{
+		"id":   "my_tip",
+		"name": "my_tip",
+	})
+}
+
+func TestNestedFieldPath(t *testing.T) {
+	cases := []struct {
+		resourceType string
+		field        string
+		expected     string
+	}{
+		{"vtm_virtual_server", "http_add_cluster_ip", "http.add_cluster_ip"},
+		{"vtm_virtual_server", "ssl_decrypt", "ssl_decrypt"},
+		{"vtm_virtual_server", "tcp_nagle", "tcp_nagle"},
+		{"vtm_pool", "ssl_support_tls1", "ssl_support_tls1"},
+		{"vtm_pool", "auto_scaling_enabled", "auto_scaling.enabled"},
+		{"vtm_protection", "connection_rate_rate_timer", "connection_rate_rate_timer"},
+	}
+	for _, c := range cases {
+		if path := nestedFieldPath(c.resourceType, c.field); path != c.expected {
+			t.Errorf("Expected %s %s to be %s, got %s", c.resourceType, c.field, c.expected, path)
+		}
+	}
+}
+
+func TestDataSourceApiVersionChanges(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceApiVersionChanges().Schema, map[string]interface{}{
+		"from_version":  "5.2",
+		"resource_type": "vtm_pool",
+	})
+	if err := dataSourceApiVersionChangesRead(d, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	removed := d.Get("removed_fields").([]interface{})
+	if len(removed) != 1 || removed[0] != "vtm_pool.l4accel_snat" {
+		t.Errorf("Unexpected removed fields: %v", removed)
+	}
+	renamed := d.Get("renamed_fields").(map[string]interface{})
+	if renamed["vtm_pool.auto_scaling_enabled"] != "vtm_pool.auto_scaling.enabled" {
+		t.Errorf("Unexpected renamed fields: %v", renamed)
+	}
+}
+
+func TestProviderStateMigrations(t *testing.T) {
+	resources := Provider().(*schema.Provider).ResourcesMap
+	for _, resourceType := range []string{"vtm_pool", "vtm_virtual_server", "vtm_traffic_ip_group"} {
+		if resources[resourceType].SchemaVersion != 2 || resources[resourceType].MigrateState == nil {
+			t.Errorf("No state migration is registered for %s", resourceType)
+		}
+	}
+}
+
+func TestChangedStateKeys(t *testing.T) {
+	changed := changedStateKeys(
+		map[string]string{"name": "my_pool", "auto_scaling_enabled": "true", "note": "secret"},
+		map[string]string{"name": "my_pool", "auto_scaling.#": "1", "auto_scaling.0.enabled": "true", "note": "changed"},
+	)
+	expected := []string{"auto_scaling.#", "auto_scaling.0.enabled", "auto_scaling_enabled", "note"}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected changed keys %v, got %v", expected, changed)
+	}
+}
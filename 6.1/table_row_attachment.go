@@ -0,0 +1,190 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+// tableRowAttachment implements a resource that manages one row of a table
+// belonging to another configuration object, leaving the other rows alone.
+// The resource's attributes are the row's fields, apart from the one naming
+// the object; the row is found by the value of its key field, and has the ID
+// "<object>/<key>".
+type tableRowAttachment struct {
+	resourceType string
+	objectType   string
+	objectAttr   string
+	keyAttr      string
+	schema       func() map[string]*schema.Schema
+	read         configObjectReader
+
+	// fields maps the attributes whose name differs from the table field
+	// they are stored in to that field.
+	fields map[string]string
+}
+
+func (a *tableRowAttachment) rowField(attr string) string {
+	if field, ok := a.fields[attr]; ok {
+		return field
+	}
+	return attr
+}
+
+// rowSchema returns the schema of the row's fields, keyed by field name.
+func (a *tableRowAttachment) rowSchema() map[string]*schema.Schema {
+	rowSchema := map[string]*schema.Schema{}
+	for attr, attrSchema := range a.schema() {
+		if attr != a.objectAttr {
+			rowSchema[a.rowField(attr)] = attrSchema
+		}
+	}
+	return rowSchema
+}
+
+// findRow returns the index of the row with a key in a decoded table, or -1.
+func (a *tableRowAttachment) findRow(rows []interface{}, key string) int {
+	keyField := a.rowField(a.keyAttr)
+	for i, row := range rows {
+		if fields, ok := row.(map[string]interface{}); ok && fields[keyField] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// setRow returns a copy of the table with the resource's row added, or
+// changed in place so that fields it does not manage are kept.
+func (a *tableRowAttachment) setRow(rows []interface{}, d *schema.ResourceData) []interface{} {
+	updated := append([]interface{}{}, rows...)
+	row := map[string]interface{}{}
+	index := a.findRow(updated, d.Get(a.keyAttr).(string))
+	if index >= 0 {
+		for field, value := range updated[index].(map[string]interface{}) {
+			row[field] = value
+		}
+	}
+	for attr := range a.schema() {
+		if attr == a.objectAttr {
+			continue
+		}
+		value := d.Get(attr)
+		if set, ok := value.(*schema.Set); ok {
+			value = set.List()
+		}
+		row[a.rowField(attr)] = value
+	}
+	if index >= 0 {
+		updated[index] = row
+	} else {
+		updated = append(updated, row)
+	}
+	return updated
+}
+
+// removeRow returns a copy of the table without the row with a key.
+func (a *tableRowAttachment) removeRow(rows []interface{}, key string) []interface{} {
+	updated := append([]interface{}{}, rows...)
+	if index := a.findRow(updated, key); index >= 0 {
+		updated = append(updated[:index:index], updated[index+1:]...)
+	}
+	return updated
+}
+
+func (a *tableRowAttachment) modify(tm interface{}, objectName string, update func([]interface{}) ([]interface{}, error)) error {
+	return modifyConfigObjectField(tm, a.objectType, objectName, a.read, a.rowSchema(), func(value interface{}) (interface{}, error) {
+		return update(jsonRows(value))
+	})
+}
+
+func (a *tableRowAttachment) Read(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get(a.objectAttr).(string)
+	key := d.Get(a.keyAttr).(string)
+	field, _, err := a.read(tm.(*vtm.VirtualTrafficManager), objectName)
+	if err != nil {
+		if err.ErrorId == "resource.not_found" {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Failed to read %s '%v/%v': %v", a.resourceType, objectName, key, err.ErrorText)
+	}
+	table, decodeErr := decodeJsonValue(field)
+	if decodeErr != nil {
+		return fmt.Errorf("Failed to read %s '%v/%v': %v", a.resourceType, objectName, key, decodeErr)
+	}
+	rows := jsonRows(table)
+	index := a.findRow(rows, key)
+	if index < 0 {
+		d.SetId("")
+		return nil
+	}
+	row := rows[index].(map[string]interface{})
+	for attr, attrSchema := range a.schema() {
+		if attr != a.objectAttr && attr != a.keyAttr {
+			d.Set(attr, normalizeTableValue(row[a.rowField(attr)], attrSchema))
+		}
+	}
+	d.SetId(objectName + "/" + key)
+	return nil
+}
+
+func (a *tableRowAttachment) Create(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get(a.objectAttr).(string)
+	key := d.Get(a.keyAttr).(string)
+
+	// Only the first pass may find the row already present; later passes
+	// see the row this resource wrote itself.
+	written := false
+	err := a.modify(tm, objectName, func(rows []interface{}) ([]interface{}, error) {
+		if !written && a.findRow(rows, key) >= 0 {
+			return nil, fmt.Errorf("%s '%s' already exists; import it with the ID '%s/%s'", a.keyAttr, key, objectName, key)
+		}
+		written = true
+		return a.setRow(rows, d), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating %s '%s/%s': %v", a.resourceType, objectName, key, err)
+	}
+	d.SetId(objectName + "/" + key)
+	return nil
+}
+
+func (a *tableRowAttachment) Update(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get(a.objectAttr).(string)
+	key := d.Get(a.keyAttr).(string)
+	err := a.modify(tm, objectName, func(rows []interface{}) ([]interface{}, error) {
+		return a.setRow(rows, d), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating %s '%s/%s': %v", a.resourceType, objectName, key, err)
+	}
+	return nil
+}
+
+func (a *tableRowAttachment) Delete(d *schema.ResourceData, tm interface{}) error {
+	objectName := d.Get(a.objectAttr).(string)
+	key := d.Get(a.keyAttr).(string)
+	err := a.modify(tm, objectName, func(rows []interface{}) ([]interface{}, error) {
+		return a.removeRow(rows, key), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete %s '%v/%v': %v", a.resourceType, objectName, key, err)
+	}
+	d.SetId("")
+	return nil
+}
+
+func (a *tableRowAttachment) Import(d *schema.ResourceData, tm interface{}) ([]*schema.ResourceData, error) {
+	separator := strings.LastIndex(d.Id(), "/")
+	if separator <= 0 || separator == len(d.Id())-1 {
+		return nil, fmt.Errorf("Invalid %s ID '%s', expected '<%s>/<%s>'", a.resourceType, d.Id(), a.objectAttr, a.keyAttr)
+	}
+	d.Set(a.objectAttr, d.Id()[:separator])
+	d.Set(a.keyAttr, d.Id()[separator+1:])
+	return []*schema.ResourceData{d}, nil
+}
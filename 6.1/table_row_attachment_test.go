@@ -0,0 +1,74 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	vtm "github.com/pulse-vadc/go-vtm/6.1"
+)
+
+type testTableRow struct {
+	Name   *string `json:"name,omitempty"`
+	Weight *int    `json:"weight,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+func TestTableRowAttachment(t *testing.T) {
+	table := &[]testTableRow{
+		{Name: getStringAddr("a"), Weight: getIntAddr(2), Note: getStringAddr("kept")},
+		{Name: getStringAddr("b")},
+	}
+	writes := 0
+	attachment := &tableRowAttachment{
+		resourceType: "vtm_test_row",
+		objectType:   "vtm_test",
+		objectAttr:   "object",
+		keyAttr:      "name",
+		schema: func() map[string]*schema.Schema {
+			return map[string]*schema.Schema{
+				"object": &schema.Schema{Type: schema.TypeString, Required: true},
+				"name":   &schema.Schema{Type: schema.TypeString, Required: true},
+				"weight": &schema.Schema{Type: schema.TypeInt, Optional: true, Default: 1},
+			}
+		},
+		read: func(tm *vtm.VirtualTrafficManager, objectName string) (interface{}, func() *vtm.ReqError, *vtm.ReqError) {
+			return &table, func() *vtm.ReqError {
+				writes++
+				return nil
+			}, nil
+		},
+	}
+	tm := (*vtm.VirtualTrafficManager)(nil)
+	d := schema.TestResourceDataRaw(t, attachment.schema(), map[string]interface{}{"object": "test", "name": "a", "weight": 5})
+
+	// The row is changed in place, keeping the field it does not manage
+	if err := attachment.Update(d, tm); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*table) != 2 || *(*table)[0].Weight != 5 || (*table)[0].Note == nil || *(*table)[0].Note != "kept" || writes != 1 {
+		t.Errorf("Unexpected table after update: %+v, %d writes", *table, writes)
+	}
+	// The second row is compared with its default weight filled in
+	if err := attachment.Update(d, tm); err != nil || writes != 1 {
+		t.Errorf("Unchanged row was written again: %v, %d writes", err, writes)
+	}
+
+	if err := attachment.Create(d, tm); err == nil || !strings.Contains(err.Error(), "import it with the ID 'test/a'") {
+		t.Errorf("Creating an existing row gave %v", err)
+	}
+
+	if err := attachment.Delete(d, tm); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*table) != 1 || *(*table)[0].Name != "b" {
+		t.Errorf("Unexpected table after delete: %+v", *table)
+	}
+	d.SetId("test/a")
+	if err := attachment.Read(d, tm); err != nil || d.Id() != "" {
+		t.Errorf("Reading a deleted row gave %v, ID '%s'", err, d.Id())
+	}
+}
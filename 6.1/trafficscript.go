@@ -0,0 +1,895 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// tsPos is a position in TrafficScript source, counted from 1.
+type tsPos struct {
+	line   int
+	column int
+}
+
+// tsError is a TrafficScript syntax error.
+type tsError struct {
+	pos     tsPos
+	message string
+}
+
+func (err *tsError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.pos.line, err.pos.column, err.message)
+}
+
+// tsToken kinds.
+const (
+	tsTokenIdent    = "identifier"
+	tsTokenVariable = "variable"
+	tsTokenString   = "string"
+	tsTokenNumber   = "number"
+	tsTokenOperator = "operator"
+	tsTokenEOF      = "end of rule"
+)
+
+type tsToken struct {
+	kind  string
+	value string
+	pos   tsPos
+}
+
+func (token tsToken) String() string {
+	switch token.kind {
+	case tsTokenEOF:
+		return token.kind
+	case tsTokenString:
+		return "string"
+	case tsTokenVariable:
+		return "'$" + token.value + "'"
+	}
+	return "'" + token.value + "'"
+}
+
+// tsOperators are the TrafficScript operators and punctuation, longest
+// first so that the lexer matches greedily.
+var tsOperators = []string{
+	"<<=", ">>=",
+	"==", "!=", "<=", ">=", "&&", "||", "++", "--", "+=", "-=", "*=", "/=",
+	"%=", ".=", "&=", "|=", "^=", "<<", ">>", "=>",
+	"+", "-", "*", "/", "%", ".", "=", "<", ">", "!", "~", "&", "|", "^",
+	"?", ":", ";", ",", "(", ")", "{", "}", "[", "]",
+}
+
+var tsKeywords = map[string]bool{
+	"if": true, "else": true, "while": true, "do": true, "for": true,
+	"foreach": true, "in": true, "sub": true, "import": true, "as": true,
+	"return": true, "break": true, "continue": true,
+}
+
+// tsEscapes are the escape sequences allowed in TrafficScript strings,
+// other than "\x" followed by two hex digits.
+var tsEscapes = map[byte]string{
+	'n': "\n", 'r': "\r", 't': "\t", '0': "\x00", 'e': "\x1b", 'f': "\f",
+	'v': "\v", 'a': "\a", 'b': "\b", '\\': "\\", '"': "\"", '\'': "'", '$': "$",
+}
+
+func isTsIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isTsIdentChar(c byte) bool {
+	return isTsIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func isTsDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isTsHexDigit(c byte) bool {
+	return isTsDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// lexTrafficScript splits a rule into tokens. String tokens hold the
+// decoded value of the string.
+func lexTrafficScript(source string) ([]tsToken, error) {
+	tokens := []tsToken{}
+	line, lineStart := 1, 0
+	pos := func(i int) tsPos {
+		return tsPos{line, i - lineStart + 1}
+	}
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			i++
+			line, lineStart = line+1, i
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			start := pos(i)
+			var value bytes.Buffer
+			i++
+			closed := false
+			for i < len(source) {
+				if source[i] == c {
+					closed = true
+					i++
+					break
+				}
+				if source[i] == '\n' {
+					value.WriteByte('\n')
+					i++
+					line, lineStart = line+1, i
+					continue
+				}
+				if source[i] != '\\' {
+					value.WriteByte(source[i])
+					i++
+					continue
+				}
+				if i+1 >= len(source) {
+					break
+				}
+				escape := source[i+1]
+				if decoded, ok := tsEscapes[escape]; ok {
+					value.WriteString(decoded)
+					i += 2
+				} else if escape == 'x' && i+3 < len(source) && isTsHexDigit(source[i+2]) && isTsHexDigit(source[i+3]) {
+					var b byte
+					fmt.Sscanf(source[i+2:i+4], "%x", &b)
+					value.WriteByte(b)
+					i += 4
+				} else {
+					return nil, &tsError{pos(i), fmt.Sprintf("invalid escape sequence '\\%c' in string", escape)}
+				}
+			}
+			if !closed {
+				return nil, &tsError{start, "unterminated string"}
+			}
+			tokens = append(tokens, tsToken{tsTokenString, value.String(), start})
+		case c == '$':
+			start := i
+			i++
+			for i < len(source) && isTsIdentChar(source[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, &tsError{pos(start), "'$' must be followed by a variable name"}
+			}
+			tokens = append(tokens, tsToken{tsTokenVariable, source[start+1 : i], pos(start)})
+		case isTsDigit(c):
+			start := i
+			if c == '0' && i+1 < len(source) && (source[i+1] == 'x' || source[i+1] == 'X') {
+				i += 2
+				for i < len(source) && isTsHexDigit(source[i]) {
+					i++
+				}
+			} else {
+				for i < len(source) && isTsDigit(source[i]) {
+					i++
+				}
+				if i+1 < len(source) && source[i] == '.' && isTsDigit(source[i+1]) {
+					i++
+					for i < len(source) && isTsDigit(source[i]) {
+						i++
+					}
+				}
+				if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+					j := i + 1
+					if j < len(source) && (source[j] == '+' || source[j] == '-') {
+						j++
+					}
+					if j < len(source) && isTsDigit(source[j]) {
+						for i = j; i < len(source) && isTsDigit(source[i]); i++ {
+						}
+					}
+				}
+			}
+			if i < len(source) && isTsIdentChar(source[i]) {
+				return nil, &tsError{pos(start), fmt.Sprintf("invalid number '%s'", source[start:i+1])}
+			}
+			tokens = append(tokens, tsToken{tsTokenNumber, source[start:i], pos(start)})
+		case isTsIdentStart(c):
+			// Function names include their module, as in "http.getHeader".
+			start := i
+			for i < len(source) && isTsIdentChar(source[i]) {
+				i++
+				if i+1 < len(source) && source[i] == '.' && isTsIdentStart(source[i+1]) {
+					i++
+				}
+			}
+			tokens = append(tokens, tsToken{tsTokenIdent, source[start:i], pos(start)})
+		default:
+			matched := ""
+			for _, operator := range tsOperators {
+				if strings.HasPrefix(source[i:], operator) {
+					matched = operator
+					break
+				}
+			}
+			if matched == "" {
+				return nil, &tsError{pos(i), fmt.Sprintf("unexpected character '%c'", c)}
+			}
+			tokens = append(tokens, tsToken{tsTokenOperator, matched, pos(i)})
+			i += len(matched)
+		}
+	}
+	tokens = append(tokens, tsToken{tsTokenEOF, "", pos(len(source))})
+	return tokens, nil
+}
+
+// tsNode is a node of a parsed rule. Statements and expressions share the
+// same type, distinguished by kind:
+//
+//   block     children are statements
+//   if        children are condition, then block and optional else
+//   while     children are condition and body
+//   do        children are body and condition
+//   for       children are init, condition, step (each may be nil) and body
+//   foreach   value is the variable; children are the list and body
+//   sub       value is the name, params the parameters; child is the body
+//   import    value is the rule name, alias the name it is used by
+//   return    optional child is the value
+//   break, continue
+//   expr      child is an expression evaluated for its side effects
+//
+//   number, string   value is the literal
+//   var       value is the variable name without '$'
+//   call      value is the function name; children are the arguments
+//   binary    value is the operator; children are the operands
+//   unary     value is the operator; child is the operand
+//   assign    value is the operator; children are the target and value
+//   incdec    value is "++" or "--", prefix is set for "++$x"; child is the
+//             target
+//   index     children are the array or hash and the key
+//   array     children are the elements
+//   hash      children are alternating keys and values
+//   ternary   children are the condition and the two values
+type tsNode struct {
+	kind     string
+	value    string
+	pos      tsPos
+	children []*tsNode
+	params   []string
+	alias    string
+	prefix   bool
+}
+
+// tsParser is a recursive descent parser for TrafficScript.
+type tsParser struct {
+	tokens []tsToken
+	next   int
+}
+
+// parseTrafficScript parses a rule into a block of statements.
+func parseTrafficScript(source string) (*tsNode, error) {
+	tokens, err := lexTrafficScript(source)
+	if err != nil {
+		return nil, err
+	}
+	parser := &tsParser{tokens: tokens}
+	program := &tsNode{kind: "block", pos: tsPos{1, 1}}
+	for parser.peek().kind != tsTokenEOF {
+		statement, err := parser.statement(true)
+		if err != nil {
+			return nil, err
+		}
+		program.children = append(program.children, statement)
+	}
+	return program, nil
+}
+
+func (parser *tsParser) peek() tsToken {
+	return parser.tokens[parser.next]
+}
+
+func (parser *tsParser) advance() tsToken {
+	token := parser.tokens[parser.next]
+	if token.kind != tsTokenEOF {
+		parser.next++
+	}
+	return token
+}
+
+// isOperator reports whether the next token is one of operators.
+func (parser *tsParser) isOperator(operators ...string) bool {
+	token := parser.peek()
+	if token.kind != tsTokenOperator {
+		return false
+	}
+	for _, operator := range operators {
+		if token.value == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (parser *tsParser) isKeyword(keyword string) bool {
+	token := parser.peek()
+	return token.kind == tsTokenIdent && token.value == keyword
+}
+
+func (parser *tsParser) expect(operator string) (tsToken, error) {
+	if !parser.isOperator(operator) {
+		return tsToken{}, &tsError{parser.peek().pos, fmt.Sprintf("expected '%s', found %s", operator, parser.peek())}
+	}
+	return parser.advance(), nil
+}
+
+// statement parses one statement. Subroutines and imports are only allowed
+// at the top level of a rule.
+func (parser *tsParser) statement(topLevel bool) (*tsNode, error) {
+	token := parser.peek()
+	if token.kind == tsTokenOperator {
+		switch token.value {
+		case "{":
+			return parser.block()
+		case "}":
+			return nil, &tsError{token.pos, "unexpected '}' without a matching '{'"}
+		case ";":
+			parser.advance()
+			return &tsNode{kind: "block", pos: token.pos}, nil
+		}
+	}
+
+	if token.kind == tsTokenIdent && tsKeywords[token.value] {
+		parser.advance()
+		node := &tsNode{kind: token.value, pos: token.pos}
+		switch token.value {
+		case "if":
+			return parser.ifStatement(node)
+		case "while":
+			condition, err := parser.condition()
+			if err != nil {
+				return nil, err
+			}
+			body, err := parser.body()
+			if err != nil {
+				return nil, err
+			}
+			node.children = []*tsNode{condition, body}
+			return node, nil
+		case "do":
+			body, err := parser.body()
+			if err != nil {
+				return nil, err
+			}
+			if !parser.isKeyword("while") {
+				return nil, &tsError{parser.peek().pos, fmt.Sprintf("expected 'while', found %s", parser.peek())}
+			}
+			parser.advance()
+			condition, err := parser.condition()
+			if err != nil {
+				return nil, err
+			}
+			node.children = []*tsNode{body, condition}
+			return node, parser.endStatement()
+		case "for":
+			return parser.forStatement(node)
+		case "foreach":
+			return parser.foreachStatement(node)
+		case "sub":
+			if !topLevel {
+				return nil, &tsError{token.pos, "subroutines must be defined at the top level of a rule"}
+			}
+			return parser.subStatement(node)
+		case "import":
+			if !topLevel {
+				return nil, &tsError{token.pos, "imports must be at the top level of a rule"}
+			}
+			name := parser.advance()
+			if name.kind != tsTokenIdent && name.kind != tsTokenString {
+				return nil, &tsError{name.pos, fmt.Sprintf("expected a rule name after 'import', found %s", name)}
+			}
+			node.value, node.alias = name.value, name.value
+			if parser.isKeyword("as") {
+				parser.advance()
+				alias := parser.advance()
+				if alias.kind != tsTokenIdent || strings.Contains(alias.value, ".") {
+					return nil, &tsError{alias.pos, fmt.Sprintf("expected a name after 'as', found %s", alias)}
+				}
+				node.alias = alias.value
+			}
+			return node, parser.endStatement()
+		case "return":
+			if !parser.isOperator(";") && !parser.isOperator("}") && parser.peek().kind != tsTokenEOF {
+				value, err := parser.expression()
+				if err != nil {
+					return nil, err
+				}
+				node.children = []*tsNode{value}
+			}
+			return node, parser.endStatement()
+		case "break", "continue":
+			return node, parser.endStatement()
+		case "else":
+			return nil, &tsError{token.pos, "'else' without a matching 'if'"}
+		}
+		return nil, &tsError{token.pos, fmt.Sprintf("unexpected '%s'", token.value)}
+	}
+
+	// Anything else must be an expression, which on its own only makes
+	// sense as a call, assignment or increment.
+	if token.kind == tsTokenIdent && !parser.followedByCall() {
+		return nil, &tsError{token.pos, fmt.Sprintf("unknown statement '%s'", token.value)}
+	}
+	expression, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	// A call followed by a block is a misspelt keyword, such as "elsif".
+	if expression.kind == "call" && parser.isOperator("{") {
+		return nil, &tsError{token.pos, fmt.Sprintf("unknown statement '%s'", expression.value)}
+	}
+	return &tsNode{kind: "expr", pos: token.pos, children: []*tsNode{expression}}, parser.endStatement()
+}
+
+// followedByCall reports whether the identifier at the current position is
+// a function call.
+func (parser *tsParser) followedByCall() bool {
+	following := parser.tokens[parser.next+1]
+	return following.kind == tsTokenOperator && following.value == "("
+}
+
+// endStatement consumes the ';' after a statement. It may be left out
+// before a '}' or at the end of the rule.
+func (parser *tsParser) endStatement() error {
+	if parser.isOperator(";") {
+		parser.advance()
+		return nil
+	}
+	if parser.isOperator("}") || parser.peek().kind == tsTokenEOF {
+		return nil
+	}
+	return &tsError{parser.peek().pos, fmt.Sprintf("expected ';', found %s", parser.peek())}
+}
+
+func (parser *tsParser) block() (*tsNode, error) {
+	open, err := parser.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	node := &tsNode{kind: "block", pos: open.pos}
+	for !parser.isOperator("}") {
+		if parser.peek().kind == tsTokenEOF {
+			return nil, &tsError{open.pos, "'{' is not closed"}
+		}
+		statement, err := parser.statement(false)
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, statement)
+	}
+	parser.advance()
+	return node, nil
+}
+
+// body parses the body of a loop or branch, which is normally a block but
+// may be a single statement.
+func (parser *tsParser) body() (*tsNode, error) {
+	if parser.isOperator("{") {
+		return parser.block()
+	}
+	statement, err := parser.statement(false)
+	if err != nil {
+		return nil, err
+	}
+	return &tsNode{kind: "block", pos: statement.pos, children: []*tsNode{statement}}, nil
+}
+
+func (parser *tsParser) condition() (*tsNode, error) {
+	if _, err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	condition, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.expect(")"); err != nil {
+		return nil, err
+	}
+	return condition, nil
+}
+
+func (parser *tsParser) ifStatement(node *tsNode) (*tsNode, error) {
+	condition, err := parser.condition()
+	if err != nil {
+		return nil, err
+	}
+	then, err := parser.body()
+	if err != nil {
+		return nil, err
+	}
+	node.children = []*tsNode{condition, then}
+	if parser.isKeyword("else") {
+		token := parser.advance()
+		if parser.isKeyword("if") {
+			parser.advance()
+			elseIf, err := parser.ifStatement(&tsNode{kind: "if", pos: token.pos})
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, elseIf)
+		} else {
+			otherwise, err := parser.body()
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, otherwise)
+		}
+	}
+	return node, nil
+}
+
+func (parser *tsParser) forStatement(node *tsNode) (*tsNode, error) {
+	if _, err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	for _, end := range []string{";", ";", ")"} {
+		var clause *tsNode
+		if !parser.isOperator(end) {
+			expression, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			clause = expression
+		}
+		if _, err := parser.expect(end); err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, clause)
+	}
+	body, err := parser.body()
+	if err != nil {
+		return nil, err
+	}
+	node.children = append(node.children, body)
+	return node, nil
+}
+
+func (parser *tsParser) foreachStatement(node *tsNode) (*tsNode, error) {
+	if _, err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	variable := parser.advance()
+	if variable.kind != tsTokenVariable {
+		return nil, &tsError{variable.pos, fmt.Sprintf("expected a variable in 'foreach', found %s", variable)}
+	}
+	node.value = variable.value
+	if !parser.isKeyword("in") {
+		return nil, &tsError{parser.peek().pos, fmt.Sprintf("expected 'in', found %s", parser.peek())}
+	}
+	parser.advance()
+	list, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.expect(")"); err != nil {
+		return nil, err
+	}
+	body, err := parser.body()
+	if err != nil {
+		return nil, err
+	}
+	node.children = []*tsNode{list, body}
+	return node, nil
+}
+
+func (parser *tsParser) subStatement(node *tsNode) (*tsNode, error) {
+	name := parser.advance()
+	if name.kind != tsTokenIdent || strings.Contains(name.value, ".") || tsKeywords[name.value] {
+		return nil, &tsError{name.pos, fmt.Sprintf("expected a subroutine name, found %s", name)}
+	}
+	node.value = name.value
+	if _, err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	for !parser.isOperator(")") {
+		if len(node.params) > 0 {
+			if _, err := parser.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		param := parser.advance()
+		if param.kind != tsTokenVariable {
+			return nil, &tsError{param.pos, fmt.Sprintf("expected a parameter variable, found %s", param)}
+		}
+		node.params = append(node.params, param.value)
+	}
+	parser.advance()
+	body, err := parser.block()
+	if err != nil {
+		return nil, err
+	}
+	node.children = []*tsNode{body}
+	return node, nil
+}
+
+var tsAssignmentOperators = []string{"=", "+=", "-=", "*=", "/=", "%=", ".=", "&=", "|=", "^=", "<<=", ">>="}
+
+// tsBinaryOperators lists the binary operators from lowest to highest
+// precedence.
+var tsBinaryOperators = [][]string{
+	{"||"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"<<", ">>"},
+	{"+", "-", "."},
+	{"*", "/", "%"},
+}
+
+func (parser *tsParser) expression() (*tsNode, error) {
+	target, err := parser.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if parser.isOperator(tsAssignmentOperators...) {
+		operator := parser.advance()
+		if target.kind != "var" && target.kind != "index" {
+			return nil, &tsError{operator.pos, fmt.Sprintf("cannot assign to %s", describeTsNode(target))}
+		}
+		value, err := parser.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &tsNode{kind: "assign", value: operator.value, pos: operator.pos, children: []*tsNode{target, value}}, nil
+	}
+	return target, nil
+}
+
+func (parser *tsParser) ternary() (*tsNode, error) {
+	condition, err := parser.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !parser.isOperator("?") {
+		return condition, nil
+	}
+	operator := parser.advance()
+	first, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.expect(":"); err != nil {
+		return nil, err
+	}
+	second, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &tsNode{kind: "ternary", pos: operator.pos, children: []*tsNode{condition, first, second}}, nil
+}
+
+func (parser *tsParser) binary(level int) (*tsNode, error) {
+	if level == len(tsBinaryOperators) {
+		return parser.unary()
+	}
+	left, err := parser.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for parser.isOperator(tsBinaryOperators[level]...) {
+		operator := parser.advance()
+		right, err := parser.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &tsNode{kind: "binary", value: operator.value, pos: operator.pos, children: []*tsNode{left, right}}
+	}
+	return left, nil
+}
+
+func (parser *tsParser) unary() (*tsNode, error) {
+	if parser.isOperator("!", "-", "+", "~") {
+		operator := parser.advance()
+		operand, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &tsNode{kind: "unary", value: operator.value, pos: operator.pos, children: []*tsNode{operand}}, nil
+	}
+	if parser.isOperator("++", "--") {
+		operator := parser.advance()
+		target, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		if target.kind != "var" && target.kind != "index" {
+			return nil, &tsError{operator.pos, fmt.Sprintf("cannot apply '%s' to %s", operator.value, describeTsNode(target))}
+		}
+		return &tsNode{kind: "incdec", value: operator.value, prefix: true, pos: operator.pos, children: []*tsNode{target}}, nil
+	}
+	return parser.postfix()
+}
+
+func (parser *tsParser) postfix() (*tsNode, error) {
+	node, err := parser.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case parser.isOperator("["):
+			open := parser.advance()
+			key, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := parser.expect("]"); err != nil {
+				return nil, err
+			}
+			node = &tsNode{kind: "index", pos: open.pos, children: []*tsNode{node, key}}
+		case parser.isOperator("++", "--"):
+			if node.kind != "var" && node.kind != "index" {
+				return node, nil
+			}
+			operator := parser.advance()
+			node = &tsNode{kind: "incdec", value: operator.value, pos: operator.pos, children: []*tsNode{node}}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (parser *tsParser) primary() (*tsNode, error) {
+	token := parser.advance()
+	switch token.kind {
+	case tsTokenNumber, tsTokenString:
+		return &tsNode{kind: token.kind, value: token.value, pos: token.pos}, nil
+	case tsTokenVariable:
+		return &tsNode{kind: "var", value: token.value, pos: token.pos}, nil
+	case tsTokenIdent:
+		if tsKeywords[token.value] || !parser.isOperator("(") {
+			return nil, &tsError{token.pos, fmt.Sprintf("unexpected '%s'", token.value)}
+		}
+		parser.advance()
+		node := &tsNode{kind: "call", value: token.value, pos: token.pos}
+		for !parser.isOperator(")") {
+			if len(node.children) > 0 {
+				if _, err := parser.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			argument, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, argument)
+		}
+		parser.advance()
+		return node, nil
+	case tsTokenOperator:
+		switch token.value {
+		case "(":
+			node, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := parser.expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		case "[":
+			return parser.arrayOrHash(token)
+		}
+	}
+	return nil, &tsError{token.pos, fmt.Sprintf("unexpected %s", token)}
+}
+
+// arrayOrHash parses an array literal "[ 1, 2 ]", or a hash literal
+// "[ "a" => 1 ]" if the first element is followed by "=>".
+func (parser *tsParser) arrayOrHash(open tsToken) (*tsNode, error) {
+	node := &tsNode{kind: "array", pos: open.pos}
+	for !parser.isOperator("]") {
+		if len(node.children) > 0 {
+			if _, err := parser.expect(","); err != nil {
+				return nil, err
+			}
+			// A trailing comma is allowed.
+			if parser.isOperator("]") {
+				break
+			}
+		}
+		element, err := parser.expression()
+		if err != nil {
+			return nil, err
+		}
+		if len(node.children) == 0 && parser.isOperator("=>") {
+			node.kind = "hash"
+		}
+		node.children = append(node.children, element)
+		if node.kind == "hash" {
+			if _, err := parser.expect("=>"); err != nil {
+				return nil, err
+			}
+			value, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, value)
+		}
+	}
+	parser.advance()
+	return node, nil
+}
+
+func describeTsNode(node *tsNode) string {
+	switch node.kind {
+	case "call":
+		return "a function call"
+	case "string", "number":
+		return "a " + node.kind
+	}
+	return "an expression"
+}
+
+// walkTrafficScript calls visit for node and each node below it, in source
+// order.
+func walkTrafficScript(node *tsNode, visit func(*tsNode)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+	for _, child := range node.children {
+		walkTrafficScript(child, visit)
+	}
+}
+
+// checkTrafficScriptCalls reports calls to functions that are neither in
+// the built-in library nor defined as subroutines in the rule. Calls through
+// an imported rule's alias cannot be checked.
+func checkTrafficScriptCalls(program *tsNode) []error {
+	subs := map[string]bool{}
+	aliases := map[string]bool{}
+	for _, statement := range program.children {
+		switch statement.kind {
+		case "sub":
+			subs[strings.ToLower(statement.value)] = true
+		case "import":
+			aliases[strings.ToLower(statement.alias)] = true
+		}
+	}
+
+	errors := []error{}
+	walkTrafficScript(program, func(node *tsNode) {
+		if node.kind != "call" {
+			return
+		}
+		name := strings.ToLower(node.value)
+		if tsFunctions[name] || subs[name] {
+			return
+		}
+		if dot := strings.Index(name, "."); dot > 0 && aliases[name[:dot]] {
+			return
+		}
+		errors = append(errors, &tsError{node.pos, fmt.Sprintf("unknown function '%s'", node.value)})
+	})
+	return errors
+}
+
+// validateTrafficScript is a ValidateFunc for rule content. The traffic
+// manager stores rules it cannot compile, and the function table may lag
+// behind the traffic manager, so problems are warnings rather than errors.
+func validateTrafficScript(v interface{}, k string) (ws []string, errors []error) {
+	program, err := parseTrafficScript(v.(string))
+	if err != nil {
+		return []string{fmt.Sprintf("%q is not valid TrafficScript: %v", k, err)}, nil
+	}
+	for _, err := range checkTrafficScriptCalls(program) {
+		ws = append(ws, fmt.Sprintf("%q may not be valid TrafficScript: %v", k, err))
+	}
+	return
+}
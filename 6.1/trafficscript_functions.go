@@ -0,0 +1,154 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"strings"
+)
+
+// tsFunctionLibrary lists the TrafficScript built-in functions of the
+// traffic manager versions that support this API version, by module.
+// Function names are not case sensitive.
+var tsFunctionLibrary = map[string]string{
+	"array": `
+		append contains copy filter join length pop push reverse shift slice
+		sort sortNumerical splice unshift`,
+	"auth": `
+		query`,
+	"bandwidth": `
+		getName`,
+	"connection": `
+		checkPersistence close data.get data.getMemoryUsage data.reset
+		data.set discard getBandwidthClass getCompletionReasonCode
+		getCompletionReasonInfo getData getDataLen getId getIdle
+		getLocalIP getLocalPort getMemoryUsage getNode getPersistence
+		getPool getRemoteIP getRemotePort getServiceLevelClass
+		getVirtualServer isClientClosed setBandwidthClass setData
+		setIdempotent setMemoryLimit setPersistence setPersistenceKey
+		setPersistenceNode setPriority setServiceLevelClass sleep`,
+	"counter": `
+		increment`,
+	"counter64": `
+		increment`,
+	"data": `
+		get getMemoryUsage local.get local.remove local.reset local.set
+		remove reset set`,
+	"dns": `
+		addResponseRR getQuestion getRCode getResponseRR listQuestions
+		listResponseRRs removeResponseRR sendResponse setRCode
+		setQuestion`,
+	"event": `
+		emit`,
+	"geo": `
+		getCity getCountry getCountryCode getCountryName getDistance
+		getLatitude getLongitude getRegion getRegionName`,
+	"glb": `
+		service.getLocation service.getName service.listLocations
+		service.setLocation`,
+	"hash": `
+		contains count delete empty keys values`,
+	"http": `
+		addCookie addHeader addResponseCookie addResponseHeader
+		aptimizer.bypass aptimizer.use cache.disable cache.enable
+		cache.exitStatus cache.getKey cache.setKey changeSite
+		compress.disable compress.enable escape getBody getCookie
+		getCookies getFormParam getFormParams getHeader getHeaderNames
+		getHeaders getHostHeader getMethod getPath getQueryString
+		getRawHeaders getRawRequestBody getRawResponseHeaders getRawURL
+		getRequestLine getResponseBody getResponseCode getResponseCookie
+		getResponseCookies getResponseHeader getResponseHeaderNames
+		getResponseHeaders getResponseLine getVersion headerExists
+		listHeaderNames listResponseHeaderNames mergeHeader redirect
+		removeCookie removeHeader removeResponseCookie
+		removeResponseHeader request.get request.getLine
+		response.get response.getLine sendResponse setBody setCookie
+		setHeader setMethod setPath setQueryString setRawURL
+		setResponseBody setResponseCode setResponseCookie
+		setResponseHeader setVersion stream.continueFromBackend
+		stream.continueFromClient stream.read stream.readResponse
+		stream.startResponse stream.write stream.writeResponse unescape
+		urlDecode urlEncode`,
+	"http2": `
+		getStreamId`,
+	"java": `
+		run`,
+	"json": `
+		decode encode`,
+	"lang": `
+		chr dump isArray isHash max min ord toArray toDouble toHash toInt
+		toString typeof`,
+	"log": `
+		debug emerg error info warn`,
+	"math": `
+		abs ceil floor random round`,
+	"net": `
+		dns.resolveAll dns.resolveHost dns.resolveIP ipMaskMatch
+		isIPInSubnet`,
+	"pool": `
+		activeNodes getName getNodeInfo listActiveNodes listAllNodes
+		listDisabledNodes listDrainingNodes select use`,
+	"rate": `
+		getBacklog use use.noQueue`,
+	"recentconns": `
+		include exclude`,
+	"request": `
+		avoidNagle endsWith get getDestIP getDestPort getLength getLine
+		getLocalIP getLocalPort getRemoteIP getRemotePort getRetries
+		retry sendResponse set setIdleTimeout setMaxReplyLength
+		startsWith`,
+	"resource": `
+		exists get getLength getMD5 getMTime list`,
+	"response": `
+		append endsWith get getLength getLine set startsWith`,
+	"rtsp": `
+		getHeader getMethod getPath getResponseCode getResponseHeader
+		removeHeader removeResponseHeader setHeader setPath
+		setResponseHeader`,
+	"rule": `
+		getName`,
+	"sip": `
+		getHeader getMethod getRequestURI getResponseCode
+		getResponseHeader removeHeader removeResponseHeader setHeader
+		setRequestURI setResponseHeader`,
+	"slm": `
+		conforming isOK responseTime`,
+	"ssl": `
+		clientCertHash clientCertIssuer clientCertNotAfter
+		clientCertNotBefore clientCertSerial clientCertSubject
+		getCipher getCipherBits getClientCert getClientCertDER
+		getServerName getSessionID getVersion isSSL`,
+	"string": `
+		append base64decode base64encode bin2hex cmp contains containsI
+		count decrypt drop encrypt endsWith endsWithI escape escapeRegex
+		extractHost find findI findr gmtime hashMD5 hashSHA1 hashSHA256
+		hashSHA384 hashSHA512 hex2bin hexDecode hexEncode htmlDecode
+		htmlEncode icmp ipMaskMatch left len length lowercase ltrim
+		normalize regexEscape regexmatch regexsub repeat replace
+		replaceAll replaceAllI replaceI reverse right rtrim skip split
+		sprintf startsWith startsWithI substring toInt trim unescape
+		uppercase urldecode urlencode validUTF8`,
+	"sys": `
+		domainName getEnv getPid gmtime.format hostname localtime.format
+		time time.highres time.hour time.minutes time.month
+		time.monthday time.seconds time.weekday time.year time.yearday`,
+	"tcp": `
+		close getData read write`,
+	"udp": `
+		getDestIP getDestPort getSourceIP getSourcePort sendResponse`,
+	"xml": `
+		escape unescape validate.dtd validate.xsd xpath.matchNodeCount
+		xpath.matchNodeSet xslt.transform`,
+}
+
+// tsFunctions are the lower case names of the TrafficScript built-in
+// functions.
+var tsFunctions = func() map[string]bool {
+	functions := map[string]bool{}
+	for module, names := range tsFunctionLibrary {
+		for _, name := range strings.Fields(names) {
+			functions[strings.ToLower(module+"."+name)] = true
+		}
+	}
+	return functions
+}()
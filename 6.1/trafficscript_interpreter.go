@@ -0,0 +1,1420 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tsMaxSteps limits the number of statements and loop iterations a
+// simulated rule may run, so that a rule that never finishes is reported
+// rather than hanging the plan.
+const tsMaxSteps = 100000
+
+// tsSimulation is a mocked HTTP request that a rule is run against, and the
+// result of running it. The request fields are updated as the rule changes
+// them.
+type tsSimulation struct {
+	method     string
+	path       string
+	query      string
+	version    string
+	headers    [][2]string
+	body       string
+	remoteIP   string
+	remotePort int
+	localIP    string
+	localPort  int
+
+	pool            string
+	redirect        string
+	responseCode    int
+	responseHeaders [][2]string
+	responseBody    string
+	actions         []string
+	logs            []string
+}
+
+// errTsRuleFinished is returned internally when a rule stops processing,
+// for example by choosing a pool or sending a redirect.
+var errTsRuleFinished = errors.New("rule finished")
+
+type tsFlow int
+
+const (
+	tsFlowNormal tsFlow = iota
+	tsFlowBreak
+	tsFlowContinue
+	tsFlowReturn
+)
+
+// tsInterpreter runs a parsed rule against a simulation. Only a subset of
+// the TrafficScript library is available; calls to other built-in functions
+// are reported as errors.
+type tsInterpreter struct {
+	sim         *tsSimulation
+	subs        map[string]*tsNode
+	imports     map[string]map[string]*tsNode
+	scope       map[string]interface{}
+	returnValue interface{}
+	steps       int
+}
+
+// tsBuiltin implements a built-in function. node is the call, so that
+// functions which change an array or hash in place can assign to their
+// first argument.
+type tsBuiltin func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error)
+
+// runTrafficScript runs a rule against sim. imports holds the parsed rules
+// that the rule may import, by name.
+func runTrafficScript(program *tsNode, imports map[string]*tsNode, sim *tsSimulation) error {
+	in := &tsInterpreter{
+		sim:     sim,
+		subs:    tsSubroutines(program),
+		imports: map[string]map[string]*tsNode{},
+		scope:   map[string]interface{}{},
+	}
+	for _, statement := range program.children {
+		if statement.kind != "import" {
+			continue
+		}
+		imported, ok := imports[statement.value]
+		if !ok {
+			return &tsError{statement.pos, fmt.Sprintf("imported rule '%s' was not provided", statement.value)}
+		}
+		in.imports[strings.ToLower(statement.alias)] = tsSubroutines(imported)
+	}
+
+	_, err := in.exec(program)
+	if err == errTsRuleFinished {
+		return nil
+	}
+	return err
+}
+
+func tsSubroutines(program *tsNode) map[string]*tsNode {
+	subs := map[string]*tsNode{}
+	for _, statement := range program.children {
+		if statement.kind == "sub" {
+			subs[strings.ToLower(statement.value)] = statement
+		}
+	}
+	return subs
+}
+
+func (in *tsInterpreter) step(pos tsPos) error {
+	in.steps++
+	if in.steps > tsMaxSteps {
+		return &tsError{pos, fmt.Sprintf("rule did not finish within %d steps", tsMaxSteps)}
+	}
+	return nil
+}
+
+// exec runs a statement. A "break" or "return" at the top level of the rule
+// ends the rule.
+func (in *tsInterpreter) exec(node *tsNode) (tsFlow, error) {
+	if err := in.step(node.pos); err != nil {
+		return tsFlowNormal, err
+	}
+	switch node.kind {
+	case "block":
+		for _, statement := range node.children {
+			flow, err := in.exec(statement)
+			if err != nil || flow != tsFlowNormal {
+				return flow, err
+			}
+		}
+	case "expr":
+		_, err := in.eval(node.children[0])
+		return tsFlowNormal, err
+	case "if":
+		condition, err := in.eval(node.children[0])
+		if err != nil {
+			return tsFlowNormal, err
+		}
+		if tsTruthy(condition) {
+			return in.exec(node.children[1])
+		} else if len(node.children) > 2 {
+			return in.exec(node.children[2])
+		}
+	case "while", "do", "for":
+		return in.loop(node)
+	case "foreach":
+		list, err := in.eval(node.children[0])
+		if err != nil {
+			return tsFlowNormal, err
+		}
+		var items []interface{}
+		switch list := list.(type) {
+		case []interface{}:
+			items = list
+		case map[string]interface{}:
+			for _, key := range tsSortedKeys(list) {
+				items = append(items, key)
+			}
+		default:
+			return tsFlowNormal, &tsError{node.children[0].pos, "foreach needs an array or hash"}
+		}
+		for _, item := range items {
+			in.scope[node.value] = item
+			flow, err := in.exec(node.children[1])
+			if err != nil || flow == tsFlowReturn {
+				return flow, err
+			}
+			if flow == tsFlowBreak {
+				break
+			}
+		}
+	case "return":
+		in.returnValue = ""
+		if len(node.children) > 0 {
+			value, err := in.eval(node.children[0])
+			if err != nil {
+				return tsFlowNormal, err
+			}
+			in.returnValue = value
+		}
+		return tsFlowReturn, nil
+	case "break":
+		return tsFlowBreak, nil
+	case "continue":
+		return tsFlowContinue, nil
+	case "sub", "import":
+	default:
+		return tsFlowNormal, &tsError{node.pos, fmt.Sprintf("'%s' is not supported by the simulation", node.kind)}
+	}
+	return tsFlowNormal, nil
+}
+
+func (in *tsInterpreter) loop(node *tsNode) (tsFlow, error) {
+	var condition, step, body *tsNode
+	switch node.kind {
+	case "while":
+		condition, body = node.children[0], node.children[1]
+	case "do":
+		body, condition = node.children[0], node.children[1]
+	case "for":
+		if node.children[0] != nil {
+			if _, err := in.eval(node.children[0]); err != nil {
+				return tsFlowNormal, err
+			}
+		}
+		condition, step, body = node.children[1], node.children[2], node.children[3]
+	}
+
+	first := node.kind == "do"
+	for {
+		if err := in.step(node.pos); err != nil {
+			return tsFlowNormal, err
+		}
+		if !first && condition != nil {
+			value, err := in.eval(condition)
+			if err != nil {
+				return tsFlowNormal, err
+			}
+			if !tsTruthy(value) {
+				return tsFlowNormal, nil
+			}
+		}
+		first = false
+		flow, err := in.exec(body)
+		if err != nil || flow == tsFlowReturn {
+			return flow, err
+		}
+		if flow == tsFlowBreak {
+			return tsFlowNormal, nil
+		}
+		if step != nil {
+			if _, err := in.eval(step); err != nil {
+				return tsFlowNormal, err
+			}
+		}
+	}
+}
+
+func (in *tsInterpreter) eval(node *tsNode) (interface{}, error) {
+	switch node.kind {
+	case "string":
+		return node.value, nil
+	case "number":
+		return tsToNumber(node.value), nil
+	case "var":
+		value, ok := in.scope[node.value]
+		if !ok {
+			return "", nil
+		}
+		return value, nil
+	case "array":
+		array := []interface{}{}
+		for _, child := range node.children {
+			value, err := in.eval(child)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case "hash":
+		hash := map[string]interface{}{}
+		for i := 0; i < len(node.children); i += 2 {
+			key, err := in.eval(node.children[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := in.eval(node.children[i+1])
+			if err != nil {
+				return nil, err
+			}
+			hash[tsToString(key)] = value
+		}
+		return hash, nil
+	case "index":
+		container, err := in.eval(node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		key, err := in.eval(node.children[1])
+		if err != nil {
+			return nil, err
+		}
+		return tsIndex(container, key), nil
+	case "unary":
+		value, err := in.eval(node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		switch node.value {
+		case "!":
+			return tsBool(!tsTruthy(value)), nil
+		case "-":
+			return tsArithmetic("-", int64(0), value, node.pos)
+		case "~":
+			return ^tsToInt(value), nil
+		}
+		return tsToNumber(tsToString(value)), nil
+	case "binary":
+		return in.binary(node)
+	case "ternary":
+		condition, err := in.eval(node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		if tsTruthy(condition) {
+			return in.eval(node.children[1])
+		}
+		return in.eval(node.children[2])
+	case "assign":
+		value, err := in.eval(node.children[1])
+		if err != nil {
+			return nil, err
+		}
+		if node.value != "=" {
+			current, err := in.eval(node.children[0])
+			if err != nil {
+				return nil, err
+			}
+			if value, err = tsBinary(strings.TrimSuffix(node.value, "="), current, value, node.pos); err != nil {
+				return nil, err
+			}
+		}
+		return value, in.assign(node.children[0], value)
+	case "incdec":
+		current, err := in.eval(node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		updated, err := tsArithmetic(node.value[:1], current, int64(1), node.pos)
+		if err != nil {
+			return nil, err
+		}
+		if err := in.assign(node.children[0], updated); err != nil {
+			return nil, err
+		}
+		if node.prefix {
+			return updated, nil
+		}
+		return current, nil
+	case "call":
+		return in.call(node)
+	}
+	return nil, &tsError{node.pos, fmt.Sprintf("'%s' is not supported by the simulation", node.kind)}
+}
+
+func (in *tsInterpreter) binary(node *tsNode) (interface{}, error) {
+	left, err := in.eval(node.children[0])
+	if err != nil {
+		return nil, err
+	}
+	// The logical operators only evaluate their right operand if needed.
+	switch node.value {
+	case "&&":
+		if !tsTruthy(left) {
+			return int64(0), nil
+		}
+	case "||":
+		if tsTruthy(left) {
+			return int64(1), nil
+		}
+	}
+	right, err := in.eval(node.children[1])
+	if err != nil {
+		return nil, err
+	}
+	return tsBinary(node.value, left, right, node.pos)
+}
+
+// assign stores value in a variable, or an element of an array or hash.
+func (in *tsInterpreter) assign(target *tsNode, value interface{}) error {
+	if target.kind == "var" {
+		in.scope[target.value] = value
+		return nil
+	}
+	container, err := in.eval(target.children[0])
+	if err != nil {
+		return err
+	}
+	key, err := in.eval(target.children[1])
+	if err != nil {
+		return err
+	}
+	updated, setErr := tsSetIndex(container, key, value)
+	if setErr != nil {
+		return &tsError{target.pos, setErr.Error()}
+	}
+	return in.assign(target.children[0], updated)
+}
+
+func (in *tsInterpreter) call(node *tsNode) (interface{}, error) {
+	args := make([]interface{}, 0, len(node.children))
+	for _, child := range node.children {
+		value, err := in.eval(child)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	name := strings.ToLower(node.value)
+	if sub, ok := in.subs[name]; ok {
+		return in.callSub(sub, in.subs, args)
+	}
+	if dot := strings.Index(name, "."); dot > 0 {
+		if subs, ok := in.imports[name[:dot]]; ok {
+			sub, ok := subs[name[dot+1:]]
+			if !ok {
+				return nil, &tsError{node.pos, fmt.Sprintf("imported rule has no subroutine '%s'", node.value[dot+1:])}
+			}
+			return in.callSub(sub, subs, args)
+		}
+	}
+	builtin, ok := tsBuiltins[name]
+	if !ok {
+		if tsFunctions[name] {
+			return nil, &tsError{node.pos, fmt.Sprintf("function '%s' is not supported by the simulation", node.value)}
+		}
+		return nil, &tsError{node.pos, fmt.Sprintf("unknown function '%s'", node.value)}
+	}
+	value, err := builtin(in, node, args)
+	if err != nil && err != errTsRuleFinished {
+		if _, ok := err.(*tsError); !ok {
+			err = &tsError{node.pos, fmt.Sprintf("%s: %v", node.value, err)}
+		}
+	}
+	return value, err
+}
+
+// callSub runs a subroutine with its own variables. A "break" in a
+// subroutine ends the rule.
+func (in *tsInterpreter) callSub(sub *tsNode, subs map[string]*tsNode, args []interface{}) (interface{}, error) {
+	scope := map[string]interface{}{}
+	for i, param := range sub.params {
+		scope[param] = ""
+		if i < len(args) {
+			scope[param] = args[i]
+		}
+	}
+	savedScope, savedSubs := in.scope, in.subs
+	in.scope, in.subs, in.returnValue = scope, subs, ""
+	flow, err := in.exec(sub.children[0])
+	in.scope, in.subs = savedScope, savedSubs
+	if err != nil {
+		return nil, err
+	}
+	if flow == tsFlowBreak {
+		return nil, errTsRuleFinished
+	}
+	return in.returnValue, nil
+}
+
+// tsTruthy reports whether a value counts as true: anything other than
+// zero, the empty string, or an empty array or hash.
+func tsTruthy(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return false
+	case string:
+		return value != ""
+	case int64:
+		return value != 0
+	case float64:
+		return value != 0
+	case []interface{}:
+		return len(value) > 0
+	case map[string]interface{}:
+		return len(value) > 0
+	}
+	return true
+}
+
+func tsBool(value bool) int64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func tsToString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, item := range value {
+			parts[i] = tsToString(item)
+		}
+		return strings.Join(parts, "")
+	}
+	return fmt.Sprint(value)
+}
+
+var tsNumberPrefix = regexp.MustCompile(`^\s*[-+]?(0[xX][0-9a-fA-F]+|[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?)`)
+
+// tsToNumber converts a value to an int64 or float64, using the number at
+// the start of a string, or zero.
+func tsToNumber(value interface{}) interface{} {
+	switch value := value.(type) {
+	case int64, float64:
+		return value
+	case string:
+		prefix := strings.TrimSpace(tsNumberPrefix.FindString(value))
+		if prefix == "" {
+			return int64(0)
+		}
+		if number, err := strconv.ParseInt(prefix, 0, 64); err == nil {
+			return number
+		}
+		if number, err := strconv.ParseFloat(prefix, 64); err == nil {
+			return number
+		}
+	case []interface{}:
+		return int64(len(value))
+	case map[string]interface{}:
+		return int64(len(value))
+	}
+	return int64(0)
+}
+
+func tsToInt(value interface{}) int64 {
+	switch number := tsToNumber(value).(type) {
+	case float64:
+		return int64(number)
+	case int64:
+		return number
+	}
+	return 0
+}
+
+func tsToFloat(value interface{}) float64 {
+	switch number := tsToNumber(value).(type) {
+	case float64:
+		return number
+	case int64:
+		return float64(number)
+	}
+	return 0
+}
+
+func tsIsNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// tsBinary applies a binary operator. Values are compared as numbers if
+// either is a number, and as strings otherwise.
+func tsBinary(operator string, left, right interface{}, pos tsPos) (interface{}, error) {
+	switch operator {
+	case ".":
+		return tsToString(left) + tsToString(right), nil
+	case "&&":
+		return tsBool(tsTruthy(left) && tsTruthy(right)), nil
+	case "||":
+		return tsBool(tsTruthy(left) || tsTruthy(right)), nil
+	case "==", "!=", "<", "<=", ">", ">=":
+		var comparison int
+		if tsIsNumber(left) || tsIsNumber(right) {
+			l, r := tsToFloat(left), tsToFloat(right)
+			if l < r {
+				comparison = -1
+			} else if l > r {
+				comparison = 1
+			}
+		} else {
+			comparison = strings.Compare(tsToString(left), tsToString(right))
+		}
+		switch operator {
+		case "==":
+			return tsBool(comparison == 0), nil
+		case "!=":
+			return tsBool(comparison != 0), nil
+		case "<":
+			return tsBool(comparison < 0), nil
+		case "<=":
+			return tsBool(comparison <= 0), nil
+		case ">":
+			return tsBool(comparison > 0), nil
+		}
+		return tsBool(comparison >= 0), nil
+	case "&":
+		return tsToInt(left) & tsToInt(right), nil
+	case "|":
+		return tsToInt(left) | tsToInt(right), nil
+	case "^":
+		return tsToInt(left) ^ tsToInt(right), nil
+	case "<<":
+		return tsToInt(left) << uint64(tsToInt(right)), nil
+	case ">>":
+		return tsToInt(left) >> uint64(tsToInt(right)), nil
+	}
+	return tsArithmetic(operator, left, right, pos)
+}
+
+func tsArithmetic(operator string, left, right interface{}, pos tsPos) (interface{}, error) {
+	l, r := tsToNumber(left), tsToNumber(right)
+	li, lInt := l.(int64)
+	ri, rInt := r.(int64)
+	if (operator == "/" || operator == "%") && tsToFloat(r) == 0 {
+		return nil, &tsError{pos, "division by zero"}
+	}
+	if lInt && rInt {
+		switch operator {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "%":
+			return li % ri, nil
+		case "/":
+			if li%ri == 0 {
+				return li / ri, nil
+			}
+		}
+	}
+	lf, rf := tsToFloat(l), tsToFloat(r)
+	switch operator {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		return lf / rf, nil
+	case "%":
+		return float64(int64(lf) % int64(rf)), nil
+	}
+	return nil, &tsError{pos, fmt.Sprintf("unsupported operator '%s'", operator)}
+}
+
+func tsIndex(container, key interface{}) interface{} {
+	switch container := container.(type) {
+	case []interface{}:
+		index := int(tsToInt(key))
+		if index < 0 {
+			index += len(container)
+		}
+		if index >= 0 && index < len(container) {
+			return container[index]
+		}
+	case map[string]interface{}:
+		if value, ok := container[tsToString(key)]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// tsSetIndex returns a copy of an array or hash with one element set. An
+// empty value becomes an array if the key is a number, or a hash otherwise.
+func tsSetIndex(container, key, value interface{}) (interface{}, error) {
+	if !tsTruthy(container) {
+		if _, isArray := container.([]interface{}); isArray || tsIsNumber(key) {
+			container = []interface{}{}
+		} else {
+			container = map[string]interface{}{}
+		}
+	}
+	switch container := container.(type) {
+	case []interface{}:
+		index := int(tsToInt(key))
+		if index < 0 {
+			index += len(container)
+		}
+		if index < 0 || index > tsMaxSteps {
+			return nil, fmt.Errorf("array index %d is out of range", index)
+		}
+		updated := append([]interface{}{}, container...)
+		for len(updated) <= index {
+			updated = append(updated, "")
+		}
+		updated[index] = value
+		return updated, nil
+	case map[string]interface{}:
+		updated := map[string]interface{}{}
+		for k, v := range container {
+			updated[k] = v
+		}
+		updated[tsToString(key)] = value
+		return updated, nil
+	}
+	return nil, fmt.Errorf("cannot index a %s", tsTypeOf(container))
+}
+
+func tsTypeOf(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "hash"
+	}
+	return "string"
+}
+
+func tsSortedKeys(hash map[string]interface{}) []string {
+	keys := make([]string, 0, len(hash))
+	for key := range hash {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordAction notes a call that changes the request or its handling.
+func (in *tsInterpreter) recordAction(node *tsNode, args []interface{}) {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		if tsIsNumber(arg) {
+			formatted[i] = tsToString(arg)
+		} else {
+			formatted[i] = strconv.Quote(tsToString(arg))
+		}
+	}
+	in.sim.actions = append(in.sim.actions, node.value+"("+strings.Join(formatted, ", ")+")")
+}
+
+// tsArg returns an argument as a string, or "" if it was not given.
+func tsArg(args []interface{}, index int) string {
+	if index < len(args) {
+		return tsToString(args[index])
+	}
+	return ""
+}
+
+func tsHeaderGet(headers [][2]string, name string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header[0], name) {
+			return header[1]
+		}
+	}
+	return ""
+}
+
+func tsHeaderRemove(headers [][2]string, name string) [][2]string {
+	updated := [][2]string{}
+	for _, header := range headers {
+		if !strings.EqualFold(header[0], name) {
+			updated = append(updated, header)
+		}
+	}
+	return updated
+}
+
+// tsHeaderSet replaces the headers called name with one holding value, or
+// removes them if value is empty.
+func tsHeaderSet(headers [][2]string, name, value string) [][2]string {
+	if value == "" {
+		return tsHeaderRemove(headers, name)
+	}
+	for i, header := range headers {
+		if strings.EqualFold(header[0], name) {
+			updated := append(append([][2]string{}, headers[:i]...), [2]string{header[0], value})
+			return append(updated, tsHeaderRemove(headers[i+1:], name)...)
+		}
+	}
+	return append(headers, [2]string{name, value})
+}
+
+// tsHeaderMap returns headers by name, joining repeated headers with ", ".
+func tsHeaderMap(headers [][2]string) map[string]string {
+	result := map[string]string{}
+	names := map[string]string{}
+	for _, header := range headers {
+		lower := strings.ToLower(header[0])
+		if name, ok := names[lower]; ok {
+			result[name] += ", " + header[1]
+			continue
+		}
+		names[lower] = header[0]
+		result[header[0]] = header[1]
+	}
+	return result
+}
+
+// tsRegexp compiles a TrafficScript regular expression, which is close
+// enough to Go's syntax for most rules. The "i" flag ignores case.
+func tsRegexp(pattern, flags string) (*regexp.Regexp, error) {
+	if strings.Contains(flags, "i") {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// tsRangeStart converts a possibly negative string index into an offset.
+func tsRangeStart(index int64, length int) int {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 {
+		return 0
+	}
+	if index > int64(length) {
+		return length
+	}
+	return int(index)
+}
+
+var tsSprintfVerb = regexp.MustCompile(`%[-+ #0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+func tsSprintf(format string, args []interface{}) string {
+	converted := []interface{}{}
+	for _, verb := range tsSprintfVerb.FindAllString(format, -1) {
+		if len(converted) >= len(args) {
+			break
+		}
+		arg := args[len(converted)]
+		switch verb[len(verb)-1] {
+		case '%':
+			continue
+		case 'd', 'i', 'x', 'X', 'o', 'c', 'b':
+			converted = append(converted, tsToInt(arg))
+		case 'e', 'E', 'f', 'F', 'g', 'G':
+			converted = append(converted, tsToFloat(arg))
+		default:
+			converted = append(converted, tsToString(arg))
+		}
+	}
+	return fmt.Sprintf(strings.Replace(format, "%i", "%d", -1), converted...)
+}
+
+// tsBuiltins are the built-in functions available to simulated rules, by
+// lower case name.
+var tsBuiltins map[string]tsBuiltin
+
+func init() {
+	stringFunction := func(f func(string) string) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return f(tsArg(args, 0)), nil
+		}
+	}
+	testFunction := func(f func(string, string) bool) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsBool(f(tsArg(args, 0), tsArg(args, 1))), nil
+		}
+	}
+	ignoreCase := func(f func(string, string) bool) func(string, string) bool {
+		return func(s, t string) bool {
+			return f(strings.ToLower(s), strings.ToLower(t))
+		}
+	}
+	requestValue := func(f func(sim *tsSimulation) interface{}) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return f(in.sim), nil
+		}
+	}
+	// action records a call and applies its change to the simulation.
+	action := func(f func(sim *tsSimulation, args []interface{})) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			in.recordAction(node, args)
+			f(in.sim, args)
+			return "", nil
+		}
+	}
+	// finalAction is an action that ends the rule.
+	finalAction := func(f func(sim *tsSimulation, args []interface{})) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			in.recordAction(node, args)
+			f(in.sim, args)
+			return "", errTsRuleFinished
+		}
+	}
+	logFunction := func(level string) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			in.sim.logs = append(in.sim.logs, level+": "+tsArg(args, 0))
+			return "", nil
+		}
+	}
+	// modifyArgument changes the array or hash passed as the first
+	// argument in place, as array.push and hash.delete do.
+	modifyArgument := func(f func(value interface{}, args []interface{}) (interface{}, interface{}, error)) tsBuiltin {
+		return func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("missing argument")
+			}
+			updated, result, err := f(args[0], args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if target := node.children[0]; target.kind == "var" || target.kind == "index" {
+				if err := in.assign(target, updated); err != nil {
+					return nil, err
+				}
+			}
+			return result, nil
+		}
+	}
+	redirect := func(sim *tsSimulation, location string) {
+		sim.redirect = location
+		sim.responseCode = 302
+		sim.responseHeaders = tsHeaderSet(sim.responseHeaders, "Location", location)
+	}
+	rawURL := func(sim *tsSimulation) string {
+		if sim.query != "" {
+			return sim.path + "?" + sim.query
+		}
+		return sim.path
+	}
+	asArray := func(value interface{}) []interface{} {
+		if array, ok := value.([]interface{}); ok {
+			return append([]interface{}{}, array...)
+		}
+		return []interface{}{}
+	}
+	asHash := func(value interface{}) map[string]interface{} {
+		copied := map[string]interface{}{}
+		if hash, ok := value.(map[string]interface{}); ok {
+			for k, v := range hash {
+				copied[k] = v
+			}
+		}
+		return copied
+	}
+
+	tsBuiltins = map[string]tsBuiltin{
+		// Strings
+		"string.len": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return int64(len(tsArg(args, 0))), nil
+		},
+		"string.length": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return int64(len(tsArg(args, 0))), nil
+		},
+		"string.lowercase":   stringFunction(strings.ToLower),
+		"string.uppercase":   stringFunction(strings.ToUpper),
+		"string.trim":        stringFunction(strings.TrimSpace),
+		"string.ltrim":       stringFunction(func(s string) string { return strings.TrimLeft(s, " \t\r\n") }),
+		"string.rtrim":       stringFunction(func(s string) string { return strings.TrimRight(s, " \t\r\n") }),
+		"string.startswith":  testFunction(strings.HasPrefix),
+		"string.startswithi": testFunction(ignoreCase(strings.HasPrefix)),
+		"string.endswith":    testFunction(strings.HasSuffix),
+		"string.endswithi":   testFunction(ignoreCase(strings.HasSuffix)),
+		"string.contains":    testFunction(strings.Contains),
+		"string.containsi":   testFunction(ignoreCase(strings.Contains)),
+		"string.cmp": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return int64(strings.Compare(tsArg(args, 0), tsArg(args, 1))), nil
+		},
+		"string.icmp": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return int64(strings.Compare(strings.ToLower(tsArg(args, 0)), strings.ToLower(tsArg(args, 1)))), nil
+		},
+		"string.find": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			start := 0
+			if len(args) > 2 {
+				start = tsRangeStart(tsToInt(args[2]), len(s))
+			}
+			index := strings.Index(s[start:], tsArg(args, 1))
+			if index < 0 {
+				return int64(-1), nil
+			}
+			return int64(start + index), nil
+		},
+		"string.findi": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return int64(strings.Index(strings.ToLower(tsArg(args, 0)), strings.ToLower(tsArg(args, 1)))), nil
+		},
+		"string.count": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if tsArg(args, 1) == "" {
+				return int64(0), nil
+			}
+			return int64(strings.Count(tsArg(args, 0), tsArg(args, 1))), nil
+		},
+		// string.substring includes the character at the end index.
+		"string.substring": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			start := tsRangeStart(tsToInt(tsArg(args, 1)), len(s))
+			end := len(s)
+			if len(args) > 2 {
+				end = tsRangeStart(tsToInt(args[2]), len(s)) + 1
+				if end > len(s) {
+					end = len(s)
+				}
+			}
+			if end < start {
+				return "", nil
+			}
+			return s[start:end], nil
+		},
+		"string.left": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			return s[:tsRangeStart(tsToInt(tsArg(args, 1)), len(s))], nil
+		},
+		"string.right": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			return s[len(s)-tsRangeStart(tsToInt(tsArg(args, 1)), len(s)):], nil
+		},
+		"string.skip": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			return s[tsRangeStart(tsToInt(tsArg(args, 1)), len(s)):], nil
+		},
+		"string.drop": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			s := tsArg(args, 0)
+			return s[:len(s)-tsRangeStart(tsToInt(tsArg(args, 1)), len(s))], nil
+		},
+		"string.replace": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return strings.Replace(tsArg(args, 0), tsArg(args, 1), tsArg(args, 2), 1), nil
+		},
+		"string.replaceall": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return strings.Replace(tsArg(args, 0), tsArg(args, 1), tsArg(args, 2), -1), nil
+		},
+		"string.replacei": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(tsArg(args, 1)))
+			replaced := false
+			return re.ReplaceAllStringFunc(tsArg(args, 0), func(match string) string {
+				if replaced {
+					return match
+				}
+				replaced = true
+				return tsArg(args, 2)
+			}), nil
+		},
+		"string.replacealli": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(tsArg(args, 1)))
+			return re.ReplaceAllLiteralString(tsArg(args, 0), tsArg(args, 2)), nil
+		},
+		"string.split": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			var parts []string
+			if len(args) < 2 {
+				parts = strings.Fields(tsArg(args, 0))
+			} else {
+				parts = strings.Split(tsArg(args, 0), tsArg(args, 1))
+			}
+			array := make([]interface{}, len(parts))
+			for i, part := range parts {
+				array[i] = part
+			}
+			return array, nil
+		},
+		"string.repeat": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			count := tsToInt(tsArg(args, 1))
+			if count < 0 || count > tsMaxSteps {
+				return nil, fmt.Errorf("invalid count %d", count)
+			}
+			return strings.Repeat(tsArg(args, 0), int(count)), nil
+		},
+		// string.regexmatch sets $1 to $9 to the groups of the match.
+		"string.regexmatch": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			re, err := tsRegexp(tsArg(args, 1), tsArg(args, 2))
+			if err != nil {
+				return nil, err
+			}
+			groups := re.FindStringSubmatch(tsArg(args, 0))
+			if groups == nil {
+				return int64(0), nil
+			}
+			for i := 1; i <= 9; i++ {
+				in.scope[strconv.Itoa(i)] = ""
+				if i < len(groups) {
+					in.scope[strconv.Itoa(i)] = groups[i]
+				}
+			}
+			return int64(1), nil
+		},
+		// string.regexsub replaces the first match, or all of them with
+		// the "g" flag. "$1" in the replacement is the first group.
+		"string.regexsub": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			flags := tsArg(args, 3)
+			re, err := tsRegexp(tsArg(args, 1), flags)
+			if err != nil {
+				return nil, err
+			}
+			replacement := regexp.MustCompile(`\$([0-9])`).ReplaceAllString(tsArg(args, 2), "$${$1}")
+			s := tsArg(args, 0)
+			if strings.Contains(flags, "g") {
+				return re.ReplaceAllString(s, replacement), nil
+			}
+			match := re.FindStringSubmatchIndex(s)
+			if match == nil {
+				return s, nil
+			}
+			expanded := re.ExpandString(nil, replacement, s, match)
+			return s[:match[0]] + string(expanded) + s[match[1]:], nil
+		},
+		"string.urlencode": stringFunction(url.QueryEscape),
+		"string.urldecode": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return url.QueryUnescape(tsArg(args, 0))
+		},
+		"string.base64encode": stringFunction(func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }),
+		"string.base64decode": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			decoded, err := base64.StdEncoding.DecodeString(tsArg(args, 0))
+			return string(decoded), err
+		},
+		"string.bin2hex": stringFunction(func(s string) string { return hex.EncodeToString([]byte(s)) }),
+		"string.hex2bin": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			decoded, err := hex.DecodeString(tsArg(args, 0))
+			return string(decoded), err
+		},
+		"string.hashmd5": stringFunction(func(s string) string {
+			sum := md5.Sum([]byte(s))
+			return string(sum[:])
+		}),
+		"string.hashsha1": stringFunction(func(s string) string {
+			sum := sha1.Sum([]byte(s))
+			return string(sum[:])
+		}),
+		"string.hashsha256": stringFunction(func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return string(sum[:])
+		}),
+		"string.sprintf": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return "", nil
+			}
+			return tsSprintf(tsToString(args[0]), args[1:]), nil
+		},
+		"string.toint": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsToInt(tsArg(args, 0)), nil
+		},
+
+		// Types
+		"lang.toint": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsToInt(tsArg(args, 0)), nil
+		},
+		"lang.todouble": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsToFloat(tsArg(args, 0)), nil
+		},
+		"lang.tostring": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsArg(args, 0), nil
+		},
+		"lang.typeof": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return "string", nil
+			}
+			return tsTypeOf(args[0]), nil
+		},
+		"lang.isarray": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return int64(0), nil
+			}
+			_, ok := args[0].([]interface{})
+			return tsBool(ok), nil
+		},
+		"lang.ishash": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return int64(0), nil
+			}
+			_, ok := args[0].(map[string]interface{})
+			return tsBool(ok), nil
+		},
+
+		// Arrays and hashes
+		"array.length": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return int64(0), nil
+			}
+			return int64(len(asArray(args[0]))), nil
+		},
+		"array.join": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			parts := []string{}
+			if len(args) > 0 {
+				for _, item := range asArray(args[0]) {
+					parts = append(parts, tsToString(item))
+				}
+			}
+			return strings.Join(parts, tsArg(args, 1)), nil
+		},
+		"array.contains": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) > 1 {
+				for _, item := range asArray(args[0]) {
+					if tsToString(item) == tsToString(args[1]) {
+						return int64(1), nil
+					}
+				}
+			}
+			return int64(0), nil
+		},
+		"array.reverse": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			array := []interface{}{}
+			if len(args) > 0 {
+				array = asArray(args[0])
+			}
+			for i, j := 0, len(array)-1; i < j; i, j = i+1, j-1 {
+				array[i], array[j] = array[j], array[i]
+			}
+			return array, nil
+		},
+		"array.sort": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			array := []interface{}{}
+			if len(args) > 0 {
+				array = asArray(args[0])
+			}
+			sort.SliceStable(array, func(i, j int) bool { return tsToString(array[i]) < tsToString(array[j]) })
+			return array, nil
+		},
+		"array.push": modifyArgument(func(value interface{}, args []interface{}) (interface{}, interface{}, error) {
+			array := append(asArray(value), args...)
+			return array, int64(len(array)), nil
+		}),
+		"array.unshift": modifyArgument(func(value interface{}, args []interface{}) (interface{}, interface{}, error) {
+			array := append(append([]interface{}{}, args...), asArray(value)...)
+			return array, int64(len(array)), nil
+		}),
+		"array.pop": modifyArgument(func(value interface{}, args []interface{}) (interface{}, interface{}, error) {
+			array := asArray(value)
+			if len(array) == 0 {
+				return array, "", nil
+			}
+			return array[:len(array)-1], array[len(array)-1], nil
+		}),
+		"array.shift": modifyArgument(func(value interface{}, args []interface{}) (interface{}, interface{}, error) {
+			array := asArray(value)
+			if len(array) == 0 {
+				return array, "", nil
+			}
+			return array[1:], array[0], nil
+		}),
+		"hash.count": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return int64(0), nil
+			}
+			return int64(len(asHash(args[0]))), nil
+		},
+		"hash.keys": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			keys := []interface{}{}
+			if len(args) > 0 {
+				for _, key := range tsSortedKeys(asHash(args[0])) {
+					keys = append(keys, key)
+				}
+			}
+			return keys, nil
+		},
+		"hash.values": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			values := []interface{}{}
+			if len(args) > 0 {
+				hash := asHash(args[0])
+				for _, key := range tsSortedKeys(hash) {
+					values = append(values, hash[key])
+				}
+			}
+			return values, nil
+		},
+		"hash.contains": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			if len(args) < 2 {
+				return int64(0), nil
+			}
+			_, ok := asHash(args[0])[tsToString(args[1])]
+			return tsBool(ok), nil
+		},
+		"hash.delete": modifyArgument(func(value interface{}, args []interface{}) (interface{}, interface{}, error) {
+			hash := asHash(value)
+			removed := interface{}("")
+			if len(args) > 0 {
+				if old, ok := hash[tsToString(args[0])]; ok {
+					removed = old
+				}
+				delete(hash, tsToString(args[0]))
+			}
+			return hash, removed, nil
+		}),
+
+		// Request headers and URL
+		"http.getheader": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsHeaderGet(in.sim.headers, tsArg(args, 0)), nil
+		},
+		"http.getheaders": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			hash := map[string]interface{}{}
+			for name, value := range tsHeaderMap(in.sim.headers) {
+				hash[name] = value
+			}
+			return hash, nil
+		},
+		"http.getheadernames": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			names := []interface{}{}
+			for _, name := range sortedStringMapKeys(tsHeaderMap(in.sim.headers)) {
+				names = append(names, name)
+			}
+			return names, nil
+		},
+		"http.headerexists": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			for _, header := range in.sim.headers {
+				if strings.EqualFold(header[0], tsArg(args, 0)) {
+					return int64(1), nil
+				}
+			}
+			return int64(0), nil
+		},
+		"http.setheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.headers = tsHeaderSet(sim.headers, tsArg(args, 0), tsArg(args, 1))
+		}),
+		"http.addheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.headers = append(sim.headers, [2]string{tsArg(args, 0), tsArg(args, 1)})
+		}),
+		"http.removeheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.headers = tsHeaderRemove(sim.headers, tsArg(args, 0))
+		}),
+		"http.gethostheader": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsHeaderGet(in.sim.headers, "Host"), nil
+		},
+		"http.getmethod":      requestValue(func(sim *tsSimulation) interface{} { return sim.method }),
+		"http.getversion":     requestValue(func(sim *tsSimulation) interface{} { return sim.version }),
+		"http.getpath":        requestValue(func(sim *tsSimulation) interface{} { return sim.path }),
+		"http.getquerystring": requestValue(func(sim *tsSimulation) interface{} { return sim.query }),
+		"http.getrawurl":      requestValue(func(sim *tsSimulation) interface{} { return rawURL(sim) }),
+		"http.getbody":        requestValue(func(sim *tsSimulation) interface{} { return sim.body }),
+		"http.setpath": action(func(sim *tsSimulation, args []interface{}) {
+			sim.path = tsArg(args, 0)
+		}),
+		"http.setquerystring": action(func(sim *tsSimulation, args []interface{}) {
+			sim.query = tsArg(args, 0)
+		}),
+		"http.setrawurl": action(func(sim *tsSimulation, args []interface{}) {
+			parts := strings.SplitN(tsArg(args, 0), "?", 2)
+			sim.path, sim.query = parts[0], ""
+			if len(parts) > 1 {
+				sim.query = parts[1]
+			}
+		}),
+		"http.setbody": action(func(sim *tsSimulation, args []interface{}) {
+			sim.body = tsArg(args, 0)
+		}),
+		"http.getformparam": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			values, _ := url.ParseQuery(in.sim.query)
+			return values.Get(tsArg(args, 0)), nil
+		},
+		"http.getcookie": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			for _, header := range in.sim.headers {
+				if !strings.EqualFold(header[0], "Cookie") {
+					continue
+				}
+				for _, cookie := range strings.Split(header[1], ";") {
+					parts := strings.SplitN(strings.TrimSpace(cookie), "=", 2)
+					if len(parts) == 2 && parts[0] == tsArg(args, 0) {
+						return parts[1], nil
+					}
+				}
+			}
+			return "", nil
+		},
+
+		// Responses
+		"http.getresponseheader": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return tsHeaderGet(in.sim.responseHeaders, tsArg(args, 0)), nil
+		},
+		"http.setresponseheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.responseHeaders = tsHeaderSet(sim.responseHeaders, tsArg(args, 0), tsArg(args, 1))
+		}),
+		"http.addresponseheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.responseHeaders = append(sim.responseHeaders, [2]string{tsArg(args, 0), tsArg(args, 1)})
+		}),
+		"http.removeresponseheader": action(func(sim *tsSimulation, args []interface{}) {
+			sim.responseHeaders = tsHeaderRemove(sim.responseHeaders, tsArg(args, 0))
+		}),
+		"http.setresponsecode": action(func(sim *tsSimulation, args []interface{}) {
+			sim.responseCode = int(tsToInt(tsArg(args, 0)))
+		}),
+		"http.redirect": finalAction(func(sim *tsSimulation, args []interface{}) {
+			redirect(sim, tsArg(args, 0))
+		}),
+		"http.changesite": finalAction(func(sim *tsSimulation, args []interface{}) {
+			redirect(sim, strings.TrimSuffix(tsArg(args, 0), "/")+rawURL(sim))
+		}),
+		// http.sendResponse takes the status code, MIME type, body and
+		// any extra headers as "Name: value" lines.
+		"http.sendresponse": finalAction(func(sim *tsSimulation, args []interface{}) {
+			sim.responseCode = int(tsToInt(tsArg(args, 0)))
+			if mimeType := tsArg(args, 1); mimeType != "" {
+				sim.responseHeaders = tsHeaderSet(sim.responseHeaders, "Content-Type", mimeType)
+			}
+			sim.responseBody = tsArg(args, 2)
+			for _, line := range strings.Split(tsArg(args, 3), "\n") {
+				if parts := strings.SplitN(strings.TrimSpace(line), ":", 2); len(parts) == 2 {
+					sim.responseHeaders = append(sim.responseHeaders, [2]string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
+				}
+			}
+		}),
+
+		// Pools and connections
+		"pool.use": finalAction(func(sim *tsSimulation, args []interface{}) {
+			sim.pool = tsArg(args, 0)
+		}),
+		"pool.select": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			in.recordAction(node, args)
+			in.sim.pool = tsArg(args, 0)
+			return int64(1), nil
+		},
+		"connection.discard":       finalAction(func(sim *tsSimulation, args []interface{}) {}),
+		"connection.close":         finalAction(func(sim *tsSimulation, args []interface{}) {}),
+		"connection.getremoteip":   requestValue(func(sim *tsSimulation) interface{} { return sim.remoteIP }),
+		"connection.getremoteport": requestValue(func(sim *tsSimulation) interface{} { return int64(sim.remotePort) }),
+		"connection.getlocalip":    requestValue(func(sim *tsSimulation) interface{} { return sim.localIP }),
+		"connection.getlocalport":  requestValue(func(sim *tsSimulation) interface{} { return int64(sim.localPort) }),
+		"request.getremoteip":      requestValue(func(sim *tsSimulation) interface{} { return sim.remoteIP }),
+		"request.getremoteport":    requestValue(func(sim *tsSimulation) interface{} { return int64(sim.remotePort) }),
+		"request.getlocalip":       requestValue(func(sim *tsSimulation) interface{} { return sim.localIP }),
+		"request.getlocalport":     requestValue(func(sim *tsSimulation) interface{} { return int64(sim.localPort) }),
+		"event.emit":               action(func(sim *tsSimulation, args []interface{}) {}),
+
+		// Logging and time
+		"log.debug": logFunction("DEBUG"),
+		"log.info":  logFunction("INFO"),
+		"log.warn":  logFunction("WARN"),
+		"log.error": logFunction("ERROR"),
+		"log.emerg": logFunction("EMERG"),
+		"sys.time": func(in *tsInterpreter, node *tsNode, args []interface{}) (interface{}, error) {
+			return time.Now().Unix(), nil
+		},
+	}
+}
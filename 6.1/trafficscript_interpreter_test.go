@@ -0,0 +1,126 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func simulateTrafficScript(t *testing.T, source string, imports map[string]string, sim *tsSimulation) error {
+	program, err := parseTrafficScript(source)
+	if err != nil {
+		t.Fatalf("Parsing rule failed: %v", err)
+	}
+	parsedImports := map[string]*tsNode{}
+	for name, content := range imports {
+		parsed, err := parseTrafficScript(content)
+		if err != nil {
+			t.Fatalf("Parsing imported rule %s failed: %v", name, err)
+		}
+		parsedImports[name] = parsed
+	}
+	return runTrafficScript(program, parsedImports, sim)
+}
+
+func TestRunTrafficScript(t *testing.T) {
+	sim := &tsSimulation{
+		method:   "GET",
+		path:     "/old/page",
+		headers:  [][2]string{{"Host", "www.example.com"}, {"X-Debug", "1"}},
+		remoteIP: "192.0.2.1",
+	}
+	imports := map[string]string{"helpers": "sub enabled() { return 0; }"}
+	if err := simulateTrafficScript(t, testTrafficScript, imports, sim); err != nil {
+		t.Fatalf("Running rule failed: %v", err)
+	}
+	if sim.redirect != "/new/page" || sim.responseCode != 302 {
+		t.Errorf("Rule redirected to '%s' with %d, expected '/new/page' with 302", sim.redirect, sim.responseCode)
+	}
+	if expected := []string{`http.redirect("/new/page")`}; !reflect.DeepEqual(sim.actions, expected) {
+		t.Errorf("Rule made calls %v, expected %v", sim.actions, expected)
+	}
+
+	sim = &tsSimulation{
+		path:    "/index.html",
+		headers: [][2]string{{"Host", "www.example.com"}, {"X-Debug", "1"}},
+	}
+	if err := simulateTrafficScript(t, testTrafficScript, imports, sim); err != nil {
+		t.Fatalf("Running rule failed: %v", err)
+	}
+	expectedHeaders := map[string]string{"Host": "www.example.com", "X-Tag": "bigA"}
+	if headers := tsHeaderMap(sim.headers); !reflect.DeepEqual(headers, expectedHeaders) {
+		t.Errorf("Rule left headers %v, expected %v", headers, expectedHeaders)
+	}
+	expectedActions := []string{`http.removeHeader("X-Debug")`, `http.setHeader("X-Tag", "bigA")`}
+	if !reflect.DeepEqual(sim.actions, expectedActions) {
+		t.Errorf("Rule made calls %v, expected %v", sim.actions, expectedActions)
+	}
+	if sim.pool != "" || sim.redirect != "" {
+		t.Errorf("Rule chose pool '%s' and redirect '%s', expected neither", sim.pool, sim.redirect)
+	}
+
+	sim = &tsSimulation{path: "/api/users"}
+	imports["helpers"] = "sub enabled() { return 1; }"
+	if err := simulateTrafficScript(t, testTrafficScript, imports, sim); err != nil {
+		t.Fatalf("Running rule failed: %v", err)
+	}
+	if sim.pool != "api" {
+		t.Errorf("Rule chose pool '%s', expected 'api'", sim.pool)
+	}
+}
+
+func TestRunTrafficScriptValues(t *testing.T) {
+	rule := `
+		$ip = connection.getRemoteIP();
+		$parts = string.split($ip, ".");
+		array.push($parts, "x");
+		$h = [ "a" => 1 ];
+		$h["b"] = $h["a"] + 1;
+		hash.delete($h, "a");
+		$n = 0;
+		while (1) {
+			$n++;
+			if ($n % 2) { continue; }
+			if ($n >= 6) { break; }
+		}
+		$s = string.substring("abcdef", 1, 3) . string.sprintf(":%d:%s", "7", 8);
+		$s .= string.regexsub("a-b-c", "-", "+") . string.uppercase(string.regexsub("a-b-c", "(-)", "$1$1", "g"));
+		log.info(array.join($parts, "|") . " " . hash.count($h) . " " . $h["b"] . " " . $n . " " . $s);
+		log.info((10 / 4) . " " . (10 / 5) . " " . ("10" > 9) . " " . ("10" > "9") . " " . (!"" && 1));
+		pool.use("after");
+		log.info("not reached");
+	`
+	sim := &tsSimulation{remoteIP: "192.0.2.1"}
+	if err := simulateTrafficScript(t, rule, nil, sim); err != nil {
+		t.Fatalf("Running rule failed: %v", err)
+	}
+	expected := []string{"INFO: 192|0|2|1|x 1 2 6 bcd:7:8a+b-cA--B--C", "INFO: 2.5 2 1 0 1"}
+	if !reflect.DeepEqual(sim.logs, expected) {
+		t.Errorf("Rule logged %q, expected %q", sim.logs, expected)
+	}
+	if sim.pool != "after" {
+		t.Errorf("Rule chose pool '%s', expected 'after'", sim.pool)
+	}
+}
+
+func TestRunTrafficScriptErrors(t *testing.T) {
+	tables := []struct {
+		source string
+		err    string
+	}{
+		{"while (1) { }", "rule did not finish within"},
+		{"$x = 1 / 0;", "line 1, column 8: division by zero"},
+		{"geo.getCountry('192.0.2.1');", "line 1, column 1: function 'geo.getCountry' is not supported by the simulation"},
+		{"import missing;", "line 1, column 1: imported rule 'missing' was not provided"},
+		{"string.regexmatch('a', '(');", "line 1, column 1: string.regexmatch: error parsing regexp"},
+	}
+	for _, table := range tables {
+		err := simulateTrafficScript(t, table.source, nil, &tsSimulation{})
+		if err == nil || !strings.Contains(err.Error(), table.err) {
+			t.Errorf("Running %q gave error '%v', expected '%s'", table.source, err, table.err)
+		}
+	}
+}
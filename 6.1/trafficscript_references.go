@@ -0,0 +1,97 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tsReferenceFunctions are the built-in functions whose first argument
+// names a configuration object, with the type of that object.
+var tsReferenceFunctions = map[string]string{
+	"pool.use":                        "vtm_pool",
+	"pool.select":                     "vtm_pool",
+	"pool.activenodes":                "vtm_pool",
+	"pool.getnodeinfo":                "vtm_pool",
+	"pool.listactivenodes":            "vtm_pool",
+	"pool.listallnodes":               "vtm_pool",
+	"pool.listdisablednodes":          "vtm_pool",
+	"pool.listdrainingnodes":          "vtm_pool",
+	"resource.exists":                 "vtm_extra_file",
+	"resource.get":                    "vtm_extra_file",
+	"resource.getlength":              "vtm_extra_file",
+	"resource.getmd5":                 "vtm_extra_file",
+	"resource.getmtime":               "vtm_extra_file",
+	"rate.use":                        "vtm_rate",
+	"rate.use.noqueue":                "vtm_rate",
+	"rate.getbacklog":                 "vtm_rate",
+	"connection.setbandwidthclass":    "vtm_bandwidth",
+	"connection.setservicelevelclass": "vtm_service_level_monitor",
+}
+
+// tsReferenceTypes are the types of object a rule can refer to, in the
+// order they are reported. Imported rules are "vtm_rule".
+var tsReferenceTypes = []string{"vtm_pool", "vtm_rule", "vtm_extra_file", "vtm_rate", "vtm_bandwidth", "vtm_service_level_monitor"}
+
+// findTrafficScriptReferences returns the names of the objects a rule
+// refers to by type, sorted and without duplicates. Only names written as
+// literal strings can be found; the other calls that refer to objects are
+// returned as unresolved, with their position.
+func findTrafficScriptReferences(program *tsNode) (map[string][]string, []string) {
+	found := map[string]map[string]bool{}
+	for _, objectType := range tsReferenceTypes {
+		found[objectType] = map[string]bool{}
+	}
+	unresolved := []string{}
+
+	walkTrafficScript(program, func(node *tsNode) {
+		switch node.kind {
+		case "import":
+			found["vtm_rule"][node.value] = true
+		case "call":
+			objectType, ok := tsReferenceFunctions[strings.ToLower(node.value)]
+			if !ok || len(node.children) == 0 {
+				return
+			}
+			name, literal := tsLiteralString(node.children[0])
+			if !literal {
+				unresolved = append(unresolved, fmt.Sprintf("line %d, column %d: %s", node.pos.line, node.pos.column, node.value))
+			} else if name != "" {
+				found[objectType][name] = true
+			}
+		}
+	})
+
+	references := map[string][]string{}
+	for objectType, names := range found {
+		references[objectType] = []string{}
+		for name := range names {
+			references[objectType] = append(references[objectType], name)
+		}
+		sort.Strings(references[objectType])
+	}
+	return references, unresolved
+}
+
+// tsLiteralString returns the value of a string or number literal, or of
+// literals joined with ".".
+func tsLiteralString(node *tsNode) (string, bool) {
+	switch node.kind {
+	case "string", "number":
+		return node.value, true
+	case "binary":
+		if node.value != "." {
+			return "", false
+		}
+		left, ok := tsLiteralString(node.children[0])
+		if !ok {
+			return "", false
+		}
+		right, ok := tsLiteralString(node.children[1])
+		return left + right, ok
+	}
+	return "", false
+}
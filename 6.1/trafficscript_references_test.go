@@ -0,0 +1,42 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindTrafficScriptReferences(t *testing.T) {
+	program, err := parseTrafficScript(`
+		import common as c;
+		import "shared";
+		$page = resource.get("maintenance.html");
+		if (rate.use.noQueue("api_" . "limit")) {
+			Pool.Use("web");
+		}
+		connection.setBandwidthClass("slow");
+		pool.select($pool);
+		pool.use("web", "192.0.2.1", 80);
+		pool.use("backup");
+	`)
+	if err != nil {
+		t.Fatalf("Parsing rule failed: %v", err)
+	}
+	references, unresolved := findTrafficScriptReferences(program)
+	expected := map[string][]string{
+		"vtm_pool":                  {"backup", "web"},
+		"vtm_rule":                  {"common", "shared"},
+		"vtm_extra_file":            {"maintenance.html"},
+		"vtm_rate":                  {"api_limit"},
+		"vtm_bandwidth":             {"slow"},
+		"vtm_service_level_monitor": {},
+	}
+	if !reflect.DeepEqual(references, expected) {
+		t.Errorf("Found references %v, expected %v", references, expected)
+	}
+	if expected := []string{"line 9, column 3: pool.select"}; !reflect.DeepEqual(unresolved, expected) {
+		t.Errorf("Found unresolved references %v, expected %v", unresolved, expected)
+	}
+}
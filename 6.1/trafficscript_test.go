@@ -0,0 +1,136 @@
+// Copyright (C) 2018, Pulse Secure, LLC. 
+// Licensed under the terms of the MPL 2.0. See LICENSE file for details.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTrafficScript = `# Send API requests to their own pool
+import helpers as h;
+
+sub isApi($path) {
+	return string.startsWith($path, "/api/");
+}
+
+$path = http.getPath();
+$hosts = [ "www.example.com" => "web", 'api.example.com' => "api" ];
+if (isApi($path) && h.enabled()) {
+	pool.use("api");
+} else if (string.regexmatch($path, "^/old/(.*)$")) {
+	http.redirect("/new/" . $1);
+} else {
+	for ($i = 0; $i < 3; $i++) {
+		$count += $i * 2;
+	}
+	foreach ($name in http.getHeaderNames()) {
+		if ($name == "X-Debug") { http.removeHeader($name); break; }
+	}
+	$tag = $count > 2 ? "big" : 'small\n';
+	http.setHeader("X-Tag", $tag . "\x41");
+	HTTP.GETHEADER("Host");
+	$list[0] = -1;
+	do { $count--; } while ($count > 0);
+}
+`
+
+func TestParseTrafficScript(t *testing.T) {
+	program, err := parseTrafficScript(testTrafficScript)
+	if err != nil {
+		t.Fatalf("Parsing rule failed: %v", err)
+	}
+	kinds := []string{}
+	for _, statement := range program.children {
+		kinds = append(kinds, statement.kind)
+	}
+	if expected := []string{"import", "sub", "expr", "expr", "if"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Rule was parsed into %v, expected %v", kinds, expected)
+	}
+	if imported := program.children[0]; imported.value != "helpers" || imported.alias != "h" {
+		t.Errorf("Import was parsed as %s as %s", imported.value, imported.alias)
+	}
+	if sub := program.children[1]; sub.value != "isApi" || !reflect.DeepEqual(sub.params, []string{"path"}) {
+		t.Errorf("Subroutine was parsed as %s%v", sub.value, sub.params)
+	}
+	if hash := program.children[3].children[0].children[1]; hash.kind != "hash" || len(hash.children) != 4 {
+		t.Errorf("Hash literal was parsed as %s with %d children", hash.kind, len(hash.children))
+	}
+
+	calls := []string{}
+	walkTrafficScript(program, func(node *tsNode) {
+		if node.kind == "call" && node.value == "http.setHeader" {
+			calls = append(calls, node.children[1].children[1].value)
+		}
+	})
+	if len(calls) != 1 || calls[0] != "A" {
+		t.Errorf("String escapes were decoded as %q", calls)
+	}
+	if errors := checkTrafficScriptCalls(program); len(errors) != 0 {
+		t.Errorf("Checking function calls failed: %v", errors)
+	}
+}
+
+func TestParseTrafficScriptErrors(t *testing.T) {
+	tables := []struct {
+		source string
+		err    string
+	}{
+		{"TEST_TEXT", "line 1, column 1: unknown statement 'TEST_TEXT'"},
+		{"if (1) {\n  log.info('x');\n", "line 1, column 8: '{' is not closed"},
+		{"log.info('x');\n}", "line 2, column 1: unexpected '}' without a matching '{'"},
+		{"log.info(\"a\\qb\");", "line 1, column 12: invalid escape sequence '\\q' in string"},
+		{"log.info('x)", "line 1, column 10: unterminated string"},
+		{"$x = 1\n$y = 2;", "line 2, column 1: expected ';', found '$y'"},
+		{"elsif ($x) { }", "line 1, column 1: unknown statement 'elsif'"},
+		{"else { }", "line 1, column 1: 'else' without a matching 'if'"},
+		{"$x = (1 + 2;", "line 1, column 12: expected ')', found ';'"},
+		{"1 = $x;", "line 1, column 3: cannot assign to a number"},
+		{"if ($x) { sub f() { } }", "line 1, column 11: subroutines must be defined at the top level of a rule"},
+		{"$x = @;", "line 1, column 6: unexpected character '@'"},
+		{"foreach ($x of $list) { }", "line 1, column 13: expected 'in', found 'of'"},
+	}
+	for _, table := range tables {
+		_, err := parseTrafficScript(table.source)
+		if err == nil || err.Error() != table.err {
+			t.Errorf("Parsing %q gave error '%v', expected '%s'", table.source, err, table.err)
+		}
+	}
+}
+
+func TestTrafficScriptBuiltinsAreKnown(t *testing.T) {
+	for name := range tsBuiltins {
+		if !tsFunctions[strings.ToLower(name)] {
+			t.Errorf("Simulated function '%s' is not in the function table", name)
+		}
+	}
+}
+
+func TestCheckTrafficScriptCalls(t *testing.T) {
+	program, err := parseTrafficScript("http.getHeader('a');\nhttp.getHeaderz('a');\n  nosuch();\nimport lib;\nlib.anything();")
+	if err != nil {
+		t.Fatalf("Parsing rule failed: %v", err)
+	}
+	errors := checkTrafficScriptCalls(program)
+	expected := []string{"line 2, column 1: unknown function 'http.getHeaderz'", "line 3, column 3: unknown function 'nosuch'"}
+	if len(errors) != len(expected) {
+		t.Fatalf("Checking function calls gave %v, expected %v", errors, expected)
+	}
+	for i, err := range errors {
+		if err.Error() != expected[i] {
+			t.Errorf("Checking function calls gave '%v', expected '%s'", err, expected[i])
+		}
+	}
+
+	if ws, errors := validateTrafficScript("log.info('one');\n$h = [];\nhash.delete($h, 'a');", "content"); len(ws) != 0 || len(errors) != 0 {
+		t.Errorf("Valid rule gave warnings %v and errors %v", ws, errors)
+	}
+	if ws, errors := validateTrafficScript("log.inf('one');", "content"); len(ws) != 1 || len(errors) != 0 {
+		t.Errorf("Rule with an unknown function gave warnings %v and errors %v", ws, errors)
+	}
+	if ws, errors := validateTrafficScript("TEST_TEXT", "content"); len(ws) != 1 || len(errors) != 0 {
+		t.Errorf("Rule that does not parse gave warnings %v and errors %v", ws, errors)
+	}
+}
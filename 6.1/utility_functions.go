@@ -8,7 +8,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -34,28 +36,344 @@ func formatErrorInfo(errorInfo map[string]interface{}) string {
 	return errorMsg
 }
 
-func validateTableJson(tableStruct interface{}, requiredFields []string) schema.SchemaValidateFunc {
-	return func (i interface{}, k string) (s []string, es []error) {
-		err := json.Unmarshal(i.([]byte), tableStruct)
-		if err != nil {
-			es = append(es, fmt.Errorf("Failed to parse table JSON"))
+// validateTableJson checks the JSON representation of a table against the
+// schema of the table's rows, so that mistakes are reported with the row and
+// key at fault while planning rather than by the vTM when applying.
+func validateTableJson(resourceSchema func() map[string]*schema.Schema, tableName string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (s []string, es []error) {
+		rows := []interface{}{}
+		if err := json.Unmarshal([]byte(i.(string)), &rows); err != nil {
+			es = append(es, fmt.Errorf("%s: failed to parse table JSON: %v", k, err))
 			return
 		}
+		fields := resourceSchema()[tableName].Elem.(*schema.Resource).Schema
+		for index, row := range rows {
+			rowKey := fmt.Sprintf("%s[%d]", k, index)
+			rowMap, ok := row.(map[string]interface{})
+			if !ok {
+				es = append(es, fmt.Errorf("%s: expected a JSON object", rowKey))
+				continue
+			}
+			for _, field := range sortedSchemaKeys(fields) {
+				value, present := rowMap[field]
+				if !present {
+					if fields[field].Required {
+						es = append(es, fmt.Errorf("%s: missing required key %q", rowKey, field))
+					}
+					continue
+				}
+				es = append(es, validateTableValue(value, fields[field], rowKey+"."+field)...)
+			}
+			for _, key := range sortedMapKeys(rowMap) {
+				if _, ok := fields[key]; !ok {
+					es = append(es, fmt.Errorf("%s: unknown key %q, expected one of %s", rowKey, key, strings.Join(sortedSchemaKeys(fields), ", ")))
+				}
+			}
+		}
 		return
 	}
 }
 
-func suppressTableDiffs(tableName string) schema.SchemaDiffSuppressFunc {
-	return func (k, old, new string, d *schema.ResourceData) bool {
-		if _, ok := d.GetOk(tableName + "_json"); ok {
-			return true
+// validateTableValue checks the type of a value in a table row and then runs
+// the field's own validation, which covers enumerations and ranges.
+func validateTableValue(value interface{}, fieldSchema *schema.Schema, k string) []error {
+	switch fieldSchema.Type {
+	case schema.TypeBool:
+		if _, ok := value.(bool); !ok {
+			return []error{fmt.Errorf("%s: expected a boolean, got %v", k, value)}
+		}
+	case schema.TypeInt:
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) {
+			return []error{fmt.Errorf("%s: expected an integer, got %v", k, value)}
+		}
+		value = int(number)
+	case schema.TypeString:
+		if _, ok := value.(string); !ok {
+			return []error{fmt.Errorf("%s: expected a string, got %v", k, value)}
+		}
+	case schema.TypeList, schema.TypeSet:
+		list, ok := value.([]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a list of strings, got %v", k, value)}
+		}
+		for index, item := range list {
+			if _, ok := item.(string); !ok {
+				return []error{fmt.Errorf("%s[%d]: expected a string, got %v", k, index, item)}
+			}
+		}
+	}
+	if fieldSchema.ValidateFunc == nil {
+		return nil
+	}
+	_, es := fieldSchema.ValidateFunc(value, k)
+	return es
+}
+
+// suppressEquivalentTableJsonDiffs compares the JSON representation of a
+// table by its meaning rather than its text: key order and formatting are
+// ignored, keys that are left out take their default from the table's schema
+// and, for tables that are sets, the order of the rows does not matter.
+func suppressEquivalentTableJsonDiffs(resourceSchema func() map[string]*schema.Schema, tableName string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		table := resourceSchema()[tableName]
+		oldRows, oldErr := normalizeTableJson(old, table)
+		newRows, newErr := normalizeTableJson(new, table)
+		if oldErr != nil || newErr != nil {
+			return false
+		}
+		return reflect.DeepEqual(oldRows, newRows)
+	}
+}
+
+// normalizeTableJson returns the rows of a table's JSON representation in a
+// canonical form, each row encoded as JSON with every field of the table
+// present.
+func normalizeTableJson(tableJson string, table *schema.Schema) ([]string, error) {
+	rows := []map[string]interface{}{}
+	if err := json.Unmarshal([]byte(tableJson), &rows); err != nil {
+		return nil, err
+	}
+	fields := table.Elem.(*schema.Resource).Schema
+	normalized := make([]string, 0, len(rows))
+	for _, row := range rows {
+		for field, fieldSchema := range fields {
+			row[field] = normalizeTableValue(row[field], fieldSchema)
+		}
+		rowJson, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
 		}
+		normalized = append(normalized, string(rowJson))
+	}
+	if table.Type == schema.TypeSet {
+		sort.Strings(normalized)
+	}
+	return normalized, nil
+}
+
+func normalizeTableValue(value interface{}, fieldSchema *schema.Schema) interface{} {
+	if value == nil {
+		if fieldSchema.Default != nil {
+			return fieldSchema.Default
+		}
+		switch fieldSchema.Type {
+		case schema.TypeBool:
+			return false
+		case schema.TypeInt:
+			return 0
+		case schema.TypeFloat:
+			return 0.0
+		case schema.TypeString:
+			return ""
+		default:
+			return []interface{}{}
+		}
+	}
+	switch fieldSchema.Type {
+	case schema.TypeInt:
+		if number, ok := value.(float64); ok {
+			return int(number)
+		}
+	case schema.TypeSet:
+		if list, ok := value.([]interface{}); ok {
+			sorted := make([]string, 0, len(list))
+			for _, item := range list {
+				sorted = append(sorted, fmt.Sprintf("%v", item))
+			}
+			sort.Strings(sorted)
+			return sorted
+		}
+	}
+	return value
+}
+
+func suppressEquivalentJsonDiffs(k, old, new string, d *schema.ResourceData) bool {
+	var oldValue, newValue interface{}
+	if json.Unmarshal([]byte(old), &oldValue) != nil || json.Unmarshal([]byte(new), &newValue) != nil {
 		return false
 	}
+	return reflect.DeepEqual(oldValue, newValue)
+}
+
+// tableRowKeys returns the values of one field across the rows of a table,
+// given either as a set of blocks or as its JSON form. When listField is
+// set, each entry of that list in a row has its own key, "<value>/<entry>".
+func tableRowKeys(table, tableJson interface{}, keyField, listField string) map[string]bool {
+	rows := []interface{}{}
+	if set, ok := table.(*schema.Set); ok {
+		rows = append(rows, set.List()...)
+	}
+	if jsonString, ok := tableJson.(string); ok && jsonString != "" {
+		var jsonRows []interface{}
+		if json.Unmarshal([]byte(jsonString), &jsonRows) == nil {
+			rows = append(rows, jsonRows...)
+		}
+	}
+	keys := map[string]bool{}
+	for _, row := range rows {
+		for _, key := range tableRowEntryKeys(row, keyField, listField) {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// tableRowEntryKeys returns the keys of a table row, as a block or decoded
+// from JSON: its key field, or one key for each entry of its listField.
+func tableRowEntryKeys(row interface{}, keyField, listField string) []string {
+	fields, _ := row.(map[string]interface{})
+	key, ok := fields[keyField].(string)
+	if !ok {
+		return nil
+	}
+	if listField == "" {
+		return []string{key}
+	}
+	keys := []string{}
+	for _, entry := range tableRowList(fields[listField]) {
+		keys = append(keys, key+"/"+entry)
+	}
+	return keys
+}
+
+// tableRowList returns the entries of a list field of a table row, as a set,
+// a list of strings or a decoded JSON list.
+func tableRowList(value interface{}) []string {
+	switch list := value.(type) {
+	case *schema.Set:
+		return expandStringSet(list)
+	case []string:
+		return list
+	}
+	return jsonStringList(value)
+}
+
+// managedTableRowKeys returns the keys of the rows that a resource manages in
+// one of its tables, before or after the change being made.
+func managedTableRowKeys(d *schema.ResourceData, tableName, keyField, listField string) map[string]bool {
+	oldTable, newTable := d.GetChange(tableName)
+	oldJson, newJson := d.GetChange(tableName + "_json")
+	managed := tableRowKeys(oldTable, oldJson, keyField, listField)
+	for key := range tableRowKeys(newTable, newJson, keyField, listField) {
+		managed[key] = true
+	}
+	return managed
+}
+
+// unmanagedTableRows returns the decoded rows of a table, as held by the
+// traffic manager at the address current, whose keys are not in managed.
+// When listField is set, rows keep only the entries of that list that are
+// not managed, and rows with none left are dropped.
+func unmanagedTableRows(current interface{}, keyField, listField string, managed map[string]bool) []interface{} {
+	table, err := decodeJsonValue(current)
+	if err != nil {
+		return nil
+	}
+	unmanaged := []interface{}{}
+	for _, row := range jsonRows(table) {
+		fields, ok := row.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if listField == "" {
+			if key, ok := fields[keyField].(string); ok && !managed[key] {
+				unmanaged = append(unmanaged, row)
+			}
+			continue
+		}
+		entries := []string{}
+		for _, entry := range tableRowList(fields[listField]) {
+			if !managed[fmt.Sprintf("%v/%s", fields[keyField], entry)] {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) > 0 {
+			fields[listField] = entries
+			unmanaged = append(unmanaged, fields)
+		}
+	}
+	return unmanaged
+}
+
+// externalTableRows returns the decoded rows of a table, as held by the
+// traffic manager at the address current, that a resource with ignoreField
+// set should keep: those that neither were nor will be managed by the
+// resource itself. They are added back with addTableRows.
+func externalTableRows(d *schema.ResourceData, ignoreField, tableName, keyField, listField string, current interface{}) []interface{} {
+	if d.Get(ignoreField) != true {
+		return nil
+	}
+	return unmanagedTableRows(current, keyField, listField, managedTableRowKeys(d, tableName, keyField, listField))
+}
+
+// mergeTableRows returns a copy of the decoded rows with additions appended.
+// When listField is set, the entries of an addition whose key already has a
+// row are added to that row's list instead.
+func mergeTableRows(rows, additions []interface{}, keyField, listField string) []interface{} {
+	merged := append([]interface{}{}, rows...)
+	for _, addition := range additions {
+		fields, _ := addition.(map[string]interface{})
+		index := -1
+		if listField != "" {
+			for i, row := range merged {
+				if rowFields, ok := row.(map[string]interface{}); ok && rowFields[keyField] == fields[keyField] {
+					index = i
+				}
+			}
+		}
+		if index < 0 {
+			merged = append(merged, addition)
+			continue
+		}
+		row := map[string]interface{}{}
+		for field, value := range merged[index].(map[string]interface{}) {
+			row[field] = value
+		}
+		row[listField] = addMissingStrings(tableRowList(row[listField]), tableRowList(fields[listField]))
+		merged[index] = row
+	}
+	return merged
+}
+
+// removeTableRowEntry returns a copy of the decoded rows without the entry of
+// listField in the row with key, dropping the row if it has no entries left.
+func removeTableRowEntry(rows []interface{}, keyField, listField, key, entry string) []interface{} {
+	updated := []interface{}{}
+	for _, row := range rows {
+		fields, ok := row.(map[string]interface{})
+		if ok && fields[keyField] == key {
+			entries := removeString(tableRowList(fields[listField]), entry)
+			if len(entries) == 0 {
+				continue
+			}
+			copied := map[string]interface{}{}
+			for field, value := range fields {
+				copied[field] = value
+			}
+			copied[listField] = entries
+			row = copied
+		}
+		updated = append(updated, row)
+	}
+	return updated
+}
+
+// addTableRows adds decoded rows to the table at the address target, for
+// example &object.Basic.Permissions, as mergeTableRows does.
+func addTableRows(target interface{}, rows []interface{}, keyField, listField string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	table, err := decodeJsonValue(target)
+	if err != nil {
+		return err
+	}
+	return setJsonValue(target, mergeTableRows(jsonRows(table), rows, keyField, listField))
 }
 
 func suppressHashedDiffs(fieldName string) schema.SchemaDiffSuppressFunc {
-	return func (k, old, new string, d *schema.ResourceData) bool {
+	return func(k, old, new string, d *schema.ResourceData) bool {
 		fieldValue := d.Get(fieldName)
 		fieldValueHash := sha256.New()
 		fieldValueHash.Write([]byte(fieldValue.(string)))
@@ -66,6 +384,159 @@ func suppressHashedDiffs(fieldName string) schema.SchemaDiffSuppressFunc {
 	}
 }
 
+// addSectionAliases adds the flattened fields that a nested section block
+// replaced, such as "auto_scaling_enabled" for "auto_scaling.0.enabled", as
+// deprecated aliases of the block's fields, so that configurations written
+// before the blocks existed keep working.
+func addSectionAliases(fields map[string]*schema.Schema, prefix, section string) {
+	for field, fieldSchema := range fields[section].Elem.(*schema.Resource).Schema {
+		if _, ok := fields[prefix+"_"+field]; ok {
+			continue
+		}
+		alias := *fieldSchema
+		alias.Required = false
+		alias.Optional = true
+		alias.Deprecated = fmt.Sprintf("use the %s block instead", section)
+		alias.ConflictsWith = []string{section}
+		fields[prefix+"_"+field] = &alias
+	}
+}
+
+// sectionInUse reports whether a nested section block is configured, or is
+// in the state. Otherwise its settings are held by its flattened aliases.
+func sectionInUse(d *schema.ResourceData, section string) bool {
+	sections, ok := d.Get(section).([]interface{})
+	return ok && len(sections) > 0 && sections[0] != nil
+}
+
+// setSectionDefaults fills in a nested section block from its flattened
+// aliases when the block is not configured, so that "section.0.field"
+// lookups return the aliases' values, or the defaults that reset the
+// section on the traffic manager. The returned function empties the block
+// again once the fields have been assigned, so that it is not kept in the
+// state.
+func setSectionDefaults(d *schema.ResourceData, section, prefix string, fields map[string]*schema.Schema) func() {
+	if sectionInUse(d, section) {
+		return func() {}
+	}
+	values := make(map[string]interface{})
+	for field := range fields {
+		values[field] = d.Get(prefix + "_" + field)
+	}
+	d.Set(section, []map[string]interface{}{values})
+	return func() {
+		d.Set(section, []map[string]interface{}{})
+	}
+}
+
+// setSection sets a nested section block, or its flattened aliases, read
+// from the traffic manager. The form that is in the state is set, and the
+// other is reset to its defaults so that it has no diff. When neither is in
+// the state, as after an import, the aliases are set. A block that only
+// holds default values is left out while it is not in the state, so that
+// configurations without the block have no diff. Any other values are set,
+// so that removing the block from the configuration plans the change that
+// resets them.
+func setSection(d *schema.ResourceData, section, prefix string, values map[string]interface{}, fields map[string]*schema.Schema) {
+	inUse := sectionInUse(d, section)
+	for field, fieldSchema := range fields {
+		if inUse {
+			d.Set(prefix+"_"+field, sectionFieldDefault(fieldSchema))
+		} else {
+			d.Set(prefix+"_"+field, values[field])
+		}
+	}
+	if inUse {
+		d.Set(section, []map[string]interface{}{sectionSetValues(values, fields)})
+	}
+}
+
+// sectionSetValues converts the slices read for set fields of a section into
+// sets, as Terraform can only write sets within a block from a *schema.Set.
+func sectionSetValues(values map[string]interface{}, fields map[string]*schema.Schema) map[string]interface{} {
+	converted := make(map[string]interface{}, len(values))
+	for field, value := range values {
+		converted[field] = value
+		fieldSchema, ok := fields[field]
+		list := reflect.ValueOf(value)
+		if !ok || fieldSchema.Type != schema.TypeSet || list.Kind() != reflect.Slice {
+			continue
+		}
+		set := fieldSchema.ZeroValue().(*schema.Set)
+		for i := 0; i < list.Len(); i++ {
+			set.Add(list.Index(i).Interface())
+		}
+		converted[field] = set
+	}
+	return converted
+}
+
+// setSectionFromAliases sets a nested section block from its flattened
+// aliases after a read, for data sources and exports, which offer the
+// block form. A block that only holds default values is left out.
+func setSectionFromAliases(d *schema.ResourceData, section, prefix string, fields map[string]*schema.Schema) {
+	values := make(map[string]interface{})
+	isDefault := true
+	for field, fieldSchema := range fields {
+		values[field] = d.Get(prefix + "_" + field)
+		if !isDefaultValue(fieldSchema, values[field]) {
+			isDefault = false
+		}
+	}
+	if isDefault {
+		return
+	}
+	d.Set(section, []map[string]interface{}{values})
+}
+
+// setSectionsFromAliases sets every nested section block of a resource from
+// its flattened aliases.
+func setSectionsFromAliases(resourceType string, d *schema.ResourceData) {
+	resource, ok := sectionedResources[resourceType]
+	if !ok {
+		return
+	}
+	resourceSchema := resource.schema()
+	for prefix, section := range resource.sections {
+		setSectionFromAliases(d, section, prefix, resourceSchema[section].Elem.(*schema.Resource).Schema)
+	}
+}
+
+// sectionFieldDefault returns the value a field of a section is reset to.
+func sectionFieldDefault(fieldSchema *schema.Schema) interface{} {
+	if fieldSchema.Default != nil {
+		return fieldSchema.Default
+	}
+	return fieldSchema.ZeroValue()
+}
+
+func sortedSchemaKeys(fields map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedMapKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStringMapKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func getStringAddr(target string) *string {
 	return &target
 }
@@ -127,6 +598,91 @@ func expandStringList(interfaceList []interface{}) []string {
 	return strList
 }
 
+// managedListEntries returns the entries of a list that a resource manages:
+// all of them, unless the resource's ignoreField is set, in which case only
+// those already in its state.
+func managedListEntries(d *schema.ResourceData, ignoreField, field string, values []string) []string {
+	if d.Get(ignoreField) != true {
+		return values
+	}
+	managed := map[string]bool{}
+	for _, value := range expandStringList(d.Get(field).([]interface{})) {
+		managed[value] = true
+	}
+	filtered := make([]string, 0, len(values))
+	for _, value := range values {
+		if managed[value] {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
+
+// mergeExternalListEntries adds the entries of the current list that the
+// resource neither did nor will manage to its configured list.
+func mergeExternalListEntries(d *schema.ResourceData, field string, configured, current []string) []string {
+	oldValues, newValues := d.GetChange(field)
+	managed := map[string]bool{}
+	for _, value := range append(expandStringList(oldValues.([]interface{})), expandStringList(newValues.([]interface{}))...) {
+		managed[value] = true
+	}
+	return mergeExternalEntries(configured, current, managed)
+}
+
+// mergeExternalEntries adds the entries of current that are not managed to
+// the configured list. Each one is placed after the entry that preceded it in
+// current, so that the relative order of all entries is kept.
+func mergeExternalEntries(configured, current []string, managed map[string]bool) []string {
+	merged := append([]string{}, configured...)
+	position := 0
+	for _, value := range current {
+		if managed[value] {
+			if index := indexOfString(merged, value); index >= 0 {
+				position = index + 1
+			}
+			continue
+		}
+		merged = append(merged, "")
+		copy(merged[position+1:], merged[position:])
+		merged[position] = value
+		position++
+	}
+	return merged
+}
+
+// indexOfString returns the index of value in values, or -1.
+func indexOfString(values []string, value string) int {
+	for i, candidate := range values {
+		if candidate == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeString returns a copy of values without any occurrence of value.
+func removeString(values []string, value string) []string {
+	remaining := make([]string, 0, len(values))
+	for _, candidate := range values {
+		if candidate != value {
+			remaining = append(remaining, candidate)
+		}
+	}
+	return remaining
+}
+
+// addMissingStrings returns a copy of values with the entries of additions
+// that it does not already contain appended.
+func addMissingStrings(values, additions []string) []string {
+	result := append([]string{}, values...)
+	for _, value := range additions {
+		if indexOfString(result, value) < 0 {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
 func expandStringSet(set *schema.Set) []string {
 	itemList := set.List()
 	strList := make([]string, 0, len(itemList))